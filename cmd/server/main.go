@@ -7,14 +7,18 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "github.com/ridwanfathin/invoice-processor-service/docs"
 	"github.com/ridwanfathin/invoice-processor-service/internal/config"
 	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
 	"github.com/ridwanfathin/invoice-processor-service/internal/database"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 	"github.com/ridwanfathin/invoice-processor-service/internal/handler"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
 	"github.com/ridwanfathin/invoice-processor-service/internal/middleware"
 	"github.com/ridwanfathin/invoice-processor-service/internal/mlxclient"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
 	"github.com/ridwanfathin/invoice-processor-service/internal/openrouter"
 	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
 	"github.com/ridwanfathin/invoice-processor-service/internal/server"
@@ -62,12 +66,17 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	log.Printf("Configuration: %s", cfg.Summary())
 
 	// Initialize OpenRouter client for receipt processing
 	openRouterClient := openrouter.NewClient(&openrouter.Config{
 		APIKey:            cfg.OpenRouterAPIKey,
 		ModelID:           cfg.OpenRouterModelID,
 		Timeout:           cfg.OpenRouterTimeout,
+		MaxResponseBytes:  cfg.OpenRouterMaxResponseBytes,
 		S3Endpoint:        cfg.SupabaseS3Endpoint,
 		S3AccessKeyID:     cfg.SupabaseAccessKeyID,
 		S3AccessKeySecret: cfg.SupabaseAccessKeySecret,
@@ -76,11 +85,11 @@ func main() {
 	})
 
 	// Initialize S3 uploader for image storage
-	var s3Uploader *storage.S3Uploader
+	// Left as a nil interface when unavailable, so the service sees storage as disabled
+	var imageStore service.ImageStore
 	if cfg.SupabaseS3Endpoint != "" {
 		log.Println("Initializing S3 uploader...")
-		var err error
-		s3Uploader, err = storage.NewS3Uploader(&storage.Config{
+		s3Uploader, err := storage.NewS3Uploader(&storage.Config{
 			Endpoint:        cfg.SupabaseS3Endpoint,
 			AccessKeyID:     cfg.SupabaseAccessKeyID,
 			AccessKeySecret: cfg.SupabaseAccessKeySecret,
@@ -89,11 +98,13 @@ func main() {
 		})
 		if err != nil {
 			log.Printf("Warning: Failed to initialize S3 uploader: %v", err)
+		} else {
+			imageStore = s3Uploader
 		}
 	}
 
 	// Initialize MLX client if enabled
-	var mlxClient *mlxclient.Client
+	var mlxClient service.URLInvoiceExtractor
 	if cfg.UseMLXService {
 		log.Println("MLX service is enabled, initializing MLX client...")
 		mlxClient = mlxclient.NewClient(&mlxclient.Config{
@@ -107,11 +118,13 @@ func main() {
 	var db *database.PostgresDB
 	var receiptRepo repository.ReceiptRepository
 	var userRepo repository.UserRepository
-
-	// Require database connection - exit if not available
-	if cfg.PostgresDBURL == "" {
-		log.Fatalf("Error: PostgreSQL database URL not configured. Please set POSTGRES_DB_URL environment variable")
-	}
+	var merchantRuleRepo repository.MerchantRuleRepository
+	var organizationRepo repository.OrganizationRepository
+	var receiptViewRepo repository.ReceiptViewRepository
+	var activityRepo repository.ActivityRepository
+	var backfillRepo repository.BackfillRepository
+	var retentionRepo repository.RetentionRepository
+	var feedbackRepo repository.ExtractionFeedbackRepository
 
 	log.Println("Initializing database connection...")
 	db, err = database.NewPostgresDB()
@@ -122,11 +135,91 @@ func main() {
 	defer db.Close()
 	receiptRepo = repository.NewPostgresReceiptRepository(db.GetPool())
 	userRepo = repository.NewPostgresUserRepository(db.GetPool())
+	merchantRuleRepo = repository.NewPostgresMerchantRuleRepository(db.GetPool())
+	organizationRepo = repository.NewPostgresOrganizationRepository(db.GetPool())
+	receiptViewRepo = repository.NewPostgresReceiptViewRepository(db.GetPool())
+	activityRepo = repository.NewPostgresActivityRepository(db.GetPool())
+	backfillRepo = repository.NewPostgresBackfillRepository(db.GetPool())
+	retentionRepo = repository.NewPostgresRetentionRepository(db.GetPool())
+	if cfg.ExtractionFeedback {
+		feedbackRepo = repository.NewPostgresExtractionFeedbackRepository(db.GetPool())
+	}
 	log.Println("Successfully connected to PostgreSQL database.")
 
+	// Configure money precision and rounding
+	moneyPolicy, err := money.NewPolicy(cfg.MoneyPrecision, cfg.MoneyRoundingMode)
+	if err != nil {
+		log.Fatalf("Invalid money configuration: %v", err)
+	}
+
+	// Keep uploaded images in their own format unless a storage format is configured
+	imageStorage := imageutil.DefaultConfig()
+	imageStorage.Quality = cfg.ImageJPEGQuality
+	if cfg.ImageStorageFormat != "original" {
+		imageStorage.OutputFormat = cfg.ImageStorageFormat
+	}
+
+	// Initialize currency client
+	log.Println("Initializing currency client...")
+	currencyClient := currency.NewClient(cfg.CurrencyAPIBaseURL)
+	currencyClient.SetRounding(moneyPolicy.Rounding)
+	if cfg.CurrencyPrefetch {
+		// Stops with the server when the shutdown signal cancels ctx
+		log.Println("Starting currency rate prefetch...")
+		currencyClient.StartPrefetch(ctx)
+	}
+
 	// Initialize services
 	log.Println("Initializing services...")
-	receiptService := service.NewReceiptService(receiptRepo, openRouterClient, mlxClient, s3Uploader, cfg.UseMLXService, cfg.MaxWorkers)
+	receiptService := service.NewReceiptService(service.ReceiptServiceConfig{
+		Repository:             receiptRepo,
+		MerchantRuleRepository: merchantRuleRepo,
+		OrganizationRepository: organizationRepo,
+		ReceiptViewRepository:  receiptViewRepo,
+		ActivityRepository:     activityRepo,
+		UserRepository:         userRepo,
+		FeedbackRepository:     feedbackRepo,
+		OpenAIClient:           openRouterClient,
+		MLXClient:              mlxClient,
+		S3Uploader:             imageStore,
+		ImageStorage:           imageStorage,
+		CurrencyCatalog:        currencyClient,
+		CurrencyConverter:      currencyClient,
+		UseMLXService:          cfg.UseMLXService,
+		MLXFallback:            cfg.MLXFallback,
+		InlineImages:           cfg.OpenRouterInlineImages,
+		KeepFailedScanImages:   cfg.KeepFailedScanImages,
+		ScanDeadline:           cfg.ScanDeadline,
+		MaxScanPages:           cfg.ScanMaxPages,
+		MaxScanWorkers:         cfg.MaxScanWorkers,
+		AnomalyZScoreThreshold: cfg.AnomalyZScoreThreshold,
+		MinConfidenceAutosave:  cfg.MinConfidenceAutosave,
+		StrictCategories:       cfg.StrictCategories,
+		MoneyPolicy:            moneyPolicy,
+		CurrencyRounding:       cfg.ScanCurrencyRounding,
+		MonthlyScanQuota:       cfg.MonthlyScanQuota,
+		ItemNameRules: &domain.ItemNameRules{
+			StripQuantities: cfg.ItemNameStripQuantities,
+			IgnoredWords:    cfg.ItemNameIgnoredWords,
+		},
+	})
+
+	merchantRuleService := service.NewMerchantRuleService(merchantRuleRepo)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo)
+	backfillService := service.NewBackfillService(backfillRepo)
+
+	if cfg.RetentionEnabled {
+		// Stops with the server when the shutdown signal cancels ctx
+		log.Printf("Starting receipt retention job (receipts: %d days, images: %d days, dry run: %t)...",
+			cfg.RetentionReceiptDays, cfg.RetentionImageDays, cfg.RetentionDryRun)
+		retentionService := service.NewRetentionService(retentionRepo, imageStore, domain.RetentionPolicy{
+			ReceiptMaxAge: time.Duration(cfg.RetentionReceiptDays) * 24 * time.Hour,
+			ImageMaxAge:   time.Duration(cfg.RetentionImageDays) * 24 * time.Hour,
+			BatchSize:     cfg.RetentionBatchSize,
+			DryRun:        cfg.RetentionDryRun,
+		}, nil)
+		retentionService.Start(ctx, cfg.RetentionInterval)
+	}
 
 	authService := service.NewAuthService(service.AuthServiceConfig{
 		UserRepo:              userRepo,
@@ -138,18 +231,40 @@ func main() {
 		JWTSecret:             cfg.JWTSecret,
 		JWTAccessExpiration:   cfg.JWTAccessExpiration,
 		JWTRefreshExpiration:  cfg.JWTRefreshExpiration,
+		JWTIssuer:             cfg.JWTIssuer,
+		JWTAudience:           cfg.JWTAudience,
+		PasswordPolicy: service.PasswordPolicy{
+			MinLength:       cfg.PasswordMinLength,
+			RequiredClasses: cfg.PasswordRequiredClasses,
+		},
+		LoginLockout: service.LoginLockoutPolicy{
+			MaxFailedAttempts: cfg.LoginMaxFailedAttempts,
+			Duration:          cfg.LoginLockoutDuration,
+		},
+		UserCacheSize: cfg.UserCacheSize,
+		UserCacheTTL:  cfg.UserCacheTTL,
 	})
 
-	// Initialize currency client
-	log.Println("Initializing currency client...")
-	currencyClient := currency.NewClient()
+	userExportService := service.NewUserExportService(authService, receiptRepo, cfg.ExportMaxReceipts)
+	emailIngestService := service.NewEmailIngestService(userRepo, receiptService, cfg.EmailIngestDomain)
 
 	// Initialize handlers
 	log.Println("Initializing API handlers...")
 	receiptHandler := handler.NewReceiptHandler(receiptService)
 	authHandler := handler.NewAuthHandler(authService, cfg.FrontendURL)
 	currencyHandler := handler.NewCurrencyHandler(currencyClient)
-	analyticsHandler := handler.NewAnalyticsHandler(receiptRepo, currencyClient)
+	merchantRuleHandler := handler.NewMerchantRuleHandler(merchantRuleService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService)
+	analyticsHandler := handler.NewAnalyticsHandler(receiptRepo, currencyClient, moneyPolicy)
+	adminHandler := handler.NewAdminHandler(backfillService, receiptService, authService)
+	userExportHandler := handler.NewUserExportHandler(userExportService)
+	emailIngestHandler := handler.NewEmailIngestHandler(emailIngestService, int64(cfg.EmailIngestMaxBytes), cfg.EmailIngestMaxFiles)
+	featureHandler := handler.NewFeatureHandler(cfg.Features())
+	exportRateLimit := middleware.NewRateLimiter(cfg.ExportRateLimit, time.Hour)
+	emailIngestRateLimit := middleware.RateLimit(cfg.EmailIngestRateLimit, time.Minute)
+	usageHandler := handler.NewUsageHandler(receiptService, map[string]handler.RateLimitReporter{
+		"export": exportRateLimit,
+	})
 
 	// Create and configure server
 	log.Println("Configuring server...")
@@ -161,12 +276,28 @@ func main() {
 
 	// Create auth middleware
 	authMiddleware := middleware.AuthMiddleware(authService)
+	adminMiddleware := middleware.AdminOnly(authService)
 
 	// Register API routes
 	receiptHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware)
 	authHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware)
+	merchantRuleHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware)
+	organizationHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware)
 	currencyHandler.RegisterCurrencyRoutes(appServer.GetRouter().Group("/v1"))
 	analyticsHandler.RegisterAnalyticsRoutes(appServer.GetRouter().Group("/v1"), authMiddleware)
+	adminHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware, adminMiddleware)
+	if cfg.ScanDebugEnabled {
+		adminHandler.RegisterScanDebugRoute(appServer.GetRouter(), authMiddleware, adminMiddleware)
+	}
+	userExportHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware, exportRateLimit.Handler())
+	usageHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware)
+	emailIngestHandler.RegisterRoutes(appServer.GetRouter(), authMiddleware, emailIngestRateLimit)
+	featureHandler.RegisterRoutes(appServer.GetRouter())
+
+	// Give the registered scan routes the scan request timeout
+	appServer.SetScanRoutes(receiptHandler.ScanRoutes()...)
+	appServer.SetScanRoutes(adminHandler.ScanRoutes()...)
+	appServer.SetScanRoutes(emailIngestHandler.ScanRoutes()...)
 
 	// Start server in a goroutine so we can handle shutdown gracefully
 	serverErr := make(chan error, 1)
@@ -183,7 +314,7 @@ func main() {
 		}
 	case <-ctx.Done():
 		// Shutdown requested, stop the server gracefully
-		log.Println("Shutting down server...")
+		log.Printf("Shutting down server, waiting up to %s for in-flight requests...", cfg.ShutdownTimeout)
 		if err := appServer.Shutdown(); err != nil {
 			log.Printf("Server shutdown error: %v", err)
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,14 +13,28 @@ import (
 // Config holds all configuration for the application
 type Config struct {
 	// Server configuration
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port               int
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	RequestTimeout     time.Duration // Deadline for handling a single request
+	ScanRequestTimeout time.Duration // Deadline for receipt scan requests
+	ScanDeadline       time.Duration // Extraction time after which a multi-page scan skips its remaining pages, 0 disables it
+	ScanMaxPages       int           // Page images a single scan may upload
+	ShutdownTimeout    time.Duration // Time allowed for in-flight requests and scans to finish on shutdown
+
+	// CORS configuration
+	CORSAllowedOrigins   []string      // Origins allowed to call the API from a browser, "*" allows any
+	CORSAllowCredentials bool          // Allow credentialed requests from explicitly listed origins
+	CORSMaxAge           time.Duration // How long browsers may cache preflight responses
 
 	// OpenRouter configuration
 	OpenRouterAPIKey  string
 	OpenRouterModelID string
 	OpenRouterTimeout time.Duration
+	// Largest model response accepted, in bytes; larger responses are rejected before parsing
+	OpenRouterMaxResponseBytes int
+	// Send receipt images to OpenRouter inline as base64 data URLs instead of uploading them to S3
+	OpenRouterInlineImages bool
 
 	// Supabase S3-compatible storage configuration
 	SupabaseS3Endpoint      string
@@ -33,14 +48,42 @@ type Config struct {
 	UseMLXService bool
 	MLXServiceURL string
 	MLXTimeout    time.Duration
+	MLXFallback   bool // Retry failed MLX extractions with OpenRouter
+
+	KeepFailedScanImages bool // Keep images uploaded by scans that fail instead of deleting them
 
 	// Application configuration
-	MaxWorkers  int
-	APIBasePath string
+	MaxWorkers            int
+	MaxScanWorkers        int // Receipt extractions (model calls) running at once, defaults to MaxWorkers
+	APIBasePath           string
+	MinConfidenceAutosave float64 // Minimum extraction confidence to auto-verify a scanned receipt, 0 disables
+	StrictCategories      bool    // Reject item categories outside the category taxonomy
+	ScanDebugEnabled      bool    // Expose the admin scan diagnostics endpoint
+	ExtractionFeedback    bool    // Record anonymous scan accuracy feedback from users who opt in
+
+	// Image storage configuration
+	ImageStorageFormat string // "original", "jpeg" or "png"
+	ImageJPEGQuality   int    // JPEG quality 1-100 when storing as JPEG
+
+	// Money configuration
+	MoneyPrecision       int    // Decimal places kept for internal money math
+	MoneyRoundingMode    string // "half_up", "half_even" or "down"
+	ScanCurrencyRounding bool   // Round scanned amounts to the decimal places of their currency
+
+	// Insights configuration
+	AnomalyZScoreThreshold  float64  // Standard deviations above baseline that flag a spending anomaly
+	ItemNameStripQuantities bool     // Group item names ignoring sizes and strengths such as "2%" or "500ml"
+	ItemNameIgnoredWords    []string // Words ignored when grouping item names
+
+	// Currency configuration
+	CurrencyPrefetch   bool   // Refresh recently used exchange rates in the background before they expire
+	CurrencyAPIBaseURL string // Frankfurter-compatible API base URL, including the version path
 
 	// Logging configuration
-	LogFormat string // "json" or "pretty"
-	LogLevel  string // "debug", "info", "warn", "error"
+	LogFormat         string   // "json" or "pretty"
+	LogLevel          string   // "debug", "info", "warn", "error"
+	LogExcludePaths   []string // Request paths not logged, a trailing "*" matches a prefix
+	LogExcludedErrors bool     // Still log 5xx responses on excluded paths
 
 	// Authentication configuration
 	GoogleClientIDWeb     string // Web OAuth client (for future web support)
@@ -51,7 +94,42 @@ type Config struct {
 	JWTSecret             string
 	JWTAccessExpiration   time.Duration
 	JWTRefreshExpiration  time.Duration
+	JWTIssuer             string // iss claim of issued tokens, required when validating; empty skips the check
+	JWTAudience           string // aud claim of issued tokens, required when validating; empty skips the check
 	FrontendURL           string
+
+	// Data export configuration
+	ExportRateLimit   int // Data exports allowed per user per hour, 0 disables the limit
+	ExportMaxReceipts int // Receipts a single export may include, 0 disables the cap
+
+	// Usage limits
+	MonthlyScanQuota int // Receipt scans allowed per user per calendar month, 0 disables the quota
+
+	// Email forwarding configuration
+	EmailIngestDomain    string // Domain of users' forwarding addresses, as in receipts+<token>@domain; empty reports only the token
+	EmailIngestRateLimit int    // Inbound emails accepted per client IP per minute, 0 disables the limit
+	EmailIngestMaxBytes  int    // Largest inbound email request body accepted, in bytes
+	EmailIngestMaxFiles  int    // Attachments accepted per inbound email
+
+	// Retention configuration
+	RetentionEnabled     bool          // Run the retention job that deletes old receipts and images
+	RetentionReceiptDays int           // Receipts older than this many days are deleted with their images, 0 keeps them
+	RetentionImageDays   int           // Stored images of receipts older than this many days are deleted, 0 keeps them
+	RetentionDryRun      bool          // Only log what the retention job would delete
+	RetentionBatchSize   int           // Receipts handled per retention batch
+	RetentionInterval    time.Duration // Time between retention runs
+
+	// Password policy configuration
+	PasswordMinLength       int
+	PasswordRequiredClasses []string // Any of "letter", "lower", "upper", "digit", "symbol"
+
+	// Login lockout configuration
+	LoginMaxFailedAttempts int           // Consecutive failed logins that lock an account, 0 disables lockout
+	LoginLockoutDuration   time.Duration // How long a locked account refuses password logins
+
+	// User cache configuration
+	UserCacheSize int           // Users kept in memory for per-request lookups, 0 disables the cache
+	UserCacheTTL  time.Duration // How long a cached user is served before it is read again
 }
 
 // LoadConfig loads configuration from environment variables
@@ -63,14 +141,26 @@ func LoadConfig() (*Config, error) {
 
 	// Load configuration with defaults
 	config := &Config{
-		Port:         getEnvInt("PORT", 8080),
-		ReadTimeout:  time.Duration(getEnvInt("READ_TIMEOUT_SECONDS", 30)) * time.Second,
-		WriteTimeout: time.Duration(getEnvInt("WRITE_TIMEOUT_SECONDS", 30)) * time.Second,
+		Port:               getEnvInt("PORT", 8080),
+		ReadTimeout:        time.Duration(getEnvInt("READ_TIMEOUT_SECONDS", 30)) * time.Second,
+		WriteTimeout:       time.Duration(getEnvInt("WRITE_TIMEOUT_SECONDS", 30)) * time.Second,
+		RequestTimeout:     time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
+		ScanRequestTimeout: time.Duration(getEnvInt("SCAN_REQUEST_TIMEOUT_SECONDS", 120)) * time.Second,
+		ScanDeadline:       time.Duration(getEnvInt("SCAN_PROCESSING_DEADLINE_SECONDS", 0)) * time.Second,
+		ScanMaxPages:       getEnvInt("SCAN_MAX_PAGES", 10),
+		ShutdownTimeout:    time.Duration(getEnvInt("SHUTDOWN_TIMEOUT", 10)) * time.Second,
+
+		CORSAllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", []string{"*"}),
+		CORSAllowCredentials: getEnvString("CORS_ALLOW_CREDENTIALS", "false") == "true",
+		CORSMaxAge:           time.Duration(getEnvInt("CORS_MAX_AGE", 600)) * time.Second,
 
 		OpenRouterAPIKey:  os.Getenv("OPENROUTER_API_KEY"),
 		OpenRouterModelID: getEnvString("OPENROUTER_MODEL_ID", "mistralai/mistral-7b-instruct"),
 		OpenRouterTimeout: time.Duration(getEnvInt("OPENROUTER_TIMEOUT", 60)) * time.Second,
 
+		OpenRouterMaxResponseBytes: getEnvInt("OPENROUTER_MAX_RESPONSE_BYTES", 1<<20),
+		OpenRouterInlineImages:     getEnvString("OPENROUTER_INLINE_IMAGES", "false") == "true",
+
 		SupabaseS3Endpoint:      os.Getenv("SUPABASE_S3_ENDPOINT"),
 		SupabaseAccessKeyID:     os.Getenv("SUPABASE_ACCESS_KEY_ID"),
 		SupabaseAccessKeySecret: os.Getenv("SUPABASE_ACCESS_KEY_SECRET"),
@@ -81,12 +171,35 @@ func LoadConfig() (*Config, error) {
 		UseMLXService: getEnvString("USE_MLX_SERVICE", "false") == "true",
 		MLXServiceURL: getEnvString("MLX_SERVICE_URL", "http://localhost:8000"),
 		MLXTimeout:    time.Duration(getEnvInt("MLX_TIMEOUT", 300)) * time.Second,
+		MLXFallback:   getEnvString("MLX_FALLBACK_TO_OPENROUTER", "false") == "true",
+
+		KeepFailedScanImages: getEnvString("KEEP_FAILED_SCAN_IMAGES", "false") == "true",
+
+		MaxWorkers:            getEnvInt("MAX_WORKERS", 5),
+		APIBasePath:           getEnvString("API_BASE_PATH", "/v1"),
+		MinConfidenceAutosave: getEnvFloat("MIN_CONFIDENCE_AUTOSAVE", 0),
+		StrictCategories:      getEnvString("STRICT_CATEGORIES", "false") == "true",
+		ScanDebugEnabled:      getEnvString("SCAN_DEBUG_ENABLED", "false") == "true",
+		ExtractionFeedback:    getEnvString("EXTRACTION_FEEDBACK_ENABLED", "false") == "true",
+
+		ImageStorageFormat: getEnvString("IMAGE_STORAGE_FORMAT", "original"),
+		ImageJPEGQuality:   getEnvInt("IMAGE_JPEG_QUALITY", 85),
 
-		MaxWorkers:  getEnvInt("MAX_WORKERS", 5),
-		APIBasePath: getEnvString("API_BASE_PATH", "/v1"),
+		MoneyPrecision:       getEnvInt("MONEY_PRECISION", 2),
+		MoneyRoundingMode:    getEnvString("MONEY_ROUNDING_MODE", "half_up"),
+		ScanCurrencyRounding: getEnvString("SCAN_CURRENCY_ROUNDING", "true") == "true",
 
-		LogFormat: getEnvString("LOG_FORMAT", "json"),
-		LogLevel:  getEnvString("LOG_LEVEL", "info"),
+		AnomalyZScoreThreshold:  getEnvFloat("ANOMALY_ZSCORE_THRESHOLD", 2.0),
+		ItemNameStripQuantities: getEnvString("ITEM_NAME_STRIP_QUANTITIES", "true") == "true",
+		ItemNameIgnoredWords:    getEnvList("ITEM_NAME_IGNORED_WORDS", []string{}),
+
+		CurrencyPrefetch:   getEnvString("CURRENCY_PREFETCH", "false") == "true",
+		CurrencyAPIBaseURL: getEnvString("CURRENCY_API_BASE_URL", "https://api.frankfurter.dev/v1"),
+
+		LogFormat:         getEnvString("LOG_FORMAT", "json"),
+		LogLevel:          getEnvString("LOG_LEVEL", "info"),
+		LogExcludePaths:   getEnvList("LOG_EXCLUDE_PATHS", []string{"/health*", "/metrics", "/api-docs*"}),
+		LogExcludedErrors: getEnvString("LOG_EXCLUDED_ERRORS", "true") == "true",
 
 		GoogleClientIDWeb:     os.Getenv("GOOGLE_CLIENT_ID_WEB"),
 		GoogleClientSecretWeb: os.Getenv("GOOGLE_CLIENT_SECRET_WEB"),
@@ -96,8 +209,37 @@ func LoadConfig() (*Config, error) {
 		JWTSecret:             getEnvString("JWT_SECRET", "your-secret-key-change-in-production"),
 		JWTAccessExpiration:   time.Duration(getEnvInt("JWT_ACCESS_EXPIRATION_HOURS", 24)) * time.Hour,
 		JWTRefreshExpiration:  time.Duration(getEnvInt("JWT_REFRESH_EXPIRATION_DAYS", 30)) * 24 * time.Hour,
+		JWTIssuer:             os.Getenv("JWT_ISSUER"),
+		JWTAudience:           os.Getenv("JWT_AUDIENCE"),
 		FrontendURL:           getEnvString("FRONTEND_URL", "http://localhost:3000"),
+
+		ExportRateLimit:   getEnvInt("EXPORT_RATE_LIMIT_PER_HOUR", 3),
+		ExportMaxReceipts: getEnvInt("EXPORT_MAX_RECEIPTS", 5000),
+
+		MonthlyScanQuota: getEnvInt("SCAN_MONTHLY_QUOTA", 0),
+
+		EmailIngestDomain:    os.Getenv("EMAIL_INGEST_DOMAIN"),
+		EmailIngestRateLimit: getEnvInt("EMAIL_INGEST_RATE_LIMIT_PER_MINUTE", 60),
+		EmailIngestMaxBytes:  getEnvInt("EMAIL_INGEST_MAX_BYTES", 25<<20),
+		EmailIngestMaxFiles:  getEnvInt("EMAIL_INGEST_MAX_ATTACHMENTS", 10),
+
+		RetentionEnabled:     getEnvString("RETENTION_ENABLED", "false") == "true",
+		RetentionReceiptDays: getEnvInt("RETENTION_RECEIPT_DAYS", 0),
+		RetentionImageDays:   getEnvInt("RETENTION_IMAGE_DAYS", 0),
+		RetentionDryRun:      getEnvString("RETENTION_DRY_RUN", "false") == "true",
+		RetentionBatchSize:   getEnvInt("RETENTION_BATCH_SIZE", 500),
+		RetentionInterval:    time.Duration(getEnvInt("RETENTION_INTERVAL_HOURS", 24)) * time.Hour,
+
+		PasswordMinLength:       getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequiredClasses: getEnvList("PASSWORD_REQUIRED_CLASSES", []string{"letter", "digit"}),
+
+		LoginMaxFailedAttempts: getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		LoginLockoutDuration:   time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+
+		UserCacheSize: getEnvInt("USER_CACHE_SIZE", 1000),
+		UserCacheTTL:  time.Duration(getEnvInt("USER_CACHE_TTL_SECONDS", 30)) * time.Second,
 	}
+	config.MaxScanWorkers = getEnvInt("MAX_SCAN_WORKERS", config.MaxWorkers)
 
 	return config, nil
 }
@@ -117,6 +259,21 @@ func getEnvInt(key string, defaultValue int) int {
 	return intValue
 }
 
+// getEnvFloat gets an environment variable as a float with a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return floatValue
+}
+
 // getEnvString gets an environment variable with a default value
 func getEnvString(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -125,3 +282,23 @@ func getEnvString(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvList gets a comma-separated environment variable as a list with a default value.
+// The value "none" yields an empty list.
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if strings.TrimSpace(value) == "none" {
+		return []string{}
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
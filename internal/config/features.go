@@ -0,0 +1,22 @@
+package config
+
+// Features reports which optional capabilities this configuration enables, keyed by the
+// camelCase names clients see at GET /v1/features
+func (c *Config) Features() map[string]bool {
+	imageStorage := c.SupabaseS3Endpoint != ""
+	// Both extraction paths read the image from storage, unless OpenRouter is sent images inline
+	scan := (imageStorage || c.OpenRouterInlineImages) && (c.OpenRouterAPIKey != "" || c.UseMLXService)
+
+	return map[string]bool{
+		"scan":               scan,
+		"scanFromUrl":        scan,
+		"emailIngest":        scan,
+		"mlx":                scan && c.UseMLXService,
+		"mlxFallback":        scan && c.UseMLXService && c.MLXFallback && c.OpenRouterAPIKey != "",
+		"imageStorage":       imageStorage,
+		"currencyConversion": c.CurrencyAPIBaseURL != "",
+		"googleLogin":        c.GoogleClientIDWeb != "" || c.GoogleClientIDAndroid != "" || c.GoogleClientIDIOS != "",
+		"strictCategories":   c.StrictCategories,
+		"extractionFeedback": c.ExtractionFeedback,
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	features := validConfig().Features()
+	assert.True(t, features["scan"])
+	assert.True(t, features["imageStorage"])
+	assert.False(t, features["mlx"])
+	assert.False(t, features["googleLogin"])
+
+	t.Run("scanning without image storage is disabled", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SupabaseS3Endpoint = ""
+
+		features := cfg.Features()
+		assert.False(t, features["scan"])
+		assert.False(t, features["scanFromUrl"])
+		assert.False(t, features["emailIngest"])
+		assert.False(t, features["imageStorage"])
+	})
+
+	t.Run("OpenRouter with inline images scans without image storage", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SupabaseS3Endpoint = ""
+		cfg.OpenRouterInlineImages = true
+
+		features := cfg.Features()
+		assert.True(t, features["scan"])
+		assert.True(t, features["scanFromUrl"])
+		assert.True(t, features["emailIngest"])
+		assert.False(t, features["imageStorage"])
+	})
+
+	t.Run("MLX fallback needs an OpenRouter key", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.UseMLXService = true
+		cfg.MLXFallback = true
+		assert.True(t, cfg.Features()["mlxFallback"])
+
+		cfg.OpenRouterAPIKey = ""
+		assert.True(t, cfg.Features()["mlx"])
+		assert.False(t, cfg.Features()["mlxFallback"])
+	})
+}
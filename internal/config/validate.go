@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Validate checks that the values required by the enabled features are set and that numeric
+// settings are in range. It reports every problem found at once, naming the environment variables.
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(value, envVar, reason string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required %s", envVar, reason))
+		}
+	}
+
+	require(c.PostgresDBURL, "POSTGRES_DB_URL", "to connect to the database")
+	require(c.JWTSecret, "JWT_SECRET", "to sign access tokens")
+
+	// Both extraction paths store the receipt image in S3 before or while extracting,
+	// except OpenRouter with inline images, which then saves receipts without an image
+	s3Reason := "to store receipt images"
+	s3Required := true
+	if c.UseMLXService {
+		require(c.MLXServiceURL, "MLX_SERVICE_URL", "when USE_MLX_SERVICE is true")
+		s3Reason = "when USE_MLX_SERVICE is true, as MLX reads receipt images from S3"
+		if c.MLXFallback {
+			require(c.OpenRouterAPIKey, "OPENROUTER_API_KEY", "when MLX_FALLBACK_TO_OPENROUTER is true")
+		}
+	} else {
+		require(c.OpenRouterAPIKey, "OPENROUTER_API_KEY", "when USE_MLX_SERVICE is false")
+		s3Required = !c.OpenRouterInlineImages
+		s3Reason = "to store receipt images, unless OPENROUTER_INLINE_IMAGES is true"
+	}
+	if s3Required {
+		require(c.SupabaseS3Endpoint, "SUPABASE_S3_ENDPOINT", s3Reason)
+		require(c.SupabaseAccessKeyID, "SUPABASE_ACCESS_KEY_ID", s3Reason)
+		require(c.SupabaseAccessKeySecret, "SUPABASE_ACCESS_KEY_SECRET", s3Reason)
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.MaxWorkers < 1 {
+		errs = append(errs, fmt.Errorf("MAX_WORKERS must be at least 1, got %d", c.MaxWorkers))
+	}
+	if c.MaxScanWorkers < 1 {
+		errs = append(errs, fmt.Errorf("MAX_SCAN_WORKERS must be at least 1, got %d", c.MaxScanWorkers))
+	}
+	if c.MinConfidenceAutosave < 0 || c.MinConfidenceAutosave > 1 {
+		errs = append(errs, fmt.Errorf("MIN_CONFIDENCE_AUTOSAVE must be between 0 and 1, got %g", c.MinConfidenceAutosave))
+	}
+	if c.ImageStorageFormat != "original" && c.ImageStorageFormat != "jpeg" && c.ImageStorageFormat != "png" {
+		errs = append(errs, fmt.Errorf("IMAGE_STORAGE_FORMAT must be original, jpeg or png, got %q", c.ImageStorageFormat))
+	}
+	if c.ImageJPEGQuality < 1 || c.ImageJPEGQuality > 100 {
+		errs = append(errs, fmt.Errorf("IMAGE_JPEG_QUALITY must be between 1 and 100, got %d", c.ImageJPEGQuality))
+	}
+	if c.EmailIngestMaxBytes < 1 {
+		errs = append(errs, fmt.Errorf("EMAIL_INGEST_MAX_BYTES must be positive, got %d", c.EmailIngestMaxBytes))
+	}
+	if c.EmailIngestMaxFiles < 1 {
+		errs = append(errs, fmt.Errorf("EMAIL_INGEST_MAX_ATTACHMENTS must be at least 1, got %d", c.EmailIngestMaxFiles))
+	}
+	if c.ScanMaxPages < 1 {
+		errs = append(errs, fmt.Errorf("SCAN_MAX_PAGES must be at least 1, got %d", c.ScanMaxPages))
+	}
+	if c.ScanDeadline < 0 {
+		errs = append(errs, fmt.Errorf("SCAN_PROCESSING_DEADLINE_SECONDS must not be negative, got %s", c.ScanDeadline))
+	}
+	if c.ScanDeadline > 0 && c.ScanRequestTimeout > 0 && c.ScanDeadline >= c.ScanRequestTimeout {
+		errs = append(errs, fmt.Errorf("SCAN_PROCESSING_DEADLINE_SECONDS must be less than SCAN_REQUEST_TIMEOUT_SECONDS, got %s and %s", c.ScanDeadline, c.ScanRequestTimeout))
+	}
+	if c.LoginMaxFailedAttempts > 0 && c.LoginLockoutDuration <= 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCKOUT_MINUTES must be positive when LOGIN_MAX_FAILED_ATTEMPTS is set, got %s", c.LoginLockoutDuration))
+	}
+	if c.UserCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("USER_CACHE_SIZE must not be negative, got %d", c.UserCacheSize))
+	}
+	if c.UserCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("USER_CACHE_TTL_SECONDS must not be negative, got %s", c.UserCacheTTL))
+	}
+	if c.RetentionEnabled {
+		if c.RetentionReceiptDays < 0 || c.RetentionImageDays < 0 {
+			errs = append(errs, fmt.Errorf("RETENTION_RECEIPT_DAYS and RETENTION_IMAGE_DAYS must not be negative"))
+		}
+		if c.RetentionReceiptDays == 0 && c.RetentionImageDays == 0 {
+			errs = append(errs, fmt.Errorf("RETENTION_RECEIPT_DAYS or RETENTION_IMAGE_DAYS is required when RETENTION_ENABLED is true"))
+		}
+		if c.RetentionInterval <= 0 {
+			errs = append(errs, fmt.Errorf("RETENTION_INTERVAL_HOURS must be positive, got %s", c.RetentionInterval))
+		}
+	}
+	if c.MonthlyScanQuota < 0 {
+		errs = append(errs, fmt.Errorf("SCAN_MONTHLY_QUOTA must not be negative, got %d", c.MonthlyScanQuota))
+	}
+	if c.OpenRouterMaxResponseBytes < 1 {
+		errs = append(errs, fmt.Errorf("OPENROUTER_MAX_RESPONSE_BYTES must be positive, got %d", c.OpenRouterMaxResponseBytes))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Summary describes the effective configuration for the startup log.
+// Secrets are reported only as set or unset, and the database URL's password is masked.
+func (c *Config) Summary() string {
+	fields := []string{
+		fmt.Sprintf("port=%d", c.Port),
+		fmt.Sprintf("apiBasePath=%s", c.APIBasePath),
+		fmt.Sprintf("maxWorkers=%d", c.MaxWorkers),
+		fmt.Sprintf("maxScanWorkers=%d", c.MaxScanWorkers),
+		fmt.Sprintf("database=%s", redactURL(c.PostgresDBURL)),
+		fmt.Sprintf("useMLXService=%t", c.UseMLXService),
+	}
+	if c.UseMLXService {
+		fields = append(fields,
+			fmt.Sprintf("mlxServiceURL=%s", redactURL(c.MLXServiceURL)),
+			fmt.Sprintf("mlxFallback=%t", c.MLXFallback),
+		)
+	}
+	fields = append(fields,
+		fmt.Sprintf("openRouterModel=%s", c.OpenRouterModelID),
+		fmt.Sprintf("openRouterAPIKey=%s", secretState(c.OpenRouterAPIKey)),
+		fmt.Sprintf("openRouterInlineImages=%t", c.OpenRouterInlineImages),
+		fmt.Sprintf("s3Endpoint=%s", redactURL(c.SupabaseS3Endpoint)),
+		fmt.Sprintf("s3Bucket=%s", c.SupabaseBucket),
+		fmt.Sprintf("s3Credentials=%s", secretState(c.SupabaseAccessKeyID+c.SupabaseAccessKeySecret)),
+		fmt.Sprintf("currencyAPI=%s", redactURL(c.CurrencyAPIBaseURL)),
+		fmt.Sprintf("jwtSecret=%s", secretState(c.JWTSecret)),
+		fmt.Sprintf("googleOAuth=%s", secretState(c.GoogleClientIDWeb)),
+		fmt.Sprintf("corsAllowedOrigins=%s", strings.Join(c.CORSAllowedOrigins, ",")),
+		fmt.Sprintf("logLevel=%s", c.LogLevel),
+	)
+	return strings.Join(fields, " ")
+}
+
+// secretState reports whether a secret is configured without revealing it
+func secretState(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return "set"
+}
+
+// redactURL masks any password in a URL, reporting unparseable values only as set
+func redactURL(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "set"
+	}
+	return parsed.Redacted()
+}
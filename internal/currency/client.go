@@ -4,14 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
 )
 
 const (
 	frankfurterBaseURL = "https://api.frankfurter.dev/v1"
 	cacheTTL           = 1 * time.Hour
+	// maxDatedRates bounds how many historical rate sets are cached, since every receipt date adds one
+	maxDatedRates = 256
 )
 
 // ExchangeRates represents the response from Frankfurter API
@@ -21,42 +31,137 @@ type ExchangeRates struct {
 	Rates map[string]float64 `json:"rates"`
 }
 
+// Conversion is the result of converting an amount, with the rate that was applied
+type Conversion struct {
+	Amount          float64 // Converted amount rounded to Precision decimal places
+	UnroundedAmount float64 // Converted amount before rounding
+	Precision       int     // Decimal places of the target currency, see Decimals
+	Rate            float64 // Units of the target currency per unit of the source currency
+	RateBase        string  // Base currency of the rates used
+	RateDate        string  // Publication date of the rates used (YYYY-MM-DD), empty when no rate applied
+}
+
 // Client handles currency conversion using Frankfurter API
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	cacheTTL   time.Duration
+	maxDated   int
+	rounding   money.RoundingMode
 	cache      map[string]*cachedRates
 	cacheMu    sync.RWMutex
+	fetchGroup singleflight.Group
 }
 
 type cachedRates struct {
-	rates     *ExchangeRates
-	expiresAt time.Time
+	base       string
+	rates      *ExchangeRates
+	expiresAt  time.Time
+	lastAccess atomic.Int64 // Unix nanoseconds of the last read, used to pick bases worth prefetching
 }
 
-// NewClient creates a new currency client
-func NewClient() *Client {
+// NewClient creates a new currency client for a Frankfurter-compatible API.
+// baseURL includes the API version, e.g. "https://api.frankfurter.dev/v1"; empty uses the public API.
+func NewClient(baseURL string) *Client {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = frankfurterBaseURL
+	}
+
 	return &Client{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		cache: make(map[string]*cachedRates),
+		baseURL:  baseURL,
+		cacheTTL: cacheTTL,
+		maxDated: maxDatedRates,
+		rounding: money.RoundHalfUp,
+		cache:    make(map[string]*cachedRates),
 	}
 }
 
-// GetLatestRates fetches the latest exchange rates for a base currency
+// SetRounding sets how converted amounts are rounded to the target currency's precision.
+// Amounts are rounded half-up unless set otherwise.
+func (c *Client) SetRounding(mode money.RoundingMode) {
+	c.rounding = mode
+}
+
+// CacheTTL returns how long fetched rates are cached before they are fetched again
+func (c *Client) CacheTTL() time.Duration {
+	return c.cacheTTL
+}
+
+// GetLatestRates fetches the latest exchange rates for a base currency.
+// Concurrent cache misses for the same base share a single upstream request.
 func (c *Client) GetLatestRates(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
-	cacheKey := fmt.Sprintf("latest_%s", baseCurrency)
+	return c.getRates(ctx, fmt.Sprintf("latest_%s", baseCurrency), baseCurrency, "latest")
+}
+
+// GetRatesOn fetches the exchange rates for a base currency published on a date, or on the closest
+// earlier publication day for weekends and holidays. A zero or future date returns the latest rates.
+func (c *Client) GetRatesOn(ctx context.Context, baseCurrency string, date time.Time) (*ExchangeRates, error) {
+	if date.IsZero() || date.After(time.Now()) {
+		return c.GetLatestRates(ctx, baseCurrency)
+	}
 
+	day := date.Format("2006-01-02")
+	return c.getRates(ctx, fmt.Sprintf("date_%s_%s", day, baseCurrency), baseCurrency, day)
+}
+
+// getRates returns cached rates for a key, fetching them from the API endpoint
+// ("latest" or a YYYY-MM-DD date) on a miss
+func (c *Client) getRates(ctx context.Context, cacheKey, baseCurrency, endpoint string) (*ExchangeRates, error) {
 	// Check cache
+	if rates, ok := c.getCached(cacheKey); ok {
+		return rates, nil
+	}
+
+	// The shared fetch is detached from any single caller's cancellation and bounded by the HTTP client timeout
+	fetch := c.fetchGroup.DoChan(cacheKey, func() (interface{}, error) {
+		if rates, ok := c.getCached(cacheKey); ok {
+			return rates, nil
+		}
+		return c.fetchRates(context.WithoutCancel(ctx), cacheKey, baseCurrency, endpoint)
+	})
+
+	select {
+	case result := <-fetch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		c.touch(cacheKey)
+		return result.Val.(*ExchangeRates), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to fetch rates: %w", ctx.Err())
+	}
+}
+
+// getCached returns unexpired cached rates for a key
+func (c *Client) getCached(cacheKey string) (*ExchangeRates, bool) {
 	c.cacheMu.RLock()
-	if cached, ok := c.cache[cacheKey]; ok && time.Now().Before(cached.expiresAt) {
-		c.cacheMu.RUnlock()
-		return cached.rates, nil
+	defer c.cacheMu.RUnlock()
+
+	cached, ok := c.cache[cacheKey]
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		return nil, false
 	}
-	c.cacheMu.RUnlock()
+	cached.lastAccess.Store(time.Now().UnixNano())
+	return cached.rates, true
+}
+
+// touch records a read of the cached rates for a key
+func (c *Client) touch(cacheKey string) {
+	c.cacheMu.RLock()
+	defer c.cacheMu.RUnlock()
 
-	// Fetch from API
-	url := fmt.Sprintf("%s/latest?base=%s", frankfurterBaseURL, baseCurrency)
+	if cached, ok := c.cache[cacheKey]; ok {
+		cached.lastAccess.Store(time.Now().UnixNano())
+	}
+}
+
+// fetchRates requests rates from the API endpoint and caches them
+func (c *Client) fetchRates(ctx context.Context, cacheKey, baseCurrency, endpoint string) (*ExchangeRates, error) {
+	url := fmt.Sprintf("%s/%s?base=%s", c.baseURL, endpoint, baseCurrency)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -77,35 +182,87 @@ func (c *Client) GetLatestRates(ctx context.Context, baseCurrency string) (*Exch
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Cache the result
-	c.cacheMu.Lock()
-	c.cache[cacheKey] = &cachedRates{
+	// Cache the result, keeping when the previous rates were last read
+	entry := &cachedRates{
+		base:      baseCurrency,
 		rates:     &rates,
-		expiresAt: time.Now().Add(cacheTTL),
+		expiresAt: time.Now().Add(c.cacheTTL),
 	}
+	c.cacheMu.Lock()
+	previous, ok := c.cache[cacheKey]
+	if ok {
+		entry.lastAccess.Store(previous.lastAccess.Load())
+	} else if strings.HasPrefix(cacheKey, "date_") {
+		c.evictDated()
+	}
+	c.cache[cacheKey] = entry
 	c.cacheMu.Unlock()
 
 	return &rates, nil
 }
 
+// evictDated makes room for one historical rate set when the dated entries are at their limit,
+// dropping expired ones first, then the least recently read. The caller must hold cacheMu.
+func (c *Client) evictDated() {
+	now := time.Now()
+	dated := 0
+	var oldestKey string
+	var oldest int64
+	for cacheKey, cached := range c.cache {
+		if !strings.HasPrefix(cacheKey, "date_") {
+			continue
+		}
+		if !now.Before(cached.expiresAt) {
+			delete(c.cache, cacheKey)
+			continue
+		}
+		dated++
+		if lastAccess := cached.lastAccess.Load(); oldestKey == "" || lastAccess < oldest {
+			oldestKey, oldest = cacheKey, lastAccess
+		}
+	}
+	if dated >= c.maxDated {
+		delete(c.cache, oldestKey)
+	}
+}
+
 // Convert converts an amount from one currency to another
-func (c *Client) Convert(ctx context.Context, amount float64, fromCurrency, toCurrency string) (float64, error) {
+func (c *Client) Convert(ctx context.Context, amount float64, fromCurrency, toCurrency string) (*Conversion, error) {
+	return c.ConvertOn(ctx, amount, fromCurrency, toCurrency, time.Time{})
+}
+
+// ConvertOn converts an amount from one currency to another using the rates published on a date.
+// A zero date uses the latest rates.
+func (c *Client) ConvertOn(ctx context.Context, amount float64, fromCurrency, toCurrency string, date time.Time) (*Conversion, error) {
 	if fromCurrency == toCurrency {
-		return amount, nil
+		return c.rounded(amount, toCurrency, &Conversion{Rate: 1, RateBase: fromCurrency}), nil
 	}
 
 	// Get rates with fromCurrency as base
-	rates, err := c.GetLatestRates(ctx, fromCurrency)
+	rates, err := c.GetRatesOn(ctx, fromCurrency, date)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get exchange rates: %w", err)
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
 	}
 
 	rate, ok := rates.Rates[toCurrency]
 	if !ok {
-		return 0, fmt.Errorf("exchange rate not found for %s to %s", fromCurrency, toCurrency)
+		return nil, fmt.Errorf("exchange rate not found for %s to %s", fromCurrency, toCurrency)
 	}
 
-	return amount * rate, nil
+	return c.rounded(amount*rate, toCurrency, &Conversion{
+		Rate:     rate,
+		RateBase: rates.Base,
+		RateDate: rates.Date,
+	}), nil
+}
+
+// rounded fills in a conversion's amount, rounded to the decimal places of the target currency
+func (c *Client) rounded(amount float64, toCurrency string, conversion *Conversion) *Conversion {
+	policy := money.Policy{Precision: Decimals(toCurrency), Rounding: c.rounding}
+	conversion.UnroundedAmount = amount
+	conversion.Precision = policy.Precision
+	conversion.Amount = policy.ToFloat(policy.FromFloat(amount))
+	return conversion
 }
 
 // GetSupportedCurrencies returns a list of supported currencies
@@ -120,6 +277,52 @@ func (c *Client) GetSupportedCurrencies(ctx context.Context) ([]string, error) {
 	for currency := range rates.Rates {
 		currencies = append(currencies, currency)
 	}
+	sort.Strings(currencies) // A stable order keeps responses cacheable
 
 	return currencies, nil
 }
+
+// StartPrefetch refreshes cached rates in the background shortly before they expire, so bases read
+// within the last TTL are always served warm. It stops when the context is cancelled.
+func (c *Client) StartPrefetch(ctx context.Context) {
+	// Refresh within the last tenth of the TTL, checking twice in that window
+	refreshAhead := c.cacheTTL / 10
+	ticker := time.NewTicker(refreshAhead / 2)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.prefetchExpiring(ctx, refreshAhead)
+			}
+		}
+	}()
+}
+
+// prefetchExpiring re-fetches recently read rates that expire within refreshAhead
+func (c *Client) prefetchExpiring(ctx context.Context, refreshAhead time.Duration) {
+	now := time.Now()
+	due := map[string]string{}
+
+	c.cacheMu.RLock()
+	for cacheKey, cached := range c.cache {
+		recentlyRead := now.Sub(time.Unix(0, cached.lastAccess.Load())) < c.cacheTTL
+		if strings.HasPrefix(cacheKey, "latest_") && recentlyRead && cached.expiresAt.Sub(now) <= refreshAhead {
+			due[cacheKey] = cached.base
+		}
+	}
+	c.cacheMu.RUnlock()
+
+	for cacheKey, base := range due {
+		// Share the fetch with any concurrent miss for the same base
+		_, err, _ := c.fetchGroup.Do(cacheKey, func() (interface{}, error) {
+			return c.fetchRates(ctx, cacheKey, base, "latest")
+		})
+		if err != nil {
+			log.Printf("Warning: failed to prefetch %s exchange rates: %v", base, err)
+		}
+	}
+}
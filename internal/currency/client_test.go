@@ -0,0 +1,221 @@
+package currency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLatestRatesCoalescesConcurrentMisses(t *testing.T) {
+	var upstreamCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+		// Hold the response so every caller misses the cache while the request is in flight
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9,"IDR":15500}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	const callers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rates, err := client.GetLatestRates(context.Background(), "USD")
+			if err == nil && rates.Rates["EUR"] != 0.9 {
+				t.Errorf("unexpected rates: %+v", rates)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstreamCalls))
+
+	// Subsequent calls are served from the cache
+	_, err := client.GetLatestRates(context.Background(), "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstreamCalls))
+}
+
+func TestPrefetchKeepsRecentBaseWarm(t *testing.T) {
+	var upstreamCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+		// Slow enough that a request missing the cache is easy to tell apart from a hit
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.cacheTTL = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Warm the cache, then keep reading the base past several TTL boundaries
+	_, err := client.GetLatestRates(ctx, "USD")
+	require.NoError(t, err)
+	client.StartPrefetch(ctx)
+
+	deadline := time.Now().Add(5 * client.cacheTTL / 2)
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		_, err := client.GetLatestRates(ctx, "USD")
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 25*time.Millisecond, "request waited on the rates API")
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The refreshes came from the prefetcher, not from requests
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&upstreamCalls), int32(3))
+}
+
+func TestConvertReportsRateDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	conversion, err := client.Convert(context.Background(), 10, "USD", "EUR")
+	require.NoError(t, err)
+
+	assert.InDelta(t, 9, conversion.Amount, 0.0001)
+	assert.InDelta(t, 0.9, conversion.Rate, 0.0001)
+	assert.Equal(t, "USD", conversion.RateBase)
+	assert.Equal(t, "2024-01-02", conversion.RateDate)
+}
+
+func TestNewClientUsesBaseURL(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path + "?" + r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9}}`))
+	}))
+	defer server.Close()
+
+	// A trailing slash is tolerated and the version path is kept
+	client := NewClient(server.URL + "/v2/")
+	rates, err := client.GetLatestRates(context.Background(), "USD")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v2/latest?base=USD", requestedPath)
+	assert.Equal(t, 0.9, rates.Rates["EUR"])
+
+	assert.Equal(t, frankfurterBaseURL, NewClient("").baseURL)
+}
+
+func TestConvertOnUsesHistoricalRates(t *testing.T) {
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/latest" {
+			_, _ = w.Write([]byte(`{"base":"USD","date":"2024-06-03","rates":{"EUR":0.92}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	conversion, err := client.ConvertOn(context.Background(), 10, "USD", "EUR", time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 9, conversion.Amount, 0.0001)
+	assert.Equal(t, "2024-01-02", conversion.RateDate)
+
+	// Future dates have no published rates yet and fall back to the latest
+	conversion, err = client.ConvertOn(context.Background(), 10, "USD", "EUR", time.Now().AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.InDelta(t, 9.2, conversion.Amount, 0.0001)
+
+	assert.Equal(t, []string{"/2024-01-02", "/latest"}, requestedPaths)
+}
+
+func TestConvertRoundsToTargetPrecision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("base") {
+		case "USD":
+			_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"IDR":15523.37,"JPY":143.217}}`))
+		default:
+			_, _ = w.Write([]byte(`{"base":"IDR","date":"2024-01-02","rates":{"USD":0.0000644189}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	conversion, err := client.Convert(ctx, 12.34, "USD", "IDR")
+	require.NoError(t, err)
+	assert.Equal(t, 0, conversion.Precision)
+	assert.Equal(t, float64(191558), conversion.Amount)
+	assert.InDelta(t, 191558.3858, conversion.UnroundedAmount, 0.0001)
+
+	conversion, err = client.Convert(ctx, 150000, "IDR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 2, conversion.Precision)
+	assert.Equal(t, 9.66, conversion.Amount)
+	assert.InDelta(t, 9.662835, conversion.UnroundedAmount, 0.000001)
+
+	t.Run("same currency is rounded too", func(t *testing.T) {
+		conversion, err := client.Convert(ctx, 1999.5, "JPY", "JPY")
+		require.NoError(t, err)
+		assert.Equal(t, float64(2000), conversion.Amount)
+		assert.Equal(t, 1999.5, conversion.UnroundedAmount)
+	})
+}
+
+func TestDatedRatesCacheIsBounded(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base":"USD","date":"2024-01-02","rates":{"EUR":0.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.maxDated = 2
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, d := range []int{1, 2, 1, 3} {
+		_, err := client.GetRatesOn(ctx, "USD", day(d))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Len(t, client.cache, 2)
+
+	// The least recently read date was dropped to make room, the others are still cached
+	_, err := client.GetRatesOn(ctx, "USD", day(1))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+	_, err = client.GetRatesOn(ctx, "USD", day(2))
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
@@ -0,0 +1,34 @@
+package currency
+
+import "strings"
+
+// currencyAliases maps symbols and local abbreviations printed on receipts to ISO 4217 codes.
+// Ambiguous symbols resolve to the most common currency using them, e.g. "$" to USD.
+var currencyAliases = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"€":   "EUR",
+	"£":   "GBP",
+	"¥":   "JPY",
+	"₩":   "KRW",
+	"₹":   "INR",
+	"₱":   "PHP",
+	"฿":   "THB",
+	"RP":  "IDR",
+	"RM":  "MYR",
+	"S$":  "SGD",
+	"A$":  "AUD",
+	"C$":  "CAD",
+	"HK$": "HKD",
+	"NT$": "TWD",
+}
+
+// NormalizeCurrency returns the ISO 4217 code for a currency code or symbol, upper-casing codes
+// and mapping common symbols such as "€" or "Rp". Unrecognized input is returned trimmed and upper-cased.
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if alias, ok := currencyAliases[code]; ok {
+		return alias
+	}
+	return code
+}
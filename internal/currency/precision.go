@@ -0,0 +1,22 @@
+package currency
+
+import "strings"
+
+// currencyDecimals lists the currencies whose amounts aren't written with two decimal places.
+// IDR officially has two, but prices are never quoted in sen, so it is treated as whole units.
+var currencyDecimals = map[string]int{
+	// Zero-decimal currencies
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IDR": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// Three-decimal currencies
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Decimals returns the number of decimal places amounts in a currency are written with,
+// two for currencies not listed otherwise
+func Decimals(code string) int {
+	if decimals, ok := currencyDecimals[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return decimals
+	}
+	return 2
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Activity actions recorded for a user's receipts
+const (
+	ActivityReceiptScanned = "scanned"
+	ActivityReceiptCreated = "created"
+	ActivityReceiptUpdated = "updated"
+	ActivityReceiptDeleted = "deleted"
+)
+
+// Activity is one entry in a user's activity feed
+type Activity struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Action    string    `json:"action"`     // One of the ActivityReceipt constants
+	ReceiptID string    `json:"receipt_id"` // The receipt acted on, which may since have been deleted
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActivityFeed is a page of a user's activity, newest first
+type ActivityFeed struct {
+	Data       []Activity `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// Derived fields that can be recomputed for existing rows
+const (
+	BackfillFieldNormalizedMerchant = "normalized_merchant"
+)
+
+// DerivedFieldSource is a row whose derived field has not been computed yet, with the value it derives from
+type DerivedFieldSource struct {
+	ID     string
+	Source string
+}
+
+// BackfillProgress reports how far a derived field backfill has come
+type BackfillProgress struct {
+	Field     string `json:"field"`
+	Processed int    `json:"processed"` // Rows filled by this call
+	Remaining int    `json:"remaining"` // Rows still missing the field
+	Done      bool   `json:"done"`
+}
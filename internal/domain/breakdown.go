@@ -0,0 +1,26 @@
+package domain
+
+// Dimensions the spending breakdown can group by
+const (
+	BreakdownByCategory      = "category"
+	BreakdownByMerchant      = "merchant"
+	BreakdownByPaymentMethod = "paymentMethod"
+)
+
+// BreakdownDimensions lists the accepted spending breakdown dimensions
+var BreakdownDimensions = []string{BreakdownByCategory, BreakdownByMerchant, BreakdownByPaymentMethod}
+
+// SpendingBreakdown is the spending in scope grouped by one dimension, largest group first
+type SpendingBreakdown struct {
+	By     string                   `json:"by"`
+	Total  float64                  `json:"total"` // Spend on every receipt in scope, the base of the percentages
+	Groups []SpendingBreakdownGroup `json:"groups"`
+}
+
+// SpendingBreakdownGroup is the spending of one category, merchant or payment method
+type SpendingBreakdownGroup struct {
+	Name       string  `json:"name"`
+	Amount     float64 `json:"amount"`
+	Count      int     `json:"count"` // Receipts contributing to the group
+	Percentage float64 `json:"percentage"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+// BulkResult reports the outcome of a bulk operation for each ID it was given
+type BulkResult struct {
+	Succeeded []string      `json:"succeeded"`
+	Failed    []BulkFailure `json:"failed"`
+}
+
+// BulkFailure describes why a bulk operation skipped one ID
+type BulkFailure struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// NewBulkResult creates an empty result that serializes both lists as arrays
+func NewBulkResult() *BulkResult {
+	return &BulkResult{Succeeded: []string{}, Failed: []BulkFailure{}}
+}
+
+// Fail records that the operation did not apply to the ID
+func (r *BulkResult) Fail(id, reason string) {
+	r.Failed = append(r.Failed, BulkFailure{ID: id, Reason: reason})
+}
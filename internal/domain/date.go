@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrEmptyDate is returned by ParseFlexibleDate for a blank or null date
+var ErrEmptyDate = errors.New("date is empty")
+
+// DateParseError reports a date that matches none of the accepted formats
+type DateParseError struct {
+	Value string
+}
+
+func (e *DateParseError) Error() string {
+	return fmt.Sprintf("unrecognized date %q, expected YYYY-MM-DD, an RFC 3339 timestamp, DD/MM/YYYY or MM/DD/YYYY", e.Value)
+}
+
+// DateOrder says how an ambiguous numeric date such as 03/04/2025 is read
+type DateOrder int
+
+const (
+	DayFirst   DateOrder = iota // 03/04/2025 is 3 April
+	MonthFirst                  // 03/04/2025 is 4 March
+)
+
+// monthFirstRegions are the regions that write numeric dates month first
+var monthFirstRegions = map[string]bool{"US": true, "PH": true, "CA": true, "FM": true, "MH": true, "PW": true}
+
+// DateOrderForLocale returns the numeric date order of a locale such as "en-US" or "de_DE".
+// Locales without a region, or with an unknown one, read dates day first.
+func DateOrderForLocale(locale string) DateOrder {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) > 1 && monthFirstRegions[strings.ToUpper(parts[1])] {
+		return MonthFirst
+	}
+	return DayFirst
+}
+
+// dateLayouts are the non-numeric-order layouts tried in turn
+var dateLayouts = []string{
+	"2006-01-02",          // YYYY-MM-DD
+	time.RFC3339,          // 2006-01-02T15:04:05Z07:00
+	"2006-01-02T15:04:05", // Without timezone
+	time.RFC3339Nano,      // With nanoseconds
+	"2006/01/02",          // YYYY/MM/DD
+	"2 Jan 2006",          // 14 Mar 2025
+	"Jan 2, 2006",         // Mar 14, 2025
+}
+
+// numericDateRegex matches day and month in either order followed by a four digit year,
+// separated by slashes, dots or dashes
+var numericDateRegex = regexp.MustCompile(`^(\d{1,2})[/.-](\d{1,2})[/.-](\d{4})$`)
+
+// ParseFlexibleDate parses a date in any accepted format. Numeric dates such as 14/03/2025 or
+// 03/14/2025 are read in whichever order is valid; when both are, the order decides.
+// It returns ErrEmptyDate for a blank value and a *DateParseError when no format matches.
+func ParseFlexibleDate(value string, order DateOrder) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" {
+		return time.Time{}, ErrEmptyDate
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if matches := numericDateRegex.FindStringSubmatch(value); matches != nil {
+		first, _ := strconv.Atoi(matches[1])
+		second, _ := strconv.Atoi(matches[2])
+		year, _ := strconv.Atoi(matches[3])
+
+		day, month := first, second
+		if first <= 12 && (second > 12 || order == MonthFirst) {
+			day, month = second, first
+		}
+		if t, ok := validDate(year, month, day); ok {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, &DateParseError{Value: value}
+}
+
+// DateWithTimeOfDay returns the day of date at the HH:MM or HH:MM:SS wall clock time in loc, or nil
+// when the date is zero or the time is empty or not a valid time of day
+func DateWithTimeOfDay(date time.Time, clock string, loc *time.Location) *time.Time {
+	if date.IsZero() {
+		return nil
+	}
+	var parsed time.Time
+	var err error
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if parsed, err = time.Parse(layout, strings.TrimSpace(clock)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	t := time.Date(date.Year(), date.Month(), date.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, loc)
+	return &t
+}
+
+// validDate builds the date at midnight UTC, reporting false for days the month doesn't have
+func validDate(year, month, day int) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 {
+		return time.Time{}, false
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t, t.Day() == day
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlexibleDate(t *testing.T) {
+	march14 := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+	april3 := time.Date(2025, time.April, 3, 0, 0, 0, 0, time.UTC)
+	march4 := time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		order DateOrder
+		want  time.Time
+	}{
+		{name: "ISO date", value: "2025-03-14", want: march14},
+		{name: "RFC 3339", value: "2025-03-14T00:00:00Z", want: march14},
+		{name: "slashed ISO date", value: "2025/03/14", want: march14},
+		{name: "day first", value: "14/03/2025", want: march14},
+		{name: "month first", value: "03/14/2025", want: march14},
+		{name: "dotted day first", value: "14.03.2025", want: march14},
+		{name: "month name", value: "14 Mar 2025", want: march14},
+		{name: "ambiguous read day first", value: "03/04/2025", order: DayFirst, want: april3},
+		{name: "ambiguous read month first", value: "03/04/2025", order: MonthFirst, want: march4},
+		{name: "unambiguous ignores the order", value: "14/03/2025", order: MonthFirst, want: march14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFlexibleDate(tt.value, tt.order)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+
+	for _, value := range []string{"", "  ", "null"} {
+		_, err := ParseFlexibleDate(value, DayFirst)
+		assert.ErrorIs(t, err, ErrEmptyDate)
+	}
+
+	for _, value := range []string{"next tuesday", "31/02/2025", "13/13/2025"} {
+		_, err := ParseFlexibleDate(value, DayFirst)
+		var dateErr *DateParseError
+		require.ErrorAs(t, err, &dateErr, value)
+		assert.Contains(t, err.Error(), value)
+		assert.Contains(t, err.Error(), "expected YYYY-MM-DD")
+	}
+
+	assert.Equal(t, MonthFirst, DateOrderForLocale("en-US"))
+	assert.Equal(t, DayFirst, DateOrderForLocale("en_GB"))
+	assert.Equal(t, DayFirst, DateOrderForLocale("id"))
+}
+
+func TestFlexibleDateUnmarshal(t *testing.T) {
+	var receipt struct {
+		Date FlexibleDate `json:"date"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"date":null}`), &receipt))
+	assert.True(t, receipt.Date.IsZero())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"date":"14/03/2025"}`), &receipt))
+	assert.Equal(t, "2025-03-14", receipt.Date.Format("2006-01-02"))
+
+	err := json.Unmarshal([]byte(`{"date":"not a date"}`), &receipt)
+	var dateErr *DateParseError
+	assert.ErrorAs(t, err, &dateErr)
+}
+
+func TestReceiptPurchaseTime(t *testing.T) {
+	var receipt Receipt
+	require.NoError(t, json.Unmarshal([]byte(`{"date":"2025-03-14"}`), &receipt))
+	assert.False(t, receipt.Date.HasTimeOfDay())
+	assert.Nil(t, receipt.PurchaseTime())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"date":"2025-03-14T18:05:00+07:00"}`), &receipt))
+	require.NotNil(t, receipt.PurchaseTime())
+	assert.Equal(t, "2025-03-14T11:05:00Z", receipt.PurchaseTime().UTC().Format(time.RFC3339))
+
+	// A stored time of purchase wins over a date without one
+	stored := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	receipt = Receipt{Date: FlexibleDate{Time: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)}, PurchasedAt: &stored}
+	assert.Equal(t, &stored, receipt.PurchaseTime())
+}
+
+func TestDateWithTimeOfDay(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	got := DateWithTimeOfDay(date, "18:05", jakarta)
+	require.NotNil(t, got)
+	assert.Equal(t, "2025-03-14T18:05:00+07:00", got.Format(time.RFC3339))
+
+	got = DateWithTimeOfDay(date, " 07:30:15 ", nil)
+	require.NotNil(t, got)
+	assert.Equal(t, "2025-03-14T07:30:15Z", got.Format(time.RFC3339))
+
+	assert.Nil(t, DateWithTimeOfDay(date, "", jakarta))
+	assert.Nil(t, DateWithTimeOfDay(date, "25:00", jakarta))
+	assert.Nil(t, DateWithTimeOfDay(time.Time{}, "18:05", jakarta))
+}
@@ -0,0 +1,35 @@
+package domain
+
+// InboundEmail is a forwarded email as parsed by the inbound mail provider
+type InboundEmail struct {
+	To          []string          `json:"to"` // Recipient addresses, one of which carries the user's ingest token
+	From        string            `json:"from"`
+	Subject     string            `json:"subject"`
+	Attachments []EmailAttachment `json:"attachments"`
+}
+
+// EmailAttachment is one file attached to an inbound email
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content" swaggertype:"string" format:"base64"` // Base64 encoded in JSON
+}
+
+// SkippedAttachment is an attachment that did not produce a receipt, with the reason
+type SkippedAttachment struct {
+	Filename string `json:"filename"`
+	Reason   string `json:"reason"`
+}
+
+// EmailIngestResult reports which attachments of an inbound email were queued to be scanned into receipts
+type EmailIngestResult struct {
+	UserID  string              `json:"-"`
+	Queued  []string            `json:"queued"` // Filenames of the attachments being scanned
+	Skipped []SkippedAttachment `json:"skipped"`
+}
+
+// EmailIngestAddress is the forwarding address a user sends receipts to
+type EmailIngestAddress struct {
+	Token   string `json:"token"`
+	Address string `json:"address,omitempty"` // receipts+<token>@<inbound domain>, omitted when no inbound domain is configured
+}
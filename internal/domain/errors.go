@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+// ErrServiceNotConfigured is returned when a required external service is missing its configuration.
+// It indicates a server-side misconfiguration rather than a problem with the request.
+var ErrServiceNotConfigured = errors.New("service is not configured")
+
+// ErrReceiptModified is returned when a conditional update finds the receipt changed since the
+// client read it, so applying the update would overwrite someone else's edit.
+var ErrReceiptModified = errors.New("receipt was modified since it was last read")
+
+// ErrImageUndecodable is returned when an uploaded image is in a supported format but its data
+// cannot be decoded, usually because the upload was corrupted. Uploading it again may help.
+var ErrImageUndecodable = errors.New("image could not be decoded")
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"math"
+	"strings"
+)
+
+// Scanned fields tracked by extraction feedback
+const (
+	FeedbackFieldMerchant = "merchant"
+	FeedbackFieldDate     = "date"
+	FeedbackFieldTotal    = "total"
+	FeedbackFieldItems    = "items"
+)
+
+// ExtractionFeedback records whether one scanned field was corrected when the user first edited
+// the receipt. It holds nothing about the user or the receipt.
+type ExtractionFeedback struct {
+	Field            string
+	Corrected        bool
+	ExtractionMethod string
+}
+
+// FieldAccuracy summarizes extraction feedback for one field and extractor
+type FieldAccuracy struct {
+	Field            string  `json:"field"`
+	ExtractionMethod string  `json:"extractionMethod"`
+	Reviewed         int     `json:"reviewed"`    // Scanned receipts edited by consenting users
+	Corrections      int     `json:"corrections"` // Of those, the edits that changed this field
+	Accuracy         float64 `json:"accuracy"`    // Share of reviewed receipts whose field was kept, 0 to 1
+}
+
+// ScanCorrections compares a scanned receipt with the user's edit of it and reports, for each
+// tracked field, whether the edit corrected it. Merchants match ignoring case and surrounding
+// spaces, dates by day, totals to the cent, and items as a set by name, quantity, price and currency.
+func ScanCorrections(scanned, edited *Receipt) []ExtractionFeedback {
+	corrected := map[string]bool{
+		FeedbackFieldMerchant: !strings.EqualFold(strings.TrimSpace(scanned.Merchant), strings.TrimSpace(edited.Merchant)),
+		FeedbackFieldDate:     scanned.Date.Format("2006-01-02") != edited.Date.Format("2006-01-02"),
+		FeedbackFieldTotal:    math.Round(scanned.Total*100) != math.Round(edited.Total*100),
+		FeedbackFieldItems:    !sameItems(scanned.Items, edited.Items),
+	}
+
+	feedback := make([]ExtractionFeedback, 0, len(corrected))
+	for _, field := range []string{FeedbackFieldMerchant, FeedbackFieldDate, FeedbackFieldTotal, FeedbackFieldItems} {
+		feedback = append(feedback, ExtractionFeedback{
+			Field:            field,
+			Corrected:        corrected[field],
+			ExtractionMethod: scanned.ExtractionMethod,
+		})
+	}
+	return feedback
+}
+
+// sameItems reports whether two item lists hold the same purchase lines in any order
+func sameItems(a, b []ReceiptItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(a))
+	for _, item := range b {
+		found := false
+		for i := range a {
+			if !matched[i] && sameReceiptItem(a[i], item) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -53,16 +53,43 @@ type LineItem struct {
 
 // Invoice represents the core domain entity for an invoice
 type Invoice struct {
-	VendorName     string     `json:"vendor_name"`
-	InvoiceNumber  string     `json:"invoice_number"`
-	InvoiceDate    DateOnly   `json:"invoice_date"`
-	DueDate        DateOnly   `json:"due_date"`
-	Items          []LineItem `json:"items"`
-	Subtotal       float64    `json:"subtotal"`
-	TaxRatePercent float64    `json:"tax_rate_percent"`
-	TaxAmount      float64    `json:"tax_amount"`
-	Discount       float64    `json:"discount"`
-	TotalDue       float64    `json:"total_due"`
+	VendorName     string      `json:"vendor_name"`
+	InvoiceNumber  string      `json:"invoice_number"`
+	InvoiceDate    DateOnly    `json:"invoice_date"`
+	InvoiceTime    string      `json:"invoice_time,omitempty"` // Time of purchase as HH:MM, if printed
+	DueDate        DateOnly    `json:"due_date"`
+	Items          []LineItem  `json:"items"`
+	Subtotal       float64     `json:"subtotal"`
+	TaxRatePercent float64     `json:"tax_rate_percent"`
+	TaxAmount      float64     `json:"tax_amount"`
+	Tip            float64     `json:"tip"`
+	ServiceCharge  float64     `json:"service_charge"`
+	Discount       float64     `json:"discount"`
+	TotalDue       float64     `json:"total_due"`
+	Confidence     *float64    `json:"confidence,omitempty"`     // Extractor's confidence between 0 and 1
+	PaymentMethod  string      `json:"payment_method,omitempty"` // How the invoice was paid, e.g. "cash" or "card", if shown
+	TaxID          string      `json:"tax_id,omitempty"`         // Seller's tax or registration number, e.g. NPWP or VAT ID, if shown
+	Usage          *TokenUsage `json:"-"`                        // Model usage of the extraction, if the extractor reports it
+
+	// Alternatives holds other plausible readings of the fields the extractor was unsure of,
+	// keyed by receipt field (merchant, date, total, ...)
+	Alternatives map[string][]string `json:"alternatives,omitempty"`
+}
+
+// TokenUsage is the model usage an extraction request was billed for
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64 // In OpenRouter credits (USD), 0 when not reported
+}
+
+// Add accumulates another request's usage, as for the pages of one receipt
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.Cost += other.Cost
 }
 
 // NewInvoice creates a new invoice with default values
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// quantityUnits are size and strength units that describe a package rather than the product
+var quantityUnits = map[string]bool{
+	"%": true, "percent": true, "pct": true,
+	"ml": true, "l": true, "ltr": true, "liter": true, "litre": true,
+	"g": true, "gr": true, "gram": true, "grams": true, "kg": true,
+	"oz": true, "floz": true, "lb": true, "lbs": true,
+	"pc": true, "pcs": true, "pk": true, "pack": true, "x": true,
+}
+
+// ItemNameRules configures how item names are reduced to grouping keys in insights
+type ItemNameRules struct {
+	StripQuantities bool     // Drop sizes and strengths such as "2%", "2 percent", "500ml" or "6 pack"
+	IgnoredWords    []string // Words dropped from the key regardless of case, such as store brands
+}
+
+// DefaultItemNameRules strips quantities and ignores no words
+func DefaultItemNameRules() ItemNameRules {
+	return ItemNameRules{StripQuantities: true}
+}
+
+// NormalizeItemName reduces an item name to a grouping key: lowercased, punctuation replaced by
+// spaces and whitespace collapsed, then quantities and ignored words removed per the rules.
+// "MILK 2%", "milk 2 percent" and "Milk" all normalize to "milk". When the rules would remove
+// every word, the key keeps them instead.
+func (r ItemNameRules) NormalizeItemName(name string) string {
+	fields := strings.FieldsFunc(apostrophes.Replace(strings.ToLower(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%' && r != '.'
+	})
+
+	kept := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, ".")
+		if field == "" || r.isIgnored(field) {
+			continue
+		}
+		if r.StripQuantities && isQuantity(field) {
+			continue
+		}
+		kept = append(kept, field)
+	}
+
+	if len(kept) == 0 {
+		return strings.Join(strings.Fields(strings.Join(fields, " ")), " ")
+	}
+	return strings.Join(kept, " ")
+}
+
+// isIgnored reports whether a word is one of the rules' ignored words
+func (r ItemNameRules) isIgnored(word string) bool {
+	for _, ignored := range r.IgnoredWords {
+		if strings.EqualFold(word, ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuantity reports whether a word is a number, a unit, or a number with a unit like "2%" or "1.5l"
+func isQuantity(word string) bool {
+	unit := strings.TrimLeftFunc(word, func(r rune) bool {
+		return unicode.IsDigit(r) || r == '.'
+	})
+	return unit == "" || quantityUnits[unit]
+}
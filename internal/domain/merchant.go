@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// apostrophes are dropped rather than split on, so "Trader Joe's" keeps "joes" as one word
+var apostrophes = strings.NewReplacer("'", "", "’", "")
+
+// NormalizeMerchant reduces a merchant name to a grouping key: lowercased, punctuation replaced
+// by spaces, whitespace collapsed and trailing store numbers such as "#1234" or "0042" removed.
+// "WALMART #1234" and "Walmart" both normalize to "walmart".
+func NormalizeMerchant(merchant string) string {
+	fields := strings.FieldsFunc(apostrophes.Replace(strings.ToLower(merchant)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#'
+	})
+
+	// Drop trailing store numbers, keeping at least one word
+	for len(fields) > 1 && isStoreNumber(fields[len(fields)-1]) {
+		fields = fields[:len(fields)-1]
+	}
+
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, "#")
+	}
+	return strings.Join(strings.Fields(strings.Join(fields, " ")), " ")
+}
+
+// isStoreNumber reports whether a word is a branch number like "#1234", "no1234" or "0042"
+func isStoreNumber(word string) bool {
+	word = strings.TrimPrefix(strings.TrimPrefix(word, "#"), "no")
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
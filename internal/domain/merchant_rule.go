@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MerchantRule assigns a category to items from a merchant for a user
+type MerchantRule struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Merchant  string    `json:"merchant"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Sources of an auto-classified category
+const (
+	ClassificationMerchantRule = "merchant_rule" // The user's rule for the merchant
+	ClassificationKeyword      = "keyword"       // A built-in keyword in the description
+	ClassificationDefault      = "default"       // Nothing matched
+)
+
+// CategoryClassification is the category auto-classification gives a text and the rule that decided it
+type CategoryClassification struct {
+	Category string `json:"category"`
+	Source   string `json:"source"`
+	RuleID   string `json:"ruleId,omitempty"` // The matching merchant rule
+	Match    string `json:"match,omitempty"`  // The rule's merchant or the keyword found in the text
+}
+
+// MerchantRuleKey normalizes a merchant name for rule matching
+func MerchantRuleKey(merchant string) string {
+	return strings.ToLower(strings.TrimSpace(merchant))
+}
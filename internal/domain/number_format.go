@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat is a locale's convention for writing amounts
+type NumberFormat struct {
+	Locale   string
+	Grouping string // Separator between groups of three integer digits
+	Decimal  string // Mark before the fractional digits
+}
+
+// numberFormats lists the supported number locales by lower-case tag
+var numberFormats = map[string]NumberFormat{
+	"en-us": {Locale: "en-US", Grouping: ",", Decimal: "."},
+	"en-gb": {Locale: "en-GB", Grouping: ",", Decimal: "."},
+	"ja-jp": {Locale: "ja-JP", Grouping: ",", Decimal: "."},
+	"id-id": {Locale: "id-ID", Grouping: ".", Decimal: ","},
+	"de-de": {Locale: "de-DE", Grouping: ".", Decimal: ","},
+	"es-es": {Locale: "es-ES", Grouping: ".", Decimal: ","},
+	"it-it": {Locale: "it-IT", Grouping: ".", Decimal: ","},
+	"nl-nl": {Locale: "nl-NL", Grouping: ".", Decimal: ","},
+	"pt-br": {Locale: "pt-BR", Grouping: ".", Decimal: ","},
+	"fr-fr": {Locale: "fr-FR", Grouping: "\u202f", Decimal: ","},
+	"de-ch": {Locale: "de-CH", Grouping: "\u2019", Decimal: "."},
+}
+
+// LookupNumberFormat returns the number format of a locale tag such as "de-DE" or "id_ID",
+// matched case-insensitively
+func LookupNumberFormat(locale string) (NumberFormat, bool) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	format, ok := numberFormats[key]
+	return format, ok
+}
+
+// FormatAmount writes an amount with two decimal places in the format's convention,
+// e.g. 1234.56 as "1,234.56" in en-US and "1.234,56" in de-DE
+func (f NumberFormat) FormatAmount(amount float64) string {
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	integer, fraction := digits[:len(digits)-3], digits[len(digits)-2:]
+
+	var b strings.Builder
+	if amount < 0 && digits != "0.00" {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.Grouping)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(f.Decimal)
+	b.WriteString(fraction)
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		locale string
+		amount float64
+		want   string
+	}{
+		{"en-US", 1234.56, "1,234.56"},
+		{"id-ID", 1234.56, "1.234,56"},
+		{"de-DE", 1234.56, "1.234,56"},
+		{"fr-FR", 1234.56, "1\u202f234,56"},
+		{"en-US", 1234567.891, "1,234,567.89"},
+		{"de-DE", -1234.5, "-1.234,50"},
+		{"en-US", 999.999, "1,000.00"},
+		{"id-ID", 0, "0,00"},
+		{"en-US", -0.001, "0.00"},
+	}
+	for _, tt := range tests {
+		format, ok := LookupNumberFormat(tt.locale)
+		if assert.True(t, ok, tt.locale) {
+			assert.Equal(t, tt.want, format.FormatAmount(tt.amount), "%s %v", tt.locale, tt.amount)
+		}
+	}
+
+	format, ok := LookupNumberFormat(" id_id ")
+	assert.True(t, ok)
+	assert.Equal(t, "id-ID", format.Locale)
+
+	_, ok = LookupNumberFormat("xx-XX")
+	assert.False(t, ok)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Organization member roles
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleMember = "member"
+)
+
+// Organization groups users that share receipts
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember represents a user's membership in an organization
+type OrganizationMember struct {
+	OrganizationID string    `json:"organization_id"`
+	UserID         string    `json:"user_id"`
+	Email          string    `json:"email,omitempty"`
+	Name           string    `json:"name,omitempty"`
+	Role           string    `json:"role"` // OrganizationRoleOwner or OrganizationRoleMember
+	CreatedAt      time.Time `json:"created_at"`
+}
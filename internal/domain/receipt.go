@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 )
@@ -14,8 +15,43 @@ type ReceiptItem struct {
 	Price    float64 `json:"price"`
 	Currency string  `json:"currency,omitempty"`
 	Category string  `json:"category,omitempty"`
+
+	// Non-persisted price in another currency, set only when a conversion was requested
+	ConvertedPrice    *float64 `json:"convertedPrice,omitempty"`
+	ConvertedCurrency string   `json:"convertedCurrency,omitempty"`
+}
+
+// ItemCategories is the taxonomy of item categories, as assigned by keyword inference during scans
+var ItemCategories = []string{
+	"Food",
+	"Transport",
+	"Travel",
+	"Accommodation",
+	"Office Supplies",
+	"Professional Services",
+	"Other",
 }
 
+// Receipt review statuses
+const (
+	ReceiptStatusVerified   = "verified"
+	ReceiptStatusUnverified = "unverified"
+)
+
+// Receipt payment methods. Receipts without a captured method are reported as PaymentMethodUnknown.
+const (
+	PaymentMethodCash    = "cash"
+	PaymentMethodCard    = "card"
+	PaymentMethodUnknown = "Unknown"
+)
+
+// Receipt extraction methods, recording which extractor produced a scanned receipt
+const (
+	ExtractionMethodMLX                = "mlx"
+	ExtractionMethodOpenRouter         = "openrouter"
+	ExtractionMethodOpenRouterFallback = "openrouter_fallback" // OpenRouter after the MLX extraction failed
+)
+
 // Receipt represents a scanned or manually entered receipt
 type Receipt struct {
 	ID         string        `json:"id"`
@@ -28,8 +64,43 @@ type Receipt struct {
 	Items      []ReceiptItem `json:"items"`
 	ImageURL   string        `json:"image_url,omitempty"`
 	ReceiptURL string        `json:"receipt_url,omitempty"`
+	SourceURL  string        `json:"source_url,omitempty"` // Remote URL the image was fetched from, if scanned by URL
+	Status     string        `json:"status,omitempty"`     // ReceiptStatusVerified or ReceiptStatusUnverified
+	Confidence *float64      `json:"confidence,omitempty"` // Extraction confidence between 0 and 1, if reported
+	Warnings   []string      `json:"warnings,omitempty"`   // Non-persisted notices produced while scanning
+	OrgID      string        `json:"org_id,omitempty"`     // Organization the receipt is shared with, if any
+	Category   string        `json:"category,omitempty"`   // Receipt-level category, inherited in insights by items without one
 	CreatedAt  time.Time     `json:"created_at"`
 	UpdatedAt  time.Time     `json:"updated_at"`
+
+	PurchasedAt *time.Time `json:"purchased_at,omitempty"` // Time of purchase, if the receipt shows one
+
+	PaymentMethod    string `json:"payment_method,omitempty"`    // PaymentMethodCash, PaymentMethodCard or another method, if captured
+	ExtractionMethod string `json:"extraction_method,omitempty"` // One of the ExtractionMethod constants, empty for manually entered receipts
+	TaxID            string `json:"tax_id,omitempty"`            // Seller's tax or registration number (NPWP, VAT ID), if printed
+
+	// Charges on top of the subtotal and tax, counted in the total
+	Tip           float64 `json:"tip,omitempty"`
+	ServiceCharge float64 `json:"service_charge,omitempty"`
+
+	// UnmodifiedSince is a non-persisted update precondition: when set, the update only
+	// applies if the stored receipt has not changed since this time (second precision)
+	UnmodifiedSince *time.Time `json:"-"`
+
+	// Extraction is non-persisted: how long the scan's extraction took and the model usage it
+	// reported, set on receipts returned by the scan endpoints
+	Extraction *ExtractionStats `json:"-"`
+
+	// Alternatives is non-persisted: other plausible readings of the fields the extractor was
+	// unsure of, keyed by response field, set on receipts returned by the scan endpoints
+	Alternatives map[string][]string `json:"-"`
+}
+
+// ExtractionStats describes the extraction of a scanned receipt
+type ExtractionStats struct {
+	Latency time.Duration // Time spent extracting every page, including image uploads
+	Usage   *TokenUsage   // Summed over the pages, nil when the extractor reported none
+	Partial bool          // The processing deadline passed before every page was extracted
 }
 
 // FlexibleDate is a custom type that can unmarshal multiple date formats
@@ -37,31 +108,20 @@ type FlexibleDate struct {
 	time.Time
 }
 
-// UnmarshalJSON implements custom JSON unmarshaling for FlexibleDate
+// UnmarshalJSON implements custom JSON unmarshaling for FlexibleDate. A null or empty date
+// leaves the zero time, so required-field validation can report it; an unrecognized date
+// returns a *DateParseError. Ambiguous numeric dates are read day first.
 func (fd *FlexibleDate) UnmarshalJSON(b []byte) error {
-	s := strings.Trim(string(b), "\"")
-	if s == "null" || s == "" {
+	t, err := ParseFlexibleDate(strings.Trim(string(b), "\""), DayFirst)
+	if errors.Is(err, ErrEmptyDate) {
 		fd.Time = time.Time{}
 		return nil
 	}
-
-	// Try multiple date formats
-	formats := []string{
-		"2006-01-02",          // YYYY-MM-DD
-		time.RFC3339,          // 2006-01-02T15:04:05Z07:00
-		"2006-01-02T15:04:05", // Without timezone
-		time.RFC3339Nano,      // With nanoseconds
-	}
-
-	var err error
-	for _, format := range formats {
-		fd.Time, err = time.Parse(format, s)
-		if err == nil {
-			return nil
-		}
+	if err != nil {
+		return err
 	}
-
-	return err
+	fd.Time = t
+	return nil
 }
 
 // MarshalJSON implements custom JSON marshaling for FlexibleDate
@@ -72,14 +132,59 @@ func (fd FlexibleDate) MarshalJSON() ([]byte, error) {
 	return json.Marshal(fd.Time.Format(time.RFC3339))
 }
 
+// HasTimeOfDay reports whether the date carries a time of day. Dates given without a time are
+// held as midnight UTC, so a purchase at exactly midnight UTC reads as a date alone.
+func (fd FlexibleDate) HasTimeOfDay() bool {
+	utc := fd.Time.UTC()
+	return !fd.Time.IsZero() && (utc.Hour() != 0 || utc.Minute() != 0 || utc.Second() != 0 || utc.Nanosecond() != 0)
+}
+
+// PurchaseTime returns when the purchase was made: PurchasedAt when known, otherwise the receipt
+// date when it carries a time of day, or nil for a receipt with only a date
+func (r *Receipt) PurchaseTime() *time.Time {
+	if r.PurchasedAt != nil {
+		return r.PurchasedAt
+	}
+	if r.Date.HasTimeOfDay() {
+		t := r.Date.Time
+		return &t
+	}
+	return nil
+}
+
 // ReceiptFilter represents filters for querying receipts
 type ReceiptFilter struct {
-	UserID    string
-	StartDate *time.Time
-	EndDate   *time.Time
-	Merchant  string
-	Page      int
-	Limit     int
+	UserID      string
+	AllUsers    bool // Admin listing across every user's receipts; UserID then only narrows to one owner when set
+	StartDate   *time.Time
+	EndDate     *time.Time
+	Merchant    string
+	Category    string // Only receipts with at least one item in the category
+	TaxID       string // Only receipts from the seller with this tax or registration number
+	NeedsReview bool   // Only unverified receipts
+	HasImage    *bool  // When set, only receipts with (true) or without (false) a stored image
+	// ConfidenceBelow, when set, only includes receipts with a lower or unknown extraction confidence
+	ConfidenceBelow *float64
+	OrgID           string // When set, lists receipts shared with the organization instead of the user's own
+	// ModifiedSince, when set, only includes receipts updated after this time, for incremental sync
+	ModifiedSince *time.Time
+	SortBy        string // date (default), total, merchant, createdAt or updatedAt
+	SortOrder     string // desc (default) or asc
+	Page          int
+	Limit         int
+	// RequestedLimit is the page size the client asked for, when it exceeded the maximum and Limit was reduced
+	RequestedLimit int
+}
+
+// ReceiptScope selects whose receipts listing and insights cover.
+// UserID is always the requesting user; when OrgID is set the organization's shared receipts are covered instead.
+// Insights additionally leave out spend in ExcludeCategories and receipts from ExcludeMerchants, both matched case-insensitively.
+type ReceiptScope struct {
+	UserID            string
+	OrgID             string
+	ExcludeCategories []string
+	ExcludeMerchants  []string
+	RawMerchants      bool // Group merchant insights by the name as written instead of the normalized merchant
 }
 
 // Pagination represents pagination metadata
@@ -94,6 +199,8 @@ type Pagination struct {
 type PaginatedReceipts struct {
 	Data       []Receipt  `json:"data"`
 	Pagination Pagination `json:"pagination"`
+	// DeletedIDs lists the user's receipts deleted after the filter's ModifiedSince, when it is set
+	DeletedIDs []string `json:"deletedIds,omitempty"`
 }
 
 // DashboardSummary represents summary data for the dashboard
@@ -147,9 +254,28 @@ type CategorySpendingItem struct {
 
 // CategorySpendingItemDetail represents detailed spending data for items in a category
 type CategorySpendingItemDetail struct {
-	Name       string  `json:"name"`
-	TotalSpent float64 `json:"totalSpent"`
-	Count      int     `json:"count"`
+	Name          string  `json:"name"`
+	TotalSpent    float64 `json:"totalSpent"`
+	Count         int     `json:"count"`         // Number of line items the item appears on
+	TotalQuantity int     `json:"totalQuantity"` // Units purchased across those line items
+}
+
+// ReceiptStats represents metrics derived from a single receipt's items
+type ReceiptStats struct {
+	ReceiptID        string                    `json:"receiptId"`
+	ItemCount        int                       `json:"itemCount"`        // Number of line items
+	Subtotal         float64                   `json:"subtotal"`         // Sum of item prices times quantities
+	Categories       []ReceiptCategorySubtotal `json:"categories"`       // One entry per distinct category, largest first
+	AverageItemPrice float64                   `json:"averageItemPrice"` // Subtotal divided by units purchased
+	MaxItemPrice     float64                   `json:"maxItemPrice"`
+	TaxRatio         float64                   `json:"taxRatio"` // Tax divided by subtotal, 0 when the subtotal is 0
+}
+
+// ReceiptCategorySubtotal is the subtotal of one category's items within a receipt
+type ReceiptCategorySubtotal struct {
+	Category  string  `json:"category"`
+	Subtotal  float64 `json:"subtotal"`
+	ItemCount int     `json:"itemCount"`
 }
 
 // MerchantFrequency represents data on frequently visited merchants
@@ -160,11 +286,24 @@ type MerchantFrequency struct {
 
 // MerchantFrequencyDetail represents detailed data for a merchant's frequency
 type MerchantFrequencyDetail struct {
-	Name         string  `json:"name"`
-	Visits       int     `json:"visits"`
-	TotalSpent   float64 `json:"totalSpent"`
-	AverageSpent float64 `json:"averageSpent"`
-	Percentage   float64 `json:"percentage"`
+	Name              string  `json:"name"`
+	Visits            int     `json:"visits"`
+	TotalSpent        float64 `json:"totalSpent"`
+	AverageSpent      float64 `json:"averageSpent"`
+	Percentage        float64 `json:"percentage"`
+	AverageItems      float64 `json:"averageItems"`      // Item quantity per visit
+	AverageCategories float64 `json:"averageCategories"` // Distinct item categories per visit
+}
+
+// SetBasket sets the merchant's average basket from the item quantity and the distinct
+// categories summed over all of its visits. Visits without items count as empty baskets.
+func (d *MerchantFrequencyDetail) SetBasket(totalItems, totalCategories int) {
+	if d.Visits == 0 {
+		d.AverageItems, d.AverageCategories = 0, 0
+		return
+	}
+	d.AverageItems = float64(totalItems) / float64(d.Visits)
+	d.AverageCategories = float64(totalCategories) / float64(d.Visits)
 }
 
 // MonthlyComparison represents a comparison between two months
@@ -186,3 +325,82 @@ type MonthlyCategoryComparison struct {
 	Difference       float64 `json:"difference"`
 	PercentageChange float64 `json:"percentageChange"`
 }
+
+// PaymentMethodTotal represents the spending for a single stored payment method, empty if not captured
+type PaymentMethodTotal struct {
+	Method string  `json:"method"`
+	Amount float64 `json:"amount"`
+	Count  int     `json:"count"`
+}
+
+// HourlySpend is the spending on receipts purchased within one hour of the day, 0 to 23
+type HourlySpend struct {
+	Hour  int     `json:"hour"`
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+// HourlySpending is the spending in each of the 24 hours of the day in a time zone. Only
+// receipts with a time of purchase are counted.
+type HourlySpending struct {
+	Timezone string        `json:"timezone"`
+	Hours    []HourlySpend `json:"hours"`
+}
+
+// PaymentMethodSpending represents spending breakdown by payment method
+type PaymentMethodSpending struct {
+	Total   float64                     `json:"total"`
+	Methods []PaymentMethodSpendingItem `json:"methods"`
+}
+
+// PaymentMethodSpendingItem represents spending data for a single payment method
+type PaymentMethodSpendingItem struct {
+	Method     string  `json:"method"`
+	Amount     float64 `json:"amount"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// CurrencyUsage counts the receipts and items a user recorded in one currency
+type CurrencyUsage struct {
+	Currency     string `json:"currency"`
+	ReceiptCount int    `json:"receiptCount"`
+	ItemCount    int    `json:"itemCount"`
+}
+
+// MonthlySpendTotal represents the total spending for a single month
+type MonthlySpendTotal struct {
+	Month  string  `json:"month"`
+	Amount float64 `json:"amount"`
+}
+
+// MonthlyCategorySpend represents the spending for a category within a single month
+type MonthlyCategorySpend struct {
+	Month    string  `json:"month"`
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+// SpendingAnomaly represents a month's spending compared against its trailing baseline
+type SpendingAnomaly struct {
+	Month          string            `json:"month"`
+	BaselineMonths int               `json:"baselineMonths"`
+	Threshold      float64           `json:"threshold"`
+	Total          AnomalyMetric     `json:"total"`
+	Categories     []CategoryAnomaly `json:"categories"`
+}
+
+// AnomalyMetric represents an amount compared against its baseline average
+type AnomalyMetric struct {
+	Amount    float64 `json:"amount"`
+	Baseline  float64 `json:"baseline"`
+	StdDev    float64 `json:"stdDev"`
+	ZScore    float64 `json:"zScore"`
+	IsAnomaly bool    `json:"isAnomaly"`
+}
+
+// CategoryAnomaly represents a category's spending compared against its baseline average
+type CategoryAnomaly struct {
+	Name string `json:"name"`
+	AnomalyMetric
+}
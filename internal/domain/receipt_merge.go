@@ -0,0 +1,69 @@
+package domain
+
+import "strings"
+
+// MergeReceipts combines a receipt with a duplicate record of the same purchase. The kept receipt's
+// fields win; empty ones are filled from the duplicate. The duplicate's items are added unless kept
+// already has the same item, matched by name regardless of case, quantity, price and currency, with
+// each of kept's items matching at most one duplicate item. It returns the merged receipt and the
+// duplicate's items that were added, leaving the receipts themselves unchanged.
+func MergeReceipts(kept, duplicate Receipt) (Receipt, []ReceiptItem) {
+	merged := kept
+	merged.Items = append([]ReceiptItem(nil), kept.Items...)
+
+	if merged.Merchant == "" {
+		merged.Merchant = duplicate.Merchant
+	}
+	if merged.PaymentMethod == "" {
+		merged.PaymentMethod = duplicate.PaymentMethod
+	}
+	if merged.Category == "" {
+		merged.Category = duplicate.Category
+	}
+	if merged.TaxID == "" {
+		merged.TaxID = duplicate.TaxID
+	}
+	if merged.ReceiptURL == "" {
+		merged.ReceiptURL = duplicate.ReceiptURL
+	}
+	if merged.SourceURL == "" {
+		merged.SourceURL = duplicate.SourceURL
+	}
+	if merged.PurchaseTime() == nil {
+		merged.PurchasedAt = duplicate.PurchaseTime()
+	}
+	if merged.Tax == 0 {
+		merged.Tax = duplicate.Tax
+	}
+	if merged.Tip == 0 {
+		merged.Tip = duplicate.Tip
+	}
+	if merged.ServiceCharge == 0 {
+		merged.ServiceCharge = duplicate.ServiceCharge
+	}
+
+	matched := make([]bool, len(kept.Items))
+	var moved []ReceiptItem
+	for _, item := range duplicate.Items {
+		found := false
+		for i, existing := range kept.Items {
+			if !matched[i] && sameReceiptItem(existing, item) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			moved = append(moved, item)
+		}
+	}
+	merged.Items = append(merged.Items, moved...)
+
+	return merged, moved
+}
+
+// sameReceiptItem reports whether two items record the same purchase line
+func sameReceiptItem(a, b ReceiptItem) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name)) &&
+		a.Quantity == b.Quantity && a.Price == b.Price && strings.EqualFold(a.Currency, b.Currency)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerchantFrequencySetBasket(t *testing.T) {
+	// Three bakery visits: 2 bread and a coffee (Food, Drinks), 1 cake (Food) and one without items
+	baskets := []struct{ items, categories int }{{3, 2}, {1, 1}, {0, 0}}
+	merchant := MerchantFrequencyDetail{Name: "Bakery", Visits: len(baskets)}
+
+	var totalItems, totalCategories int
+	for _, basket := range baskets {
+		totalItems += basket.items
+		totalCategories += basket.categories
+	}
+	merchant.SetBasket(totalItems, totalCategories)
+
+	// (3 + 1 + 0) / 3 items and (2 + 1 + 0) / 3 categories per visit
+	assert.InDelta(t, 4.0/3, merchant.AverageItems, 0.0001)
+	assert.InDelta(t, 1.0, merchant.AverageCategories, 0.0001)
+
+	empty := MerchantFrequencyDetail{Name: "Unknown"}
+	empty.SetBasket(0, 0)
+	assert.Zero(t, empty.AverageItems)
+}
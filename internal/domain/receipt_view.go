@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// ReceiptView is a named set of receipt listing parameters saved by a user
+type ReceiptView struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Name      string            `json:"name"`
+	Filters   map[string]string `json:"filters"` // Listing query parameters such as startDate, category and sortBy
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Reprocess job statuses
+const (
+	ReprocessStatusRunning   = "running"
+	ReprocessStatusCompleted = "completed"
+)
+
+// ReprocessFilter selects the receipts whose stored images are extracted again
+type ReprocessFilter struct {
+	ConfidenceBelow float64 // Receipts with a lower or unknown extraction confidence
+	StartDate       *time.Time
+	EndDate         *time.Time
+}
+
+// ReprocessJob reports the progress of re-running extraction over stored receipt images.
+// Receipts are only updated when the new extraction is more confident than the stored one.
+type ReprocessJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Matched    int        `json:"matched"`   // Receipts selected by the filter
+	Processed  int        `json:"processed"` // Receipts handled so far, whatever the outcome
+	Improved   int        `json:"improved"`  // Receipts updated with a more confident extraction
+	Unchanged  int        `json:"unchanged"` // Receipts whose new extraction was not more confident
+	Skipped    int        `json:"skipped"`   // Receipts edited out of the filter or deleted since matching
+	Failed     int        `json:"failed"`    // Receipts whose image could not be extracted
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
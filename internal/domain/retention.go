@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// RetentionPolicy decides which receipts and stored images the retention job removes
+type RetentionPolicy struct {
+	ReceiptMaxAge time.Duration // Receipts created longer ago are deleted with their images, 0 keeps receipts
+	ImageMaxAge   time.Duration // Stored images of receipts created longer ago are deleted, keeping the receipt; 0 keeps images
+	BatchSize     int           // Receipts handled per batch
+	DryRun        bool          // Only log what would be removed
+}
+
+// RetentionCandidate is a receipt the retention policy applies to, with its stored image if any
+type RetentionCandidate struct {
+	ID         string
+	ReceiptURL string
+	CreatedAt  time.Time
+}
+
+// RetentionResult reports what a retention run removed, or would remove in a dry run
+type RetentionResult struct {
+	ReceiptsDeleted int  `json:"receiptsDeleted"`
+	ImagesDeleted   int  `json:"imagesDeleted"`
+	Failed          int  `json:"failed"` // Receipts or images left in place because deleting their image failed
+	DryRun          bool `json:"dryRun"`
+}
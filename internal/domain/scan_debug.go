@@ -0,0 +1,12 @@
+package domain
+
+// ScanDebug shows the stages of a receipt scan for tuning preprocessing and prompts
+type ScanDebug struct {
+	OriginalSize      int      // Size of the uploaded image in bytes
+	PreprocessedImage []byte   // The image after resizing, as stored for the receipt
+	PreprocessedSize  int      // Size of the preprocessed image in bytes
+	ExtractionMethod  string   // ExtractionMethod constant of the extractor used
+	RawOutput         string   // The extractor's raw response, when it exposes one
+	Invoice           *Invoice // The parsed result, nil when extraction failed
+	ExtractionError   string   // Why extraction failed, empty on success
+}
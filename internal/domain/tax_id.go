@@ -0,0 +1,10 @@
+package domain
+
+import "strings"
+
+// NormalizeTaxID tidies a tax or registration number as printed, such as an NPWP or VAT ID, by
+// trimming it, collapsing inner whitespace and uppercasing letters. Punctuation is kept since
+// formats like 01.234.567.8-901.000 rely on it.
+func NormalizeTaxID(taxID string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(taxID), " "))
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// maxTrendWindowDays bounds rolling windows to ten years of days, months or years
+const maxTrendWindowDays = 3660
+
+// TrendWindow is a rolling span of days or months ending today, such as the last 30 days or 12 months.
+// Windows given in years are kept as months.
+type TrendWindow struct {
+	Days   int
+	Months int
+}
+
+// ParseTrendWindow parses a window such as "30d", "12m" or "1y"
+func ParseTrendWindow(value string) (TrendWindow, error) {
+	invalid := fmt.Errorf("window must be a positive number followed by d, m or y, e.g. 30d, 12m or 1y")
+	if len(value) < 2 {
+		return TrendWindow{}, invalid
+	}
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count <= 0 {
+		return TrendWindow{}, invalid
+	}
+
+	var window TrendWindow
+	switch value[len(value)-1] {
+	case 'd':
+		window.Days = count
+	case 'm':
+		window.Months = count
+	case 'y':
+		window.Months = count * 12
+	default:
+		return TrendWindow{}, invalid
+	}
+	if window.Days > maxTrendWindowDays || window.Months > maxTrendWindowDays/30 {
+		return TrendWindow{}, fmt.Errorf("window must not exceed 10 years")
+	}
+	return window, nil
+}
+
+// Range returns the first and last local days of the window ending on the day containing now.
+// Day windows end today and include it; month windows start on the first of the month so the
+// current month is the last of their calendar months.
+func (w TrendWindow) Range(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	if w.Days > 0 {
+		return today.AddDate(0, 0, 1-w.Days), today
+	}
+	return time.Date(today.Year(), today.Month()-time.Month(w.Months-1), 1, 0, 0, 0, 0, loc), today
+}
+
+// Period returns the trend granularity suited to the window: daily up to a month of days,
+// weekly up to six months, monthly up to three years and yearly beyond
+func (w TrendWindow) Period() string {
+	days := w.Days + w.Months*30
+	switch {
+	case days <= 31:
+		return "daily"
+	case days <= 180:
+		return "weekly"
+	case days <= 3*365:
+		return "monthly"
+	default:
+		return "yearly"
+	}
+}
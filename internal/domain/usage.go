@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// ScanUsage is a user's receipt scans in the current calendar month (UTC) against the monthly quota
+type ScanUsage struct {
+	MonthlyQuota int       // Scans allowed per month, 0 when unlimited
+	Used         int       // Scans made since the month started
+	ResetsAt     time.Time // Start of the next month, when the count starts over
+}
+
+// Remaining returns the scans left this month, or -1 when scans are unlimited
+func (u ScanUsage) Remaining() int {
+	if u.MonthlyQuota <= 0 {
+		return -1
+	}
+	return max(u.MonthlyQuota-u.Used, 0)
+}
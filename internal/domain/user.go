@@ -13,10 +13,34 @@ type User struct {
 	PictureURL    string    `json:"pictureUrl,omitempty"`
 	EmailVerified bool      `json:"emailVerified"`
 	IsActive      bool      `json:"isActive"`
+	Role          string    `json:"role"` // UserRoleUser or UserRoleAdmin
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
+// User roles. Admins can use the /v1/admin endpoints.
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// UserFilter represents filtering and pagination options for the admin user listing
+type UserFilter struct {
+	Email          string // Case-insensitive partial match on the email
+	Name           string // Case-insensitive partial match on the name
+	Role           string // UserRoleUser or UserRoleAdmin
+	Active         *bool  // When set, only active (true) or deactivated (false) users
+	Page           int
+	Limit          int
+	RequestedLimit int // Limit asked for when it exceeded the maximum and was clamped, 0 otherwise
+}
+
+// PaginatedUsers represents a paginated list of users
+type PaginatedUsers struct {
+	Data       []User     `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
 // OAuthProvider represents an OAuth provider linked to a user
 type OAuthProvider struct {
 	ID             string                 `json:"id"`
@@ -29,6 +53,60 @@ type OAuthProvider struct {
 	UpdatedAt      time.Time              `json:"updatedAt"`
 }
 
+// LoginAttempts tracks a user's consecutive failed password logins
+type LoginAttempts struct {
+	FailedCount int        // Failures since the last successful login or lockout
+	LockedUntil *time.Time // Password logins are refused until this time, nil when never locked
+}
+
+// LockedAt reports whether password logins are locked at the given time
+func (a *LoginAttempts) LockedAt(at time.Time) bool {
+	return a != nil && a.LockedUntil != nil && at.Before(*a.LockedUntil)
+}
+
+// LinkedProvider is an OAuth provider as shown to its user, without provider IDs or profile data
+type LinkedProvider struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linkedAt"`
+}
+
+// UserPreferences holds a user's display and conversion defaults
+type UserPreferences struct {
+	DefaultCurrency string `json:"defaultCurrency"` // ISO 4217 code
+	Timezone        string `json:"timezone"`        // IANA time zone name
+	// Locale whose digit grouping and decimal mark are used for display amounts, e.g. de-DE; empty adds none
+	NumberLocale string `json:"numberLocale"`
+
+	// Consent to record anonymous feedback on scan accuracy when editing scanned receipts, off by default
+	ShareExtractionFeedback *bool `json:"shareExtractionFeedback,omitempty"`
+}
+
+// SharesExtractionFeedback reports whether the user consented to extraction feedback
+func (p UserPreferences) SharesExtractionFeedback() bool {
+	return p.ShareExtractionFeedback != nil && *p.ShareExtractionFeedback
+}
+
+// DefaultUserPreferences returns the preferences of users who have not saved any
+func DefaultUserPreferences() UserPreferences {
+	share := false
+	return UserPreferences{DefaultCurrency: "USD", Timezone: "UTC", ShareExtractionFeedback: &share}
+}
+
+// UserProfile is the authenticated user's view of their own account
+type UserProfile struct {
+	*User
+	Providers   []LinkedProvider `json:"providers"`
+	Preferences UserPreferences  `json:"preferences"`
+}
+
+// UserDataExport is everything stored about a user, assembled for download
+type UserDataExport struct {
+	ExportedAt time.Time    `json:"exportedAt"`
+	Profile    *UserProfile `json:"profile"` // Account, linked providers and preferences, without secrets
+	Receipts   []Receipt    `json:"receipts"`
+}
+
 // GoogleUserInfo represents user information from Google OAuth
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/model"
+)
+
+// maxActivityLimit is the largest page size returned by the activity feed
+const maxActivityLimit = 100
+
+// GetActivity handles the GET /activity endpoint
+// @Summary List recent activity
+// @Description List the receipts the authenticated user scanned, created, updated or deleted, newest first
+// @Tags receipts
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Entries per page, at most 100" default(20)
+// @Success 200 {object} map[string]interface{} "Activity entries with action, receiptId and createdAt, plus pagination"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Activity feed not configured"
+// @Security BearerAuth
+// @Router /v1/activity [get]
+func (h *ReceiptHandler) GetActivity(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, err := getQueryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("page", "Page must be a positive integer"))
+		return
+	}
+	limit, err := getQueryInt(c, "limit", 20)
+	if err != nil || limit < 1 || limit > maxActivityLimit {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("limit", fmt.Sprintf("Limit must be between 1 and %d", maxActivityLimit)))
+		return
+	}
+
+	feed, err := h.receiptService.ListActivity(c.Request.Context(), userID.(string), page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, "Activity feed is not configured")
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve activity: %v", err))
+		return
+	}
+
+	data := make([]gin.H, len(feed.Data))
+	for i, activity := range feed.Data {
+		data[i] = gin.H{
+			"id":        activity.ID,
+			"action":    activity.Action,
+			"receiptId": activity.ReceiptID,
+			"createdAt": activity.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	respondOK(c, gin.H{
+		"data": data,
+		"pagination": model.PaginationResponse{
+			TotalItems:  feed.Pagination.TotalItems,
+			TotalPages:  feed.Pagination.TotalPages,
+			CurrentPage: feed.Pagination.CurrentPage,
+			Limit:       feed.Pagination.Limit,
+		},
+	})
+}
@@ -0,0 +1,393 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// maxBackfillBatchSize bounds the rows updated in one backfill transaction
+const maxBackfillBatchSize = 5000
+
+// AdminHandler handles operator endpoints restricted to admin users
+type AdminHandler struct {
+	backfillService service.BackfillService
+	receiptService  service.ReceiptService
+	authService     service.AuthService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(backfillService service.BackfillService, receiptService service.ReceiptService, authService service.AuthService) *AdminHandler {
+	return &AdminHandler{
+		backfillService: backfillService,
+		receiptService:  receiptService,
+		authService:     authService,
+	}
+}
+
+// Backfill handles the POST /admin/backfill endpoint
+// @Summary Backfill a derived field
+// @Description Compute a derived field for existing rows that are missing it, in batches. Each call processes up to maxBatches batches and reports the rows still remaining; repeat until done is true. Calls only touch rows still missing the field, so they are safe to repeat or interrupt
+// @Tags admin
+// @Produce json
+// @Param field query string true "Derived field to fill: normalized_merchant"
+// @Param batchSize query int false "Rows per batch, at most 5000" default(500)
+// @Param maxBatches query int false "Batches to process in this call" default(10)
+// @Success 200 {object} domain.BackfillProgress "Backfill progress"
+// @Failure 400 {object} model.ErrorResponse "Unknown field or invalid batch parameters"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/admin/backfill [post]
+func (h *AdminHandler) Backfill(c *gin.Context) {
+	field := c.Query("field")
+
+	batchSize, err := strconv.Atoi(c.DefaultQuery("batchSize", "0"))
+	if err != nil || batchSize < 0 || batchSize > maxBackfillBatchSize {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("batchSize", fmt.Sprintf("Must be between 1 and %d", maxBackfillBatchSize)))
+		return
+	}
+	maxBatches, err := strconv.Atoi(c.DefaultQuery("maxBatches", "0"))
+	if err != nil || maxBatches < 0 {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("maxBatches", "Must be a positive number"))
+		return
+	}
+
+	progress, err := h.backfillService.Backfill(c.Request.Context(), field, batchSize, maxBatches)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownBackfillField) {
+			respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("field", "Must be one of: "+strings.Join(service.BackfillFields(), ", ")))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to backfill %s: %v", field, err))
+		}
+		return
+	}
+
+	respondOK(c, progress)
+}
+
+// ListReceipts handles the GET /admin/receipts endpoint
+// @Summary List receipts of all users
+// @Description Browse any user's receipts for support and extraction debugging, with the same filters as the receipts listing. Each receipt includes its owner's userId
+// @Tags admin
+// @Produce json
+// @Param userId query string false "Only receipts owned by this user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(10)
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param merchant query string false "Merchant name filter"
+// @Param category query string false "Only receipts with an item in this category"
+// @Param taxId query string false "Only receipts from the seller with this tax or registration number"
+// @Param needsReview query bool false "Only return unverified receipts that need review"
+// @Param hasImage query bool false "Only return receipts with (true) or without (false) a stored image"
+// @Param sortBy query string false "Sort field: date, total, merchant or createdAt" default(date)
+// @Param sortOrder query string false "Sort direction: asc or desc" default(desc)
+// @Success 200 {object} model.ReceiptsListResponse "List of receipts"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/admin/receipts [get]
+func (h *AdminHandler) ListReceipts(c *gin.Context) {
+	filter, err := parseReceiptFilter(c)
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("query", err.Error()))
+		return
+	}
+	filter.AllUsers = true
+	filter.UserID = strings.TrimSpace(c.Query("userId"))
+
+	paginatedReceipts, err := h.receiptService.ListReceipts(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve receipts: %v", err))
+		return
+	}
+
+	data := formatReceiptsResponse(paginatedReceipts.Data)
+	for i, receipt := range paginatedReceipts.Data {
+		data[i]["userId"] = receipt.UserID
+	}
+
+	respondOK(c, gin.H{
+		"data":       data,
+		"pagination": formatPaginationResponse(paginatedReceipts.Pagination, filter.RequestedLimit),
+	})
+}
+
+// ListUsers handles the GET /admin/users endpoint
+// @Summary List users
+// @Description Browse user accounts for support, newest first, with the same pagination envelope as the receipts listing
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(10)
+// @Param email query string false "Case-insensitive partial match on the email"
+// @Param name query string false "Case-insensitive partial match on the name"
+// @Param role query string false "Only users with this role: user or admin"
+// @Param active query bool false "Only active (true) or deactivated (false) users"
+// @Success 200 {object} map[string]interface{} "List of users"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("query", err.Error()))
+		return
+	}
+
+	paginatedUsers, err := h.authService.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve users: %v", err))
+		return
+	}
+
+	respondOK(c, gin.H{
+		"data":       paginatedUsers.Data,
+		"pagination": formatPaginationResponse(paginatedUsers.Pagination, filter.RequestedLimit),
+	})
+}
+
+// parseUserFilter extracts the admin user listing's filter and pagination parameters
+func parseUserFilter(c *gin.Context) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		Email: strings.TrimSpace(c.Query("email")),
+		Name:  strings.TrimSpace(c.Query("name")),
+		Role:  strings.TrimSpace(c.Query("role")),
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return filter, fmt.Errorf("invalid page number")
+	}
+	filter.Page = page
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		return filter, fmt.Errorf("invalid limit")
+	}
+	if limit > maxReceiptListLimit {
+		filter.RequestedLimit = limit
+		limit = maxReceiptListLimit
+	}
+	filter.Limit = limit
+
+	if filter.Role != "" && filter.Role != domain.UserRoleUser && filter.Role != domain.UserRoleAdmin {
+		return filter, fmt.Errorf("invalid role (use %s or %s)", domain.UserRoleUser, domain.UserRoleAdmin)
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid active value (use true or false)")
+		}
+		filter.Active = &active
+	}
+
+	return filter, nil
+}
+
+// GetExtractionAccuracy handles the GET /admin/extraction-accuracy endpoint
+// @Summary Get scan extraction accuracy
+// @Description How often users corrected each scanned field (merchant, date, total, items) on their first edit of a scanned receipt, per extractor. Only users who opted in through the shareExtractionFeedback preference contribute, and no user or receipt data is kept. Requires EXTRACTION_FEEDBACK_ENABLED
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Accuracy per field and extractor"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Extraction feedback disabled"
+// @Security BearerAuth
+// @Router /v1/admin/extraction-accuracy [get]
+func (h *AdminHandler) GetExtractionAccuracy(c *gin.Context) {
+	accuracy, err := h.receiptService.GetExtractionAccuracy(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, "Extraction feedback is not enabled on the server")
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to retrieve extraction accuracy: %v", err))
+		}
+		return
+	}
+
+	respondOK(c, gin.H{"fields": accuracy})
+}
+
+// ReprocessRequest selects the receipts an extraction reprocess covers
+type ReprocessRequest struct {
+	ConfidenceBelow float64 `json:"confidenceBelow" example:"0.6"`
+	StartDate       string  `json:"startDate,omitempty" example:"2024-01-01"`
+	EndDate         string  `json:"endDate,omitempty" example:"2024-12-31"`
+}
+
+// StartReprocess handles the POST /admin/reprocess endpoint
+// @Summary Re-extract low-confidence receipts
+// @Description Start a background job re-running extraction over the stored images of receipts whose confidence is below confidenceBelow or unknown, optionally limited to a date range. A receipt's data and items are only replaced when the new extraction is more confident. Poll the returned job for progress; jobs are kept in memory on the instance that started them, and only one runs at a time
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body ReprocessRequest true "Receipts to reprocess"
+// @Success 202 {object} domain.ReprocessJob "Started job"
+// @Header 202 {string} Location "URL of the job's progress"
+// @Failure 400 {object} model.ErrorResponse "Invalid filter"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 409 {object} model.ErrorResponse "A reprocess job is already running"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Scanning not configured"
+// @Security BearerAuth
+// @Router /v1/admin/reprocess [post]
+func (h *AdminHandler) StartReprocess(c *gin.Context) {
+	var req ReprocessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if req.ConfidenceBelow <= 0 || req.ConfidenceBelow > 1 {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("confidenceBelow", "Must be greater than 0 and at most 1"))
+		return
+	}
+	startDate, err := parseExportDate(req.StartDate)
+	if err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("startDate", err.Error()))
+		return
+	}
+	endDate, err := parseExportDate(req.EndDate)
+	if err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("endDate", err.Error()))
+		return
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("endDate", "endDate must not be before startDate"))
+		return
+	}
+
+	job, err := h.receiptService.StartReprocess(c.Request.Context(), domain.ReprocessFilter{
+		ConfidenceBelow: req.ConfidenceBelow,
+		StartDate:       startDate,
+		EndDate:         endDate,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReprocessRunning):
+			respondConflict(c, "A reprocess job is already running")
+		case errors.Is(err, domain.ErrServiceNotConfigured):
+			respondServiceUnavailable(c, ErrScanNotConfigured)
+		default:
+			respondInternalServerError(c, fmt.Sprintf("Failed to start reprocessing: %v", err))
+		}
+		return
+	}
+
+	c.Header("Location", resourceLocation(c, job.ID))
+	respondSuccess(c, http.StatusAccepted, job)
+}
+
+// GetReprocessJob handles the GET /admin/reprocess/{jobId} endpoint
+// @Summary Get reprocess job progress
+// @Description Report how many receipts a reprocess job has handled so far and their outcomes
+// @Tags admin
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} domain.ReprocessJob "Job progress"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 404 {object} model.ErrorResponse "Job not found"
+// @Security BearerAuth
+// @Router /v1/admin/reprocess/{jobId} [get]
+func (h *AdminHandler) GetReprocessJob(c *gin.Context) {
+	job, err := h.receiptService.GetReprocessJob(c.Request.Context(), c.Param("jobId"))
+	if err != nil {
+		respondNotFound(c, "Reprocess job not found")
+		return
+	}
+
+	respondOK(c, job)
+}
+
+// DebugScan handles the POST /receipts/scan/debug endpoint
+// @Summary Diagnose a receipt scan
+// @Description Run a receipt image through preprocessing and OpenRouter extraction without saving a receipt, returning the preprocessed image (base64), the model's raw response and the parsed result. Extraction failures are reported in extractionError alongside the raw response. Only registered when SCAN_DEBUG_ENABLED is true
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param receiptImage formData file true "Receipt image file"
+// @Success 200 {object} map[string]interface{} "Scan stages"
+// @Failure 400 {object} model.ErrorResponse "Missing image"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Admin access required"
+// @Failure 422 {object} model.ErrorResponse "Image could not be decoded"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Scanning not configured"
+// @Security BearerAuth
+// @Router /v1/receipts/scan/debug [post]
+func (h *AdminHandler) DebugScan(c *gin.Context) {
+	fileHeaders, err := getFormFiles(c, "receiptImage")
+	if err != nil {
+		respondBadRequest(c, err.Error(), newErrorDetail("receiptImage", "Receipt image is required"))
+		return
+	}
+	images, err := readFormFiles(fileHeaders[:1])
+	if err != nil {
+		respondInternalServerError(c, ErrFileProcessing)
+		return
+	}
+
+	debug, err := h.receiptService.DebugScanReceipt(c.Request.Context(), images[0])
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, ErrScanNotConfigured)
+		} else if errors.Is(err, domain.ErrImageUndecodable) {
+			respondUnprocessableEntity(c, ErrImageUndecodable, newErrorDetail("receiptImage", err.Error()))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to run scan diagnostics: %v", err))
+		}
+		return
+	}
+
+	respondOK(c, gin.H{
+		"originalSize":            debug.OriginalSize,
+		"preprocessedSize":        debug.PreprocessedSize,
+		"preprocessedContentType": http.DetectContentType(debug.PreprocessedImage),
+		"preprocessedImage":       base64.StdEncoding.EncodeToString(debug.PreprocessedImage),
+		"extractionMethod":        debug.ExtractionMethod,
+		"rawOutput":               debug.RawOutput,
+		"parsed":                  debug.Invoice,
+		"extractionError":         debug.ExtractionError,
+	})
+}
+
+// ScanRoutes returns the routes registered by RegisterScanDebugRoute that scan a receipt within the request
+func (h *AdminHandler) ScanRoutes() []string {
+	return []string{"/v1/receipts/scan/debug"}
+}
+
+// RegisterScanDebugRoute registers the scan diagnostics route, which requires authentication and the admin role
+func (h *AdminHandler) RegisterScanDebugRoute(router *gin.Engine, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	router.POST("/v1/receipts/scan/debug", authMiddleware, adminMiddleware, h.DebugScan)
+}
+
+// RegisterRoutes registers admin routes, which require authentication and the admin role
+func (h *AdminHandler) RegisterRoutes(router *gin.Engine, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	admin := router.Group("/v1/admin", authMiddleware, adminMiddleware)
+	{
+		admin.POST("/backfill", h.Backfill)
+		admin.GET("/receipts", h.ListReceipts)
+		admin.GET("/users", h.ListUsers)
+		admin.GET("/extraction-accuracy", h.GetExtractionAccuracy)
+		admin.POST("/reprocess", h.StartReprocess)
+		admin.GET("/reprocess/:jobId", h.GetReprocessJob)
+	}
+}
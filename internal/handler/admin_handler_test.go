@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/middleware"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// roleUserRepository looks users up by ID for the admin role check
+type roleUserRepository struct {
+	repository.UserRepository
+	users map[string]domain.User
+}
+
+func (r *roleUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", userID)
+	}
+	return &user, nil
+}
+
+// ListUsers pages through the users, ordered by ID, whose email contains the filter's
+func (r *roleUserRepository) ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.PaginatedUsers, error) {
+	var matched []domain.User
+	for _, user := range r.users {
+		if strings.Contains(strings.ToLower(user.Email), strings.ToLower(filter.Email)) {
+			matched = append(matched, user)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	start := min((filter.Page-1)*filter.Limit, len(matched))
+	end := min(start+filter.Limit, len(matched))
+	return &domain.PaginatedUsers{
+		Data: matched[start:end],
+		Pagination: domain.Pagination{
+			TotalItems:  len(matched),
+			TotalPages:  (len(matched) + filter.Limit - 1) / filter.Limit,
+			CurrentPage: filter.Page,
+			Limit:       filter.Limit,
+		},
+	}, nil
+}
+
+// newTestAdminRouter registers the admin routes behind a stub auth middleware that
+// authenticates the user named in the X-User-ID header, and the real admin check
+func newTestAdminRouter(receiptService service.ReceiptService) *gin.Engine {
+	return newTestAdminRouterWithUsers(receiptService, nil)
+}
+
+// newTestAdminRouterWithUsers is newTestAdminRouter with further users alongside admin-1 and user-1
+func newTestAdminRouterWithUsers(receiptService service.ReceiptService, users []domain.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User-ID"))
+		c.Next()
+	}
+	userRepo := &roleUserRepository{users: map[string]domain.User{
+		"admin-1": {ID: "admin-1", Email: "admin@example.com", Role: domain.UserRoleAdmin},
+		"user-1":  {ID: "user-1", Email: "user@example.com", Role: domain.UserRoleUser},
+	}}
+	for _, user := range users {
+		userRepo.users[user.ID] = user
+	}
+	authService := service.NewAuthService(service.AuthServiceConfig{
+		UserRepo:  userRepo,
+		JWTSecret: "test-secret",
+	})
+	adminHandler := NewAdminHandler(nil, receiptService, authService)
+	adminHandler.RegisterRoutes(router, auth, middleware.AdminOnly(authService))
+	adminHandler.RegisterScanDebugRoute(router, auth, middleware.AdminOnly(authService))
+	return router
+}
+
+func TestAdminListReceipts(t *testing.T) {
+	router := newTestAdminRouter(&stubReceiptService{receipts: []domain.Receipt{
+		{ID: "receipt-1", UserID: "user-1", Merchant: "Corner Cafe"},
+		{ID: "receipt-2", UserID: "user-2", Merchant: "Book Shop"},
+	}})
+
+	listReceipts := func(userID, query string) (int, []map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/receipts"+query, nil)
+		req.Header.Set("X-User-ID", userID)
+		router.ServeHTTP(w, req)
+
+		var body struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		}
+		return w.Code, body.Data
+	}
+
+	code, _ := listReceipts("user-1", "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, receipts := listReceipts("admin-1", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, receipts, 2)
+
+	// An admin can narrow the listing to another user's receipts
+	code, receipts = listReceipts("admin-1", "?userId=user-2")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, receipts, 1)
+	assert.Equal(t, "receipt-2", receipts[0]["id"])
+	assert.Equal(t, "user-2", receipts[0]["userId"])
+}
+
+func TestAdminListUsers(t *testing.T) {
+	router := newTestAdminRouterWithUsers(nil, []domain.User{
+		{ID: "user-2", Email: "ana@acme.test", Role: domain.UserRoleUser},
+		{ID: "user-3", Email: "ben@acme.test", Role: domain.UserRoleUser},
+		{ID: "user-4", Email: "cy@other.test", Role: domain.UserRoleUser},
+		{ID: "user-5", Email: "dee@ACME.test", Role: domain.UserRoleUser},
+	})
+
+	listUsers := func(query string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/users"+query, nil)
+		req.Header.Set("X-User-ID", "admin-1")
+		router.ServeHTTP(w, req)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	// The second page of two acme users per page holds the last of the three
+	code, body := listUsers("?email=acme&page=2&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	users := body["data"].([]interface{})
+	require.Len(t, users, 1)
+	assert.Equal(t, "user-5", users[0].(map[string]interface{})["id"])
+	assert.Equal(t, map[string]interface{}{
+		"totalItems":  float64(3),
+		"totalPages":  float64(2),
+		"currentPage": float64(2),
+		"limit":       float64(2),
+	}, body["pagination"])
+
+	code, _ = listUsers("?role=owner")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = listUsers("?active=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+// rawExtractor returns a fixed invoice together with the raw model response it came from
+type rawExtractor struct {
+	raw string
+}
+
+func (e *rawExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	invoice, _, err := e.ExtractInvoiceDataWithRaw(ctx, imageData)
+	return invoice, err
+}
+
+func (e *rawExtractor) ExtractInvoiceDataWithRaw(ctx context.Context, imageData []byte) (*domain.Invoice, string, error) {
+	invoice := domain.NewInvoice()
+	invoice.VendorName = "Corner Cafe"
+	invoice.TotalDue = 4.5
+	return invoice, e.raw, nil
+}
+
+func TestAdminDebugScan(t *testing.T) {
+	receiptService := service.NewReceiptService(service.ReceiptServiceConfig{
+		OpenAIClient:   &rawExtractor{raw: `{"choices":[{"message":{"content":"{\"vendor_name\":\"Corner Cafe\"}"}}]}`},
+		MaxScanWorkers: 1,
+	})
+	router := newTestAdminRouter(receiptService)
+
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 40, 60))))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("receiptImage", "receipt.png")
+	require.NoError(t, err)
+	_, _ = part.Write(img.Bytes())
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan/debug", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-User-ID", "admin-1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var debug struct {
+		PreprocessedImage       string                 `json:"preprocessedImage"`
+		PreprocessedContentType string                 `json:"preprocessedContentType"`
+		RawOutput               string                 `json:"rawOutput"`
+		Parsed                  map[string]interface{} `json:"parsed"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &debug))
+
+	decoded, err := base64.StdEncoding.DecodeString(debug.PreprocessedImage)
+	require.NoError(t, err)
+	assert.NotEmpty(t, decoded)
+	assert.Equal(t, "image/png", debug.PreprocessedContentType)
+	assert.Contains(t, debug.RawOutput, "Corner Cafe")
+	assert.Equal(t, "Corner Cafe", debug.Parsed["vendor_name"])
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
 	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
 )
 
@@ -13,13 +17,15 @@ import (
 type AnalyticsHandler struct {
 	receiptRepo    repository.ReceiptRepository
 	currencyClient *currency.Client
+	moneyPolicy    money.Policy
 }
 
 // NewAnalyticsHandler creates a new analytics handler
-func NewAnalyticsHandler(receiptRepo repository.ReceiptRepository, currencyClient *currency.Client) *AnalyticsHandler {
+func NewAnalyticsHandler(receiptRepo repository.ReceiptRepository, currencyClient *currency.Client, moneyPolicy money.Policy) *AnalyticsHandler {
 	return &AnalyticsHandler{
 		receiptRepo:    receiptRepo,
 		currencyClient: currencyClient,
+		moneyPolicy:    moneyPolicy,
 	}
 }
 
@@ -30,8 +36,18 @@ type AnalyticsSummary struct {
 	Average      float64                `json:"average"`
 	Highest      float64                `json:"highest"`
 	Currency     string                 `json:"currency"`
+	RateBase     string                 `json:"rateBase"` // Base currency of the exchange rates used
+	RateDate     string                 `json:"rateDate"` // Publication date of the exchange rates used (YYYY-MM-DD)
 	ByCategory   []CategoryAmount       `json:"byCategory"`
 	ByPeriod     []PeriodAmount         `json:"byPeriod"`
+	Totals       []CurrencyTotal        `json:"totals,omitempty"` // Total spent in each currency requested via the currencies parameter
+}
+
+// CurrencyTotal represents total spending converted into one currency
+type CurrencyTotal struct {
+	Currency   string  `json:"currency"`
+	TotalSpent float64 `json:"totalSpent"`
+	RateDate   string  `json:"rateDate"`
 }
 
 // CategoryAmount represents spending by category
@@ -49,15 +65,20 @@ type PeriodAmount struct {
 
 // GetAnalytics handles GET /v1/analytics endpoint
 // @Summary Get analytics with currency conversion
-// @Description Get spending analytics with all amounts converted to target currency
+// @Description Get spending analytics with all amounts converted to target currency at the latest rates. rateBase and rateDate identify the published rates used
 // @Tags analytics
 // @Accept json
 // @Produce json
 // @Param currency query string false "Target currency (default: USD)"
+// @Param currencies query string false "Comma-separated currencies (at most 5) to also report the total spent in, e.g. USD,EUR"
 // @Param period query string false "Period type: weekly, monthly, yearly (default: monthly)"
 // @Param startDate query string false "Start date filter (YYYY-MM-DD)"
 // @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param fields query string false "Comma-separated aggregates to compute (totalSpent, receiptCount, average, highest, byCategory, byPeriod). Defaults to all"
+// @Param excludeCategories query string false "Comma-separated categories whose spend is left out, e.g. Rent,Utilities"
+// @Param excludeMerchants query string false "Comma-separated merchants whose receipts are left out"
 // @Success 200 {object} AnalyticsSummary "Analytics summary"
+// @Failure 400 {object} model.ErrorResponse "Invalid period, currencies or fields parameter"
 // @Failure 401 {object} model.ErrorResponse "Unauthorized"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/analytics [get]
@@ -77,6 +98,51 @@ func (h *AnalyticsHandler) GetAnalytics(c *gin.Context) {
 	periodType := c.DefaultQuery("period", "monthly")
 	startDateStr := c.Query("startDate")
 	endDateStr := c.Query("endDate")
+	fieldsParam := c.Query("fields")
+
+	if !isAnalyticsPeriod(periodType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid period parameter",
+			"details": []gin.H{
+				{
+					"field":   "period",
+					"message": "Period must be one of: " + strings.Join(analyticsPeriods, ", "),
+				},
+			},
+		})
+		return
+	}
+
+	totalCurrencies, err := parseAnalyticsCurrencies(c.Query("currencies"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid currencies parameter",
+			"details": []gin.H{
+				{
+					"field":   "currencies",
+					"message": err.Error(),
+				},
+			},
+		})
+		return
+	}
+
+	fields, invalidField := parseAnalyticsFields(fieldsParam)
+	if invalidField != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid fields parameter",
+			"details": []gin.H{
+				{
+					"field":   "fields",
+					"message": fmt.Sprintf("Unknown field '%s'. Allowed: %s", invalidField, strings.Join(analyticsFieldNames, ", ")),
+				},
+			},
+		})
+		return
+	}
 
 	// Get exchange rates for target currency
 	rates, err := h.currencyClient.GetLatestRates(c.Request.Context(), targetCurrency)
@@ -103,74 +169,309 @@ func (h *AnalyticsHandler) GetAnalytics(c *gin.Context) {
 		})
 		return
 	}
+	receipts = excludeFromAnalytics(receipts, getQueryList(c, "excludeCategories"), getQueryList(c, "excludeMerchants"))
 
 	// Calculate analytics with currency conversion
+	summary := calculateAnalytics(receipts, targetCurrency, periodType, rates, fields, h.moneyPolicy)
+
+	// Fetch each additional currency's rates once and convert the total into it
+	if len(totalCurrencies) > 0 {
+		ratesByCurrency := map[string]*currency.ExchangeRates{targetCurrency: rates}
+		for _, code := range totalCurrencies {
+			if _, ok := ratesByCurrency[code]; ok {
+				continue
+			}
+			ratesByCurrency[code], err = h.currencyClient.GetLatestRates(c.Request.Context(), code)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"status":  "500",
+					"message": "Failed to fetch exchange rates: " + err.Error(),
+				})
+				return
+			}
+		}
+		summary.Totals = calculateCurrencyTotals(receipts, totalCurrencies, ratesByCurrency, h.moneyPolicy)
+	}
+
+	if fieldsParam != "" {
+		c.JSON(http.StatusOK, fields.filter(summary))
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// analyticsPeriods lists the period types getPeriodKey can group by
+var analyticsPeriods = []string{"weekly", "monthly", "yearly"}
+
+// isAnalyticsPeriod reports whether the period type is supported
+func isAnalyticsPeriod(periodType string) bool {
+	for _, period := range analyticsPeriods {
+		if period == periodType {
+			return true
+		}
+	}
+	return false
+}
+
+// analyticsFieldNames lists the aggregates that can be requested via the fields parameter
+var analyticsFieldNames = []string{"totalSpent", "receiptCount", "average", "highest", "byCategory", "byPeriod"}
+
+// analyticsFields holds which aggregates should be computed and returned
+type analyticsFields struct {
+	TotalSpent   bool
+	ReceiptCount bool
+	Average      bool
+	Highest      bool
+	ByCategory   bool
+	ByPeriod     bool
+}
+
+// parseAnalyticsFields parses a comma-separated fields parameter.
+// An empty parameter selects every aggregate. The first unknown field name is returned if any.
+func parseAnalyticsFields(fieldsParam string) (analyticsFields, string) {
+	if strings.TrimSpace(fieldsParam) == "" {
+		return analyticsFields{true, true, true, true, true, true}, ""
+	}
+
+	var fields analyticsFields
+	for _, name := range strings.Split(fieldsParam, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case "totalSpent":
+			fields.TotalSpent = true
+		case "receiptCount":
+			fields.ReceiptCount = true
+		case "average":
+			fields.Average = true
+		case "highest":
+			fields.Highest = true
+		case "byCategory":
+			fields.ByCategory = true
+		case "byPeriod":
+			fields.ByPeriod = true
+		default:
+			return analyticsFields{}, strings.TrimSpace(name)
+		}
+	}
+
+	return fields, ""
+}
+
+// needsReceiptTotals reports whether any requested aggregate depends on converted receipt totals
+func (f analyticsFields) needsReceiptTotals() bool {
+	return f.TotalSpent || f.Average || f.Highest || f.ByCategory || f.ByPeriod
+}
+
+// filter builds a response containing only the requested aggregates
+func (f analyticsFields) filter(summary AnalyticsSummary) gin.H {
+	response := gin.H{"currency": summary.Currency, "rateBase": summary.RateBase, "rateDate": summary.RateDate}
+	if f.TotalSpent {
+		response["totalSpent"] = summary.TotalSpent
+	}
+	if f.ReceiptCount {
+		response["receiptCount"] = summary.ReceiptCount
+	}
+	if f.Average {
+		response["average"] = summary.Average
+	}
+	if f.Highest {
+		response["highest"] = summary.Highest
+	}
+	if f.ByCategory {
+		response["byCategory"] = summary.ByCategory
+	}
+	if f.ByPeriod {
+		response["byPeriod"] = summary.ByPeriod
+	}
+	if len(summary.Totals) > 0 {
+		response["totals"] = summary.Totals
+	}
+	return response
+}
+
+// calculateAnalytics aggregates receipts into an analytics summary, computing only the requested fields
+func calculateAnalytics(receipts []domain.Receipt, targetCurrency, periodType string, rates *currency.ExchangeRates, fields analyticsFields, policy money.Policy) AnalyticsSummary {
 	summary := AnalyticsSummary{
 		Currency:   targetCurrency,
 		ByCategory: []CategoryAmount{},
 		ByPeriod:   []PeriodAmount{},
 	}
+	if rates != nil {
+		summary.RateBase = rates.Base
+		summary.RateDate = rates.Date
+	}
 
-	categoryTotals := make(map[string]float64)
-	periodTotals := make(map[string]*PeriodAmount)
-	var highest float64
+	// Totals are accumulated in minor units and converted to floats only for the response
+	categoryTotals := make(map[string]money.Amount)
+	periodTotals := make(map[string]money.Amount)
+	periodCounts := make(map[string]int)
+	var periodOrder []string
+	var totalSpent, highest money.Amount
 
 	for _, receipt := range receipts {
-		var receiptTotal float64
+		summary.ReceiptCount++
+
+		// Skip the per-item conversion loop when only the count is requested
+		if !fields.needsReceiptTotals() {
+			continue
+		}
+
+		var receiptTotal money.Amount
 
 		// Sum up items with currency conversion
 		for _, item := range receipt.Items {
 			itemTotal := float64(item.Quantity) * item.Price
-			convertedAmount := convertToTarget(itemTotal, item.Currency, targetCurrency, rates)
-			receiptTotal += convertedAmount
+			convertedAmount := policy.FromFloat(convertToTarget(itemTotal, item.Currency, targetCurrency, rates))
+			receiptTotal = receiptTotal.Add(convertedAmount)
 
 			// Track by category
-			category := item.Category
-			if category == "" {
-				category = "Uncategorized"
+			if fields.ByCategory {
+				category := item.Category
+				if category == "" {
+					category = "Uncategorized"
+				}
+				categoryTotals[category] = categoryTotals[category].Add(convertedAmount)
 			}
-			categoryTotals[category] += convertedAmount
 		}
 
 		// If no items, use receipt total (assume USD if no currency info)
 		if len(receipt.Items) == 0 {
-			receiptTotal = receipt.Total // Already in some currency, assume target
+			receiptTotal = policy.FromFloat(receipt.Total) // Already in some currency, assume target
 		}
 
-		summary.TotalSpent += receiptTotal
-		summary.ReceiptCount++
+		totalSpent = totalSpent.Add(receiptTotal)
 
 		if receiptTotal > highest {
 			highest = receiptTotal
 		}
 
 		// Track by period
-		periodKey := getPeriodKey(receipt.Date.Time, periodType)
-		if _, ok := periodTotals[periodKey]; !ok {
-			periodTotals[periodKey] = &PeriodAmount{Period: periodKey}
+		if fields.ByPeriod {
+			periodKey := getPeriodKey(receipt.Date.Time, periodType)
+			if _, ok := periodCounts[periodKey]; !ok {
+				periodOrder = append(periodOrder, periodKey)
+			}
+			periodTotals[periodKey] = periodTotals[periodKey].Add(receiptTotal)
+			periodCounts[periodKey]++
 		}
-		periodTotals[periodKey].Amount += receiptTotal
-		periodTotals[periodKey].Count++
 	}
 
-	summary.Highest = highest
-	if summary.ReceiptCount > 0 {
-		summary.Average = summary.TotalSpent / float64(summary.ReceiptCount)
+	if fields.TotalSpent {
+		summary.TotalSpent = policy.ToFloat(totalSpent)
+	}
+	if fields.Highest {
+		summary.Highest = policy.ToFloat(highest)
+	}
+	if fields.Average && summary.ReceiptCount > 0 {
+		summary.Average = policy.ToFloat(policy.FromFloat(policy.ToFloat(totalSpent) / float64(summary.ReceiptCount)))
+	}
+	if !fields.ReceiptCount {
+		summary.ReceiptCount = 0
 	}
 
 	// Convert maps to slices
 	for category, amount := range categoryTotals {
 		summary.ByCategory = append(summary.ByCategory, CategoryAmount{
 			Category: category,
-			Amount:   amount,
+			Amount:   policy.ToFloat(amount),
 		})
 	}
 
-	for _, period := range periodTotals {
-		summary.ByPeriod = append(summary.ByPeriod, *period)
+	for _, period := range periodOrder {
+		summary.ByPeriod = append(summary.ByPeriod, PeriodAmount{
+			Period: period,
+			Amount: policy.ToFloat(periodTotals[period]),
+			Count:  periodCounts[period],
+		})
 	}
 
-	c.JSON(http.StatusOK, summary)
+	return summary
+}
+
+// excludeFromAnalytics leaves out receipts from excluded merchants and items in excluded categories,
+// dropping receipts whose items were all excluded. Names are matched case-insensitively.
+func excludeFromAnalytics(receipts []domain.Receipt, categories, merchants []string) []domain.Receipt {
+	if len(categories) == 0 && len(merchants) == 0 {
+		return receipts
+	}
+
+	excluded := func(names []string, name string) bool {
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	kept := make([]domain.Receipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		if excluded(merchants, receipt.Merchant) {
+			continue
+		}
+
+		items := make([]domain.ReceiptItem, 0, len(receipt.Items))
+		for _, item := range receipt.Items {
+			category := item.Category
+			if category == "" {
+				category = "Uncategorized"
+			}
+			if !excluded(categories, category) {
+				items = append(items, item)
+			}
+		}
+		if len(receipt.Items) > 0 && len(items) == 0 {
+			continue
+		}
+
+		receipt.Items = items
+		kept = append(kept, receipt)
+	}
+	return kept
+}
+
+// maxAnalyticsCurrencies bounds how many currencies one analytics request can convert totals into
+const maxAnalyticsCurrencies = 5
+
+// parseAnalyticsCurrencies parses the comma-separated currencies parameter into unique uppercase codes
+func parseAnalyticsCurrencies(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, code := range strings.Split(param, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, fmt.Errorf("'%s' is not a 3-letter currency code", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) > maxAnalyticsCurrencies {
+		return nil, fmt.Errorf("At most %d currencies can be requested", maxAnalyticsCurrencies)
+	}
+	return codes, nil
+}
+
+// calculateCurrencyTotals converts the receipts' total spending into each currency, using the rates keyed by that currency
+func calculateCurrencyTotals(receipts []domain.Receipt, currencies []string, ratesByCurrency map[string]*currency.ExchangeRates, policy money.Policy) []CurrencyTotal {
+	totals := make([]CurrencyTotal, 0, len(currencies))
+	for _, code := range currencies {
+		rates := ratesByCurrency[code]
+		summary := calculateAnalytics(receipts, code, "", rates, analyticsFields{TotalSpent: true}, policy)
+		totals = append(totals, CurrencyTotal{
+			Currency:   code,
+			TotalSpent: summary.TotalSpent,
+			RateDate:   summary.RateDate,
+		})
+	}
+	return totals
 }
 
 // convertToTarget converts an amount from source currency to target currency
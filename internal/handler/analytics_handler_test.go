@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
+)
+
+func analyticsTestReceipts() []domain.Receipt {
+	return []domain.Receipt{
+		{
+			Date:  domain.FlexibleDate{Time: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+			Total: 30,
+			Items: []domain.ReceiptItem{
+				{Name: "Coffee", Quantity: 2, Price: 5, Category: "Food", Currency: "USD"},
+				{Name: "Taxi", Quantity: 1, Price: 20, Category: "Transport", Currency: "USD"},
+			},
+		},
+		{
+			Date:  domain.FlexibleDate{Time: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+			Total: 50,
+			Items: []domain.ReceiptItem{
+				{Name: "Groceries", Quantity: 1, Price: 50, Category: "Food", Currency: "USD"},
+			},
+		},
+	}
+}
+
+func TestParseAnalyticsFields(t *testing.T) {
+	fields, invalid := parseAnalyticsFields("")
+	assert.Empty(t, invalid)
+	assert.Equal(t, analyticsFields{true, true, true, true, true, true}, fields)
+
+	fields, invalid = parseAnalyticsFields("totalSpent, average")
+	assert.Empty(t, invalid)
+	assert.Equal(t, analyticsFields{TotalSpent: true, Average: true}, fields)
+
+	_, invalid = parseAnalyticsFields("totalSpent,median")
+	assert.Equal(t, "median", invalid)
+}
+
+func TestCalculateAnalytics(t *testing.T) {
+	rates := &currency.ExchangeRates{Base: "USD", Date: "2024-02-09", Rates: map[string]float64{}}
+
+	t.Run("all fields", func(t *testing.T) {
+		fields, _ := parseAnalyticsFields("")
+		summary := calculateAnalytics(analyticsTestReceipts(), "USD", "monthly", rates, fields, money.DefaultPolicy())
+
+		assert.Equal(t, 80.0, summary.TotalSpent)
+		assert.Equal(t, 2, summary.ReceiptCount)
+		assert.Equal(t, 40.0, summary.Average)
+		assert.Equal(t, 50.0, summary.Highest)
+		assert.Len(t, summary.ByCategory, 2)
+		assert.Len(t, summary.ByPeriod, 2)
+		assert.Equal(t, "USD", summary.RateBase)
+		assert.Equal(t, "2024-02-09", summary.RateDate)
+	})
+
+	t.Run("top-line fields skip breakdowns", func(t *testing.T) {
+		fields, _ := parseAnalyticsFields("totalSpent,average")
+		summary := calculateAnalytics(analyticsTestReceipts(), "USD", "monthly", rates, fields, money.DefaultPolicy())
+
+		assert.Equal(t, 80.0, summary.TotalSpent)
+		assert.Equal(t, 40.0, summary.Average)
+		assert.Zero(t, summary.ReceiptCount)
+		assert.Zero(t, summary.Highest)
+		require.NotNil(t, summary.ByCategory)
+		require.NotNil(t, summary.ByPeriod)
+		assert.Empty(t, summary.ByCategory)
+		assert.Empty(t, summary.ByPeriod)
+
+		response := fields.filter(summary)
+		assert.Contains(t, response, "totalSpent")
+		assert.Contains(t, response, "average")
+		assert.NotContains(t, response, "byCategory")
+		assert.NotContains(t, response, "byPeriod")
+		assert.Equal(t, "2024-02-09", response["rateDate"])
+	})
+
+	t.Run("count only skips item conversion", func(t *testing.T) {
+		fields, _ := parseAnalyticsFields("receiptCount")
+		// Nil rates would panic if any non-USD item were converted
+		receipts := analyticsTestReceipts()
+		receipts[0].Items[0].Currency = "EUR"
+		summary := calculateAnalytics(receipts, "USD", "monthly", nil, fields, money.DefaultPolicy())
+
+		assert.Equal(t, 2, summary.ReceiptCount)
+		assert.Zero(t, summary.TotalSpent)
+		assert.Empty(t, summary.ByCategory)
+		assert.Empty(t, summary.ByPeriod)
+	})
+}
+
+func TestExcludeFromAnalytics(t *testing.T) {
+	rates := &currency.ExchangeRates{Base: "USD", Date: "2024-02-09", Rates: map[string]float64{}}
+	fields, _ := parseAnalyticsFields("")
+
+	receipts := analyticsTestReceipts()
+	receipts = append(receipts, domain.Receipt{
+		Merchant: "Landlord",
+		Date:     domain.FlexibleDate{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		Total:    900,
+		Items:    []domain.ReceiptItem{{Name: "February rent", Quantity: 1, Price: 900, Category: "Rent", Currency: "USD"}},
+	})
+
+	t.Run("excluded categories are omitted from totals and breakdowns", func(t *testing.T) {
+		kept := excludeFromAnalytics(receipts, []string{"rent", "Transport"}, nil)
+		summary := calculateAnalytics(kept, "USD", "monthly", rates, fields, money.DefaultPolicy())
+
+		// The rent-only receipt is dropped entirely and the taxi leaves January's receipt
+		assert.Equal(t, 60.0, summary.TotalSpent)
+		assert.Equal(t, 2, summary.ReceiptCount)
+		assert.Equal(t, []CategoryAmount{{Category: "Food", Amount: 60}}, summary.ByCategory)
+		assert.Equal(t, []PeriodAmount{{Period: "2024-01", Amount: 10, Count: 1}, {Period: "2024-02", Amount: 50, Count: 1}}, summary.ByPeriod)
+	})
+
+	t.Run("excluded merchants are omitted", func(t *testing.T) {
+		kept := excludeFromAnalytics(receipts, nil, []string{"LANDLORD"})
+		summary := calculateAnalytics(kept, "USD", "monthly", rates, fields, money.DefaultPolicy())
+
+		assert.Equal(t, 80.0, summary.TotalSpent)
+		assert.Equal(t, 2, summary.ReceiptCount)
+	})
+
+	// The caller's receipts keep their items
+	assert.Len(t, receipts[0].Items, 2)
+}
+
+func TestGetAnalyticsRejectsInvalidPeriod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	// Validation happens before rates or receipts are fetched
+	NewAnalyticsHandler(nil, nil, money.DefaultPolicy()).RegisterAnalyticsRoutes(router.Group("/v1"), auth)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/analytics?period=hourly", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid period parameter")
+	assert.Contains(t, w.Body.String(), `"field":"period"`)
+}
+
+func TestParseAnalyticsCurrencies(t *testing.T) {
+	codes, err := parseAnalyticsCurrencies(" usd, EUR ,USD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD", "EUR"}, codes)
+
+	codes, err = parseAnalyticsCurrencies("")
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+
+	_, err = parseAnalyticsCurrencies("USD,EURO")
+	assert.Error(t, err)
+
+	_, err = parseAnalyticsCurrencies("USD,EUR,IDR,JPY,GBP,SGD")
+	assert.Error(t, err)
+}
+
+func TestCalculateCurrencyTotals(t *testing.T) {
+	receipts := analyticsTestReceipts()
+	receipts[1].Items[0].Currency = "EUR" // 50 EUR
+
+	ratesByCurrency := map[string]*currency.ExchangeRates{
+		"USD": {Base: "USD", Date: "2024-02-09", Rates: map[string]float64{"EUR": 0.8}},
+		"EUR": {Base: "EUR", Date: "2024-02-09", Rates: map[string]float64{"USD": 1.25}},
+	}
+
+	totals := calculateCurrencyTotals(receipts, []string{"USD", "EUR"}, ratesByCurrency, money.DefaultPolicy())
+	require.Len(t, totals, 2)
+
+	// 30 USD + 50 EUR at 0.8 EUR per USD
+	assert.Equal(t, "USD", totals[0].Currency)
+	assert.InDelta(t, 92.5, totals[0].TotalSpent, 0.001)
+	assert.Equal(t, "2024-02-09", totals[0].RateDate)
+
+	// 30 USD at 1.25 USD per EUR + 50 EUR
+	assert.Equal(t, "EUR", totals[1].Currency)
+	assert.InDelta(t, 74, totals[1].TotalSpent, 0.001)
+}
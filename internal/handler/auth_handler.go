@@ -3,10 +3,15 @@ package handler
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 	"github.com/ridwanfathin/invoice-processor-service/internal/service"
 )
 
@@ -144,12 +149,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // GetCurrentUser returns the current authenticated user
 // @Summary Get current user
-// @Description Get the currently authenticated user's information
+// @Description Get the currently authenticated user's information with their linked OAuth providers and preferences
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} domain.User "User information"
+// @Success 200 {object} domain.UserProfile "User information"
 // @Failure 401 {object} model.ErrorResponse "Unauthorized"
 // @Router /v1/auth/me [get]
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
@@ -161,13 +166,52 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	}
 
 	// Get user details
-	user, err := h.authService.GetUserByID(c.Request.Context(), userID.(string))
+	profile, err := h.authService.GetUserProfile(c.Request.Context(), userID.(string))
 	if err != nil {
 		respondInternalServerError(c, "Failed to get user information")
 		return
 	}
 
-	respondOK(c, user)
+	respondOK(c, profile)
+}
+
+// UpdatePreferences updates the current user's preferences
+// @Summary Update current user's preferences
+// @Description Update the default currency, time zone and number locale used to format display amounts (e.g. en-US, id-ID, de-DE). Omitted fields keep their current value
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.UserPreferences true "Preferences to change"
+// @Success 200 {object} domain.UserPreferences "Updated preferences"
+// @Failure 400 {object} model.ErrorResponse "Validation failed"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/auth/me/preferences [put]
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req domain.UserPreferences
+	if err := bindJSON(c, &req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	preferences, err := h.authService.UpdateUserPreferences(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		if details, ok := validationErrorDetails(err); ok {
+			respondBadRequest(c, "Validation failed", details...)
+			return
+		}
+		respondInternalServerError(c, "Failed to update preferences")
+		return
+	}
+
+	respondOK(c, preferences)
 }
 
 // GoogleMobileAuth handles mobile authentication with Google ID Token
@@ -231,7 +275,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 // @Produce json
 // @Param request body RegisterRequest true "Registration details"
 // @Success 201 {object} service.AuthResponse "Registration successful"
-// @Failure 400 {object} model.ErrorResponse "Bad request"
+// @Failure 400 {object} model.ErrorResponse "Invalid email, name or password, with field-level details"
 // @Failure 409 {object} model.ErrorResponse "User already exists"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/auth/register [post]
@@ -245,6 +289,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Register user
 	authResponse, err := h.authService.Register(c.Request.Context(), req.Email, req.Password, req.Name)
 	if err != nil {
+		if details, ok := validationErrorDetails(err); ok {
+			respondBadRequest(c, "Validation failed", details...)
+			return
+		}
 		if err == service.ErrUserAlreadyExists {
 			respondConflict(c, "User with this email already exists")
 			return
@@ -269,6 +317,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} service.AuthResponse "Login successful"
 // @Failure 400 {object} model.ErrorResponse "Bad request"
 // @Failure 401 {object} model.ErrorResponse "Invalid credentials"
+// @Failure 423 {object} model.ErrorResponse "Account locked after too many failed logins; retryAt detail and Retry-After header give when to try again"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -285,6 +334,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			respondUnauthorized(c, "Invalid email or password")
 			return
 		}
+		var lockedErr *service.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			respondLocked(c, "Account locked after too many failed logins",
+				newErrorDetail("retryAt", lockedErr.Until.UTC().Format(time.RFC3339)))
+			return
+		}
 		logError(c, "login_failed", err, map[string]interface{}{
 			"email": req.Email,
 		})
@@ -316,6 +373,7 @@ func (h *AuthHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.Hand
 
 		// Protected route - requires auth middleware
 		auth.GET("/me", authMiddleware, h.GetCurrentUser)
+		auth.PUT("/me/preferences", authMiddleware, h.UpdatePreferences)
 	}
 }
 
@@ -332,7 +390,7 @@ type MobileAuthRequest struct {
 // RegisterRequest represents a registration request
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // Complexity is checked against the configured password policy
 	Name     string `json:"name" binding:"required"`
 }
 
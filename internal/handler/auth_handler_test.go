@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// profileUserRepository holds one user with a linked Google account and optional saved preferences
+type profileUserRepository struct {
+	repository.UserRepository
+	user        domain.User
+	preferences *domain.UserPreferences
+}
+
+func (r *profileUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	user := r.user
+	return &user, nil
+}
+
+func (r *profileUserRepository) GetOAuthProvidersByUserID(ctx context.Context, userID string) ([]domain.OAuthProvider, error) {
+	return []domain.OAuthProvider{{
+		ID:             "provider-1",
+		UserID:         userID,
+		Provider:       "google",
+		ProviderUserID: "google-123",
+		ProviderEmail:  "jane@gmail.com",
+		ProviderData:   map[string]interface{}{"access_token": "google-secret-token"},
+		CreatedAt:      time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (r *profileUserRepository) GetUserPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	return r.preferences, nil
+}
+
+func (r *profileUserRepository) SaveUserPreferences(ctx context.Context, userID string, preferences domain.UserPreferences) error {
+	r.preferences = &preferences
+	return nil
+}
+
+func newTestAuthRouter(repo repository.UserRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	authService := service.NewAuthService(service.AuthServiceConfig{UserRepo: repo, JWTSecret: "test-secret"})
+	NewAuthHandler(authService, "").RegisterRoutes(router, auth)
+	return router
+}
+
+func TestGetCurrentUserProfile(t *testing.T) {
+	repo := &profileUserRepository{user: domain.User{
+		ID:           "user-1",
+		Email:        "jane@example.com",
+		Name:         "Jane",
+		PasswordHash: "$2a$10$secret-hash",
+		Role:         domain.UserRoleAdmin,
+	}}
+	router := newTestAuthRouter(repo)
+
+	getProfile := func() (string, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var profile map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+		return w.Body.String(), profile
+	}
+
+	raw, profile := getProfile()
+	assert.Equal(t, "jane@example.com", profile["email"])
+	assert.Equal(t, domain.UserRoleAdmin, profile["role"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"provider": "google",
+		"email":    "jane@gmail.com",
+		"linkedAt": "2025-01-02T00:00:00Z",
+	}}, profile["providers"])
+	assert.Equal(t, map[string]interface{}{"defaultCurrency": "USD", "timezone": "UTC", "shareExtractionFeedback": false, "numberLocale": ""}, profile["preferences"])
+
+	// Neither the password hash nor provider secrets are exposed
+	assert.NotContains(t, raw, "secret-hash")
+	assert.NotContains(t, raw, "passwordHash")
+	assert.NotContains(t, raw, "google-secret-token")
+
+	// Saved preferences replace the defaults
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/v1/auth/me/preferences", strings.NewReader(`{"defaultCurrency":"idr","timezone":"Asia/Jakarta","numberLocale":"de_de"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, profile = getProfile()
+	assert.Equal(t, map[string]interface{}{"defaultCurrency": "IDR", "timezone": "Asia/Jakarta", "shareExtractionFeedback": false, "numberLocale": "de-DE"}, profile["preferences"])
+}
+
+func TestUpdatePreferencesValidation(t *testing.T) {
+	router := newTestAuthRouter(&profileUserRepository{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/v1/auth/me/preferences", strings.NewReader(`{"defaultCurrency":"dollars","timezone":"Mars/Olympus"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "defaultCurrency")
+	assert.Contains(t, w.Body.String(), "timezone")
+}
@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
@@ -28,6 +33,9 @@ func NewCurrencyHandler(client *currency.Client) *CurrencyHandler {
 // @Produce json
 // @Param base query string false "Base currency (default: USD)"
 // @Success 200 {object} currency.ExchangeRates "Exchange rates"
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Version of the rates, for If-None-Match"
+// @Header 200 {string} Cache-Control "public, max-age matching the rate cache"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/currency/rates [get]
 func (h *CurrencyHandler) GetExchangeRates(c *gin.Context) {
@@ -42,12 +50,12 @@ func (h *CurrencyHandler) GetExchangeRates(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, rates)
+	h.respondCacheable(c, rates)
 }
 
 // ConvertCurrency converts an amount from one currency to another
 // @Summary Convert currency
-// @Description Convert an amount from one currency to another
+// @Description Convert an amount from one currency to another at the latest rates. convertedAmount is rounded to the target currency's decimal places, given as precision (0 for currencies such as IDR and JPY), with the full value in unroundedAmount. The response includes the rate applied and the base and date of the published rates it came from
 // @Tags currency
 // @Accept json
 // @Produce json
@@ -80,7 +88,7 @@ func (h *CurrencyHandler) ConvertCurrency(c *gin.Context) {
 		return
 	}
 
-	convertedAmount, err := h.currencyClient.Convert(c.Request.Context(), amount, fromCurrency, toCurrency)
+	conversion, err := h.currencyClient.Convert(c.Request.Context(), amount, fromCurrency, toCurrency)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "500",
@@ -93,7 +101,12 @@ func (h *CurrencyHandler) ConvertCurrency(c *gin.Context) {
 		"amount":          amount,
 		"from":            fromCurrency,
 		"to":              toCurrency,
-		"convertedAmount": convertedAmount,
+		"convertedAmount": conversion.Amount,
+		"unroundedAmount": conversion.UnroundedAmount,
+		"precision":       conversion.Precision,
+		"rate":            conversion.Rate,
+		"rateBase":        conversion.RateBase,
+		"rateDate":        conversion.RateDate,
 	})
 }
 
@@ -104,6 +117,9 @@ func (h *CurrencyHandler) ConvertCurrency(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{} "List of currencies"
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Version of the list, for If-None-Match"
+// @Header 200 {string} Cache-Control "public, max-age matching the rate cache"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/currency/supported [get]
 func (h *CurrencyHandler) GetSupportedCurrencies(c *gin.Context) {
@@ -116,11 +132,44 @@ func (h *CurrencyHandler) GetSupportedCurrencies(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	h.respondCacheable(c, gin.H{
 		"currencies": currencies,
 	})
 }
 
+// respondCacheable sends a JSON response that clients and CDNs may cache for as long as the
+// client caches rates, with an ETag of its content. A request whose If-None-Match already
+// names that ETag gets 304 Not Modified without a body.
+func (h *CurrencyHandler) respondCacheable(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		respondInternalServerError(c, "Failed to encode response: "+err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.currencyClient.CacheTTL().Seconds())))
+	c.Header("ETag", etag)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, comparing weakly as RFC 9110 requires
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // RegisterCurrencyRoutes registers currency routes
 func (h *CurrencyHandler) RegisterCurrencyRoutes(router *gin.RouterGroup) {
 	currencyGroup := router.Group("/currency")
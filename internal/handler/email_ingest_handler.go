@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// EmailIngestHandler handles receipts forwarded by email
+type EmailIngestHandler struct {
+	ingestService  service.EmailIngestService
+	maxBodyBytes   int64
+	maxAttachments int
+}
+
+// NewEmailIngestHandler creates a new email ingest handler accepting inbound emails of up to
+// maxBodyBytes with at most maxAttachments attachments
+func NewEmailIngestHandler(ingestService service.EmailIngestService, maxBodyBytes int64, maxAttachments int) *EmailIngestHandler {
+	return &EmailIngestHandler{
+		ingestService:  ingestService,
+		maxBodyBytes:   maxBodyBytes,
+		maxAttachments: maxAttachments,
+	}
+}
+
+// IngestEmail handles the POST /v1/ingest/email endpoint
+// @Summary Create receipts from a forwarded email
+// @Description Called by the inbound mail provider with a parsed email. The user is identified by the secret token in their forwarding address (receipts+<token>@domain) among the recipients, so no bearer token is needed. Each image attachment is queued and scanned into a receipt in the background; other attachments, including PDFs, and images beyond the user's monthly scan quota are listed as skipped. Requests are rate limited per client IP, and emails over EMAIL_INGEST_MAX_BYTES or with more than EMAIL_INGEST_MAX_ATTACHMENTS attachments are rejected
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param email body domain.InboundEmail true "Parsed email with base64 encoded attachments"
+// @Success 202 {object} map[string]interface{} "Queued attachment filenames and skipped attachments with reasons"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 404 {object} model.ErrorResponse "Unknown ingest address"
+// @Failure 413 {object} model.ErrorResponse "Email too large"
+// @Failure 429 {object} model.ErrorResponse "Too many requests"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/ingest/email [post]
+func (h *EmailIngestHandler) IngestEmail(c *gin.Context) {
+	// Bound the body before decoding it, as the route is public
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodyBytes)
+
+	var email domain.InboundEmail
+	if err := bindJSON(c, &email); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(c, http.StatusRequestEntityTooLarge, "Email too large",
+				newErrorDetail("body", fmt.Sprintf("Emails of up to %d bytes are accepted", h.maxBodyBytes)))
+			return
+		}
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if len(email.To) == 0 {
+		respondBadRequest(c, "Validation failed", newErrorDetail("to", "At least one recipient is required"))
+		return
+	}
+	if len(email.Attachments) > h.maxAttachments {
+		respondBadRequest(c, "Validation failed", newErrorDetail("attachments",
+			fmt.Sprintf("At most %d attachments are accepted per email, got %d", h.maxAttachments, len(email.Attachments))))
+		return
+	}
+
+	result, err := h.ingestService.IngestEmail(c.Request.Context(), email)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownIngestAddress) {
+			respondNotFound(c, "Unknown ingest address")
+			return
+		}
+		logError(c, "failed_to_ingest_email", err, map[string]interface{}{
+			"attachment_count": len(email.Attachments),
+		})
+		respondInternalServerError(c, ErrFileProcessing)
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, gin.H{
+		"queued":  result.Queued,
+		"skipped": result.Skipped,
+	})
+}
+
+// GetIngestAddress handles the GET /v1/auth/me/ingest-address endpoint
+// @Summary Get the current user's forwarding address
+// @Description Returns the secret token of the address the user forwards receipt emails to, and the full address when the server has an inbound domain configured
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.EmailIngestAddress
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/auth/me/ingest-address [get]
+func (h *EmailIngestHandler) GetIngestAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	address, err := h.ingestService.GetIngestAddress(c.Request.Context(), userID.(string))
+	if err != nil {
+		logError(c, "failed_to_get_ingest_address", err, map[string]interface{}{"user_id": userID})
+		respondInternalServerError(c, "Failed to get forwarding address")
+		return
+	}
+
+	respondOK(c, address)
+}
+
+// RotateIngestAddress handles the POST /v1/auth/me/ingest-address/rotate endpoint
+// @Summary Regenerate the current user's forwarding address
+// @Description Replaces the secret token of the user's forwarding address. Emails sent to the old address are rejected from then on
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.EmailIngestAddress
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/auth/me/ingest-address/rotate [post]
+func (h *EmailIngestHandler) RotateIngestAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	address, err := h.ingestService.RotateIngestAddress(c.Request.Context(), userID.(string))
+	if err != nil {
+		logError(c, "failed_to_rotate_ingest_address", err, map[string]interface{}{"user_id": userID})
+		respondInternalServerError(c, "Failed to regenerate forwarding address")
+		return
+	}
+
+	respondOK(c, address)
+}
+
+// ScanRoutes returns the routes registered by RegisterRoutes that get the scan timeout. Scans are
+// queued, but emails with several attachments can still take a while to upload and sort.
+func (h *EmailIngestHandler) ScanRoutes() []string {
+	return []string{"/v1/ingest/email"}
+}
+
+// RegisterRoutes registers the ingest routes. The ingest route is not behind authentication, as
+// the ingest token in the recipient address identifies the user, so it is rate limited instead.
+func (h *EmailIngestHandler) RegisterRoutes(router *gin.Engine, authMiddleware, rateLimitMiddleware gin.HandlerFunc) {
+	router.POST("/v1/ingest/email", rateLimitMiddleware, h.IngestEmail)
+	router.GET("/v1/auth/me/ingest-address", authMiddleware, h.GetIngestAddress)
+	router.POST("/v1/auth/me/ingest-address/rotate", authMiddleware, h.RotateIngestAddress)
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/middleware"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// ingestTokenUserRepository maps ingest tokens to users
+type ingestTokenUserRepository struct {
+	repository.UserRepository
+	users map[string]domain.User
+}
+
+func (r *ingestTokenUserRepository) GetUserByEmailIngestToken(ctx context.Context, token string) (*domain.User, error) {
+	user, ok := r.users[token]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *ingestTokenUserRepository) GetEmailIngestToken(ctx context.Context, userID string) (string, error) {
+	for token, user := range r.users {
+		if user.ID == userID {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("user not found: %s", userID)
+}
+
+func (r *ingestTokenUserRepository) SetEmailIngestToken(ctx context.Context, userID, token string) error {
+	for old, user := range r.users {
+		if user.ID == userID {
+			delete(r.users, old)
+			r.users[token] = user
+			return nil
+		}
+	}
+	return fmt.Errorf("user not found: %s", userID)
+}
+
+// recordingScanner creates a receipt for every scanned image, reporting it on scanned
+type recordingScanner struct {
+	remaining int // Scans left this month, -1 when unlimited
+	scanned   chan domain.Receipt
+}
+
+func (s *recordingScanner) ScanReceipt(ctx context.Context, imageData []byte, userID string) (*domain.Receipt, error) {
+	receipt := domain.Receipt{ID: "receipt-" + string(imageData), UserID: userID, Merchant: "Corner Cafe"}
+	s.scanned <- receipt
+	return &receipt, nil
+}
+
+func (s *recordingScanner) GetScanUsage(ctx context.Context, userID string) (*domain.ScanUsage, error) {
+	if s.remaining < 0 {
+		return &domain.ScanUsage{}, nil
+	}
+	return &domain.ScanUsage{MonthlyQuota: 10, Used: 10 - s.remaining}, nil
+}
+
+func TestIngestEmail(t *testing.T) {
+	userRepo := &ingestTokenUserRepository{users: map[string]domain.User{
+		"secret-token": {ID: "user-1", IsActive: true},
+	}}
+	scanner := &recordingScanner{remaining: -1, scanned: make(chan domain.Receipt, 10)}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	noAuth := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	NewEmailIngestHandler(service.NewEmailIngestService(userRepo, scanner, "inbox.example.com"), 1<<20, 10).
+		RegisterRoutes(router, noAuth, middleware.RateLimit(3, time.Minute))
+
+	post := func(email domain.InboundEmail) *httptest.ResponseRecorder {
+		body, err := json.Marshal(email)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/ingest/email", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	type ingestResponse struct {
+		Queued  []string                   `json:"queued"`
+		Skipped []domain.SkippedAttachment `json:"skipped"`
+	}
+
+	w := post(domain.InboundEmail{
+		To:      []string{"Receipts <receipts+secret-token@inbox.example.com>"},
+		From:    "jane@example.com",
+		Subject: "Fwd: receipts",
+		Attachments: []domain.EmailAttachment{
+			{Filename: "lunch.jpg", ContentType: "image/jpeg", Content: []byte("1")},
+			{Filename: "signature.txt", ContentType: "text/plain; charset=utf-8", Content: []byte("Sent from my phone")},
+			{Filename: "dinner.png", ContentType: "image/png", Content: []byte("2")},
+		},
+	})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var response ingestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"lunch.jpg", "dinner.png"}, response.Queued)
+	require.Len(t, response.Skipped, 1)
+	assert.Equal(t, "signature.txt", response.Skipped[0].Filename)
+
+	// The queued images are scanned in the background
+	for i := 0; i < 2; i++ {
+		select {
+		case receipt := <-scanner.scanned:
+			assert.Equal(t, "user-1", receipt.UserID)
+		case <-time.After(time.Second):
+			t.Fatal("queued attachment was not scanned")
+		}
+	}
+
+	t.Run("images beyond the scan quota are skipped", func(t *testing.T) {
+		scanner.remaining = 1
+		defer func() { scanner.remaining = -1 }()
+
+		w := post(domain.InboundEmail{
+			To: []string{"receipts+secret-token@inbox.example.com"},
+			Attachments: []domain.EmailAttachment{
+				{Filename: "breakfast.jpg", ContentType: "image/jpeg", Content: []byte("3")},
+				{Filename: "taxi.jpg", ContentType: "image/jpeg", Content: []byte("4")},
+			},
+		})
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var response ingestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []string{"breakfast.jpg"}, response.Queued)
+		require.Len(t, response.Skipped, 1)
+		assert.Equal(t, "taxi.jpg", response.Skipped[0].Filename)
+		assert.Contains(t, response.Skipped[0].Reason, "quota")
+		<-scanner.scanned
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		w := post(domain.InboundEmail{
+			To:          []string{"receipts+guessed@inbox.example.com", "receipts@inbox.example.com"},
+			Attachments: []domain.EmailAttachment{{Filename: "lunch.jpg", ContentType: "image/jpeg", Content: []byte("5")}},
+		})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, scanner.scanned)
+	})
+
+	t.Run("rate limited per client", func(t *testing.T) {
+		w := post(domain.InboundEmail{To: []string{"receipts+guessed@inbox.example.com"}})
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+}
+
+func TestIngestEmailLimits(t *testing.T) {
+	userRepo := &ingestTokenUserRepository{users: map[string]domain.User{
+		"secret-token": {ID: "user-1", IsActive: true},
+	}}
+	scanner := &recordingScanner{remaining: -1, scanned: make(chan domain.Receipt, 10)}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	noAuth := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	NewEmailIngestHandler(service.NewEmailIngestService(userRepo, scanner, "inbox.example.com"), 1024, 2).
+		RegisterRoutes(router, noAuth, middleware.RateLimit(0, time.Minute))
+
+	post := func(email domain.InboundEmail) *httptest.ResponseRecorder {
+		body, err := json.Marshal(email)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/ingest/email", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	image := func(name string, size int) domain.EmailAttachment {
+		return domain.EmailAttachment{Filename: name, ContentType: "image/jpeg", Content: bytes.Repeat([]byte("x"), size)}
+	}
+
+	t.Run("oversized body", func(t *testing.T) {
+		w := post(domain.InboundEmail{
+			To:          []string{"receipts+secret-token@inbox.example.com"},
+			Attachments: []domain.EmailAttachment{image("lunch.jpg", 2048)},
+		})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	})
+
+	t.Run("too many attachments", func(t *testing.T) {
+		w := post(domain.InboundEmail{
+			To:          []string{"receipts+secret-token@inbox.example.com"},
+			Attachments: []domain.EmailAttachment{image("a.jpg", 1), image("b.jpg", 1), image("c.jpg", 1)},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "attachments")
+	})
+
+	t.Run("within the limits", func(t *testing.T) {
+		w := post(domain.InboundEmail{
+			To:          []string{"receipts+secret-token@inbox.example.com"},
+			Attachments: []domain.EmailAttachment{image("a.jpg", 1), image("b.jpg", 1)},
+		})
+		assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		for i := 0; i < 2; i++ {
+			<-scanner.scanned
+		}
+	})
+	assert.Empty(t, scanner.scanned)
+}
+
+func TestIngestAddress(t *testing.T) {
+	userRepo := &ingestTokenUserRepository{users: map[string]domain.User{
+		"secret-token": {ID: "user-1", IsActive: true},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	NewEmailIngestHandler(service.NewEmailIngestService(userRepo, &recordingScanner{}, "inbox.example.com"), 1<<20, 10).
+		RegisterRoutes(router, auth, middleware.RateLimit(0, time.Minute))
+
+	request := func(method, path string) domain.EmailIngestAddress {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var address domain.EmailIngestAddress
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &address))
+		return address
+	}
+
+	address := request(http.MethodGet, "/v1/auth/me/ingest-address")
+	assert.Equal(t, "secret-token", address.Token)
+	assert.Equal(t, "receipts+secret-token@inbox.example.com", address.Address)
+
+	rotated := request(http.MethodPost, "/v1/auth/me/ingest-address/rotate")
+	assert.Len(t, rotated.Token, 32)
+	assert.NotEqual(t, address.Token, rotated.Token)
+	assert.Equal(t, "receipts+"+rotated.Token+"@inbox.example.com", rotated.Address)
+	assert.Contains(t, userRepo.users, rotated.Token)
+	assert.NotContains(t, userRepo.users, "secret-token")
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureHandler tells clients which optional features the server has enabled
+type FeatureHandler struct {
+	features map[string]bool
+}
+
+// NewFeatureHandler creates a new feature handler reporting the given features
+func NewFeatureHandler(features map[string]bool) *FeatureHandler {
+	return &FeatureHandler{
+		features: features,
+	}
+}
+
+// GetFeatures handles the GET /v1/features endpoint
+// @Summary List enabled features
+// @Description Report which optional features this server has enabled, so clients can hide unavailable options: scan, scanFromUrl, emailIngest, mlx, mlxFallback, imageStorage, currencyConversion, googleLogin and strictCategories. Features follow the server configuration, so the response may be cached
+// @Tags features
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Features keyed by name, each true when enabled"
+// @Header 200 {string} Cache-Control "public, max-age=300"
+// @Router /v1/features [get]
+func (h *FeatureHandler) GetFeatures(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	respondOK(c, gin.H{
+		"features": h.features,
+	})
+}
+
+// RegisterRoutes registers the feature route. It is not behind authentication so clients
+// can adapt before the user signs in.
+func (h *FeatureHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/v1/features", h.GetFeatures)
+}
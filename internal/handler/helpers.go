@@ -2,14 +2,19 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"mime/multipart"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridwanfathin/invoice-processor-service/internal/model"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
 )
 
 // getPathParam retrieves a path parameter and validates it's not empty
@@ -21,6 +26,27 @@ func getPathParam(c *gin.Context, paramName string) (string, error) {
 	return value, nil
 }
 
+// validationErrorDetails converts service.ValidationErrors into error details for a 400 response
+func validationErrorDetails(err error) ([]model.ErrorDetail, bool) {
+	var validationErrs service.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	details := make([]model.ErrorDetail, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		details[i] = newErrorDetail(fieldErr.Field, fieldErr.Message)
+	}
+	return details, true
+}
+
+// setLastModified sets the Last-Modified header, which clients echo back as If-Unmodified-Since
+func setLastModified(c *gin.Context, modified time.Time) {
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
 // getQueryInt retrieves an integer query parameter with a default value
 func getQueryInt(c *gin.Context, paramName string, defaultValue int) (int, error) {
 	valueStr := c.Query(paramName)
@@ -41,6 +67,17 @@ func getQueryString(c *gin.Context, paramName string) string {
 	return c.Query(paramName)
 }
 
+// getQueryList retrieves a comma-separated query parameter as its trimmed, non-empty values
+func getQueryList(c *gin.Context, paramName string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Query(paramName), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // parseDate parses a date string in YYYY-MM-DD format
 func parseDate(dateStr string) (time.Time, error) {
 	if dateStr == "" {
@@ -64,10 +101,37 @@ func getFormFile(c *gin.Context, fieldName string) (multipart.File, *multipart.F
 	return file, header, nil
 }
 
+// getFormFiles retrieves every file uploaded under a multipart form field, in upload order
+func getFormFiles(c *gin.Context, fieldName string) ([]*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File[fieldName]) == 0 {
+		return nil, fmt.Errorf("no %s provided", fieldName)
+	}
+	return form.File[fieldName], nil
+}
+
+// readFormFiles reads the contents of uploaded files
+func readFormFiles(headers []*multipart.FileHeader) ([][]byte, error) {
+	files := make([][]byte, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, data)
+	}
+	return files, nil
+}
+
 // bindJSON binds JSON request body to a struct
 func bindJSON(c *gin.Context, obj interface{}) error {
 	if err := c.ShouldBindJSON(obj); err != nil {
-		return fmt.Errorf("invalid JSON format: %v", err)
+		return fmt.Errorf("invalid JSON format: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/model"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// MerchantRuleHandler handles HTTP requests for merchant categorization rules
+type MerchantRuleHandler struct {
+	merchantRuleService service.MerchantRuleService
+}
+
+// NewMerchantRuleHandler creates a new merchant rule handler
+func NewMerchantRuleHandler(merchantRuleService service.MerchantRuleService) *MerchantRuleHandler {
+	return &MerchantRuleHandler{
+		merchantRuleService: merchantRuleService,
+	}
+}
+
+// MerchantRuleRequest represents a request to categorize all items from a merchant
+type MerchantRuleRequest struct {
+	Merchant string `json:"merchant" example:"Starbucks"`
+	Category string `json:"category" example:"Food"`
+}
+
+// CreateMerchantRule handles the POST /categories/merchant-rules endpoint
+// @Summary Create a merchant rule
+// @Description Categorize every item from a merchant. Replaces the category of an existing rule for the same merchant
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param rule body MerchantRuleRequest true "Merchant rule"
+// @Success 201 {object} map[string]interface{} "Merchant rule created"
+// @Header 201 {string} Location "URL of the created merchant rule"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/categories/merchant-rules [post]
+func (h *MerchantRuleHandler) CreateMerchantRule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req MerchantRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+
+	var details []model.ErrorDetail
+	if strings.TrimSpace(req.Merchant) == "" {
+		details = append(details, newErrorDetail("merchant", "Merchant is required"))
+	}
+	if strings.TrimSpace(req.Category) == "" {
+		details = append(details, newErrorDetail("category", "Category is required"))
+	}
+	if len(details) > 0 {
+		respondBadRequest(c, "Validation failed", details...)
+		return
+	}
+
+	rule, err := h.merchantRuleService.CreateMerchantRule(c.Request.Context(), userID.(string), req.Merchant, req.Category)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to create merchant rule: %v", err))
+		return
+	}
+
+	respondCreatedAt(c, rule.ID, formatMerchantRuleResponse(rule))
+}
+
+// GetMerchantRules handles the GET /categories/merchant-rules endpoint
+// @Summary List merchant rules
+// @Description List the authenticated user's merchant categorization rules
+// @Tags categories
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Merchant rules"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/categories/merchant-rules [get]
+func (h *MerchantRuleHandler) GetMerchantRules(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	rules, err := h.merchantRuleService.ListMerchantRules(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve merchant rules: %v", err))
+		return
+	}
+
+	data := make([]gin.H, len(rules))
+	for i := range rules {
+		data[i] = formatMerchantRuleResponse(&rules[i])
+	}
+
+	respondOK(c, gin.H{"data": data})
+}
+
+// DeleteMerchantRule handles the DELETE /categories/merchant-rules/{ruleId} endpoint
+// @Summary Delete a merchant rule
+// @Description Delete a merchant categorization rule. Existing item categories are left unchanged
+// @Tags categories
+// @Param ruleId path string true "Rule ID"
+// @Success 204 "Merchant rule deleted successfully"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "Merchant rule not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/categories/merchant-rules/{ruleId} [delete]
+func (h *MerchantRuleHandler) DeleteMerchantRule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	ruleID, err := getPathParam(c, "ruleId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	err = h.merchantRuleService.DeleteMerchantRule(c.Request.Context(), userID.(string), ruleID)
+	if err != nil {
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Merchant rule not found: %s", ruleID))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to delete merchant rule: %v", err))
+		}
+		return
+	}
+
+	respondNoContent(c)
+}
+
+// ApplyMerchantRules handles the POST /receipts/apply-merchant-rules endpoint
+// @Summary Apply merchant rules to existing receipts
+// @Description Recategorize every existing item whose receipt merchant matches a rule
+// @Tags receipts
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Number of items updated"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/apply-merchant-rules [post]
+func (h *MerchantRuleHandler) ApplyMerchantRules(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	updated, err := h.merchantRuleService.ApplyMerchantRules(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to apply merchant rules: %v", err))
+		return
+	}
+
+	respondOK(c, gin.H{"updatedItems": updated})
+}
+
+// maxClassifyTextLength bounds the text a classification preview accepts
+const maxClassifyTextLength = 500
+
+// ClassifyText handles the GET /categories/classify endpoint
+// @Summary Preview category classification
+// @Description Show the category a merchant or item description would be given when scanned, and the rule that decided it: the user's merchant rule for a merchant of that name, else a built-in keyword in the text, else the default category
+// @Tags categories
+// @Produce json
+// @Param text query string true "Merchant name or item description"
+// @Success 200 {object} domain.CategoryClassification "Classification"
+// @Failure 400 {object} model.ErrorResponse "Missing or too long text"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/categories/classify [get]
+func (h *MerchantRuleHandler) ClassifyText(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("text"))
+	if text == "" {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("text", "Text is required"))
+		return
+	}
+	if len(text) > maxClassifyTextLength {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("text", fmt.Sprintf("Text must be at most %d characters", maxClassifyTextLength)))
+		return
+	}
+
+	classification, err := h.merchantRuleService.ClassifyText(c.Request.Context(), userID.(string), text)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to classify text: %v", err))
+		return
+	}
+
+	respondOK(c, classification)
+}
+
+// formatMerchantRuleResponse formats a merchant rule for response
+func formatMerchantRuleResponse(rule *domain.MerchantRule) gin.H {
+	return gin.H{
+		"id":        rule.ID,
+		"merchant":  rule.Merchant,
+		"category":  rule.Category,
+		"createdAt": rule.CreatedAt.Format(time.RFC3339),
+		"updatedAt": rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// RegisterRoutes registers the merchant rule API routes
+func (h *MerchantRuleHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	api := router.Group("/v1")
+
+	rules := api.Group("/categories/merchant-rules", authMiddleware)
+	{
+		rules.POST("", h.CreateMerchantRule)
+		rules.GET("", h.GetMerchantRules)
+		rules.DELETE("/:ruleId", h.DeleteMerchantRule)
+	}
+
+	api.GET("/categories/classify", authMiddleware, h.ClassifyText)
+	api.POST("/receipts/apply-merchant-rules", authMiddleware, h.ApplyMerchantRules)
+}
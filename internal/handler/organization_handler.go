@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// OrganizationHandler handles HTTP requests for organizations that share receipts
+type OrganizationHandler struct {
+	organizationService service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// OrganizationRequest represents a request to create an organization
+type OrganizationRequest struct {
+	Name string `json:"name" example:"Finance Team"`
+}
+
+// InviteMemberRequest represents a request to add a registered user to an organization
+type InviteMemberRequest struct {
+	Email string `json:"email" example:"colleague@example.com"`
+}
+
+// CreateOrganization handles the POST /organizations endpoint
+// @Summary Create an organization
+// @Description Create an organization owned by the authenticated user
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body OrganizationRequest true "Organization"
+// @Success 201 {object} map[string]interface{} "Organization created"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "Validation failed", newErrorDetail("name", "Name is required"))
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(c.Request.Context(), userID.(string), req.Name)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to create organization: %v", err))
+		return
+	}
+
+	respondCreated(c, formatOrganizationResponse(org))
+}
+
+// GetOrganizations handles the GET /organizations endpoint
+// @Summary List organizations
+// @Description List the organizations the authenticated user is a member of
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Organizations"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/organizations [get]
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	orgs, err := h.organizationService.ListOrganizations(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve organizations: %v", err))
+		return
+	}
+
+	data := make([]gin.H, len(orgs))
+	for i := range orgs {
+		data[i] = formatOrganizationResponse(&orgs[i])
+	}
+
+	respondOK(c, gin.H{"data": data})
+}
+
+// InviteMember handles the POST /organizations/{orgId}/members endpoint
+// @Summary Invite a member
+// @Description Add a registered user to an organization by email. Only the organization owner can invite members
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param orgId path string true "Organization ID"
+// @Param member body InviteMemberRequest true "Member to invite"
+// @Success 201 {object} map[string]interface{} "Member added"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Not the organization owner"
+// @Failure 404 {object} model.ErrorResponse "User not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/organizations/{orgId}/members [post]
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	orgID, err := getPathParam(c, "orgId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		respondBadRequest(c, "Validation failed", newErrorDetail("email", "Email is required"))
+		return
+	}
+
+	member, err := h.organizationService.InviteMember(c.Request.Context(), orgID, userID.(string), req.Email)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+		} else if errors.Is(err, service.ErrNotOrganizationOwner) {
+			respondForbidden(c, "Only the organization owner can invite members")
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			respondNotFound(c, fmt.Sprintf("No registered user with email: %s", req.Email))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to invite member: %v", err))
+		}
+		return
+	}
+
+	respondCreated(c, formatOrganizationMemberResponse(member))
+}
+
+// GetMembers handles the GET /organizations/{orgId}/members endpoint
+// @Summary List organization members
+// @Description List the members of an organization the authenticated user belongs to
+// @Tags organizations
+// @Produce json
+// @Param orgId path string true "Organization ID"
+// @Success 200 {object} map[string]interface{} "Organization members"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/organizations/{orgId}/members [get]
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	orgID, err := getPathParam(c, "orgId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), orgID, userID.(string))
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to retrieve organization members: %v", err))
+		}
+		return
+	}
+
+	data := make([]gin.H, len(members))
+	for i := range members {
+		data[i] = formatOrganizationMemberResponse(&members[i])
+	}
+
+	respondOK(c, gin.H{"data": data})
+}
+
+// formatOrganizationResponse formats an organization for response
+func formatOrganizationResponse(org *domain.Organization) gin.H {
+	return gin.H{
+		"id":        org.ID,
+		"name":      org.Name,
+		"ownerId":   org.OwnerID,
+		"createdAt": org.CreatedAt.Format(time.RFC3339),
+		"updatedAt": org.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// formatOrganizationMemberResponse formats an organization member for response
+func formatOrganizationMemberResponse(member *domain.OrganizationMember) gin.H {
+	return gin.H{
+		"organizationId": member.OrganizationID,
+		"userId":         member.UserID,
+		"email":          member.Email,
+		"name":           member.Name,
+		"role":           member.Role,
+		"joinedAt":       member.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// RegisterRoutes registers the organization API routes
+func (h *OrganizationHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	api := router.Group("/v1")
+
+	orgs := api.Group("/organizations", authMiddleware)
+	{
+		orgs.POST("", h.CreateOrganization)
+		orgs.GET("", h.GetOrganizations)
+		orgs.GET("/:orgId/members", h.GetMembers)
+		orgs.POST("/:orgId/members", h.InviteMember)
+	}
+}
@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
 	"github.com/ridwanfathin/invoice-processor-service/internal/model"
 	"github.com/ridwanfathin/invoice-processor-service/internal/service"
 )
@@ -28,15 +33,22 @@ func NewReceiptHandler(receiptService service.ReceiptService) *ReceiptHandler {
 
 // ScanReceipt handles the POST /receipts/scan endpoint
 // @Summary Scan a receipt image
-// @Description Upload and process a receipt image to extract data using AI
+// @Description Upload and process a receipt image to extract data using AI. A multi-page receipt can be uploaded as several receiptImage files, one per page up to SCAN_MAX_PAGES, and the pages to extract chosen with pages. When the model is unsure of fields such as the merchant or total, alternatives lists other plausible values per field for the user to pick from. When SCAN_PROCESSING_DEADLINE_SECONDS passes before every page is extracted, the remaining pages are skipped and the receipt is saved unverified from the pages so far with partial set to true
 // @Tags receipts
 // @Accept multipart/form-data
 // @Produce json
-// @Param receiptImage formData file true "Receipt image file"
+// @Param receiptImage formData file true "Receipt image file, repeated once per page for multi-page receipts"
+// @Param pages formData string false "Comma-separated 1-based pages or ranges to extract, e.g. 1,3 or 2-4 (default all pages)"
+// @Param storeImage formData boolean false "Keep the receipt image; when false only the extracted data is saved and the receipt has no image URL (default true)"
 // @Success 200 {object} model.ReceiptResponse "Successfully scanned receipt"
+// @Header 200 {integer} X-Extraction-Latency-Ms "Time spent extracting the receipt data, in milliseconds"
+// @Header 200 {integer} X-Extraction-Tokens "Model tokens used by the extraction, when the model reports them"
+// @Header 200 {number} X-Extraction-Cost "Cost of the extraction in OpenRouter credits (USD), when reported"
 // @Failure 400 {object} model.ErrorResponse "Bad request"
-// @Failure 422 {object} model.ErrorResponse "Unable to extract data"
+// @Failure 422 {object} model.ErrorResponse "Unable to extract data, or the image could not be decoded"
+// @Failure 429 {object} model.ErrorResponse "Monthly scan quota used up"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Scanning service not configured"
 // @Router /v1/receipts/scan [post]
 func (h *ReceiptHandler) ScanReceipt(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
@@ -46,16 +58,15 @@ func (h *ReceiptHandler) ScanReceipt(c *gin.Context) {
 		return
 	}
 
-	// Get receipt image from form data
-	file, _, err := getFormFile(c, "receiptImage")
+	// Get receipt page images from form data
+	fileHeaders, err := getFormFiles(c, "receiptImage")
 	if err != nil {
 		respondBadRequest(c, err.Error(), newErrorDetail("receiptImage", "Receipt image is required"))
 		return
 	}
-	defer file.Close()
 
 	// Read file contents
-	fileBytes, err := io.ReadAll(file)
+	pageImages, err := readFormFiles(fileHeaders)
 	if err != nil {
 		logError(c, "failed_to_read_file", err, map[string]interface{}{
 			"error_type": "file_read_error",
@@ -64,19 +75,123 @@ func (h *ReceiptHandler) ScanReceipt(c *gin.Context) {
 		return
 	}
 
+	// The page selection may be sent as a form field or a query parameter
+	pages := c.DefaultPostForm("pages", c.Query("pages"))
+	storeImage, err := strconv.ParseBool(c.DefaultPostForm("storeImage", c.DefaultQuery("storeImage", "true")))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("storeImage", "storeImage must be true or false"))
+		return
+	}
+
 	// Process receipt image
-	receipt, err := h.receiptService.ScanReceipt(c.Request.Context(), fileBytes, userID.(string))
+	receipt, err := h.receiptService.ScanReceiptPages(c.Request.Context(), pageImages, pages, userID.(string), storeImage)
 	if err != nil {
+		if details, ok := validationErrorDetails(err); ok {
+			respondBadRequest(c, "Validation failed", details...)
+			return
+		}
+
 		// Log the actual error with context
 		logError(c, "failed_to_scan_receipt", err, map[string]interface{}{
 			"error_type":    "service_error",
 			"error_message": err.Error(),
-			"file_size":     len(fileBytes),
+			"page_count":    len(pageImages),
 		})
 
 		// Check for specific error types
-		if strings.Contains(fmt.Sprintf("%v", err), "not configured") {
-			respondBadRequest(c, fmt.Sprintf("Configuration error: %v", err))
+		if errors.Is(err, service.ErrScanQuotaExceeded) {
+			respondTooManyRequests(c, ErrScanQuotaExceeded)
+		} else if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, ErrScanNotConfigured)
+		} else if errors.Is(err, domain.ErrImageUndecodable) {
+			respondUnprocessableEntity(c, ErrImageUndecodable, newErrorDetail("receiptImage", err.Error()))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "unable to extract") {
+			respondUnprocessableEntity(c, ErrDataExtraction)
+		} else {
+			respondInternalServerError(c, ErrFileProcessing)
+		}
+		return
+	}
+
+	setExtractionHeaders(c, receipt.Extraction)
+	respondOK(c, formatReceiptResponse(receipt))
+}
+
+// setExtractionHeaders reports a scan's extraction latency and, when the model reported them,
+// its token usage and cost in response headers
+func setExtractionHeaders(c *gin.Context, stats *domain.ExtractionStats) {
+	if stats == nil {
+		return
+	}
+	c.Header("X-Extraction-Latency-Ms", strconv.FormatInt(stats.Latency.Milliseconds(), 10))
+	if stats.Usage == nil {
+		return
+	}
+	c.Header("X-Extraction-Tokens", strconv.Itoa(stats.Usage.TotalTokens))
+	if stats.Usage.Cost > 0 {
+		c.Header("X-Extraction-Cost", strconv.FormatFloat(stats.Usage.Cost, 'f', -1, 64))
+	}
+}
+
+// ScanReceiptURLRequest represents a request to scan a remotely hosted receipt image
+type ScanReceiptURLRequest struct {
+	ImageURL string `json:"imageUrl" example:"https://example.com/receipt.jpg"`
+	// StoreImage keeps the fetched image as the receipt image, defaulting to true
+	StoreImage *bool `json:"storeImage,omitempty" example:"true"`
+}
+
+// ScanReceiptFromURL handles the POST /receipts/scan/url endpoint
+// @Summary Scan a receipt image from a URL
+// @Description Fetch a remotely hosted receipt image and process it to extract data using AI. Only public http(s) URLs are allowed. With storeImage set to false the fetched image is not kept and only the extracted data is saved
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param request body ScanReceiptURLRequest true "Receipt image URL"
+// @Success 200 {object} model.ReceiptResponse "Successfully scanned receipt"
+// @Header 200 {integer} X-Extraction-Latency-Ms "Time spent extracting the receipt data, in milliseconds"
+// @Header 200 {integer} X-Extraction-Tokens "Model tokens used by the extraction, when the model reports them"
+// @Header 200 {number} X-Extraction-Cost "Cost of the extraction in OpenRouter credits (USD), when reported"
+// @Failure 400 {object} model.ErrorResponse "Invalid or blocked image URL"
+// @Failure 422 {object} model.ErrorResponse "Unable to extract data, or the image could not be decoded"
+// @Failure 429 {object} model.ErrorResponse "Monthly scan quota used up"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Scanning service not configured"
+// @Router /v1/receipts/scan/url [post]
+func (h *ReceiptHandler) ScanReceiptFromURL(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req ScanReceiptURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.ImageURL) == "" {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("imageUrl", "Image URL is required"))
+		return
+	}
+
+	storeImage := req.StoreImage == nil || *req.StoreImage
+
+	// Fetch and process receipt image
+	receipt, err := h.receiptService.ScanReceiptFromURL(c.Request.Context(), req.ImageURL, userID.(string), storeImage)
+	if err != nil {
+		logError(c, "failed_to_scan_receipt_url", err, map[string]interface{}{
+			"error_type":    "service_error",
+			"error_message": err.Error(),
+		})
+
+		var serviceErr *service.ReceiptServiceError
+		if errors.Is(err, imageutil.ErrBlockedURL) || errors.Is(err, imageutil.ErrImageTooLarge) {
+			respondBadRequest(c, "Image URL cannot be fetched", newErrorDetail("imageUrl", err.Error()))
+		} else if errors.As(err, &serviceErr) && serviceErr.Op == "fetch_image_url" {
+			respondBadRequest(c, "Failed to fetch image from URL", newErrorDetail("imageUrl", err.Error()))
+		} else if errors.Is(err, service.ErrScanQuotaExceeded) {
+			respondTooManyRequests(c, ErrScanQuotaExceeded)
+		} else if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, ErrScanNotConfigured)
+		} else if errors.Is(err, domain.ErrImageUndecodable) {
+			respondUnprocessableEntity(c, ErrImageUndecodable, newErrorDetail("imageUrl", err.Error()))
 		} else if strings.Contains(fmt.Sprintf("%v", err), "unable to extract") {
 			respondUnprocessableEntity(c, ErrDataExtraction)
 		} else {
@@ -85,6 +200,7 @@ func (h *ReceiptHandler) ScanReceipt(c *gin.Context) {
 		return
 	}
 
+	setExtractionHeaders(c, receipt.Extraction)
 	respondOK(c, formatReceiptResponse(receipt))
 }
 
@@ -150,6 +266,7 @@ func (h *ReceiptHandler) RetryScanReceipt(c *gin.Context) {
 // @Produce json
 // @Param receipt body domain.Receipt true "Receipt data"
 // @Success 201 {object} model.ReceiptResponse "Receipt created successfully"
+// @Header 201 {string} Location "URL of the created receipt"
 // @Failure 400 {object} model.ErrorResponse "Invalid input"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/receipts [post]
@@ -163,7 +280,7 @@ func (h *ReceiptHandler) CreateReceipt(c *gin.Context) {
 
 	var input domain.Receipt
 	if err := bindJSON(c, &input); err != nil {
-		respondBadRequest(c, ErrInvalidInput)
+		respondBadRequest(c, ErrInvalidInput, receiptBindErrorDetails(err)...)
 		return
 	}
 
@@ -179,26 +296,42 @@ func (h *ReceiptHandler) CreateReceipt(c *gin.Context) {
 	// Create receipt
 	receipt, err := h.receiptService.CreateReceipt(c.Request.Context(), &input)
 	if err != nil {
-		respondInternalServerError(c, fmt.Sprintf("Failed to create receipt: %v", err))
+		if details, ok := validationErrorDetails(err); ok {
+			respondBadRequest(c, "Validation failed", details...)
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to create receipt: %v", err))
+		}
 		return
 	}
 
-	respondCreated(c, formatReceiptResponse(receipt))
+	respondCreatedAt(c, receipt.ID, formatReceiptResponse(receipt))
 }
 
 // GetReceipts handles the GET /receipts endpoint
 // @Summary List all receipts
-// @Description Get a paginated list of receipts with optional filters
+// @Description Get a paginated list of receipts with optional filters. For incremental sync, modifiedSince lists only receipts updated after that time, oldest change first, and deletedIds the IDs of the user's own receipts deleted since then
 // @Tags receipts
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
+// @Param limit query int false "Items per page, at most 100. Larger values are reduced and reported as pagination.clamped with pagination.requestedLimit" default(10)
 // @Param startDate query string false "Start date filter (YYYY-MM-DD)"
 // @Param endDate query string false "End date filter (YYYY-MM-DD)"
 // @Param merchant query string false "Merchant name filter"
+// @Param needsReview query bool false "Only return unverified receipts that need review"
+// @Param hasImage query bool false "Only return receipts with (true) or without (false) a stored image"
+// @Param scope query string false "Receipts to list: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Param category query string false "Only receipts with an item in this category"
+// @Param taxId query string false "Only receipts from the seller with this tax or registration number"
+// @Param modifiedSince query string false "Only receipts updated after this RFC3339 time, e.g. the time of the last sync; sorts by updatedAt ascending unless sortBy is given"
+// @Param sortBy query string false "Sort field: date, total, merchant, createdAt or updatedAt" default(date)
+// @Param sortOrder query string false "Sort direction: asc or desc" default(desc)
+// @Param view query string false "Name of a saved view whose parameters apply; explicit query parameters take precedence"
 // @Success 200 {object} model.ReceiptsListResponse "List of receipts"
 // @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 404 {object} model.ErrorResponse "Receipt view not found"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/receipts [get]
 func (h *ReceiptHandler) GetReceipts(c *gin.Context) {
@@ -209,28 +342,18 @@ func (h *ReceiptHandler) GetReceipts(c *gin.Context) {
 		return
 	}
 
-	// Parse query parameters
-	filter, err := parseReceiptFilter(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "400",
-			"message": "Invalid query parameters",
-			"details": []gin.H{
-				{
-					"field":   "query",
-					"message": err.Error(),
-				},
-			},
-		})
+	filter, ok := h.receiptFilterFromRequest(c, userID.(string))
+	if !ok {
 		return
 	}
 
-	// Set user ID filter to only return receipts for the authenticated user
-	filter.UserID = userID.(string)
-
 	// Get receipts
 	paginatedReceipts, err := h.receiptService.ListReceipts(c.Request.Context(), filter)
 	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "500",
 			"message": fmt.Sprintf("Failed to retrieve receipts: %v", err),
@@ -238,30 +361,159 @@ func (h *ReceiptHandler) GetReceipts(c *gin.Context) {
 		return
 	}
 
-	// Format response
+	data := formatReceiptsResponse(paginatedReceipts.Data)
+	if format := h.receiptService.UserNumberFormat(c.Request.Context(), userID.(string)); format != nil {
+		for i := range paginatedReceipts.Data {
+			addFormattedAmounts(data[i], &paginatedReceipts.Data[i], format)
+		}
+	}
+
 	response := gin.H{
-		"data": formatReceiptsResponse(paginatedReceipts.Data),
-		"pagination": gin.H{
-			"totalItems":  paginatedReceipts.Pagination.TotalItems,
-			"totalPages":  paginatedReceipts.Pagination.TotalPages,
-			"currentPage": paginatedReceipts.Pagination.CurrentPage,
-			"limit":       paginatedReceipts.Pagination.Limit,
-		},
+		"data":       data,
+		"pagination": formatPaginationResponse(paginatedReceipts.Pagination, filter.RequestedLimit),
+	}
+	if filter.ModifiedSince != nil {
+		deletedIDs := paginatedReceipts.DeletedIDs
+		if deletedIDs == nil {
+			deletedIDs = []string{}
+		}
+		response["deletedIds"] = deletedIDs
 	}
 	c.JSON(http.StatusOK, response)
 }
 
+// CountReceipts handles the GET /receipts/count endpoint
+// @Summary Count receipts
+// @Description Count the receipts matching the same filters as the receipts listing, without fetching them
+// @Tags receipts
+// @Produce json
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param merchant query string false "Merchant name filter"
+// @Param category query string false "Only receipts with an item in this category"
+// @Param taxId query string false "Only receipts from the seller with this tax or registration number"
+// @Param needsReview query bool false "Only count unverified receipts that need review"
+// @Param hasImage query bool false "Only count receipts with (true) or without (false) a stored image"
+// @Param scope query string false "Receipts to count: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Param view query string false "Name of a saved view whose parameters apply; explicit query parameters take precedence"
+// @Success 200 {object} map[string]interface{} "Receipt count"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 404 {object} model.ErrorResponse "Receipt view not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/count [get]
+func (h *ReceiptHandler) CountReceipts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	filter, ok := h.receiptFilterFromRequest(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	count, err := h.receiptService.CountReceipts(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to count receipts: %v", err))
+		return
+	}
+
+	respondOK(c, gin.H{"count": count})
+}
+
+// GetReceiptCurrencies handles the GET /receipts/currencies endpoint
+// @Summary List the currencies in use
+// @Description List the distinct currencies of the user's receipt items, with the number of receipts and items in each, most used first
+// @Tags receipts
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Currencies with receiptCount and itemCount"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/currencies [get]
+func (h *ReceiptHandler) GetReceiptCurrencies(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	currencies, err := h.receiptService.ListReceiptCurrencies(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to list currencies: %v", err))
+		return
+	}
+
+	respondOK(c, gin.H{"data": currencies})
+}
+
+// receiptFilterFromRequest builds the listing filter from the query, a saved view and the scope.
+// It responds with the error and returns false when the request is invalid.
+func (h *ReceiptHandler) receiptFilterFromRequest(c *gin.Context, userID string) (domain.ReceiptFilter, bool) {
+	// Parse query parameters, starting from a saved view's parameters when one is requested
+	query := c.Request.URL.Query()
+	if viewName := strings.TrimSpace(query.Get("view")); viewName != "" {
+		view, err := h.receiptService.GetReceiptView(c.Request.Context(), userID, viewName)
+		if err != nil {
+			if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+				respondNotFound(c, fmt.Sprintf("Receipt view not found: %s", viewName))
+			} else {
+				respondInternalServerError(c, fmt.Sprintf("Failed to retrieve receipt view: %v", err))
+			}
+			return domain.ReceiptFilter{}, false
+		}
+		query = applyReceiptView(view, query)
+	}
+
+	filter, err := parseReceiptFilterValues(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid query parameters",
+			"details": []gin.H{
+				{
+					"field":   "query",
+					"message": err.Error(),
+				},
+			},
+		})
+		return domain.ReceiptFilter{}, false
+	}
+
+	// Only cover receipts of the authenticated user, or the organization's shared receipts
+	scope, err := parseReceiptScope(c, userID)
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return domain.ReceiptFilter{}, false
+	}
+	filter.UserID = scope.UserID
+	filter.OrgID = scope.OrgID
+
+	return filter, true
+}
+
 // GetReceiptByID handles the GET /receipts/{receiptId} endpoint
 // @Summary Get a receipt by ID
-// @Description Retrieve a specific receipt by its ID
+// @Description Retrieve a specific receipt by its ID. When the user has a numberLocale preference, amounts are also given in that locale's format under formatted and as each item's formattedPrice
 // @Tags receipts
 // @Accept json
 // @Produce json
 // @Param receiptId path string true "Receipt ID"
+// @Param convertTo query string false "Also show item prices in this currency, at the rates of the receipt's date (e.g. EUR)"
 // @Success 200 {object} model.ReceiptResponse "Receipt details"
-// @Failure 400 {object} model.ErrorResponse "Invalid receipt ID"
+// @Header 200 {string} Last-Modified "Time the receipt was last updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid receipt ID or currency"
 // @Failure 404 {object} model.ErrorResponse "Receipt not found"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Currency conversion unavailable"
 // @Router /v1/receipts/{receiptId} [get]
 func (h *ReceiptHandler) GetReceiptByID(c *gin.Context) {
 	receiptID, err := getPathParam(c, "receiptId")
@@ -281,20 +533,42 @@ func (h *ReceiptHandler) GetReceiptByID(c *gin.Context) {
 		return
 	}
 
-	respondOK(c, formatReceiptResponse(receipt))
+	if convertTo := c.Query("convertTo"); convertTo != "" {
+		if err := h.receiptService.ConvertReceiptItems(c.Request.Context(), receipt, convertTo); err != nil {
+			if details, ok := validationErrorDetails(err); ok {
+				respondBadRequest(c, ErrInvalidQueryParams, details...)
+			} else if strings.Contains(fmt.Sprintf("%v", err), "exchange rate not found") {
+				respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("convertTo", fmt.Sprintf("Currency '%s' is not supported", convertTo)))
+			} else {
+				respondServiceUnavailable(c, fmt.Sprintf("Currency conversion is unavailable: %v", err))
+			}
+			return
+		}
+	}
+
+	response := formatReceiptResponse(receipt)
+	if userID, exists := c.Get("userID"); exists {
+		addFormattedAmounts(response, receipt, h.receiptService.UserNumberFormat(c.Request.Context(), userID.(string)))
+	}
+
+	setLastModified(c, receipt.UpdatedAt)
+	respondOK(c, response)
 }
 
 // UpdateReceipt handles the PUT /receipts/{receiptId} endpoint
 // @Summary Update a receipt
-// @Description Update an existing receipt by ID
+// @Description Update an existing receipt by ID. Items sent with the id of one of the receipt's items are updated in place and keep that id; items without an id are added, and items left out are removed
 // @Tags receipts
 // @Accept json
 // @Produce json
 // @Param receiptId path string true "Receipt ID"
 // @Param receipt body domain.Receipt true "Updated receipt data"
+// @Param If-Unmodified-Since header string false "Only update if the receipt is unchanged since this HTTP date, e.g. the Last-Modified of a previous read"
 // @Success 200 {object} model.ReceiptResponse "Receipt updated successfully"
+// @Header 200 {string} Last-Modified "Time of the update"
 // @Failure 400 {object} model.ErrorResponse "Invalid input"
 // @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 409 {object} model.ErrorResponse "Receipt was modified since If-Unmodified-Since"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
 // @Router /v1/receipts/{receiptId} [put]
 func (h *ReceiptHandler) UpdateReceipt(c *gin.Context) {
@@ -307,7 +581,7 @@ func (h *ReceiptHandler) UpdateReceipt(c *gin.Context) {
 	// Parse input
 	var input domain.Receipt
 	if err := bindJSON(c, &input); err != nil {
-		respondBadRequest(c, ErrInvalidInput)
+		respondBadRequest(c, ErrInvalidInput, receiptBindErrorDetails(err)...)
 		return
 	}
 
@@ -320,10 +594,24 @@ func (h *ReceiptHandler) UpdateReceipt(c *gin.Context) {
 	// Ensure ID matches path parameter
 	input.ID = receiptID
 
+	// Make the update conditional when the client sends the time it last read the receipt
+	if header := c.GetHeader("If-Unmodified-Since"); header != "" {
+		unmodifiedSince, err := http.ParseTime(header)
+		if err != nil {
+			respondBadRequest(c, "Invalid If-Unmodified-Since header", newErrorDetail("If-Unmodified-Since", "Must be an HTTP date, e.g. Mon, 02 Jan 2006 15:04:05 GMT"))
+			return
+		}
+		input.UnmodifiedSince = &unmodifiedSince
+	}
+
 	// Update receipt
 	updatedReceipt, err := h.receiptService.UpdateReceipt(c.Request.Context(), &input)
 	if err != nil {
-		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+		if details, ok := validationErrorDetails(err); ok {
+			respondBadRequest(c, "Validation failed", details...)
+		} else if errors.Is(err, domain.ErrReceiptModified) {
+			respondConflict(c, ErrReceiptModified)
+		} else if strings.Contains(fmt.Sprintf("%v", err), "not found") {
 			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
 		} else {
 			respondInternalServerError(c, fmt.Sprintf("Failed to update receipt: %v", err))
@@ -331,86 +619,688 @@ func (h *ReceiptHandler) UpdateReceipt(c *gin.Context) {
 		return
 	}
 
+	setLastModified(c, updatedReceipt.UpdatedAt)
 	respondOK(c, formatReceiptResponse(updatedReceipt))
 }
 
-// DeleteReceipt handles the DELETE /receipts/{receiptId} endpoint
-// @Summary Delete a receipt
-// @Description Delete a receipt by ID
+// ReceiptOrganizationRequest represents a request to share a receipt with an organization
+type ReceiptOrganizationRequest struct {
+	OrgID string `json:"orgId" example:"3f6c1a9e-8d2b-4c7a-9f1e-2b5d8a7c6e40"`
+}
+
+// SetReceiptOrganization handles the PUT /receipts/{receiptId}/organization endpoint
+// @Summary Share a receipt with an organization
+// @Description Share a receipt with an organization the user belongs to. An empty orgId makes the receipt personal again
 // @Tags receipts
 // @Accept json
 // @Produce json
 // @Param receiptId path string true "Receipt ID"
-// @Success 204 "Receipt deleted successfully"
-// @Failure 400 {object} model.ErrorResponse "Invalid receipt ID"
+// @Param request body ReceiptOrganizationRequest true "Organization to share with"
+// @Success 200 {object} model.ReceiptResponse "Receipt organization updated"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
 // @Failure 404 {object} model.ErrorResponse "Receipt not found"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
-// @Router /v1/receipts/{receiptId} [delete]
-func (h *ReceiptHandler) DeleteReceipt(c *gin.Context) {
+// @Router /v1/receipts/{receiptId}/organization [put]
+func (h *ReceiptHandler) SetReceiptOrganization(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
 	receiptID, err := getPathParam(c, "receiptId")
 	if err != nil {
 		respondBadRequest(c, err.Error())
 		return
 	}
 
-	// Delete receipt
-	err = h.receiptService.DeleteReceipt(c.Request.Context(), receiptID)
+	var req ReceiptOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+
+	receipt, err := h.receiptService.SetReceiptOrganization(c.Request.Context(), receiptID, userID.(string), strings.TrimSpace(req.OrgID))
 	if err != nil {
-		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+		logError(c, "failed_to_set_receipt_organization", err, map[string]interface{}{
+			"error_type":    "service_error",
+			"error_message": err.Error(),
+			"receipt_id":    receiptID,
+		})
+
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+		} else if strings.Contains(fmt.Sprintf("%v", err), "not found") {
 			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "does not belong") {
+			respondUnauthorized(c, "You don't have permission to share this receipt")
 		} else {
-			respondInternalServerError(c, fmt.Sprintf("Failed to delete receipt: %v", err))
+			respondInternalServerError(c, "Failed to set receipt organization")
 		}
 		return
 	}
 
-	respondNoContent(c)
+	respondOK(c, formatReceiptResponse(receipt))
 }
 
-// GetReceiptItems handles the GET /receipts/{receiptId}/items endpoint
-func (h *ReceiptHandler) GetReceiptItems(c *gin.Context) {
-	receiptID := c.Param("receiptId")
-	if receiptID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "400",
-			"message": "Receipt ID is required",
-		})
+// MergeReceiptsRequest represents a request to merge a duplicate receipt into another
+type MergeReceiptsRequest struct {
+	MergeWithID string `json:"mergeWithId" example:"9b1f3c2e-5a7d-4e8f-b6c1-0d2e4f6a8b9c"`
+}
+
+// MergeReceipts handles the POST /receipts/{receiptId}/merge endpoint
+// @Summary Merge two receipts of the same purchase
+// @Description Merge a duplicate receipt into this one. The receipt created first is kept and gains the other's items it doesn't already have, matched by name, quantity, price and currency, along with any fields it is missing. The other receipt is deleted
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param receiptId path string true "Receipt ID"
+// @Param request body MergeReceiptsRequest true "Receipt to merge with"
+// @Success 200 {object} model.ReceiptResponse "Merged receipt"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/receipts/{receiptId}/merge [post]
+func (h *ReceiptHandler) MergeReceipts(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
 		return
 	}
 
-	// Get receipt items
-	items, err := h.receiptService.GetReceiptItems(c.Request.Context(), receiptID)
+	receiptID, err := getPathParam(c, "receiptId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	var req MergeReceiptsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	mergeWithID := strings.TrimSpace(req.MergeWithID)
+	if mergeWithID == "" {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("mergeWithId", "mergeWithId is required"))
+		return
+	}
+	if mergeWithID == receiptID {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("mergeWithId", "cannot merge a receipt with itself"))
+		return
+	}
+
+	receipt, err := h.receiptService.MergeReceipts(c.Request.Context(), receiptID, mergeWithID, userID.(string))
 	if err != nil {
+		logError(c, "failed_to_merge_receipts", err, map[string]interface{}{
+			"error_type":    "service_error",
+			"error_message": err.Error(),
+			"receipt_id":    receiptID,
+			"merge_with_id": mergeWithID,
+		})
+
 		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{
-				"status":  "404",
-				"message": fmt.Sprintf("Receipt not found: %s", receiptID),
-			})
-			return
+			respondNotFound(c, "Receipt not found")
+		} else if strings.Contains(fmt.Sprintf("%v", err), "does not belong") {
+			respondUnauthorized(c, "You don't have permission to merge these receipts")
+		} else {
+			respondInternalServerError(c, "Failed to merge receipts")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "500",
-			"message": fmt.Sprintf("Failed to retrieve receipt items: %v", err),
-		})
 		return
 	}
 
-	// Return items
-	c.JSON(http.StatusOK, formatReceiptItemsResponse(items))
+	respondOK(c, formatReceiptResponse(receipt))
+}
+
+// maxBulkReceiptIDs bounds the receipts a single bulk request can act on
+const maxBulkReceiptIDs = 100
+
+// BulkDeleteReceiptsRequest represents a request to delete several receipts at once
+type BulkDeleteReceiptsRequest struct {
+	ReceiptIDs []string `json:"receiptIds" example:"9b1f3c2e-5a7d-4e8f-b6c1-0d2e4f6a8b9c"`
+	// Atomic deletes either every receipt or, when any of them fails, none of them
+	Atomic bool `json:"atomic" example:"false"`
+}
+
+// BulkDeleteReceipts handles the POST /receipts/bulk-delete endpoint
+// @Summary Delete several receipts
+// @Description Delete up to 100 of the user's receipts. Each receipt is deleted on its own and the response lists the IDs deleted and, for the rest, why they were not; a missing or foreign receipt doesn't stop the others. With atomic set, the receipts are deleted together in one transaction only if all of them can be, and otherwise none are
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param request body BulkDeleteReceiptsRequest true "Receipts to delete"
+// @Success 200 {object} domain.BulkResult "Per-receipt results"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/receipts/bulk-delete [post]
+func (h *ReceiptHandler) BulkDeleteReceipts(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req BulkDeleteReceiptsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if len(req.ReceiptIDs) == 0 || len(req.ReceiptIDs) > maxBulkReceiptIDs {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("receiptIds", fmt.Sprintf("Must list between 1 and %d receipt IDs", maxBulkReceiptIDs)))
+		return
+	}
+
+	result, err := h.receiptService.DeleteReceipts(c.Request.Context(), userID.(string), req.ReceiptIDs, req.Atomic)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to delete receipts: %v", err))
+		return
+	}
+
+	respondOK(c, result)
+}
+
+// DeleteReceipt handles the DELETE /receipts/{receiptId} endpoint
+// @Summary Delete a receipt
+// @Description Delete a receipt by ID
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param receiptId path string true "Receipt ID"
+// @Success 204 "Receipt deleted successfully"
+// @Failure 400 {object} model.ErrorResponse "Invalid receipt ID"
+// @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/receipts/{receiptId} [delete]
+func (h *ReceiptHandler) DeleteReceipt(c *gin.Context) {
+	receiptID, err := getPathParam(c, "receiptId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	// Delete receipt
+	err = h.receiptService.DeleteReceipt(c.Request.Context(), receiptID)
+	if err != nil {
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to delete receipt: %v", err))
+		}
+		return
+	}
+
+	respondNoContent(c)
+}
+
+// GetReceiptItems handles the GET /receipts/{receiptId}/items endpoint
+func (h *ReceiptHandler) GetReceiptItems(c *gin.Context) {
+	receiptID := c.Param("receiptId")
+	if receiptID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Receipt ID is required",
+		})
+		return
+	}
+
+	// Get receipt items
+	items, err := h.receiptService.GetReceiptItems(c.Request.Context(), receiptID)
+	if err != nil {
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"status":  "404",
+				"message": fmt.Sprintf("Receipt not found: %s", receiptID),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "500",
+			"message": fmt.Sprintf("Failed to retrieve receipt items: %v", err),
+		})
+		return
+	}
+
+	// Return items
+	c.JSON(http.StatusOK, formatReceiptItemsResponse(items))
+}
+
+// ReplaceReceiptImage handles the PUT /receipts/{receiptId}/image endpoint
+// @Summary Replace a receipt's image
+// @Description Upload a new image for a receipt without re-extracting its data. The merchant, items and totals are unchanged and the previous image is deleted
+// @Tags receipts
+// @Accept multipart/form-data
+// @Produce json
+// @Param receiptId path string true "Receipt ID"
+// @Param receiptImage formData file true "New receipt image"
+// @Success 200 {object} model.ReceiptResponse "Receipt with the new image"
+// @Failure 400 {object} model.ErrorResponse "Missing image"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 422 {object} model.ErrorResponse "Image could not be decoded"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Failure 503 {object} model.ErrorResponse "Image storage not configured"
+// @Security BearerAuth
+// @Router /v1/receipts/{receiptId}/image [put]
+func (h *ReceiptHandler) ReplaceReceiptImage(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	receiptID, err := getPathParam(c, "receiptId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	file, _, err := getFormFile(c, "receiptImage")
+	if err != nil {
+		respondBadRequest(c, err.Error(), newErrorDetail("receiptImage", "Receipt image is required"))
+		return
+	}
+	defer file.Close()
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		logError(c, "failed_to_read_file", err, map[string]interface{}{
+			"error_type": "file_read_error",
+		})
+		respondInternalServerError(c, ErrFileProcessing)
+		return
+	}
+
+	receipt, err := h.receiptService.ReplaceReceiptImage(c.Request.Context(), receiptID, userID.(string), fileBytes)
+	if err != nil {
+		logError(c, "failed_to_replace_receipt_image", err, map[string]interface{}{
+			"error_type":    "service_error",
+			"error_message": err.Error(),
+			"receipt_id":    receiptID,
+		})
+
+		if errors.Is(err, domain.ErrServiceNotConfigured) {
+			respondServiceUnavailable(c, ErrImageNotConfigured)
+		} else if errors.Is(err, domain.ErrImageUndecodable) {
+			respondUnprocessableEntity(c, ErrImageUndecodable, newErrorDetail("receiptImage", err.Error()))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "does not belong") {
+			respondUnauthorized(c, "You don't have permission to update this receipt")
+		} else {
+			respondInternalServerError(c, ErrFileUpload)
+		}
+		return
+	}
+
+	setLastModified(c, receipt.UpdatedAt)
+	respondOK(c, formatReceiptResponse(receipt))
+}
+
+// GetReceiptStats handles the GET /receipts/{receiptId}/stats endpoint
+// @Summary Get a receipt's item statistics
+// @Description Item count, per-category subtotals, average and maximum item price, and tax-to-subtotal ratio for one receipt
+// @Tags receipts
+// @Produce json
+// @Param receiptId path string true "Receipt ID"
+// @Success 200 {object} map[string]interface{} "Receipt statistics"
+// @Failure 400 {object} model.ErrorResponse "Bad request"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/receipts/{receiptId}/stats [get]
+func (h *ReceiptHandler) GetReceiptStats(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	receiptID, err := getPathParam(c, "receiptId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	stats, err := h.receiptService.GetReceiptStats(c.Request.Context(), receiptID, userID.(string))
+	if err != nil {
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "does not belong") {
+			respondUnauthorized(c, "You don't have permission to view this receipt")
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to compute receipt stats: %v", err))
+		}
+		return
+	}
+
+	respondOK(c, formatReceiptStatsResponse(stats))
+}
+
+// ExportReceiptPDF handles the GET /receipts/{receiptId}/pdf endpoint
+// @Summary Export a receipt as PDF
+// @Description Render a receipt with its items, totals and a thumbnail of the original image as a PDF document
+// @Tags receipts
+// @Produce application/pdf
+// @Param receiptId path string true "Receipt ID"
+// @Success 200 {file} file "Receipt PDF"
+// @Failure 400 {object} model.ErrorResponse "Bad request"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "Receipt not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/receipts/{receiptId}/pdf [get]
+func (h *ReceiptHandler) ExportReceiptPDF(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	receiptID, err := getPathParam(c, "receiptId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	pdfData, err := h.receiptService.ExportReceiptPDF(c.Request.Context(), receiptID, userID.(string))
+	if err != nil {
+		logError(c, "failed_to_export_receipt_pdf", err, map[string]interface{}{
+			"error_type":    "service_error",
+			"error_message": err.Error(),
+			"receipt_id":    receiptID,
+		})
+
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Receipt not found: %s", receiptID))
+		} else if strings.Contains(fmt.Sprintf("%v", err), "does not belong") {
+			respondUnauthorized(c, "You don't have permission to export this receipt")
+		} else {
+			respondInternalServerError(c, "Failed to export receipt PDF")
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"receipt-%s.pdf\"", receiptID))
+	c.Data(http.StatusOK, "application/pdf", pdfData)
+}
+
+// GetDashboardSummary handles the GET /dashboard/summary endpoint
+// @Summary Get dashboard summary
+// @Description Get summary statistics for the dashboard
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param scope query string false "Receipts to summarize: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Param excludeCategories query string false "Comma-separated categories whose spend is left out, e.g. Rent,Utilities"
+// @Param excludeMerchants query string false "Comma-separated merchants whose receipts are left out"
+// @Param normalizeMerchants query bool false "Group top merchants by normalized name, so \"WALMART #1234\" and \"Walmart\" count as one; false groups by the name as written" default(true)
+// @Success 200 {object} model.DashboardSummaryResponse "Dashboard summary"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/dashboard/summary [get]
+func (h *ReceiptHandler) GetDashboardSummary(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
+	// Parse query parameters
+	startDate, endDate := parseDateRange(c)
+
+	// Get dashboard summary
+	summary, err := h.receiptService.GetDashboardSummary(c.Request.Context(), scope, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "500",
+			"message": fmt.Sprintf("Failed to retrieve dashboard summary: %v", err),
+		})
+		return
+	}
+
+	// Format response
+	response := formatDashboardSummaryResponse(summary)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSpendingTrends handles the GET /dashboard/spending-trends endpoint
+// @Summary Get spending trends
+// @Description Total spending per day, week, month or year, either between startDate and endDate or over a rolling window ending today in the user's time zone. A window of days ends today; a window of months or years starts on the first of a month so the current month is its last bucket. Without period a window picks its own granularity: daily up to 31 days, weekly up to six months, monthly up to three years and yearly beyond
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param period query string false "Bucket size: daily, weekly, monthly or yearly (default monthly, or chosen by window)"
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param window query string false "Rolling window instead of startDate and endDate, e.g. 30d, 12m or 1y"
+// @Param scope query string false "Receipts to include: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Success 200 {object} model.SpendingTrendsResponse "Spending trends"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/dashboard/spending-trends [get]
+func (h *ReceiptHandler) GetSpendingTrends(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
+	// Parse query parameters
+	period := c.DefaultQuery("period", "monthly")
+	startDate, endDate := parseDateRange(c)
+
+	var window *domain.TrendWindow
+	if value := c.Query("window"); value != "" {
+		if startDate != nil || endDate != nil {
+			respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("window", "window cannot be combined with startDate or endDate"))
+			return
+		}
+		parsed, err := domain.ParseTrendWindow(value)
+		if err != nil {
+			respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("window", err.Error()))
+			return
+		}
+		window = &parsed
+		period = c.Query("period")
+	}
+
+	// Validate period
+	validPeriods := map[string]bool{
+		"daily":   true,
+		"weekly":  true,
+		"monthly": true,
+		"yearly":  true,
+	}
+	if !validPeriods[period] && !(window != nil && period == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid period parameter",
+			"details": []gin.H{
+				{
+					"field":   "period",
+					"message": "Period must be one of: daily, weekly, monthly, yearly",
+				},
+			},
+		})
+		return
+	}
+
+	// Get spending trends
+	var trends *domain.SpendingTrends
+	if window != nil {
+		trends, err = h.receiptService.GetSpendingTrendsWindow(c.Request.Context(), scope, period, *window)
+	} else {
+		trends, err = h.receiptService.GetSpendingTrends(c.Request.Context(), scope, period, startDate, endDate)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "500",
+			"message": fmt.Sprintf("Failed to retrieve spending trends: %v", err),
+		})
+		return
+	}
+
+	// Format response
+	response := formatSpendingTrendsResponse(trends)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSpendingByCategory handles the GET /insights/spending-by-category endpoint
+func (h *ReceiptHandler) GetSpendingByCategory(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
+	// Parse query parameters
+	startDate, endDate := parseDateRange(c)
+
+	// Get spending by category
+	categorySpending, err := h.receiptService.GetSpendingByCategory(c.Request.Context(), scope, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "500",
+			"message": fmt.Sprintf("Failed to retrieve category spending: %v", err),
+		})
+		return
+	}
+
+	// Format response
+	response := formatCategorySpendingResponse(categorySpending)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCategoryTrend handles the GET /insights/category-trend endpoint
+// @Summary Get one category's spending trend
+// @Description Spend on items in one category per day, week, month or year, summing quantity times price by receipt date. Items without a category count towards their receipt's category, as in the category breakdown
+// @Tags insights
+// @Produce json
+// @Param category query string true "Category name, e.g. Food"
+// @Param period query string false "Bucket size: daily, weekly, monthly or yearly" default(monthly)
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param scope query string false "Receipts to include: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Success 200 {object} map[string]interface{} "Category, period and spend per bucket"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/insights/category-trend [get]
+func (h *ReceiptHandler) GetCategoryTrend(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
+	// Parse query parameters
+	category := strings.TrimSpace(c.Query("category"))
+	if category == "" {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("category", "category is required"))
+		return
+	}
+	period := c.DefaultQuery("period", "monthly")
+	switch period {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("period", "Period must be one of: daily, weekly, monthly, yearly"))
+		return
+	}
+	startDate, endDate := parseDateRange(c)
+	if _, err := parseDate(c.Query("startDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("startDate", err.Error()))
+		return
+	}
+	if _, err := parseDate(c.Query("endDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("endDate", err.Error()))
+		return
+	}
+
+	trend, err := h.receiptService.GetCategoryTrend(c.Request.Context(), scope, category, period, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve category trend: %v", err))
+		return
+	}
+
+	response := formatSpendingTrendsResponse(trend)
+	response["category"] = category
+	respondOK(c, response)
 }
 
-// GetDashboardSummary handles the GET /dashboard/summary endpoint
-// @Summary Get dashboard summary
-// @Description Get summary statistics for the dashboard
-// @Tags dashboard
-// @Accept json
+// GetSpendingByHour handles the GET /insights/spending-by-hour endpoint
+// @Summary Get spending by hour of day
+// @Description Total spend and receipt count for each of the 24 hours of the day, read in the user's time zone preference. Only receipts with a time of purchase are counted
+// @Tags insights
 // @Produce json
 // @Param startDate query string false "Start date filter (YYYY-MM-DD)"
 // @Param endDate query string false "End date filter (YYYY-MM-DD)"
-// @Success 200 {object} model.DashboardSummaryResponse "Dashboard summary"
+// @Param scope query string false "Receipts to include: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Success 200 {object} map[string]interface{} "Time zone and spend per hour"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
 // @Failure 500 {object} model.ErrorResponse "Internal server error"
-// @Router /v1/dashboard/summary [get]
-func (h *ReceiptHandler) GetDashboardSummary(c *gin.Context) {
+// @Router /v1/insights/spending-by-hour [get]
+func (h *ReceiptHandler) GetSpendingByHour(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -418,26 +1308,38 @@ func (h *ReceiptHandler) GetDashboardSummary(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
 	// Parse query parameters
 	startDate, endDate := parseDateRange(c)
+	if _, err := parseDate(c.Query("startDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("startDate", err.Error()))
+		return
+	}
+	if _, err := parseDate(c.Query("endDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("endDate", err.Error()))
+		return
+	}
 
-	// Get dashboard summary
-	summary, err := h.receiptService.GetDashboardSummary(c.Request.Context(), userID.(string), startDate, endDate)
+	spending, err := h.receiptService.GetSpendingByHour(c.Request.Context(), scope, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "500",
-			"message": fmt.Sprintf("Failed to retrieve dashboard summary: %v", err),
-		})
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve spending by hour: %v", err))
 		return
 	}
 
-	// Format response
-	response := formatDashboardSummaryResponse(summary)
-	c.JSON(http.StatusOK, response)
+	respondOK(c, formatHourlySpendingResponse(spending))
 }
 
-// GetSpendingTrends handles the GET /dashboard/spending-trends endpoint
-func (h *ReceiptHandler) GetSpendingTrends(c *gin.Context) {
+// GetSpendingByPaymentMethod handles the GET /insights/spending-by-payment-method endpoint
+func (h *ReceiptHandler) GetSpendingByPaymentMethod(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -445,48 +1347,45 @@ func (h *ReceiptHandler) GetSpendingTrends(c *gin.Context) {
 		return
 	}
 
-	// Parse query parameters
-	period := c.DefaultQuery("period", "monthly")
-	startDate, endDate := parseDateRange(c)
-
-	// Validate period
-	validPeriods := map[string]bool{
-		"daily":   true,
-		"weekly":  true,
-		"monthly": true,
-		"yearly":  true,
-	}
-	if !validPeriods[period] {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "400",
-			"message": "Invalid period parameter",
-			"details": []gin.H{
-				{
-					"field":   "period",
-					"message": "Period must be one of: daily, weekly, monthly, yearly",
-				},
-			},
-		})
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
 		return
 	}
 
-	// Get spending trends
-	trends, err := h.receiptService.GetSpendingTrends(c.Request.Context(), userID.(string), period, startDate, endDate)
+	// Parse query parameters
+	startDate, endDate := parseDateRange(c)
+
+	// Get spending by payment method
+	paymentMethodSpending, err := h.receiptService.GetSpendingByPaymentMethod(c.Request.Context(), scope, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "500",
-			"message": fmt.Sprintf("Failed to retrieve spending trends: %v", err),
-		})
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve payment method spending: %v", err))
 		return
 	}
 
-	// Format response
-	response := formatSpendingTrendsResponse(trends)
-	c.JSON(http.StatusOK, response)
+	respondOK(c, formatPaymentMethodSpendingResponse(paymentMethodSpending))
 }
 
-// GetSpendingByCategory handles the GET /insights/spending-by-category endpoint
-func (h *ReceiptHandler) GetSpendingByCategory(c *gin.Context) {
+// GetSpendingBreakdown handles the GET /insights/breakdown endpoint
+// @Summary Get spending grouped by a dimension
+// @Description Spending grouped by category, merchant or payment method in one uniform shape, with the receipt count and share of total spend of each group, largest first. Groups match the dedicated spending-by-category, merchant-frequency and spending-by-payment-method insights
+// @Tags insights
+// @Produce json
+// @Param by query string true "Grouping dimension: category, merchant or paymentMethod"
+// @Param startDate query string false "Start date filter (YYYY-MM-DD)"
+// @Param endDate query string false "End date filter (YYYY-MM-DD)"
+// @Param scope query string false "Receipts to include: mine or org" default(mine)
+// @Param orgId query string false "Organization ID, required when scope is org"
+// @Success 200 {object} map[string]interface{} "Dimension, total spend and spend per group"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} model.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/insights/breakdown [get]
+func (h *ReceiptHandler) GetSpendingBreakdown(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -494,22 +1393,39 @@ func (h *ReceiptHandler) GetSpendingByCategory(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
 	// Parse query parameters
+	by := c.Query("by")
+	if !slices.Contains(domain.BreakdownDimensions, by) {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("by", "by must be one of: "+strings.Join(domain.BreakdownDimensions, ", ")))
+		return
+	}
 	startDate, endDate := parseDateRange(c)
+	if _, err := parseDate(c.Query("startDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("startDate", err.Error()))
+		return
+	}
+	if _, err := parseDate(c.Query("endDate")); err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("endDate", err.Error()))
+		return
+	}
 
-	// Get spending by category
-	categorySpending, err := h.receiptService.GetSpendingByCategory(c.Request.Context(), userID.(string), startDate, endDate)
+	breakdown, err := h.receiptService.GetSpendingBreakdown(c.Request.Context(), scope, by, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "500",
-			"message": fmt.Sprintf("Failed to retrieve category spending: %v", err),
-		})
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve spending breakdown: %v", err))
 		return
 	}
 
-	// Format response
-	response := formatCategorySpendingResponse(categorySpending)
-	c.JSON(http.StatusOK, response)
+	respondOK(c, formatSpendingBreakdownResponse(breakdown))
 }
 
 // GetMerchantFrequency handles the GET /insights/merchant-frequency endpoint
@@ -521,6 +1437,12 @@ func (h *ReceiptHandler) GetMerchantFrequency(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
 	// Parse query parameters
 	startDate, endDate := parseDateRange(c)
 
@@ -535,8 +1457,12 @@ func (h *ReceiptHandler) GetMerchantFrequency(c *gin.Context) {
 	}
 
 	// Get merchant frequency
-	merchantFrequency, err := h.receiptService.GetMerchantFrequency(c.Request.Context(), userID.(string), startDate, endDate, limit)
+	merchantFrequency, err := h.receiptService.GetMerchantFrequency(c.Request.Context(), scope, startDate, endDate, limit)
 	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "500",
 			"message": fmt.Sprintf("Failed to retrieve merchant frequency: %v", err),
@@ -558,6 +1484,12 @@ func (h *ReceiptHandler) GetMonthlyComparison(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
 	// Parse query parameters
 	month1 := c.Query("month1")
 	month2 := c.Query("month2")
@@ -578,8 +1510,12 @@ func (h *ReceiptHandler) GetMonthlyComparison(c *gin.Context) {
 	}
 
 	// Get monthly comparison
-	comparison, err := h.receiptService.GetMonthlyComparison(c.Request.Context(), userID.(string), month1, month2)
+	comparison, err := h.receiptService.GetMonthlyComparison(c.Request.Context(), scope, month1, month2)
 	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "500",
 			"message": fmt.Sprintf("Failed to retrieve monthly comparison: %v", err),
@@ -592,8 +1528,68 @@ func (h *ReceiptHandler) GetMonthlyComparison(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetSpendingAnomaly handles the GET /insights/anomaly endpoint
+func (h *ReceiptHandler) GetSpendingAnomaly(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	scope, err := parseInsightScope(c, userID.(string))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("scope", err.Error()))
+		return
+	}
+
+	// Validate month format
+	month := c.Query("month")
+	if !isValidMonth(month) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "400",
+			"message": "Invalid month format",
+			"details": []gin.H{
+				{
+					"field":   "month",
+					"message": "Month must be in YYYY-MM format (e.g., 2023-01)",
+				},
+			},
+		})
+		return
+	}
+
+	// Get spending anomaly
+	anomaly, err := h.receiptService.GetSpendingAnomaly(c.Request.Context(), scope, month)
+	if err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			respondForbidden(c, ErrNotOrgMember)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "500",
+			"message": fmt.Sprintf("Failed to retrieve spending anomaly: %v", err),
+		})
+		return
+	}
+
+	// Format response
+	response := formatSpendingAnomalyResponse(anomaly)
+	c.JSON(http.StatusOK, response)
+}
+
 // Helper functions
 
+// receiptBindErrorDetails explains a receipt body that could not be decoded when the cause is
+// known, such as a date in an unrecognized format
+func receiptBindErrorDetails(err error) []model.ErrorDetail {
+	var dateErr *domain.DateParseError
+	if errors.As(err, &dateErr) {
+		return []model.ErrorDetail{newErrorDetail("date", dateErr.Error())}
+	}
+	return nil
+}
+
 // validateReceiptInput validates required fields in a receipt
 func validateReceiptInput(receipt *domain.Receipt) []model.ErrorDetail {
 	var errors []model.ErrorDetail
@@ -644,13 +1640,24 @@ func validateReceiptInput(receipt *domain.Receipt) []model.ErrorDetail {
 	return errors
 }
 
+// maxReceiptListLimit is the largest page size returned by the receipts listing
+const maxReceiptListLimit = 100
+
+// receiptListingParams are the listing query parameters a saved view can store
+var receiptListingParams = []string{"startDate", "endDate", "merchant", "category", "needsReview", "hasImage", "sortBy", "sortOrder", "limit"}
+
 // parseReceiptFilter extracts filtering parameters from request
 func parseReceiptFilter(c *gin.Context) (domain.ReceiptFilter, error) {
+	return parseReceiptFilterValues(c.Request.URL.Query())
+}
+
+// parseReceiptFilterValues extracts filtering parameters from query values
+func parseReceiptFilterValues(query url.Values) (domain.ReceiptFilter, error) {
 	filter := domain.ReceiptFilter{}
 
 	// Parse pagination parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
+	pageStr := queryValue(query, "page", "1")
+	limitStr := queryValue(query, "limit", "10")
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
@@ -662,13 +1669,14 @@ func parseReceiptFilter(c *gin.Context) (domain.ReceiptFilter, error) {
 	if err != nil || limit < 1 {
 		return filter, fmt.Errorf("invalid limit")
 	}
-	if limit > 100 {
-		limit = 100
+	if limit > maxReceiptListLimit {
+		filter.RequestedLimit = limit
+		limit = maxReceiptListLimit
 	}
 	filter.Limit = limit
 
 	// Parse date range
-	startDateStr := c.Query("startDate")
+	startDateStr := query.Get("startDate")
 	if startDateStr != "" {
 		startDate, err := time.Parse("2006-01-02", startDateStr)
 		if err != nil {
@@ -677,7 +1685,7 @@ func parseReceiptFilter(c *gin.Context) (domain.ReceiptFilter, error) {
 		filter.StartDate = &startDate
 	}
 
-	endDateStr := c.Query("endDate")
+	endDateStr := query.Get("endDate")
 	if endDateStr != "" {
 		endDate, err := time.Parse("2006-01-02", endDateStr)
 		if err != nil {
@@ -686,12 +1694,64 @@ func parseReceiptFilter(c *gin.Context) (domain.ReceiptFilter, error) {
 		filter.EndDate = &endDate
 	}
 
-	// Parse merchant filter
-	filter.Merchant = c.Query("merchant")
+	// Parse merchant and category filters
+	filter.Merchant = query.Get("merchant")
+	filter.Category = query.Get("category")
+	filter.TaxID = domain.NormalizeTaxID(query.Get("taxId"))
+
+	// Parse review filter
+	if needsReviewStr := query.Get("needsReview"); needsReviewStr != "" {
+		needsReview, err := strconv.ParseBool(needsReviewStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid needsReview value (use true or false)")
+		}
+		filter.NeedsReview = needsReview
+	}
+
+	// Parse image filter
+	if hasImageStr := query.Get("hasImage"); hasImageStr != "" {
+		hasImage, err := strconv.ParseBool(hasImageStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid hasImage value (use true or false)")
+		}
+		filter.HasImage = &hasImage
+	}
+
+	// Parse sync filter
+	defaultSortBy, defaultSortOrder := "date", "desc"
+	if modifiedSinceStr := query.Get("modifiedSince"); modifiedSinceStr != "" {
+		modifiedSince, err := time.Parse(time.RFC3339, modifiedSinceStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid modifiedSince format (use RFC3339, e.g. 2024-01-02T15:04:05Z)")
+		}
+		filter.ModifiedSince = &modifiedSince
+		// Changes are listed in the order they happened
+		defaultSortBy, defaultSortOrder = "updatedAt", "asc"
+	}
+
+	// Parse sort
+	filter.SortBy = queryValue(query, "sortBy", defaultSortBy)
+	switch filter.SortBy {
+	case "date", "total", "merchant", "createdAt", "updatedAt":
+	default:
+		return filter, fmt.Errorf("invalid sortBy value (use date, total, merchant, createdAt or updatedAt)")
+	}
+	filter.SortOrder = queryValue(query, "sortOrder", defaultSortOrder)
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return filter, fmt.Errorf("invalid sortOrder value (use asc or desc)")
+	}
 
 	return filter, nil
 }
 
+// queryValue returns the query parameter or a default when it is missing or empty
+func queryValue(query url.Values, key, defaultValue string) string {
+	if value := query.Get(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
 // parseDateRange extracts date range parameters from request
 func parseDateRange(c *gin.Context) (*string, *string) {
 	startDateStr := c.Query("startDate")
@@ -708,6 +1768,46 @@ func parseDateRange(c *gin.Context) (*string, *string) {
 	return startDate, endDate
 }
 
+// parseReceiptScope reads the scope query parameter: "mine" (default) for the user's own receipts,
+// or "org" together with orgId for receipts shared with an organization
+func parseReceiptScope(c *gin.Context, userID string) (domain.ReceiptScope, error) {
+	scope := domain.ReceiptScope{UserID: userID}
+
+	switch c.DefaultQuery("scope", "mine") {
+	case "mine":
+		return scope, nil
+	case "org":
+		orgID := strings.TrimSpace(c.Query("orgId"))
+		if orgID == "" {
+			return scope, fmt.Errorf("orgId is required when scope is org")
+		}
+		scope.OrgID = orgID
+		return scope, nil
+	default:
+		return scope, fmt.Errorf("scope must be one of: mine, org")
+	}
+}
+
+// parseInsightScope reads the scope like parseReceiptScope, plus the comma-separated
+// excludeCategories and excludeMerchants parameters that insights leave out and whether
+// normalizeMerchants groups merchant spellings together (default true)
+func parseInsightScope(c *gin.Context, userID string) (domain.ReceiptScope, error) {
+	scope, err := parseReceiptScope(c, userID)
+	if err != nil {
+		return scope, err
+	}
+	scope.ExcludeCategories = getQueryList(c, "excludeCategories")
+	scope.ExcludeMerchants = getQueryList(c, "excludeMerchants")
+	if value := c.Query("normalizeMerchants"); value != "" {
+		normalize, err := strconv.ParseBool(value)
+		if err != nil {
+			return scope, fmt.Errorf("normalizeMerchants must be true or false")
+		}
+		scope.RawMerchants = !normalize
+	}
+	return scope, nil
+}
+
 // isValidMonth checks if a string is in the format YYYY-MM
 func isValidMonth(month string) bool {
 	_, err := time.Parse("2006-01", month)
@@ -716,17 +1816,105 @@ func isValidMonth(month string) bool {
 
 // formatReceiptResponse formats a receipt for response
 func formatReceiptResponse(receipt *domain.Receipt) gin.H {
-	return gin.H{
-		"id":        receipt.ID,
-		"merchant":  receipt.Merchant,
-		"date":      receipt.Date.Format("2006-01-02"),
-		"total":     fmt.Sprintf("%.2f", receipt.Total),
-		"tax":       fmt.Sprintf("%.2f", receipt.Tax),
-		"subtotal":  fmt.Sprintf("%.2f", receipt.Subtotal),
-		"items":     formatReceiptItemsResponse(receipt.Items),
-		"createdAt": receipt.CreatedAt.Format(time.RFC3339),
-		"updatedAt": receipt.UpdatedAt.Format(time.RFC3339),
+	response := gin.H{
+		"id":            receipt.ID,
+		"merchant":      receipt.Merchant,
+		"date":          receipt.Date.Format("2006-01-02"),
+		"dateTime":      formatReceiptDateTime(receipt),
+		"total":         fmt.Sprintf("%.2f", receipt.Total),
+		"tax":           fmt.Sprintf("%.2f", receipt.Tax),
+		"subtotal":      fmt.Sprintf("%.2f", receipt.Subtotal),
+		"tip":           fmt.Sprintf("%.2f", receipt.Tip),
+		"serviceCharge": fmt.Sprintf("%.2f", receipt.ServiceCharge),
+		"items":         formatReceiptItemsResponse(receipt.Items),
+		"sourceUrl":     receipt.SourceURL,
+		"imageUrl":      receipt.ReceiptURL,
+		"status":        receipt.Status,
+		"createdAt":     receipt.CreatedAt.Format(time.RFC3339),
+		"updatedAt":     receipt.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if receipt.Confidence != nil {
+		response["confidence"] = *receipt.Confidence
+	}
+	if len(receipt.Warnings) > 0 {
+		response["warnings"] = receipt.Warnings
+	}
+	if receipt.OrgID != "" {
+		response["orgId"] = receipt.OrgID
+	}
+	if receipt.PaymentMethod != "" {
+		response["paymentMethod"] = receipt.PaymentMethod
+	}
+	if receipt.Category != "" {
+		response["category"] = receipt.Category
+	}
+	if receipt.TaxID != "" {
+		response["taxId"] = receipt.TaxID
 	}
+	if receipt.ExtractionMethod != "" {
+		response["extractionMethod"] = receipt.ExtractionMethod
+	}
+	if len(receipt.Alternatives) > 0 {
+		response["alternatives"] = receipt.Alternatives
+	}
+	if receipt.Extraction != nil && receipt.Extraction.Partial {
+		response["partial"] = true
+	}
+
+	return response
+}
+
+// addFormattedAmounts adds the receipt's amounts written in the user's number format, under
+// formatted and as each item's formattedPrice, leaving the numeric amount fields unchanged.
+// Nothing is added without a format.
+func addFormattedAmounts(response gin.H, receipt *domain.Receipt, format *domain.NumberFormat) {
+	if format == nil {
+		return
+	}
+
+	response["numberLocale"] = format.Locale
+	response["formatted"] = gin.H{
+		"total":         format.FormatAmount(receipt.Total),
+		"tax":           format.FormatAmount(receipt.Tax),
+		"subtotal":      format.FormatAmount(receipt.Subtotal),
+		"tip":           format.FormatAmount(receipt.Tip),
+		"serviceCharge": format.FormatAmount(receipt.ServiceCharge),
+	}
+	if items, ok := response["items"].([]gin.H); ok {
+		for i, item := range receipt.Items {
+			items[i]["formattedPrice"] = format.FormatAmount(item.Price)
+			if item.ConvertedPrice != nil {
+				items[i]["formattedConvertedPrice"] = format.FormatAmount(*item.ConvertedPrice)
+			}
+		}
+	}
+}
+
+// formatReceiptDateTime formats the time of purchase, or midnight UTC of the receipt date when
+// the receipt shows no time
+func formatReceiptDateTime(receipt *domain.Receipt) string {
+	if purchased := receipt.PurchaseTime(); purchased != nil {
+		return purchased.Format(time.RFC3339)
+	}
+	date := receipt.Date.Time
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+}
+
+// formatPaginationResponse formats listing pagination for response
+func formatPaginationResponse(page domain.Pagination, requestedLimit int) gin.H {
+	pagination := gin.H{
+		"totalItems":  page.TotalItems,
+		"totalPages":  page.TotalPages,
+		"currentPage": page.CurrentPage,
+		"limit":       page.Limit,
+	}
+	// Tell the client its page size was reduced so it doesn't mistake a short page for the end
+	if requestedLimit > 0 {
+		pagination["requestedLimit"] = requestedLimit
+		pagination["clamped"] = true
+	}
+	return pagination
 }
 
 // formatReceiptsResponse formats a slice of receipts for response
@@ -750,10 +1938,37 @@ func formatReceiptItemsResponse(items []domain.ReceiptItem) []gin.H {
 			"currency": item.Currency,
 			"category": item.Category,
 		}
+		if item.ConvertedPrice != nil {
+			formatted[i]["convertedPrice"] = fmt.Sprintf("%.2f", *item.ConvertedPrice)
+			formatted[i]["convertedCurrency"] = item.ConvertedCurrency
+		}
 	}
 	return formatted
 }
 
+// formatReceiptStatsResponse formats a receipt's statistics for response
+func formatReceiptStatsResponse(stats *domain.ReceiptStats) gin.H {
+	categories := make([]gin.H, len(stats.Categories))
+	for i, category := range stats.Categories {
+		categories[i] = gin.H{
+			"category":  category.Category,
+			"subtotal":  fmt.Sprintf("%.2f", category.Subtotal),
+			"itemCount": category.ItemCount,
+		}
+	}
+
+	return gin.H{
+		"receiptId":          stats.ReceiptID,
+		"itemCount":          stats.ItemCount,
+		"distinctCategories": len(stats.Categories),
+		"subtotal":           fmt.Sprintf("%.2f", stats.Subtotal),
+		"categories":         categories,
+		"averageItemPrice":   fmt.Sprintf("%.2f", stats.AverageItemPrice),
+		"maxItemPrice":       fmt.Sprintf("%.2f", stats.MaxItemPrice),
+		"taxRatio":           math.Round(stats.TaxRatio*10000) / 10000,
+	}
+}
+
 // formatDashboardSummaryResponse formats dashboard summary for response
 func formatDashboardSummaryResponse(summary *domain.DashboardSummary) gin.H {
 	topCategories := make([]gin.H, len(summary.TopCategories))
@@ -806,9 +2021,10 @@ func formatCategorySpendingResponse(spending *domain.CategorySpending) gin.H {
 		items := make([]gin.H, len(category.Items))
 		for j, item := range category.Items {
 			items[j] = gin.H{
-				"name":       item.Name,
-				"totalSpent": fmt.Sprintf("%.2f", item.TotalSpent),
-				"count":      item.Count,
+				"name":          item.Name,
+				"totalSpent":    fmt.Sprintf("%.2f", item.TotalSpent),
+				"count":         item.Count,
+				"totalQuantity": item.TotalQuantity,
 			}
 		}
 
@@ -826,16 +2042,72 @@ func formatCategorySpendingResponse(spending *domain.CategorySpending) gin.H {
 	}
 }
 
+// formatPaymentMethodSpendingResponse formats payment method spending for response
+func formatPaymentMethodSpendingResponse(spending *domain.PaymentMethodSpending) gin.H {
+	methods := make([]gin.H, len(spending.Methods))
+	for i, method := range spending.Methods {
+		methods[i] = gin.H{
+			"method":     method.Method,
+			"amount":     fmt.Sprintf("%.2f", method.Amount),
+			"count":      method.Count,
+			"percentage": method.Percentage,
+		}
+	}
+
+	return gin.H{
+		"total":   fmt.Sprintf("%.2f", spending.Total),
+		"methods": methods,
+	}
+}
+
+// formatSpendingBreakdownResponse formats a spending breakdown for response
+func formatSpendingBreakdownResponse(breakdown *domain.SpendingBreakdown) gin.H {
+	groups := make([]gin.H, len(breakdown.Groups))
+	for i, group := range breakdown.Groups {
+		groups[i] = gin.H{
+			"name":       group.Name,
+			"amount":     fmt.Sprintf("%.2f", group.Amount),
+			"count":      group.Count,
+			"percentage": group.Percentage,
+		}
+	}
+
+	return gin.H{
+		"by":     breakdown.By,
+		"total":  fmt.Sprintf("%.2f", breakdown.Total),
+		"groups": groups,
+	}
+}
+
+// formatHourlySpendingResponse formats spending by hour of day for response
+func formatHourlySpendingResponse(spending *domain.HourlySpending) gin.H {
+	hours := make([]gin.H, len(spending.Hours))
+	for i, hour := range spending.Hours {
+		hours[i] = gin.H{
+			"hour":  hour.Hour,
+			"total": fmt.Sprintf("%.2f", hour.Total),
+			"count": hour.Count,
+		}
+	}
+
+	return gin.H{
+		"timezone": spending.Timezone,
+		"hours":    hours,
+	}
+}
+
 // formatMerchantFrequencyResponse formats merchant frequency for response
 func formatMerchantFrequencyResponse(frequency *domain.MerchantFrequency) gin.H {
 	merchants := make([]gin.H, len(frequency.Merchants))
 	for i, merchant := range frequency.Merchants {
 		merchants[i] = gin.H{
-			"name":         merchant.Name,
-			"visits":       merchant.Visits,
-			"totalSpent":   fmt.Sprintf("%.2f", merchant.TotalSpent),
-			"averageSpent": fmt.Sprintf("%.2f", merchant.AverageSpent),
-			"percentage":   merchant.Percentage,
+			"name":              merchant.Name,
+			"visits":            merchant.Visits,
+			"totalSpent":        fmt.Sprintf("%.2f", merchant.TotalSpent),
+			"averageSpent":      fmt.Sprintf("%.2f", merchant.AverageSpent),
+			"percentage":        merchant.Percentage,
+			"averageItems":      math.Round(merchant.AverageItems*100) / 100,
+			"averageCategories": math.Round(merchant.AverageCategories*100) / 100,
 		}
 	}
 
@@ -869,6 +2141,39 @@ func formatMonthlyComparisonResponse(comparison *domain.MonthlyComparison) gin.H
 	}
 }
 
+// formatAnomalyMetricResponse formats an anomaly metric for response
+func formatAnomalyMetricResponse(metric domain.AnomalyMetric) gin.H {
+	return gin.H{
+		"amount":    fmt.Sprintf("%.2f", metric.Amount),
+		"baseline":  fmt.Sprintf("%.2f", metric.Baseline),
+		"stdDev":    fmt.Sprintf("%.2f", metric.StdDev),
+		"zScore":    math.Round(metric.ZScore*100) / 100,
+		"isAnomaly": metric.IsAnomaly,
+	}
+}
+
+// formatSpendingAnomalyResponse formats spending anomaly for response
+func formatSpendingAnomalyResponse(anomaly *domain.SpendingAnomaly) gin.H {
+	categories := make([]gin.H, len(anomaly.Categories))
+	for i, category := range anomaly.Categories {
+		categories[i] = formatAnomalyMetricResponse(category.AnomalyMetric)
+		categories[i]["name"] = category.Name
+	}
+
+	return gin.H{
+		"month":          anomaly.Month,
+		"baselineMonths": anomaly.BaselineMonths,
+		"threshold":      anomaly.Threshold,
+		"total":          formatAnomalyMetricResponse(anomaly.Total),
+		"categories":     categories,
+	}
+}
+
+// ScanRoutes returns the routes registered by RegisterRoutes that scan a receipt within the request
+func (h *ReceiptHandler) ScanRoutes() []string {
+	return []string{"/v1/receipts/scan", "/v1/receipts/scan/url", "/v1/receipts/:receiptId/retry-scan"}
+}
+
 // RegisterRoutes registers the API routes for the receipt handler
 func (h *ReceiptHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
 	// Create API group with base path
@@ -878,15 +2183,30 @@ func (h *ReceiptHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.H
 	receipts := api.Group("/receipts", authMiddleware)
 	{
 		receipts.POST("/scan", h.ScanReceipt)
+		receipts.POST("/scan/url", h.ScanReceiptFromURL)
 		receipts.POST("", h.CreateReceipt)
 		receipts.GET("", h.GetReceipts)
+		receipts.GET("/count", h.CountReceipts)
+		receipts.POST("/bulk-delete", h.BulkDeleteReceipts)
+		receipts.GET("/currencies", h.GetReceiptCurrencies)
+		receipts.GET("/views", h.GetReceiptViews)
+		receipts.POST("/views", h.CreateReceiptView)
+		receipts.DELETE("/views/:viewId", h.DeleteReceiptView)
 		receipts.GET("/:receiptId", h.GetReceiptByID)
 		receipts.PUT("/:receiptId", h.UpdateReceipt)
 		receipts.DELETE("/:receiptId", h.DeleteReceipt)
 		receipts.POST("/:receiptId/retry-scan", h.RetryScanReceipt)
 		receipts.GET("/:receiptId/items", h.GetReceiptItems)
+		receipts.GET("/:receiptId/stats", h.GetReceiptStats)
+		receipts.GET("/:receiptId/pdf", h.ExportReceiptPDF)
+		receipts.PUT("/:receiptId/organization", h.SetReceiptOrganization)
+		receipts.POST("/:receiptId/merge", h.MergeReceipts)
+		receipts.PUT("/:receiptId/image", h.ReplaceReceiptImage)
 	}
 
+	// Activity feed of the user's receipt actions
+	api.GET("/activity", authMiddleware, h.GetActivity)
+
 	// Dashboard endpoints - all protected with auth
 	dashboard := api.Group("/dashboard", authMiddleware)
 	{
@@ -900,5 +2220,10 @@ func (h *ReceiptHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.H
 		insights.GET("/spending-by-category", h.GetSpendingByCategory)
 		insights.GET("/merchant-frequency", h.GetMerchantFrequency)
 		insights.GET("/monthly-comparison", h.GetMonthlyComparison)
+		insights.GET("/spending-by-payment-method", h.GetSpendingByPaymentMethod)
+		insights.GET("/anomaly", h.GetSpendingAnomaly)
+		insights.GET("/category-trend", h.GetCategoryTrend)
+		insights.GET("/spending-by-hour", h.GetSpendingByHour)
+		insights.GET("/breakdown", h.GetSpendingBreakdown)
 	}
 }
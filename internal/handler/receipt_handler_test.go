@@ -0,0 +1,1077 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
+	"github.com/ridwanfathin/invoice-processor-service/internal/model"
+	"github.com/ridwanfathin/invoice-processor-service/internal/openrouter"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// stubReceiptService implements the receipt service methods exercised by handler tests
+type stubReceiptService struct {
+	service.ReceiptService
+	fetcher    *imageutil.Fetcher
+	views      map[string]*domain.ReceiptView
+	lastFilter *domain.ReceiptFilter
+	lastScope  *domain.ReceiptScope
+	created    map[string]*domain.Receipt
+	receipts   []domain.Receipt
+}
+
+func (s *stubReceiptService) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
+	s.lastFilter = &filter
+	result := &domain.PaginatedReceipts{Data: []domain.Receipt{}}
+	result.Pagination.Limit = filter.Limit
+	for _, receipt := range s.matchingReceipts(filter) {
+		if len(result.Data) < filter.Limit {
+			result.Data = append(result.Data, receipt)
+		}
+		result.Pagination.TotalItems++
+	}
+	return result, nil
+}
+
+func (s *stubReceiptService) CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error) {
+	s.lastFilter = &filter
+	return len(s.matchingReceipts(filter)), nil
+}
+
+func (s *stubReceiptService) ListReceiptCurrencies(ctx context.Context, userID string) ([]domain.CurrencyUsage, error) {
+	currencies := []domain.CurrencyUsage{}
+	index := map[string]int{}
+	for _, receipt := range s.matchingReceipts(domain.ReceiptFilter{UserID: userID}) {
+		seen := map[string]bool{}
+		for _, item := range receipt.Items {
+			i, ok := index[item.Currency]
+			if !ok {
+				i = len(currencies)
+				index[item.Currency] = i
+				currencies = append(currencies, domain.CurrencyUsage{Currency: item.Currency})
+			}
+			currencies[i].ItemCount++
+			if !seen[item.Currency] {
+				seen[item.Currency] = true
+				currencies[i].ReceiptCount++
+			}
+		}
+	}
+	return currencies, nil
+}
+
+// matchingReceipts applies the owner, merchant, image, modification and category filters like the Postgres repository
+func (s *stubReceiptService) matchingReceipts(filter domain.ReceiptFilter) []domain.Receipt {
+	var matches []domain.Receipt
+	for _, receipt := range s.receipts {
+		if receipt.UserID != filter.UserID && !(filter.AllUsers && filter.UserID == "") {
+			continue
+		}
+		if filter.Merchant != "" && !strings.Contains(strings.ToLower(receipt.Merchant), strings.ToLower(filter.Merchant)) {
+			continue
+		}
+		if filter.HasImage != nil && *filter.HasImage != (receipt.ReceiptURL != "" || receipt.ImageURL != "") {
+			continue
+		}
+		if filter.ModifiedSince != nil && !receipt.UpdatedAt.After(*filter.ModifiedSince) {
+			continue
+		}
+		if filter.Category != "" {
+			found := false
+			for _, item := range receipt.Items {
+				found = found || strings.EqualFold(item.Category, filter.Category)
+			}
+			if !found {
+				continue
+			}
+		}
+		matches = append(matches, receipt)
+	}
+	return matches
+}
+
+func (s *stubReceiptService) UserNumberFormat(ctx context.Context, userID string) *domain.NumberFormat {
+	return nil
+}
+
+func (s *stubReceiptService) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	if s.created == nil {
+		s.created = map[string]*domain.Receipt{}
+	}
+	stored := *receipt
+	stored.ID = fmt.Sprintf("receipt-%d", len(s.created)+1)
+	s.created[stored.ID] = &stored
+	return &stored, nil
+}
+
+func (s *stubReceiptService) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	receipt, ok := s.created[receiptID]
+	if !ok {
+		return nil, fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	return receipt, nil
+}
+
+// UpdateReceipt applies the If-Unmodified-Since precondition like the Postgres repository
+// and advances updatedAt by a second per write
+func (s *stubReceiptService) UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	stored, ok := s.created[receipt.ID]
+	if !ok {
+		return nil, fmt.Errorf("receipt not found: %s", receipt.ID)
+	}
+	if receipt.UnmodifiedSince != nil && stored.UpdatedAt.Truncate(time.Second).After(*receipt.UnmodifiedSince) {
+		return nil, &service.ReceiptServiceError{Op: "update_receipt", Err: domain.ErrReceiptModified}
+	}
+	updated := *receipt
+	updated.UpdatedAt = stored.UpdatedAt.Add(time.Second)
+	s.created[receipt.ID] = &updated
+	return &updated, nil
+}
+
+func (s *stubReceiptService) SaveReceiptView(ctx context.Context, userID, name string, filters map[string]string) (*domain.ReceiptView, error) {
+	if s.views == nil {
+		s.views = map[string]*domain.ReceiptView{}
+	}
+	view := &domain.ReceiptView{ID: "view-" + name, UserID: userID, Name: name, Filters: filters}
+	s.views[name] = view
+	return view, nil
+}
+
+func (s *stubReceiptService) GetReceiptView(ctx context.Context, userID, name string) (*domain.ReceiptView, error) {
+	view, ok := s.views[name]
+	if !ok || view.UserID != userID {
+		return nil, &service.ReceiptServiceError{Op: "get_receipt_view", Err: fmt.Errorf("receipt view not found: %s", name)}
+	}
+	return view, nil
+}
+
+func (s *stubReceiptService) GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.CategorySpending, error) {
+	s.lastScope = &scope
+	return &domain.CategorySpending{
+		Total: 18,
+		Categories: []domain.CategorySpendingItem{{
+			Name:       "Food",
+			Amount:     18,
+			Percentage: 100,
+			// Bought 12 units across 3 receipts
+			Items: []domain.CategorySpendingItemDetail{{Name: "Yogurt", TotalSpent: 18, Count: 3, TotalQuantity: 12}},
+		}},
+	}, nil
+}
+
+func (s *stubReceiptService) GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDate, endDate *string) (*domain.SpendingBreakdown, error) {
+	s.lastScope = &scope
+	return &domain.SpendingBreakdown{
+		By:     by,
+		Total:  18,
+		Groups: []domain.SpendingBreakdownGroup{{Name: "Food", Amount: 18, Count: 3, Percentage: 100}},
+	}, nil
+}
+
+func (s *stubReceiptService) GetCategoryTrend(ctx context.Context, scope domain.ReceiptScope, category, period string, startDate, endDate *string) (*domain.SpendingTrends, error) {
+	s.lastScope = &scope
+	return &domain.SpendingTrends{
+		Period: period,
+		Data:   []domain.SpendingTrendDataItem{{Date: "2025-05", Amount: 42.5}},
+	}, nil
+}
+
+func (s *stubReceiptService) ScanReceiptFromURL(ctx context.Context, imageURL string, userID string, storeImage bool) (*domain.Receipt, error) {
+	// A nil fetcher accepts every URL so the success path can be exercised without network access
+	if s.fetcher != nil {
+		if _, err := s.fetcher.Fetch(ctx, imageURL); err != nil {
+			return nil, &service.ReceiptServiceError{Op: "fetch_image_url", Err: err}
+		}
+	}
+	return &domain.Receipt{
+		ID:        "receipt-1",
+		UserID:    userID,
+		Merchant:  "Starbucks",
+		Date:      domain.FlexibleDate{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		Total:     10,
+		SourceURL: imageURL,
+		Items:     []domain.ReceiptItem{},
+	}, nil
+}
+
+// newTestRouter registers the receipt routes behind a stub auth middleware
+func newTestRouter(receiptService service.ReceiptService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	NewReceiptHandler(receiptService).RegisterRoutes(router, auth)
+	return router
+}
+
+func TestScanReceiptFromURL(t *testing.T) {
+	imageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("png-bytes"))
+	}))
+	defer imageServer.Close()
+
+	router := newTestRouter(&stubReceiptService{fetcher: imageutil.NewFetcher(nil)})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "blocked private IP URL",
+			body:       `{"imageUrl":"http://10.1.2.3/receipt.png"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "not allowed",
+		},
+		{
+			name:       "blocked loopback test server",
+			body:       `{"imageUrl":"` + imageServer.URL + `/receipt.png"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "not allowed",
+		},
+		{
+			name:       "missing URL",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "imageUrl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan/url", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestScanReceiptFromURLValid(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan/url", strings.NewReader(`{"imageUrl":"https://cdn.example.com/receipt.png"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"sourceUrl":"https://cdn.example.com/receipt.png"`)
+	assert.Contains(t, rec.Body.String(), `"merchant":"Starbucks"`)
+}
+
+func TestScanReceiptMissingAPIKey(t *testing.T) {
+	// Storage is configured but the OpenRouter API key is not
+	client := openrouter.NewClient(&openrouter.Config{
+		S3Endpoint:        "https://storage.example.com",
+		S3AccessKeyID:     "key-id",
+		S3AccessKeySecret: "key-secret",
+		SupabaseBucket:    "invoices",
+		S3Region:          "us-east-1",
+	})
+	router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{OpenAIClient: client, MaxScanWorkers: 1}))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("receiptImage", "receipt.png")
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte("png-bytes"))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrScanNotConfigured)
+	assert.NotContains(t, rec.Body.String(), "OPENROUTER_API_KEY")
+}
+
+func TestGetReceiptsScope(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{name: "org scope without orgId", query: "?scope=org", wantBody: "orgId is required"},
+		{name: "unknown scope", query: "?scope=everyone", wantBody: "scope must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/receipts"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestGetReceiptsWithSavedView(t *testing.T) {
+	receiptService := &stubReceiptService{}
+	router := newTestRouter(receiptService)
+
+	body := `{"name":"groceries","filters":{"startDate":"2024-01-01","category":"Groceries"}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/views", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	t.Run("view filters apply", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/receipts?view=groceries", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		filter := receiptService.lastFilter
+		if assert.NotNil(t, filter) && assert.NotNil(t, filter.StartDate) {
+			assert.Equal(t, "2024-01-01", filter.StartDate.Format("2006-01-02"))
+		}
+		assert.Equal(t, "Groceries", filter.Category)
+		assert.Equal(t, "user-1", filter.UserID)
+	})
+
+	t.Run("query parameters override view", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/receipts?view=groceries&category=Dining", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Dining", receiptService.lastFilter.Category)
+		assert.Equal(t, "2024-01-01", receiptService.lastFilter.StartDate.Format("2006-01-02"))
+	})
+
+	t.Run("unknown view", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/receipts?view=missing", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("unsupported filter is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/receipts/views", strings.NewReader(`{"name":"bad","filters":{"userId":"someone"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Unsupported filter")
+	})
+}
+
+func TestCreateReceiptLocation(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	body := `{"merchant":"Corner Cafe","date":"2024-03-01","total":13.75,"items":[{"name":"Latte","qty":1,"price":13.75,"currency":"USD"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	location := rec.Header().Get("Location")
+	assert.Equal(t, "/v1/receipts/receipt-1", location)
+
+	// The Location must be fetchable
+	req = httptest.NewRequest(http.MethodGet, location, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Corner Cafe")
+}
+
+func TestCreateReceiptLocationFollowsBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Group("/api/v2").POST("/receipts", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		NewReceiptHandler(&stubReceiptService{}).CreateReceipt(c)
+	})
+
+	body := `{"merchant":"Corner Cafe","date":"2024-03-01","total":13.75,"items":[{"name":"Latte","qty":1,"price":13.75,"currency":"USD"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/receipts", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/api/v2/receipts/receipt-1", rec.Header().Get("Location"))
+}
+
+func TestUpdateReceiptConcurrentEdit(t *testing.T) {
+	receiptService := &stubReceiptService{created: map[string]*domain.Receipt{
+		"receipt-1": {ID: "receipt-1", UserID: "user-1", Merchant: "Corner Cafe", UpdatedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+	}}
+	router := newTestRouter(receiptService)
+
+	// Both clients read the receipt before either writes
+	req := httptest.NewRequest(http.MethodGet, "/v1/receipts/receipt-1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	lastModified := rec.Header().Get("Last-Modified")
+	assert.Equal(t, "Fri, 01 Mar 2024 09:00:00 GMT", lastModified)
+
+	update := func(merchant, ifUnmodifiedSince string) *httptest.ResponseRecorder {
+		body := `{"merchant":"` + merchant + `","date":"2024-03-01","total":5,"items":[{"name":"Latte","qty":1,"price":5,"currency":"USD"}]}`
+		req := httptest.NewRequest(http.MethodPut, "/v1/receipts/receipt-1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifUnmodifiedSince != "" {
+			req.Header.Set("If-Unmodified-Since", ifUnmodifiedSince)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := update("First Client", lastModified)
+	assert.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Equal(t, "Fri, 01 Mar 2024 09:00:01 GMT", first.Header().Get("Last-Modified"))
+
+	second := update("Second Client", lastModified)
+	assert.Equal(t, http.StatusConflict, second.Code)
+	assert.Contains(t, second.Body.String(), ErrReceiptModified)
+	assert.Equal(t, "First Client", receiptService.created["receipt-1"].Merchant)
+
+	t.Run("invalid header", func(t *testing.T) {
+		rec := update("Third Client", "yesterday")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unconditional update still overwrites", func(t *testing.T) {
+		rec := update("Third Client", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestCountReceiptsMatchesListing(t *testing.T) {
+	food := []domain.ReceiptItem{{Name: "Bagel", Category: "Food"}}
+	drinks := []domain.ReceiptItem{{Name: "Latte", Category: "Beverages"}}
+	receiptService := &stubReceiptService{receipts: []domain.Receipt{
+		{ID: "1", UserID: "user-1", Merchant: "Corner Cafe", Items: food},
+		{ID: "2", UserID: "user-1", Merchant: "Corner Cafe", Items: drinks},
+		{ID: "3", UserID: "user-1", Merchant: "Cafe Nero", Items: food},
+		{ID: "4", UserID: "user-1", Merchant: "Hardware Store"},
+		{ID: "5", UserID: "user-2", Merchant: "Corner Cafe", Items: food},
+	}}
+	router := newTestRouter(receiptService)
+
+	for _, query := range []string{"", "?merchant=cafe", "?merchant=cafe&category=food", "?category=Beverages&limit=1"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/receipts"+query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var listing struct {
+				Pagination struct {
+					TotalItems int `json:"totalItems"`
+				} `json:"pagination"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+
+			req = httptest.NewRequest(http.MethodGet, "/v1/receipts/count"+query, nil)
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var count struct {
+				Count int `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &count))
+
+			assert.Equal(t, listing.Pagination.TotalItems, count.Count)
+		})
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/receipts/count?startDate=yesterday", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetReceiptsReportsClampedLimit(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	tests := []struct {
+		name          string
+		limit         string
+		wantLimit     float64
+		wantClamped   bool
+		wantRequested float64
+	}{
+		{name: "over the maximum", limit: "5000", wantLimit: 100, wantClamped: true, wantRequested: 5000},
+		{name: "at the maximum", limit: "100", wantLimit: 100},
+		{name: "default", limit: "", wantLimit: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/receipts?limit="+tt.limit, nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var body struct {
+				Pagination map[string]interface{} `json:"pagination"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			assert.Equal(t, tt.wantLimit, body.Pagination["limit"])
+			if tt.wantClamped {
+				assert.Equal(t, true, body.Pagination["clamped"])
+				assert.Equal(t, tt.wantRequested, body.Pagination["requestedLimit"])
+			} else {
+				assert.NotContains(t, body.Pagination, "clamped")
+				assert.NotContains(t, body.Pagination, "requestedLimit")
+			}
+		})
+	}
+}
+
+// singleReceiptRepository serves one stored receipt
+type singleReceiptRepository struct {
+	repository.ReceiptRepository
+	receipt domain.Receipt
+}
+
+func (r *singleReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	if receiptID != r.receipt.ID {
+		return nil, fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	receipt := r.receipt
+	return &receipt, nil
+}
+
+// datedRateConverter converts to EUR at a rate published for a single date
+type datedRateConverter struct {
+	date time.Time
+	rate float64
+}
+
+func (c *datedRateConverter) ConvertOn(ctx context.Context, amount float64, fromCurrency, toCurrency string, date time.Time) (*currency.Conversion, error) {
+	if toCurrency != "EUR" || !date.Equal(c.date) {
+		return nil, fmt.Errorf("exchange rate not found for %s to %s", fromCurrency, toCurrency)
+	}
+	return &currency.Conversion{Amount: amount * c.rate, Rate: c.rate, RateBase: fromCurrency, RateDate: date.Format("2006-01-02")}, nil
+}
+
+func TestGetReceiptConvertedPrices(t *testing.T) {
+	receiptDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &singleReceiptRepository{receipt: domain.Receipt{
+		ID:       "receipt-1",
+		UserID:   "user-1",
+		Merchant: "Corner Cafe",
+		Date:     domain.FlexibleDate{Time: receiptDate},
+		Total:    13.75,
+		Items: []domain.ReceiptItem{
+			{ID: "item-1", Name: "Latte", Quantity: 1, Price: 13.75, Currency: "USD"},
+			{ID: "item-2", Name: "Napkin", Quantity: 1, Price: 0},
+		},
+	}}
+	router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{
+		Repository:        repo,
+		CurrencyConverter: &datedRateConverter{date: receiptDate, rate: 0.9},
+	}))
+
+	getItems := func(query string) (int, []map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/receipts/receipt-1"+query, nil))
+
+		var body struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body.Items
+	}
+
+	code, items := getItems("?convertTo=eur")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, items, 2)
+	assert.Equal(t, "13.75", items[0]["price"])
+	assert.Equal(t, "USD", items[0]["currency"])
+	assert.Equal(t, "12.38", items[0]["convertedPrice"])
+	assert.Equal(t, "EUR", items[0]["convertedCurrency"])
+
+	// Items without a currency keep only their original price
+	assert.Equal(t, "0.00", items[1]["price"])
+	assert.NotContains(t, items[1], "convertedPrice")
+
+	// Without convertTo the response is unchanged
+	code, items = getItems("")
+	require.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, items[0], "convertedPrice")
+
+	code, _ = getItems("?convertTo=euros")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = getItems("?convertTo=JPY")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetReceiptFormatsAmountsInUserLocale(t *testing.T) {
+	repo := &singleReceiptRepository{receipt: domain.Receipt{
+		ID:       "receipt-1",
+		UserID:   "user-1",
+		Merchant: "Toko Maju",
+		Date:     domain.FlexibleDate{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		Total:    1234.56,
+		Items:    []domain.ReceiptItem{{ID: "item-1", Name: "Rice", Quantity: 1, Price: 1234.56, Currency: "IDR"}},
+	}}
+	users := &profileUserRepository{preferences: &domain.UserPreferences{NumberLocale: "id-ID"}}
+	router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{Repository: repo, UserRepository: users}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/receipts/receipt-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "1234.56", body["total"], "numeric amounts stay machine-readable")
+	assert.Equal(t, "id-ID", body["numberLocale"])
+	assert.Equal(t, "1.234,56", body["formatted"].(map[string]interface{})["total"])
+	item := body["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "1234.56", item["price"])
+	assert.Equal(t, "1.234,56", item["formattedPrice"])
+
+	// Users without a number locale get no formatted amounts
+	users.preferences = &domain.UserPreferences{}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/receipts/receipt-1", nil))
+	assert.NotContains(t, w.Body.String(), "formatted")
+}
+
+func TestSpendingByCategoryItemQuantity(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/spending-by-category", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body struct {
+		Categories []struct {
+			Items []map[string]interface{} `json:"items"`
+		} `json:"categories"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Categories, 1)
+	require.Len(t, body.Categories[0].Items, 1)
+
+	item := body.Categories[0].Items[0]
+	assert.Equal(t, float64(3), item["count"])
+	assert.Equal(t, float64(12), item["totalQuantity"])
+}
+
+func TestInsightExclusions(t *testing.T) {
+	svc := &stubReceiptService{}
+	router := newTestRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/spending-by-category?excludeCategories=Rent,%20Utilities,&excludeMerchants=Acme%20Payroll", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.NotNil(t, svc.lastScope)
+	assert.Equal(t, "user-1", svc.lastScope.UserID)
+	assert.Equal(t, []string{"Rent", "Utilities"}, svc.lastScope.ExcludeCategories)
+	assert.Equal(t, []string{"Acme Payroll"}, svc.lastScope.ExcludeMerchants)
+	assert.False(t, svc.lastScope.RawMerchants)
+
+	t.Run("raw merchants", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/spending-by-category?normalizeMerchants=false", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.True(t, svc.lastScope.RawMerchants)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/spending-by-category?normalizeMerchants=maybe", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetReceiptCurrencies(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{receipts: []domain.Receipt{
+		{ID: "1", UserID: "user-1", Items: []domain.ReceiptItem{{Name: "Bagel", Currency: "USD"}, {Name: "Latte", Currency: "USD"}}},
+		{ID: "2", UserID: "user-1", Items: []domain.ReceiptItem{{Name: "Croissant", Currency: "EUR"}, {Name: "Water", Currency: "USD"}}},
+		{ID: "3", UserID: "user-2", Items: []domain.ReceiptItem{{Name: "Nasi Goreng", Currency: "IDR"}}},
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/receipts/currencies", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data []domain.CurrencyUsage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.ElementsMatch(t, []domain.CurrencyUsage{
+		{Currency: "USD", ReceiptCount: 2, ItemCount: 3},
+		{Currency: "EUR", ReceiptCount: 1, ItemCount: 1},
+	}, body.Data)
+}
+
+func TestCreateReceiptKeepsTimeOfDay(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+	createAndGet := func(date string) map[string]interface{} {
+		body := `{"merchant":"Corner Cafe","date":"` + date + `","total":4.5,"items":[{"name":"Latte","qty":1,"price":4.5,"currency":"USD"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/receipts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		location := rec.Header().Get("Location")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, location, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var receipt map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &receipt))
+		return receipt
+	}
+
+	receipt := createAndGet("2025-03-14T18:05:00+07:00")
+	assert.Equal(t, "2025-03-14", receipt["date"])
+	assert.Equal(t, "2025-03-14T18:05:00+07:00", receipt["dateTime"])
+
+	// A date alone reads as midnight
+	receipt = createAndGet("2025-03-14")
+	assert.Equal(t, "2025-03-14T00:00:00Z", receipt["dateTime"])
+}
+
+func TestCreateReceiptDateErrors(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+	create := func(date string) (int, string) {
+		body := `{"merchant":"Corner Cafe","date":` + date + `,"total":13.75,"items":[{"name":"Latte","qty":1,"price":13.75,"currency":"USD"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/receipts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := create(`""`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "Date is required")
+
+	code, body = create(`"sometime in March"`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, `unrecognized date \"sometime in March\"`)
+
+	code, body = create(`"14/03/2025"`)
+	assert.Equal(t, http.StatusCreated, code, body)
+}
+
+func TestGetReceiptsHasImageFilter(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{receipts: []domain.Receipt{
+		{ID: "scanned", UserID: "user-1", Merchant: "Corner Cafe", ReceiptURL: "https://storage.example.com/invoice_1.jpg"},
+		{ID: "attached", UserID: "user-1", Merchant: "Book Shop", ImageURL: "https://storage.example.com/invoice_2.png"},
+		{ID: "manual", UserID: "user-1", Merchant: "Hardware Store"},
+	}})
+
+	tests := []struct {
+		query   string
+		wantIDs []string
+	}{
+		{query: "", wantIDs: []string{"scanned", "attached", "manual"}},
+		{query: "?hasImage=true", wantIDs: []string{"scanned", "attached"}},
+		{query: "?hasImage=false", wantIDs: []string{"manual"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/receipts"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var listing struct {
+				Data []struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+			var ids []string
+			for _, receipt := range listing.Data {
+				ids = append(ids, receipt.ID)
+			}
+			assert.ElementsMatch(t, tt.wantIDs, ids)
+
+			req = httptest.NewRequest(http.MethodGet, "/v1/receipts/count"+tt.query, nil)
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"count":%d}`, len(tt.wantIDs)), rec.Body.String())
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/receipts?hasImage=maybe", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid hasImage value")
+}
+
+func TestGetReceiptsModifiedSince(t *testing.T) {
+	lastSync := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	receiptService := &stubReceiptService{receipts: []domain.Receipt{
+		{ID: "edited-later", UserID: "user-1", Merchant: "Corner Cafe", UpdatedAt: lastSync.Add(2 * time.Hour)},
+		{ID: "unchanged", UserID: "user-1", Merchant: "Book Shop", UpdatedAt: lastSync.Add(-time.Hour)},
+		{ID: "edited", UserID: "user-1", Merchant: "Hardware Store", UpdatedAt: lastSync.Add(time.Hour)},
+	}}
+	router := newTestRouter(receiptService)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/receipts?modifiedSince=2025-06-01T12:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var listing struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+		DeletedIDs []string `json:"deletedIds"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+	var ids []string
+	for _, receipt := range listing.Data {
+		ids = append(ids, receipt.ID)
+	}
+	assert.ElementsMatch(t, []string{"edited-later", "edited"}, ids)
+	assert.NotNil(t, listing.DeletedIDs)
+	assert.Equal(t, "updatedAt", receiptService.lastFilter.SortBy)
+	assert.Equal(t, "asc", receiptService.lastFilter.SortOrder)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/receipts?modifiedSince=2025-06-01", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid modifiedSince format")
+}
+
+// slowExtractor takes a fixed time to extract an invoice and reports model usage for it
+type slowExtractor struct {
+	delay time.Duration
+}
+
+func (e *slowExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	time.Sleep(e.delay)
+	invoice := domain.NewInvoice()
+	invoice.VendorName = "Corner Cafe"
+	invoice.TotalDue = 4.5
+	invoice.Usage = &domain.TokenUsage{PromptTokens: 1200, CompletionTokens: 300, TotalTokens: 1500, Cost: 0.0021}
+	return invoice, nil
+}
+
+// creatingReceiptRepository stores created receipts with sequential IDs
+type creatingReceiptRepository struct {
+	repository.ReceiptRepository
+}
+
+func (r *creatingReceiptRepository) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	created := *receipt
+	created.ID = "receipt-1"
+	return &created, nil
+}
+
+func TestScanReceiptExtractionHeaders(t *testing.T) {
+	router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{
+		Repository:     &creatingReceiptRepository{},
+		OpenAIClient:   &slowExtractor{delay: 20 * time.Millisecond},
+		MaxScanWorkers: 1,
+	}))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("receiptImage", "receipt.png")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("png-bytes"))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	router.ServeHTTP(rec, req)
+	elapsed := time.Since(start)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	latency, err := strconv.ParseInt(rec.Header().Get("X-Extraction-Latency-Ms"), 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, latency, int64(20))
+	assert.LessOrEqual(t, latency, elapsed.Milliseconds())
+	assert.Equal(t, "1500", rec.Header().Get("X-Extraction-Tokens"))
+	assert.Equal(t, "0.0021", rec.Header().Get("X-Extraction-Cost"))
+}
+
+// unsureExtractor returns an invoice with alternatives for the fields it was unsure of
+type unsureExtractor struct {
+	alternatives map[string][]string
+}
+
+func (e *unsureExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	invoice := domain.NewInvoice()
+	invoice.VendorName = "Corner Cafe"
+	invoice.TotalDue = 18.5
+	invoice.Alternatives = e.alternatives
+	return invoice, nil
+}
+
+func TestScanReceiptAlternatives(t *testing.T) {
+	scan := func(extractor service.InvoiceExtractor) map[string]interface{} {
+		router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{
+			Repository:     &creatingReceiptRepository{},
+			OpenAIClient:   extractor,
+			MaxScanWorkers: 1,
+		}))
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("receiptImage", "receipt.png")
+		require.NoError(t, err)
+		_, _ = part.Write([]byte("png-bytes"))
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	response := scan(&unsureExtractor{alternatives: map[string][]string{
+		"merchant": {"Comer Cafe"},
+		"total":    {"16.5", "18.56"},
+	}})
+	assert.Equal(t, "Corner Cafe", response["merchant"])
+	assert.Equal(t, map[string]interface{}{
+		"merchant": []interface{}{"Comer Cafe"},
+		"total":    []interface{}{"16.5", "18.56"},
+	}, response["alternatives"])
+
+	// A confident extraction has no alternatives
+	response = scan(&unsureExtractor{})
+	assert.NotContains(t, response, "alternatives")
+}
+
+func TestScanReceiptCorruptImage(t *testing.T) {
+	router := newTestRouter(service.NewReceiptService(service.ReceiptServiceConfig{
+		Repository:     &creatingReceiptRepository{},
+		OpenAIClient:   &slowExtractor{},
+		MaxScanWorkers: 1,
+	}))
+
+	// A JPEG header followed by garbage, like an upload cut short
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("receiptImage", "receipt.jpg")
+	require.NoError(t, err)
+	_, _ = part.Write(append([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00}, bytes.Repeat([]byte{0x42}, 64)...))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/receipts/scan", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	var response model.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Image could not be decoded, please re-upload", response.Message)
+}
+
+func TestGetSpendingBreakdown(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/breakdown?by=category", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var breakdown struct {
+		By     string                   `json:"by"`
+		Total  string                   `json:"total"`
+		Groups []map[string]interface{} `json:"groups"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &breakdown))
+
+	// Groups read the same as the dedicated category endpoint's categories
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/spending-by-category", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var categories struct {
+		Total      string                   `json:"total"`
+		Categories []map[string]interface{} `json:"categories"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &categories))
+
+	assert.Equal(t, "category", breakdown.By)
+	assert.Equal(t, categories.Total, breakdown.Total)
+	require.Len(t, breakdown.Groups, len(categories.Categories))
+	for i, group := range breakdown.Groups {
+		for _, field := range []string{"name", "amount", "percentage"} {
+			assert.Equal(t, categories.Categories[i][field], group[field], field)
+		}
+	}
+
+	for _, query := range []string{"", "by=tag", "by=Merchant", "by=merchant&startDate=2025-13-01"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/breakdown?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetCategoryTrend(t *testing.T) {
+	router := newTestRouter(&stubReceiptService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/category-trend?category=Food&period=monthly", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Food", body["category"])
+	assert.Equal(t, "monthly", body["period"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"date": "2025-05", "amount": "42.50"}}, body["data"])
+
+	for _, query := range []string{"period=monthly", "category=Food&period=hourly", "category=Food&startDate=May"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/insights/category-trend?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ReceiptViewRequest represents a request to save a named set of listing filters
+type ReceiptViewRequest struct {
+	Name    string            `json:"name" example:"Groceries this year"`
+	Filters map[string]string `json:"filters"`
+}
+
+// CreateReceiptView handles the POST /receipts/views endpoint
+// @Summary Save a receipt view
+// @Description Save a named set of receipt listing parameters. Saving a view with an existing name replaces its filters
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param view body ReceiptViewRequest true "View name and listing parameters (startDate, endDate, merchant, category, needsReview, hasImage, sortBy, sortOrder, limit)"
+// @Success 201 {object} map[string]interface{} "Saved view"
+// @Header 201 {string} Location "URL of the saved view"
+// @Failure 400 {object} model.ErrorResponse "Invalid input"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/views [post]
+func (h *ReceiptHandler) CreateReceiptView(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req ReceiptViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, ErrInvalidInput, newErrorDetail("body", err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		respondBadRequest(c, "Validation failed", newErrorDetail("name", "Name is required"))
+		return
+	}
+
+	// Only listing parameters can be saved, and they must be valid on their own
+	query := url.Values{}
+	for key, value := range req.Filters {
+		if !isReceiptListingParam(key) {
+			respondBadRequest(c, "Validation failed", newErrorDetail("filters", fmt.Sprintf("Unsupported filter: %s", key)))
+			return
+		}
+		query.Set(key, value)
+	}
+	if _, err := parseReceiptFilterValues(query); err != nil {
+		respondBadRequest(c, "Validation failed", newErrorDetail("filters", err.Error()))
+		return
+	}
+
+	view, err := h.receiptService.SaveReceiptView(c.Request.Context(), userID.(string), req.Name, req.Filters)
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to save receipt view: %v", err))
+		return
+	}
+
+	respondCreatedAt(c, view.ID, formatReceiptViewResponse(view))
+}
+
+// GetReceiptViews handles the GET /receipts/views endpoint
+// @Summary List receipt views
+// @Description List the authenticated user's saved receipt views
+// @Tags receipts
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Saved views"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/views [get]
+func (h *ReceiptHandler) GetReceiptViews(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	views, err := h.receiptService.ListReceiptViews(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve receipt views: %v", err))
+		return
+	}
+
+	data := make([]gin.H, len(views))
+	for i := range views {
+		data[i] = formatReceiptViewResponse(&views[i])
+	}
+
+	respondOK(c, gin.H{"data": data})
+}
+
+// DeleteReceiptView handles the DELETE /receipts/views/{viewId} endpoint
+// @Summary Delete a receipt view
+// @Description Delete one of the authenticated user's saved receipt views
+// @Tags receipts
+// @Param viewId path string true "View ID"
+// @Success 204 "View deleted"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 404 {object} model.ErrorResponse "View not found"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /v1/receipts/views/{viewId} [delete]
+func (h *ReceiptHandler) DeleteReceiptView(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	viewID, err := getPathParam(c, "viewId")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.receiptService.DeleteReceiptView(c.Request.Context(), userID.(string), viewID); err != nil {
+		if strings.Contains(fmt.Sprintf("%v", err), "not found") {
+			respondNotFound(c, fmt.Sprintf("Receipt view not found: %s", viewID))
+		} else {
+			respondInternalServerError(c, fmt.Sprintf("Failed to delete receipt view: %v", err))
+		}
+		return
+	}
+
+	respondNoContent(c)
+}
+
+// applyReceiptView returns the query with the view's filters filled in.
+// Parameters present in the query take precedence over the view's.
+func applyReceiptView(view *domain.ReceiptView, query url.Values) url.Values {
+	merged := url.Values{}
+	for key, value := range view.Filters {
+		if isReceiptListingParam(key) {
+			merged.Set(key, value)
+		}
+	}
+	for key, values := range query {
+		if key == "view" {
+			continue
+		}
+		merged[key] = values
+	}
+	return merged
+}
+
+// isReceiptListingParam reports whether the key is a listing parameter a view can store
+func isReceiptListingParam(key string) bool {
+	for _, param := range receiptListingParams {
+		if param == key {
+			return true
+		}
+	}
+	return false
+}
+
+// formatReceiptViewResponse formats a saved view for response
+func formatReceiptViewResponse(view *domain.ReceiptView) gin.H {
+	return gin.H{
+		"id":        view.ID,
+		"name":      view.Name,
+		"filters":   view.Filters,
+		"createdAt": view.CreatedAt.Format(time.RFC3339),
+		"updatedAt": view.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridwanfathin/invoice-processor-service/internal/model"
@@ -14,10 +15,14 @@ const (
 	StatusNoContent           = http.StatusNoContent
 	StatusBadRequest          = http.StatusBadRequest
 	StatusUnauthorized        = http.StatusUnauthorized
+	StatusForbidden           = http.StatusForbidden
 	StatusNotFound            = http.StatusNotFound
 	StatusConflict            = http.StatusConflict
 	StatusUnprocessableEntity = http.StatusUnprocessableEntity
+	StatusLocked              = http.StatusLocked
+	StatusTooManyRequests     = http.StatusTooManyRequests
 	StatusInternalServerError = http.StatusInternalServerError
+	StatusServiceUnavailable  = http.StatusServiceUnavailable
 )
 
 // Common error messages
@@ -30,6 +35,12 @@ const (
 	ErrFileUpload         = "Failed to upload file"
 	ErrFileProcessing     = "Failed to process file"
 	ErrDataExtraction     = "Unable to extract data"
+	ErrImageUndecodable   = "Image could not be decoded, please re-upload"
+	ErrScanNotConfigured  = "Receipt scanning is not configured on the server"
+	ErrImageNotConfigured = "Image storage is not configured on the server"
+	ErrNotOrgMember       = "You are not a member of this organization"
+	ErrReceiptModified    = "Receipt was modified since it was last read; fetch it again and retry"
+	ErrScanQuotaExceeded  = "Monthly scan quota used up; scans are available again when the quota resets"
 )
 
 // respondWithError sends a standardized error response
@@ -52,6 +63,11 @@ func respondUnauthorized(c *gin.Context, message string, details ...model.ErrorD
 	respondWithError(c, StatusUnauthorized, message, details...)
 }
 
+// respondForbidden sends a 403 Forbidden response
+func respondForbidden(c *gin.Context, message string) {
+	respondWithError(c, StatusForbidden, message)
+}
+
 // respondNotFound sends a 404 Not Found response
 func respondNotFound(c *gin.Context, message string) {
 	respondWithError(c, StatusNotFound, message)
@@ -67,11 +83,26 @@ func respondUnprocessableEntity(c *gin.Context, message string, details ...model
 	respondWithError(c, StatusUnprocessableEntity, message, details...)
 }
 
+// respondLocked sends a 423 Locked response
+func respondLocked(c *gin.Context, message string, details ...model.ErrorDetail) {
+	respondWithError(c, StatusLocked, message, details...)
+}
+
+// respondTooManyRequests sends a 429 Too Many Requests response
+func respondTooManyRequests(c *gin.Context, message string) {
+	respondWithError(c, StatusTooManyRequests, message)
+}
+
 // respondInternalServerError sends a 500 Internal Server Error response
 func respondInternalServerError(c *gin.Context, message string) {
 	respondWithError(c, StatusInternalServerError, message)
 }
 
+// respondServiceUnavailable sends a 503 Service Unavailable response
+func respondServiceUnavailable(c *gin.Context, message string) {
+	respondWithError(c, StatusServiceUnavailable, message)
+}
+
 // respondSuccess sends a standardized success response with data
 func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
 	c.JSON(statusCode, data)
@@ -82,6 +113,18 @@ func respondCreated(c *gin.Context, data interface{}) {
 	respondSuccess(c, StatusCreated, data)
 }
 
+// respondCreatedAt sends a 201 Created response with a Location header pointing at the new resource
+func respondCreatedAt(c *gin.Context, id string, data interface{}) {
+	c.Header("Location", resourceLocation(c, id))
+	respondCreated(c, data)
+}
+
+// resourceLocation returns the URL of the resource with the given ID below the matched route,
+// so it follows whichever base path the route was registered under
+func resourceLocation(c *gin.Context, id string) string {
+	return strings.TrimSuffix(c.FullPath(), "/") + "/" + id
+}
+
 // respondOK sends a 200 OK response with data
 func respondOK(c *gin.Context, data interface{}) {
 	respondSuccess(c, StatusOK, data)
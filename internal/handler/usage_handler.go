@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// RateLimitReporter reports a user's standing in a rate limit: the limit, the requests left in the
+// current window and when the window ends, the zero time when none is open. A zero limit is disabled.
+type RateLimitReporter interface {
+	Remaining(userID string) (int, int, time.Time)
+}
+
+// UsageHandler reports a user's usage against the scan quota and rate limits
+type UsageHandler struct {
+	receiptService service.ReceiptService
+	rateLimits     map[string]RateLimitReporter
+}
+
+// NewUsageHandler creates a new usage handler reporting the named rate limits
+func NewUsageHandler(receiptService service.ReceiptService, rateLimits map[string]RateLimitReporter) *UsageHandler {
+	return &UsageHandler{
+		receiptService: receiptService,
+		rateLimits:     rateLimits,
+	}
+}
+
+// GetUsageLimits handles the GET /usage/limits endpoint
+// @Summary Get the current user's usage limits
+// @Description The monthly scan quota with the scans used and left this calendar month (UTC) and when the count resets, and the requests left in each enabled rate limit such as export. monthlyQuota and remaining are null when scans are unlimited; a rate limit's resetsAt is omitted until the user makes a request it counts
+// @Tags usage
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Scan quota and rate limit usage"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/usage/limits [get]
+func (h *UsageHandler) GetUsageLimits(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	usage, err := h.receiptService.GetScanUsage(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondInternalServerError(c, fmt.Sprintf("Failed to retrieve scan usage: %v", err))
+		return
+	}
+
+	scans := gin.H{
+		"monthlyQuota": nil,
+		"used":         usage.Used,
+		"remaining":    nil,
+		"resetsAt":     usage.ResetsAt,
+	}
+	if usage.MonthlyQuota > 0 {
+		scans["monthlyQuota"] = usage.MonthlyQuota
+		scans["remaining"] = usage.Remaining()
+	}
+
+	rateLimits := gin.H{}
+	for name, reporter := range h.rateLimits {
+		limit, remaining, resetsAt := reporter.Remaining(userID.(string))
+		if limit <= 0 {
+			continue
+		}
+		status := gin.H{"limit": limit, "remaining": remaining}
+		if !resetsAt.IsZero() {
+			status["resetsAt"] = resetsAt
+		}
+		rateLimits[name] = status
+	}
+
+	respondOK(c, gin.H{
+		"scans":      scans,
+		"rateLimits": rateLimits,
+	})
+}
+
+// RegisterRoutes registers the usage routes behind authentication
+func (h *UsageHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	router.GET("/v1/usage/limits", authMiddleware, h.GetUsageLimits)
+}
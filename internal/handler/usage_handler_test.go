@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// fixedRateLimit reports the same rate limit standing for every user
+type fixedRateLimit struct {
+	limit, remaining int
+	resetsAt         time.Time
+}
+
+func (l fixedRateLimit) Remaining(userID string) (int, int, time.Time) {
+	return l.limit, l.remaining, l.resetsAt
+}
+
+// quotaReceiptService reports a fixed scan usage
+type quotaReceiptService struct {
+	stubReceiptService
+	usage domain.ScanUsage
+}
+
+func (s *quotaReceiptService) GetScanUsage(ctx context.Context, userID string) (*domain.ScanUsage, error) {
+	usage := s.usage
+	return &usage, nil
+}
+
+func TestGetUsageLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resetsAt := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	svc := &quotaReceiptService{usage: domain.ScanUsage{MonthlyQuota: 50, Used: 12, ResetsAt: resetsAt}}
+	h := NewUsageHandler(svc, map[string]RateLimitReporter{
+		"export":   fixedRateLimit{limit: 3, remaining: 2, resetsAt: resetsAt},
+		"disabled": fixedRateLimit{},
+	})
+	router := gin.New()
+	h.RegisterRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	})
+
+	get := func() map[string]map[string]interface{} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/usage/limits", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	body := get()
+	assert.Equal(t, float64(50), body["scans"]["monthlyQuota"])
+	assert.Equal(t, float64(12), body["scans"]["used"])
+	assert.Equal(t, float64(38), body["scans"]["remaining"])
+	assert.Equal(t, "2025-07-01T00:00:00Z", body["scans"]["resetsAt"])
+	assert.Equal(t, map[string]interface{}{"limit": float64(3), "remaining": float64(2), "resetsAt": "2025-07-01T00:00:00Z"}, body["rateLimits"]["export"])
+	assert.NotContains(t, body["rateLimits"], "disabled")
+
+	// Unlimited scans report no quota or remaining count
+	svc.usage.MonthlyQuota = 0
+	body = get()
+	assert.Nil(t, body["scans"]["monthlyQuota"])
+	assert.Nil(t, body["scans"]["remaining"])
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// UserExportHandler handles downloads of a user's own data
+type UserExportHandler struct {
+	exportService service.UserExportService
+}
+
+// NewUserExportHandler creates a new user export handler
+func NewUserExportHandler(exportService service.UserExportService) *UserExportHandler {
+	return &UserExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportUserData handles the GET /auth/me/export endpoint
+// @Summary Export all of the current user's data
+// @Description Download the user's profile, linked providers (without secrets), preferences and every receipt with its items as a single JSON attachment. Receipt images are referenced by URL. Exports are rate limited per user, and exports with more receipts than the configured maximum are refused with a request to narrow the date range
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Only export receipts dated on or after this date (YYYY-MM-DD)"
+// @Param endDate query string false "Only export receipts dated on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Exported data"
+// @Header 200 {string} Content-Disposition "attachment; filename=receipt-scanner-export-YYYY-MM-DD.json"
+// @Failure 400 {object} model.ErrorResponse "Invalid date range or too many receipts to export"
+// @Failure 401 {object} model.ErrorResponse "Unauthorized"
+// @Failure 429 {object} model.ErrorResponse "Too many export requests"
+// @Failure 500 {object} model.ErrorResponse "Internal server error"
+// @Router /v1/auth/me/export [get]
+func (h *UserExportHandler) ExportUserData(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	startDate, err := parseExportDate(c.Query("startDate"))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("startDate", err.Error()))
+		return
+	}
+	endDate, err := parseExportDate(c.Query("endDate"))
+	if err != nil {
+		respondBadRequest(c, ErrInvalidQueryParams, newErrorDetail("endDate", err.Error()))
+		return
+	}
+
+	export, err := h.exportService.ExportUserData(c.Request.Context(), userID.(string), startDate, endDate)
+	if err != nil {
+		var tooLarge *service.ExportTooLargeError
+		if errors.As(err, &tooLarge) {
+			respondBadRequest(c, fmt.Sprintf("Export covers %d receipts, more than the limit of %d. Narrow the date range with startDate and endDate and export in parts",
+				tooLarge.Receipts, tooLarge.Limit))
+			return
+		}
+		respondInternalServerError(c, fmt.Sprintf("Failed to export user data: %v", err))
+		return
+	}
+
+	filename := fmt.Sprintf("receipt-scanner-export-%s.json", export.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	respondOK(c, gin.H{
+		"exportedAt": export.ExportedAt,
+		"profile":    export.Profile,
+		"receipts":   formatReceiptsResponse(export.Receipts),
+	})
+}
+
+// parseExportDate parses an optional YYYY-MM-DD export bound, returning nil when it is empty
+func parseExportDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := parseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// RegisterRoutes registers the export route behind authentication and the export rate limit
+func (h *UserExportHandler) RegisterRoutes(router *gin.Engine, authMiddleware, rateLimitMiddleware gin.HandlerFunc) {
+	router.GET("/v1/auth/me/export", authMiddleware, rateLimitMiddleware, h.ExportUserData)
+}
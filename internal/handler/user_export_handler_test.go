@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/middleware"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+	"github.com/ridwanfathin/invoice-processor-service/internal/service"
+)
+
+// ownedReceiptRepository returns the receipts owned by the requested user
+type ownedReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts []domain.Receipt
+}
+
+func (r *ownedReceiptRepository) GetReceiptsWithItems(ctx context.Context, filter repository.ReceiptFilterWithItems) ([]domain.Receipt, error) {
+	var owned []domain.Receipt
+	for _, receipt := range r.receipts {
+		if receipt.UserID == filter.UserID {
+			owned = append(owned, receipt)
+		}
+	}
+	return owned, nil
+}
+
+func (r *ownedReceiptRepository) CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error) {
+	count := 0
+	for _, receipt := range r.receipts {
+		if receipt.UserID == filter.UserID && (filter.StartDate == nil || !receipt.Date.Time.Before(*filter.StartDate)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestExportUserData(t *testing.T) {
+	userRepo := &profileUserRepository{user: domain.User{
+		ID:           "user-1",
+		Email:        "jane@example.com",
+		Name:         "Jane",
+		PasswordHash: "$2a$10$secret-hash",
+	}}
+	receiptRepo := &ownedReceiptRepository{receipts: []domain.Receipt{
+		{
+			ID:       "receipt-1",
+			UserID:   "user-1",
+			Merchant: "Corner Cafe",
+			Date:     domain.FlexibleDate{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			Total:    4.5,
+			Items:    []domain.ReceiptItem{{ID: "item-1", Name: "Latte", Quantity: 1, Price: 4.5}},
+		},
+		{ID: "receipt-2", UserID: "user-2", Merchant: "Someone Else's Shop"},
+	}}
+	authService := service.NewAuthService(service.AuthServiceConfig{UserRepo: userRepo, JWTSecret: "test-secret"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	NewUserExportHandler(service.NewUserExportService(authService, receiptRepo, 0)).
+		RegisterRoutes(router, auth, middleware.RateLimit(1, time.Hour))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me/export", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=")
+
+	var export struct {
+		Profile  map[string]interface{}   `json:"profile"`
+		Receipts []map[string]interface{} `json:"receipts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.Equal(t, "jane@example.com", export.Profile["email"])
+	assert.Len(t, export.Profile["providers"], 1)
+	assert.Contains(t, export.Profile, "preferences")
+
+	require.Len(t, export.Receipts, 1)
+	assert.Equal(t, "Corner Cafe", export.Receipts[0]["merchant"])
+	assert.Len(t, export.Receipts[0]["items"], 1)
+
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+	assert.NotContains(t, w.Body.String(), "google-secret-token")
+	assert.NotContains(t, w.Body.String(), "Someone Else's Shop")
+
+	// Exports are heavy, so a second one within the hour is refused
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me/export", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestExportUserDataRejectsOversizedExports(t *testing.T) {
+	userRepo := &profileUserRepository{user: domain.User{ID: "user-1", Email: "jane@example.com"}}
+	receiptRepo := &ownedReceiptRepository{}
+	for day := 1; day <= 3; day++ {
+		receiptRepo.receipts = append(receiptRepo.receipts, domain.Receipt{
+			ID:     fmt.Sprintf("receipt-%d", day),
+			UserID: "user-1",
+			Date:   domain.FlexibleDate{Time: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)},
+		})
+	}
+	authService := service.NewAuthService(service.AuthServiceConfig{UserRepo: userRepo, JWTSecret: "test-secret"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	}
+	NewUserExportHandler(service.NewUserExportService(authService, receiptRepo, 2)).
+		RegisterRoutes(router, auth, middleware.RateLimit(0, time.Hour))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me/export", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "more than the limit of 2")
+	assert.Contains(t, w.Body.String(), "Narrow the date range")
+
+	// Narrowing the range brings the export under the limit
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me/export?startDate=2024-03-02", nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/auth/me/export?endDate=March", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
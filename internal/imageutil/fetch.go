@@ -0,0 +1,173 @@
+package imageutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxFetchBytes is the default maximum size of a fetched image
+	DefaultMaxFetchBytes = 10 << 20
+	// DefaultFetchTimeout is the default time limit for fetching an image
+	DefaultFetchTimeout = 15 * time.Second
+	// maxFetchRedirects bounds the redirects followed while fetching an image
+	maxFetchRedirects = 3
+)
+
+var (
+	// ErrBlockedURL is returned when a URL is not allowed to be fetched
+	ErrBlockedURL = errors.New("image URL is not allowed")
+	// ErrImageTooLarge is returned when a fetched image exceeds the size limit
+	ErrImageTooLarge = errors.New("image exceeds maximum size")
+)
+
+// FetchConfig holds configuration for fetching remote images
+type FetchConfig struct {
+	MaxBytes int64         // Maximum image size in bytes (default 10MB)
+	Timeout  time.Duration // Overall time limit for the request (default 15s)
+}
+
+// Fetcher downloads remote images while guarding against server-side request forgery.
+// Only http and https URLs are allowed, and connections to loopback, private,
+// link-local and other non-public addresses are refused after DNS resolution.
+type Fetcher struct {
+	httpClient  *http.Client
+	maxBytes    int64
+	isBlockedIP func(ip net.IP) bool
+}
+
+// NewFetcher creates a new image fetcher
+func NewFetcher(config *FetchConfig) *Fetcher {
+	if config == nil {
+		config = &FetchConfig{}
+	}
+	maxBytes := config.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxFetchBytes
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFetchTimeout
+	}
+
+	f := &Fetcher{
+		maxBytes:    maxBytes,
+		isBlockedIP: isNonPublicIP,
+	}
+
+	// Check the resolved address at dial time so DNS rebinding cannot bypass validation
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrBlockedURL, err)
+			}
+			if ip := net.ParseIP(host); ip == nil || f.isBlockedIP(ip) {
+				return fmt.Errorf("%w: address %s is not public", ErrBlockedURL, host)
+			}
+			return nil
+		},
+	}
+
+	f.httpClient = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("%w: too many redirects", ErrBlockedURL)
+			}
+			return f.validateURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+// Fetch downloads the image at rawURL
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBlockedURL, err)
+	}
+	if err := f.validateURL(parsed); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedURL) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: remote returned status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrImageTooLarge, resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, f.maxBytes)
+	}
+
+	return data, nil
+}
+
+// validateURL checks the scheme and host of a URL before any connection is made
+func (f *Fetcher) validateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrBlockedURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: host is required", ErrBlockedURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrBlockedURL)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && f.isBlockedIP(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrBlockedURL, u.Hostname())
+	}
+	return nil
+}
+
+// isNonPublicIP reports whether an IP is loopback, private, link-local, multicast or unspecified
+func isNonPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	// Carrier-grade NAT range 100.64.0.0/10
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xC0 == 64 {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,21 @@
+package imageutil
+
+import "net/http"
+
+// imageFileTypes maps detected image content types to file extensions
+var imageFileTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// FileType returns the file extension and content type of encoded image data, detected from its
+// bytes. Unrecognized data is reported as application/octet-stream with a .bin extension.
+func FileType(imageData []byte) (extension, contentType string) {
+	contentType = http.DetectContentType(imageData)
+	if extension, ok := imageFileTypes[contentType]; ok {
+		return extension, contentType
+	}
+	return ".bin", "application/octet-stream"
+}
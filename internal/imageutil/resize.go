@@ -2,6 +2,7 @@ package imageutil
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
@@ -14,11 +15,15 @@ import (
 // DefaultMaxDimension is the default maximum dimension for resizing
 const DefaultMaxDimension = 1024
 
+// ErrCorruptImage is returned when data in a supported image format fails to decode, such as a
+// truncated upload. Data in an unsupported format fails with image.ErrFormat instead.
+var ErrCorruptImage = errors.New("image is corrupt")
+
 // ResizeConfig holds configuration for image resizing
 type ResizeConfig struct {
 	MaxDimension int  // Maximum width or height (default 1024)
 	Quality      int  // JPEG quality 1-100 (default 85)
-	OutputFormat string // "png" or "jpeg" (default "png")
+	OutputFormat string // "png", "jpeg", or empty to keep the source format (default empty)
 }
 
 // DefaultConfig returns default resize configuration
@@ -26,7 +31,6 @@ func DefaultConfig() *ResizeConfig {
 	return &ResizeConfig{
 		MaxDimension: DefaultMaxDimension,
 		Quality:      85,
-		OutputFormat: "png",
 	}
 }
 
@@ -38,6 +42,9 @@ func ResizeImage(imageData []byte, config *ResizeConfig) ([]byte, error) {
 
 	// Decode the image
 	img, format, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil && !errors.Is(err, image.ErrFormat) {
+		return nil, fmt.Errorf("failed to decode image: %w: %v", ErrCorruptImage, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -46,15 +53,23 @@ func ResizeImage(imageData []byte, config *ResizeConfig) ([]byte, error) {
 	width := bounds.Dx()
 	height := bounds.Dy()
 
-	// Check if resizing is needed
-	if width <= config.MaxDimension && height <= config.MaxDimension {
-		// No resize needed, return original
-		return imageData, nil
+	outputFormat := config.OutputFormat
+	if outputFormat == "jpg" {
+		outputFormat = "jpeg"
+	}
+	if outputFormat == "" {
+		outputFormat = format // Use original format if not specified
 	}
 
-	// Calculate new dimensions maintaining aspect ratio
-	var newWidth, newHeight int
-	if width > height {
+	// Check if resizing is needed
+	newWidth, newHeight := width, height
+	if width <= config.MaxDimension && height <= config.MaxDimension {
+		if outputFormat == format {
+			// No resize or conversion needed, return original
+			return imageData, nil
+		}
+	} else if width > height {
+		// Calculate new dimensions maintaining aspect ratio
 		newWidth = config.MaxDimension
 		newHeight = int(float64(height) * float64(config.MaxDimension) / float64(width))
 	} else {
@@ -70,13 +85,8 @@ func ResizeImage(imageData []byte, config *ResizeConfig) ([]byte, error) {
 
 	// Encode the resized image
 	var buf bytes.Buffer
-	outputFormat := config.OutputFormat
-	if outputFormat == "" {
-		outputFormat = format // Use original format if not specified
-	}
-
 	switch outputFormat {
-	case "jpeg", "jpg":
+	case "jpeg":
 		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: config.Quality})
 	case "png":
 		err = png.Encode(&buf, dst)
@@ -0,0 +1,63 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// photoJPEG encodes a gradient with noise, which compresses like a photo
+func photoJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			noise := uint8((x*7 + y*13) % 23)
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / width), G: uint8(y*255/height) + noise, B: 128 + noise, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}))
+	return buf.Bytes()
+}
+
+func TestResizeImageKeepsSourceFormat(t *testing.T) {
+	photo := photoJPEG(t, 2048, 1536)
+
+	resized, err := ResizeImage(photo, nil)
+	require.NoError(t, err)
+
+	extension, contentType := FileType(resized)
+	assert.Equal(t, ".jpg", extension)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Less(t, len(resized), len(photo))
+
+	decoded, err := jpeg.Decode(bytes.NewReader(resized))
+	require.NoError(t, err)
+	assert.Equal(t, 1024, decoded.Bounds().Dx())
+
+	// The same resize stored as PNG is what the old default produced
+	asPNG, err := ResizeImage(photo, &ResizeConfig{MaxDimension: DefaultMaxDimension, OutputFormat: "png"})
+	require.NoError(t, err)
+	assert.Less(t, len(resized), len(asPNG))
+
+	t.Run("small images are converted when a format is configured", func(t *testing.T) {
+		small := photoJPEG(t, 200, 100)
+
+		unchanged, err := ResizeImage(small, nil)
+		require.NoError(t, err)
+		assert.Equal(t, small, unchanged)
+
+		converted, err := ResizeImage(small, &ResizeConfig{MaxDimension: DefaultMaxDimension, OutputFormat: "png"})
+		require.NoError(t, err)
+		_, err = png.Decode(bytes.NewReader(converted))
+		assert.NoError(t, err)
+		_, contentType := FileType(converted)
+		assert.Equal(t, "image/png", contentType)
+	})
+}
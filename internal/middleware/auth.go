@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 	"github.com/ridwanfathin/invoice-processor-service/internal/service"
 )
 
@@ -92,3 +93,32 @@ func OptionalAuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminOnly creates a middleware that only lets admin users through.
+// It must run after AuthMiddleware, which sets the user ID it looks up.
+func AdminOnly(authService service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"status":  "Unauthorized",
+				"message": "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		// Look the role up on every request so revoking admin takes effect within the user cache TTL
+		user, err := authService.GetUserByID(c.Request.Context(), userID.(string))
+		if err != nil || user.Role != domain.UserRoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  "Forbidden",
+				"message": "Admin access required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,22 +1,73 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORS adds cross-origin resource sharing headers to responses
-func CORS() gin.HandlerFunc {
+// CORSConfig holds configuration for the CORS middleware
+type CORSConfig struct {
+	AllowedOrigins   []string      // Origins allowed to make cross-origin requests, "*" allows any
+	AllowCredentials bool          // Allow cookies and Authorization headers on requests from listed origins
+	MaxAge           time.Duration // How long browsers may cache a preflight response, not sent when zero
+}
+
+// CORS adds cross-origin resource sharing headers to responses.
+// Listed origins are echoed back; a "*" entry allows any other origin with a literal wildcard.
+// Credentials are only ever allowed for an echoed origin, because browsers reject them
+// alongside "Access-Control-Allow-Origin: *" and a wildcard would expose them to every site.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(config.AllowedOrigins))
+	allowAny := false
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+			continue
+		}
+		allowed[origin] = true
+	}
+	if allowAny && config.AllowCredentials {
+		log.Println("Warning: CORS credentials are only allowed for listed origins, not for the \"*\" wildcard")
+	}
+
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Writer.Header().Set(
+		header := c.Writer.Header()
+		origin := c.GetHeader("Origin")
+
+		// The response differs by origin, so caches must not share it across origins
+		header.Add("Vary", "Origin")
+
+		switch {
+		case origin != "" && allowed[origin]:
+			header.Set("Access-Control-Allow-Origin", origin)
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		case allowAny:
+			header.Set("Access-Control-Allow-Origin", "*")
+		}
+
+		header.Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		header.Set(
 			"Access-Control-Allow-Headers",
 			"Content-Type, Content-Length, Accept-Encoding, Authorization, ngrok-skip-browser-warning",
 		)
+		// Let browser clients read the scan cost headers
+		header.Set("Access-Control-Expose-Headers", "X-Extraction-Latency-Ms, X-Extraction-Tokens, X-Extraction-Cost")
 
 		if c.Request.Method == "OPTIONS" {
+			if maxAge != "" {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
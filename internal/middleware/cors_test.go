@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(config CORSConfig) *gin.Engine {
+		router := gin.New()
+		router.Use(CORS(config))
+		router.GET("/receipts", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+		return router
+	}
+	request := func(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/receipts", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("credentials are never combined with the wildcard", func(t *testing.T) {
+		router := newRouter(CORSConfig{
+			AllowedOrigins:   []string{"https://app.example.com", "*"},
+			AllowCredentials: true,
+		})
+
+		w := request(router, http.MethodGet, "https://other.example.com")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = request(router, http.MethodGet, "https://app.example.com")
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("unlisted origins get no allow-origin", func(t *testing.T) {
+		router := newRouter(CORSConfig{
+			AllowedOrigins:   []string{"https://app.example.com"},
+			AllowCredentials: true,
+		})
+
+		w := request(router, http.MethodGet, "https://evil.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("preflight sets max age", func(t *testing.T) {
+		router := newRouter(CORSConfig{AllowedOrigins: []string{"*"}, MaxAge: 10 * time.Minute})
+
+		w := request(router, http.MethodOptions, "https://app.example.com")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+		// Max age only applies to preflight responses
+		w = request(router, http.MethodGet, "https://app.example.com")
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("max age omitted when zero", func(t *testing.T) {
+		router := newRouter(CORSConfig{AllowedOrigins: []string{"*"}})
+
+		w := request(router, http.MethodOptions, "https://app.example.com")
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+}
@@ -58,11 +58,38 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 type LoggerConfig struct {
 	Format string // "json" or "pretty"
 	Level  string // "debug", "info", "warn", "error"
+
+	// ExcludePaths are request paths that are not logged. A trailing "*" matches any path with
+	// the preceding prefix, e.g. "/health*" or "/api-docs*".
+	ExcludePaths []string
+	// LogExcludedErrors still logs requests to excluded paths that end in a 5xx response
+	LogExcludedErrors bool
+}
+
+// isExcludedPath reports whether path matches one of the exclusion patterns
+func isExcludedPath(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
 }
 
-// RequestResponseLogger creates a middleware that logs all API requests and responses
+// RequestResponseLogger creates a middleware that logs all API requests and responses, except
+// those to the configured excluded paths
 func RequestResponseLogger(config LoggerConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		excluded := isExcludedPath(c.Request.URL.Path, config.ExcludePaths)
+		if excluded && !config.LogExcludedErrors {
+			c.Next()
+			return
+		}
+
 		// Start timer
 		startTime := time.Now()
 
@@ -84,6 +111,10 @@ func RequestResponseLogger(config LoggerConfig) gin.HandlerFunc {
 		// Process request
 		c.Next()
 
+		if excluded && c.Writer.Status() < 500 {
+			return
+		}
+
 		// Calculate latency
 		latency := time.Since(startTime)
 
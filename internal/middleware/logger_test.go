@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what fn printed to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	require.NoError(t, writer.Close())
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(output)
+}
+
+func TestRequestResponseLoggerExcludesPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestResponseLogger(LoggerConfig{
+		Format:            "json",
+		ExcludePaths:      []string{"/health*", "/metrics"},
+		LogExcludedErrors: true,
+	}))
+	router.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	router.GET("/health/db", func(c *gin.Context) { c.JSON(http.StatusServiceUnavailable, gin.H{"status": "down"}) })
+	router.GET("/v1/receipts", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": []string{}}) })
+
+	request := func(path string) string {
+		return captureStdout(t, func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		})
+	}
+
+	assert.Empty(t, request("/health"))
+	assert.Contains(t, request("/v1/receipts"), `"path":"/v1/receipts"`)
+
+	// Failures on excluded paths are still logged
+	assert.Contains(t, request("/health/db"), `"status_code":503`)
+}
+
+func TestIsExcludedPath(t *testing.T) {
+	patterns := []string{"/health*", "/metrics", "/api-docs*"}
+
+	assert.True(t, isExcludedPath("/health", patterns))
+	assert.True(t, isExcludedPath("/api-docs/index.html", patterns))
+	assert.True(t, isExcludedPath("/metrics", patterns))
+	assert.False(t, isExcludedPath("/metrics/extra", patterns))
+	assert.False(t, isExcludedPath("/v1/receipts", patterns))
+	assert.False(t, isExcludedPath("/health", nil))
+}
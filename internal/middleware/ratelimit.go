@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateWindow counts the requests made by one client in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter allows each authenticated user, or client IP when unauthenticated, at most limit
+// requests per window. A limit of zero or less disables it. Counts are kept in memory, so each
+// server instance limits separately.
+type RateLimiter struct {
+	limit     int
+	window    time.Duration
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	nextSweep time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing limit requests per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:     limit,
+		window:    window,
+		windows:   map[string]*rateWindow{},
+		nextSweep: time.Now().Add(window),
+	}
+}
+
+// RateLimit creates a middleware limiting requests as a RateLimiter does. Requests over the
+// limit are rejected with a 429 JSON error and a Retry-After header until the window ends.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	return NewRateLimiter(limit, window).Handler()
+}
+
+// Handler returns the middleware enforcing the limit
+func (l *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.limit <= 0 {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID, exists := c.Get("userID"); exists {
+			key = "user:" + userID.(string)
+		}
+
+		now := time.Now()
+		l.mu.Lock()
+		// Drop finished windows now and then so idle clients don't accumulate
+		if now.After(l.nextSweep) {
+			for k, w := range l.windows {
+				if now.Sub(w.start) >= l.window {
+					delete(l.windows, k)
+				}
+			}
+			l.nextSweep = now.Add(l.window)
+		}
+
+		current, ok := l.windows[key]
+		if !ok || now.Sub(current.start) >= l.window {
+			current = &rateWindow{start: now}
+			l.windows[key] = current
+		}
+		current.count++
+		allowed := current.count <= l.limit
+		retryAfter := current.start.Add(l.window).Sub(now)
+		l.mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"status":  "429",
+				"message": "Too many requests, please try again later",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Remaining reports the limit, the requests the user has left in their current window and when
+// that window ends, which is the zero time when no window is open. A disabled limit reports a
+// limit of zero.
+func (l *RateLimiter) Remaining(userID string) (int, int, time.Time) {
+	if l.limit <= 0 {
+		return 0, 0, time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	current, ok := l.windows["user:"+userID]
+	if !ok || time.Since(current.start) >= l.window {
+		return l.limit, l.limit, time.Time{}
+	}
+	return l.limit, max(l.limit-current.count, 0), current.start.Add(l.window)
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	router.Use(RateLimit(2, 50*time.Millisecond))
+	router.GET("/export", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	request := func(userID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/export", nil)
+		req.Header.Set("X-User-ID", userID)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("user-1").Code)
+	assert.Equal(t, http.StatusOK, request("user-1").Code)
+	limited := request("user-1")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get("Retry-After"))
+
+	// Other users and unauthenticated clients have their own allowance
+	assert.Equal(t, http.StatusOK, request("user-2").Code)
+	assert.Equal(t, http.StatusOK, request("").Code)
+
+	// The allowance resets once the window ends
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, request("user-1").Code)
+}
+
+func TestRateLimiterRemaining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(3, time.Hour)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Next()
+	})
+	router.Use(limiter.Handler())
+	router.GET("/export", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	limit, remaining, resetsAt := limiter.Remaining("user-1")
+	assert.Equal(t, 3, limit)
+	assert.Equal(t, 3, remaining)
+	assert.True(t, resetsAt.IsZero())
+
+	for i := 0; i < 4; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/export", nil))
+	}
+	_, remaining, resetsAt = limiter.Remaining("user-1")
+	assert.Equal(t, 0, remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), resetsAt, time.Minute)
+
+	limit, _, _ = NewRateLimiter(0, time.Hour).Remaining("user-1")
+	assert.Zero(t, limit)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutConfig holds configuration for the timeout middleware
+type TimeoutConfig struct {
+	Default     time.Duration            // Deadline applied to every request, disabled when zero
+	Overrides   map[string]time.Duration // Per-route deadlines keyed by route pattern (e.g. "/v1/receipts/scan")
+	ExemptPaths []string                 // Route patterns that are never wrapped (e.g. streaming endpoints)
+}
+
+// timeoutWriter buffers the response so it can be discarded when the deadline is exceeded
+type timeoutWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.status != 0 || w.body.Len() > 0
+}
+
+// Timeout creates a middleware that bounds each request with a deadline on its context.
+// Downstream DB and HTTP calls observe the deadline through the request context, and a
+// 504 JSON error replaces whatever the handler wrote once the deadline has passed.
+// Server-sent event requests and exempt routes are passed through untouched.
+func Timeout(config TimeoutConfig) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(config.ExemptPaths))
+	for _, path := range config.ExemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		timeout := config.Default
+		if override, ok := config.Overrides[c.FullPath()]; ok {
+			timeout = override
+		}
+
+		if timeout <= 0 || exempt[c.FullPath()] || isStreamingRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// Buffer the response until the handler chain completes
+		originalWriter := c.Writer
+		bufferedWriter := &timeoutWriter{ResponseWriter: originalWriter}
+		c.Writer = bufferedWriter
+
+		c.Next()
+
+		c.Writer = originalWriter
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"status":  "504",
+				"message": "Request timed out",
+				"details": []gin.H{
+					{
+						"field":   "request",
+						"message": "The request did not complete within " + timeout.String(),
+					},
+				},
+			})
+			return
+		}
+
+		if bufferedWriter.Written() {
+			originalWriter.WriteHeader(bufferedWriter.Status())
+			if _, err := originalWriter.Write(bufferedWriter.body.Bytes()); err != nil {
+				_ = c.Error(err)
+			}
+		}
+	}
+}
+
+// isStreamingRequest checks if the client asked for a server-sent event stream
+func isStreamingRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowHandler waits for the given delay or until the request context is cancelled
+func slowHandler(delay time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		select {
+		case <-time.After(delay):
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		case <-c.Request.Context().Done():
+			c.JSON(http.StatusInternalServerError, gin.H{"message": c.Request.Context().Err().Error()})
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Timeout(TimeoutConfig{
+		Default: 20 * time.Millisecond,
+		Overrides: map[string]time.Duration{
+			"/scan": 500 * time.Millisecond,
+		},
+		ExemptPaths: []string{"/stream"},
+	}))
+	router.GET("/slow", slowHandler(200*time.Millisecond))
+	router.GET("/fast", slowHandler(0))
+	router.GET("/scan", slowHandler(100*time.Millisecond))
+	router.GET("/stream", slowHandler(100*time.Millisecond))
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "slow handler times out", path: "/slow", wantStatus: http.StatusGatewayTimeout, wantBody: `"message":"Request timed out"`},
+		{name: "fast handler completes", path: "/fast", wantStatus: http.StatusOK, wantBody: `"status":"ok"`},
+		{name: "route override extends deadline", path: "/scan", wantStatus: http.StatusOK, wantBody: `"status":"ok"`},
+		{name: "exempt route is not bounded", path: "/stream", wantStatus: http.StatusOK, wantBody: `"status":"ok"`},
+		{name: "event stream request is not bounded", path: "/slow", accept: "text/event-stream", wantStatus: http.StatusOK, wantBody: `"status":"ok"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+			assert.NotContains(t, rec.Body.String(), "deadline exceeded")
+		})
+	}
+}
@@ -2,6 +2,7 @@ package mlxclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,8 +38,9 @@ func NewClient(config *Config) *Client {
 	}
 }
 
-// ExtractInvoiceData extracts structured data from an invoice image URL using MLX-VLM
-func (c *Client) ExtractInvoiceData(imageURL string) (*domain.Invoice, error) {
+// ExtractInvoiceData extracts structured data from an invoice image URL using MLX-VLM.
+// The request is abandoned when the context ends.
+func (c *Client) ExtractInvoiceData(ctx context.Context, imageURL string) (*domain.Invoice, error) {
 	// Create JSON payload
 	payload := map[string]string{
 		"image_url": imageURL,
@@ -51,7 +53,7 @@ func (c *Client) ExtractInvoiceData(imageURL string) (*domain.Invoice, error) {
 
 	// Create HTTP request
 	url := fmt.Sprintf("%s/extract", c.baseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
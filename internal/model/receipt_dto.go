@@ -2,15 +2,21 @@ package model
 
 // ReceiptResponse represents the response for a single receipt
 type ReceiptResponse struct {
-	ID        string                `json:"id"`
-	Merchant  string                `json:"merchant"`
-	Date      string                `json:"date"`
-	Total     string                `json:"total"`
-	Tax       string                `json:"tax"`
-	Subtotal  string                `json:"subtotal"`
-	Items     []ReceiptItemResponse `json:"items"`
-	CreatedAt string                `json:"createdAt"`
-	UpdatedAt string                `json:"updatedAt"`
+	ID            string                `json:"id"`
+	Merchant      string                `json:"merchant"`
+	Date          string                `json:"date"`
+	DateTime      string                `json:"dateTime"` // Time of purchase, or midnight UTC of the date when the receipt shows no time
+	Total         string                `json:"total"`
+	Tax           string                `json:"tax"`
+	Subtotal      string                `json:"subtotal"`
+	Tip           string                `json:"tip"`
+	ServiceCharge string                `json:"serviceCharge"`
+	TaxID         string                `json:"taxId,omitempty"` // Seller's tax or registration number, if printed
+	Items         []ReceiptItemResponse `json:"items"`
+	// Other plausible readings of uncertain fields keyed by field name, only on scan responses when the model was unsure
+	Alternatives map[string][]string `json:"alternatives,omitempty"`
+	CreatedAt    string              `json:"createdAt"`
+	UpdatedAt    string              `json:"updatedAt"`
 }
 
 // ReceiptItemResponse represents a single receipt item
@@ -27,6 +33,7 @@ type ReceiptItemResponse struct {
 type ReceiptsListResponse struct {
 	Data       []ReceiptResponse  `json:"data"`
 	Pagination PaginationResponse `json:"pagination"`
+	DeletedIDs []string           `json:"deletedIds,omitempty"` // With modifiedSince, the user's receipts deleted since then
 }
 
 // PaginationResponse represents pagination metadata
@@ -88,9 +95,10 @@ type CategorySpendingDetail struct {
 
 // CategoryItemDetail represents item-level spending within a category
 type CategoryItemDetail struct {
-	Name       string `json:"name"`
-	TotalSpent string `json:"totalSpent"`
-	Count      int    `json:"count"`
+	Name          string `json:"name"`
+	TotalSpent    string `json:"totalSpent"`
+	Count         int    `json:"count"`         // Number of line items the item appears on
+	TotalQuantity int    `json:"totalQuantity"` // Units purchased across those line items
 }
 
 // MerchantFrequencyResponse represents merchant visit frequency
@@ -106,6 +114,9 @@ type MerchantFrequencyDetail struct {
 	TotalSpent   string  `json:"totalSpent"`
 	AverageSpent string  `json:"averageSpent"`
 	Percentage   float64 `json:"percentage"`
+	// Average item quantity and distinct item categories per visit
+	AverageItems      float64 `json:"averageItems"`
+	AverageCategories float64 `json:"averageCategories"`
 }
 
 // MonthlyComparisonResponse represents comparison between two months
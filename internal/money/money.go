@@ -0,0 +1,127 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode determines how amounts are rounded to the configured precision
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero (1.005 -> 1.01)
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds halves to the nearest even digit (1.005 -> 1.00)
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown truncates towards zero (1.009 -> 1.00)
+	RoundDown RoundingMode = "down"
+)
+
+// maxPrecision bounds the number of decimal places so minor units fit in an int64
+const maxPrecision = 8
+
+// Amount is a monetary amount stored as integer minor units of a Policy's precision
+type Amount int64
+
+// Add returns the sum of two amounts
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// Mul returns the amount multiplied by a quantity
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// Policy defines the decimal precision and rounding mode for money math
+type Policy struct {
+	Precision int
+	Rounding  RoundingMode
+}
+
+// DefaultPolicy returns a policy with two decimal places and half-up rounding
+func DefaultPolicy() Policy {
+	return Policy{Precision: 2, Rounding: RoundHalfUp}
+}
+
+// NewPolicy creates a policy after validating the precision and rounding mode
+func NewPolicy(precision int, rounding string) (Policy, error) {
+	if precision < 0 || precision > maxPrecision {
+		return Policy{}, fmt.Errorf("money precision must be between 0 and %d, got %d", maxPrecision, precision)
+	}
+
+	mode := RoundingMode(strings.ToLower(strings.TrimSpace(rounding)))
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundDown:
+	default:
+		return Policy{}, fmt.Errorf("unsupported rounding mode %q (expected %s, %s or %s)", rounding, RoundHalfUp, RoundHalfEven, RoundDown)
+	}
+
+	return Policy{Precision: precision, Rounding: mode}, nil
+}
+
+// FromFloat converts a float to minor units, rounding the decimal value the float represents
+func (p Policy) FromFloat(value float64) Amount {
+	if value == 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+
+	negative := value < 0
+	digits := strconv.FormatFloat(math.Abs(value), 'f', -1, 64)
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	for len(fraction) < p.Precision {
+		fraction += "0"
+	}
+	kept, remainder := fraction[:p.Precision], fraction[p.Precision:]
+
+	units, err := strconv.ParseInt(whole+kept, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	if p.roundUp(units, remainder) {
+		units++
+	}
+	if negative {
+		units = -units
+	}
+
+	return Amount(units)
+}
+
+// roundUp decides whether the discarded digits push the kept units up by one
+func (p Policy) roundUp(units int64, remainder string) bool {
+	remainder = strings.TrimRight(remainder, "0")
+	if remainder == "" {
+		return false
+	}
+
+	switch p.Rounding {
+	case RoundDown:
+		return false
+	case RoundHalfEven:
+		if remainder == "5" {
+			return units%2 != 0
+		}
+		return remainder[0] >= '5'
+	default:
+		return remainder[0] >= '5'
+	}
+}
+
+// ToFloat converts minor units back to a float for the response boundary
+func (p Policy) ToFloat(amount Amount) float64 {
+	return float64(amount) / math.Pow10(p.Precision)
+}
+
+// Sum adds float amounts in minor units and returns the rounded float total
+func (p Policy) Sum(values ...float64) float64 {
+	var total Amount
+	for _, value := range values {
+		total = total.Add(p.FromFloat(value))
+	}
+	return p.ToFloat(total)
+}
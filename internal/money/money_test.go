@@ -0,0 +1,67 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromFloatRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		value    float64
+		expected Amount
+	}{
+		{name: "half up rounds half away from zero", policy: Policy{2, RoundHalfUp}, value: 1.005, expected: 101},
+		{name: "half up negative", policy: Policy{2, RoundHalfUp}, value: -1.005, expected: -101},
+		{name: "half even rounds to even", policy: Policy{2, RoundHalfEven}, value: 1.005, expected: 100},
+		{name: "half even rounds odd up", policy: Policy{2, RoundHalfEven}, value: 1.015, expected: 102},
+		{name: "half even above half", policy: Policy{2, RoundHalfEven}, value: 1.0051, expected: 101},
+		{name: "down truncates", policy: Policy{2, RoundDown}, value: 1.009, expected: 100},
+		{name: "zero precision", policy: Policy{0, RoundHalfUp}, value: 15499.5, expected: 15500},
+		{name: "exact value unchanged", policy: Policy{2, RoundHalfUp}, value: 12.34, expected: 1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.FromFloat(tt.value))
+		})
+	}
+}
+
+func TestSumHasNoDrift(t *testing.T) {
+	policy := DefaultPolicy()
+
+	const count = 100000
+	values := make([]float64, count)
+	var naive float64
+	for i := range values {
+		values[i] = 0.1
+		naive += 0.1
+	}
+
+	// The naive float sum drifts away from the exact decimal total
+	assert.NotEqual(t, 10000.0, naive)
+	assert.Equal(t, 10000.0, policy.Sum(values...))
+
+	var total Amount
+	for i := 0; i < count; i++ {
+		total = total.Add(policy.FromFloat(0.01).Mul(3))
+	}
+	assert.Equal(t, Amount(300000), total)
+	assert.Equal(t, 3000.0, policy.ToFloat(total))
+}
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy(0, "HALF_EVEN")
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Precision: 0, Rounding: RoundHalfEven}, policy)
+
+	_, err = NewPolicy(2, "bankers")
+	assert.Error(t, err)
+
+	_, err = NewPolicy(-1, "half_up")
+	assert.Error(t, err)
+}
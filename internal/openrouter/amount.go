@@ -0,0 +1,51 @@
+package openrouter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// amountPattern captures a number that may use either "," or "." as its decimal or thousands
+// separator and group digits with spaces or apostrophes, optionally quoted, such as 1234.56,
+// "1,234.56", "1.234,56" or "1 234,56"
+const amountPattern = `"?(\d(?:[\d.,' ]*\d)?)`
+
+// parseAmount converts a receipt amount written in US ("1,234.56") or European ("1.234,56")
+// notation to a number. When both separators appear, the last one is the decimal separator.
+// A lone separator repeated, or a comma followed by exactly three digits, groups thousands;
+// otherwise it is the decimal separator. Spaces and apostrophes are always thousands separators.
+func parseAmount(value string) (float64, error) {
+	value = strings.NewReplacer(" ", "", "'", "").Replace(strings.TrimSpace(value))
+
+	lastDot := strings.LastIndex(value, ".")
+	lastComma := strings.LastIndex(value, ",")
+	var decimal, thousands string
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			decimal, thousands = ",", "."
+		} else {
+			decimal, thousands = ".", ","
+		}
+	case lastComma >= 0:
+		if strings.Count(value, ",") > 1 || len(value)-lastComma-1 == 3 {
+			thousands = ","
+		} else {
+			decimal = ","
+		}
+	case lastDot >= 0:
+		if strings.Count(value, ".") > 1 {
+			thousands = "."
+		} else {
+			decimal = "."
+		}
+	}
+
+	if thousands != "" {
+		value = strings.ReplaceAll(value, thousands, "")
+	}
+	if decimal != "" {
+		value = strings.Replace(value, decimal, ".", 1)
+	}
+	return strconv.ParseFloat(value, 64)
+}
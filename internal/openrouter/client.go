@@ -35,6 +35,7 @@ type Client struct {
 	apiURL         string
 	httpClient     *http.Client
 	modelID        string
+	maxResponse    int
 	s3Client       *s3.S3
 	supabaseBucket string
 	s3Endpoint     string
@@ -47,6 +48,7 @@ type Config struct {
 	ModelID           string
 	Timeout           time.Duration
 	MaxRetries        int
+	MaxResponseBytes  int // Largest response accepted before parsing, defaults to defaultMaxResponseBytes
 	S3Endpoint        string
 	S3AccessKeyID     string
 	S3AccessKeySecret string
@@ -54,13 +56,17 @@ type Config struct {
 	S3Region          string
 }
 
+// defaultMaxResponseBytes caps model responses, far above any real invoice extraction
+const defaultMaxResponseBytes = 1 << 20
+
 // DefaultConfig returns a default configuration for the OpenRouter client
 func DefaultConfig() *Config {
 	return &Config{
-		ModelID:        "meta-llama/llama-3.2-11b-vision-instruct:free",
-		Timeout:        60 * time.Second,
-		MaxRetries:     3,
-		SupabaseBucket: "invoices",
+		ModelID:          "meta-llama/llama-3.2-11b-vision-instruct:free",
+		Timeout:          60 * time.Second,
+		MaxRetries:       3,
+		MaxResponseBytes: defaultMaxResponseBytes,
+		SupabaseBucket:   "invoices",
 	}
 }
 
@@ -86,10 +92,16 @@ func NewClient(config *Config) *Client {
 		s3Client = s3.New(sess)
 	}
 
+	maxResponse := config.MaxResponseBytes
+	if maxResponse <= 0 {
+		maxResponse = defaultMaxResponseBytes
+	}
+
 	return &Client{
 		apiKey:         config.APIKey,
 		apiURL:         "https://openrouter.ai/api/v1/chat/completions",
 		modelID:        config.ModelID,
+		maxResponse:    maxResponse,
 		s3Client:       s3Client,
 		supabaseBucket: config.SupabaseBucket,
 		s3Endpoint:     config.S3Endpoint,
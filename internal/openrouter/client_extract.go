@@ -2,6 +2,8 @@ package openrouter
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,36 +11,65 @@ import (
 	"time"
 
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
 )
 
 // ExtractInvoiceData extracts structured data from an invoice image
-func (c *Client) ExtractInvoiceData(imageData []byte) (*domain.Invoice, error) {
+func (c *Client) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	invoice, _, err := c.extractInvoiceData(ctx, imageData, false)
+	return invoice, err
+}
+
+// ExtractInvoiceDataInline extracts structured data from an invoice image like ExtractInvoiceData,
+// but sends the image inline as a base64 data URL instead of uploading it, so it is never stored
+// and no S3 configuration is needed
+func (c *Client) ExtractInvoiceDataInline(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	invoice, _, err := c.extractInvoiceData(ctx, imageData, true)
+	return invoice, err
+}
+
+// ExtractInvoiceDataWithRaw extracts structured data from an invoice image like ExtractInvoiceData,
+// also returning the API's raw response body for diagnostics. The raw response is returned
+// whenever one was received, including when it could not be parsed.
+func (c *Client) ExtractInvoiceDataWithRaw(ctx context.Context, imageData []byte) (*domain.Invoice, string, error) {
+	invoice, raw, err := c.extractInvoiceData(ctx, imageData, false)
+	return invoice, string(raw), err
+}
+
+// extractInvoiceData uploads the image, or embeds it in the request when inline is set, asks the
+// model for its invoice data and parses the answer, returning the response body alongside the result.
+// The upload and the API request are abandoned when the context ends.
+func (c *Client) extractInvoiceData(ctx context.Context, imageData []byte, inline bool) (*domain.Invoice, []byte, error) {
 	// Check for required configuration
-	if c.s3Client == nil {
-		return nil, &OpenRouterError{
+	if c.s3Client == nil && !inline {
+		return nil, nil, &OpenRouterError{
 			Op:  "validate_configuration",
-			Err: fmt.Errorf("S3 client is not configured. Please set SUPABASE_S3_ENDPOINT, SUPABASE_ACCESS_KEY_ID, and SUPABASE_ACCESS_KEY_SECRET environment variables"),
+			Err: fmt.Errorf("%w: S3 client is missing. Please set SUPABASE_S3_ENDPOINT, SUPABASE_ACCESS_KEY_ID, and SUPABASE_ACCESS_KEY_SECRET environment variables", domain.ErrServiceNotConfigured),
 		}
 	}
 
 	if c.apiKey == "" {
-		return nil, &OpenRouterError{
+		return nil, nil, &OpenRouterError{
 			Op:  "validate_configuration",
-			Err: fmt.Errorf("OpenRouter API key is not configured. Please set OPENROUTER_API_KEY environment variable"),
+			Err: fmt.Errorf("%w: OpenRouter API key is missing. Please set OPENROUTER_API_KEY environment variable", domain.ErrServiceNotConfigured),
 		}
 	}
 
-	// Generate a unique filename
-	timestamp := time.Now().UnixNano()
-	filename := fmt.Sprintf("invoice_%d.png", timestamp)
-
-	// Upload the image to Supabase
-	imageURL, err := c.UploadImageToSupabase(imageData, filename)
-	if err != nil {
-		return nil, &OpenRouterError{
-			Op:  "upload_image",
-			Err: fmt.Errorf("failed to upload image to Supabase: %w", err),
+	// Send the image inline as a data URL, or upload it to Supabase under a unique filename
+	extension, contentType := imageutil.FileType(imageData)
+	var imageURL string
+	if inline {
+		imageURL = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(imageData)
+	} else {
+		filename := fmt.Sprintf("invoice_%d%s", time.Now().UnixNano(), extension)
+		uploadedURL, err := c.UploadImageToSupabase(ctx, imageData, filename)
+		if err != nil {
+			return nil, nil, &OpenRouterError{
+				Op:  "upload_image",
+				Err: fmt.Errorf("failed to upload image to Supabase: %w", err),
+			}
 		}
+		imageURL = uploadedURL
 	}
 
 	// Create the OpenRouter API request payload
@@ -64,19 +95,27 @@ func (c *Client) ExtractInvoiceData(imageData []byte) (*domain.Invoice, error) {
 - Vendor name
 - Invoice number
 - Invoice date (in YYYY-MM-DD format)
+- Time of purchase (in 24-hour HH:MM format; empty string "" if not shown)
 - Due date (in YYYY-MM-DD format)
 - Line items (including description, details, quantity, unit price, total, and category for each)
 - Subtotal
 - Tax rate percentage
 - Tax amount
+- Tip or gratuity (if any)
+- Service charge (if any)
 - Discount (if any)
 - Total due amount
+- Payment method ("cash", "card", or another method exactly as printed; empty string "" if not shown)
+- Seller tax ID or business registration number, such as an NPWP, VAT ID, GST or ABN number, exactly as printed without its label (empty string "" if not shown)
+- Confidence (a number between 0 and 1 for how confident you are that the extracted values are correct)
+- Alternatives (only for fields you are unsure of, such as a hard to read vendor name or total: other plausible values as strings)
 
 Format your response as a valid JSON object with the following structure:
 {
   "vendor_name": "...",
   "invoice_number": "...",
   "invoice_date": "YYYY-MM-DD",
+  "invoice_time": "HH:MM",
   "due_date": "YYYY-MM-DD",
   "items": [
     {
@@ -91,10 +130,21 @@ Format your response as a valid JSON object with the following structure:
   "subtotal": 0.0,
   "tax_rate_percent": 0.0,
   "tax_amount": 0.0,
+  "tip": 0.0,
+  "service_charge": 0.0,
   "discount": 0.0,
-  "total_due": 0.0
+  "total_due": 0.0,
+  "payment_method": "...",
+  "tax_id": "...",
+  "confidence": 0.0,
+  "alternatives": {
+    "vendor_name": ["..."],
+    "total_due": ["0.0"]
+  }
 }
 
+Only list alternatives for vendor_name, invoice_date, invoice_time, total_due, subtotal, tax_amount, tip, service_charge or payment_method, and only when another reading is plausible. Omit alternatives, or leave it empty, when you are confident of every value.
+
 For each line item, if you can infer the category (e.g. "Food", "Office Supplies", "Travel", etc.) from the description, provide it. If not, leave it as an empty string "".
 
 Do not include any other text in your response, only provide the JSON.`,
@@ -121,6 +171,8 @@ Do not include any other text in your response, only provide the JSON.`,
 	// Create the request payload
 	requestPayload := map[string]interface{}{
 		"model": c.modelID,
+		// Ask OpenRouter to report the request's cost alongside its token counts
+		"usage": map[string]bool{"include": true},
 		"messages": []Message{
 			{
 				Role:    "system",
@@ -136,16 +188,16 @@ Do not include any other text in your response, only provide the JSON.`,
 	// Convert the request payload to JSON
 	requestData, err := json.Marshal(requestPayload)
 	if err != nil {
-		return nil, &OpenRouterError{
+		return nil, nil, &OpenRouterError{
 			Op:  "marshal_request",
 			Err: fmt.Errorf("failed to marshal request payload: %w", err),
 		}
 	}
 
 	// Create the HTTP request
-	req, err := http.NewRequest("POST", c.apiURL, bytes.NewBuffer(requestData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewBuffer(requestData))
 	if err != nil {
-		return nil, &OpenRouterError{
+		return nil, nil, &OpenRouterError{
 			Op:  "create_extract_request",
 			Err: fmt.Errorf("failed to create request: %w", err),
 		}
@@ -159,17 +211,17 @@ Do not include any other text in your response, only provide the JSON.`,
 	// Send the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, &OpenRouterError{
+		return nil, nil, &OpenRouterError{
 			Op:  "send_extract_request",
 			Err: fmt.Errorf("failed to send request: %w", err),
 		}
 	}
 	defer resp.Body.Close()
 
-	// Read the response body
-	respBody, err := io.ReadAll(resp.Body)
+	// Read the response body, one byte past the cap so oversized responses are detected
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, int64(c.maxResponse)+1))
 	if err != nil {
-		return nil, &OpenRouterError{
+		return nil, nil, &OpenRouterError{
 			Op:  "read_response",
 			Err: fmt.Errorf("failed to read response body: %w", err),
 		}
@@ -177,12 +229,16 @@ Do not include any other text in your response, only provide the JSON.`,
 
 	// Check for error status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, &OpenRouterError{
+		return nil, respBody, &OpenRouterError{
 			Op:  "check_api_response",
 			Err: fmt.Errorf("API error: %s - %s", resp.Status, string(respBody)),
 		}
 	}
 
 	// Parse the response and extract the invoice data
-	return c.parseOpenRouterResponse(respBody)
+	invoice, err := c.parseOpenRouterResponse(respBody)
+	if err == nil {
+		invoice.Usage = parseUsage(respBody)
+	}
+	return invoice, respBody, err
 }
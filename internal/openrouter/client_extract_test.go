@@ -0,0 +1,36 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractInvoiceDataInline(t *testing.T) {
+	var requestBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requestBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"vendor_name\":\"Corner Cafe\",\"invoice_date\":\"2025-03-14\",\"total_due\":12.5,\"items\":[]}"}}]}`))
+	}))
+	defer server.Close()
+
+	// Without S3 configured, only inline extraction can reach the model
+	client := NewClient(&Config{APIKey: "sk-or-test", ModelID: "test-model"})
+	client.apiURL = server.URL
+
+	pngHeader := []byte("\x89PNG\r\n\x1a\nimage")
+	_, err := client.ExtractInvoiceData(context.Background(), pngHeader)
+	assert.ErrorContains(t, err, "S3 client is missing")
+
+	invoice, err := client.ExtractInvoiceDataInline(context.Background(), pngHeader)
+	require.NoError(t, err)
+	assert.Equal(t, "Corner Cafe", invoice.VendorName)
+	assert.Contains(t, requestBody, `"url":"data:image/png;base64,iVBORw0KGgppbWFnZQ=="`)
+}
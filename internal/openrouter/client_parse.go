@@ -6,18 +6,98 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 )
 
+// chatMessage is an assistant message from a chat completion. Content is usually a string, but
+// some models return an array of parts or put structured output in a tool or function call.
+type chatMessage struct {
+	Content   json.RawMessage `json:"content"`
+	ToolCalls []struct {
+		Function chatFunctionCall `json:"function"`
+	} `json:"tool_calls"`
+	FunctionCall *chatFunctionCall `json:"function_call"`
+}
+
+// chatFunctionCall is a function call made by the model, with its arguments as a JSON string
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// text returns the text carrying the model's answer: the string content, the concatenated
+// text parts of array content, or else the arguments of the first tool or function call
+func (m chatMessage) text() string {
+	var content string
+	if err := json.Unmarshal(m.Content, &content); err == nil && strings.TrimSpace(content) != "" {
+		return content
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(m.Content, &parts); err == nil {
+		var builder strings.Builder
+		for _, part := range parts {
+			if part.Type == "" || part.Type == "text" || part.Type == "output_text" {
+				builder.WriteString(part.Text)
+			}
+		}
+		if strings.TrimSpace(builder.String()) != "" {
+			return builder.String()
+		}
+	}
+
+	for _, call := range m.ToolCalls {
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			return call.Function.Arguments
+		}
+	}
+	if m.FunctionCall != nil {
+		return m.FunctionCall.Arguments
+	}
+	return ""
+}
+
+// parseUsage returns the token counts and cost reported in a chat completion, or nil when the
+// response has none
+func parseUsage(respBody []byte) *domain.TokenUsage {
+	var response struct {
+		Usage *struct {
+			PromptTokens     int     `json:"prompt_tokens"`
+			CompletionTokens int     `json:"completion_tokens"`
+			TotalTokens      int     `json:"total_tokens"`
+			Cost             float64 `json:"cost"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil || response.Usage == nil {
+		return nil
+	}
+	return &domain.TokenUsage{
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+		Cost:             response.Usage.Cost,
+	}
+}
+
 // parseOpenRouterResponse parses the JSON response from the OpenRouter API
 func (c *Client) parseOpenRouterResponse(respBody []byte) (*domain.Invoice, error) {
+	// Refuse oversized responses rather than spend time and memory parsing them
+	if len(respBody) > c.maxResponse {
+		return nil, &OpenRouterError{
+			Op:  "check_response_size",
+			Err: fmt.Errorf("response exceeds the %d byte limit", c.maxResponse),
+		}
+	}
+
 	// Define the response structure
 	type Choice struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
+		Message chatMessage `json:"message"`
 	}
 
 	type Response struct {
@@ -41,20 +121,36 @@ func (c *Client) parseOpenRouterResponse(respBody []byte) (*domain.Invoice, erro
 		}
 	}
 
-	// Get the content from the first choice
-	content := response.Choices[0].Message.Content
+	// Get the content from the first choice that has any
+	var content string
+	for _, choice := range response.Choices {
+		if content = choice.Message.text(); content != "" {
+			break
+		}
+	}
+	if content == "" {
+		return nil, &OpenRouterError{
+			Op:  "check_response_content",
+			Err: fmt.Errorf("no content in response choices"),
+		}
+	}
 
 	// Try to parse the content as JSON directly
 	var invoiceDTO struct {
-		VendorName     string  `json:"vendor_name"`
-		InvoiceNumber  string  `json:"invoice_number"`
-		InvoiceDate    string  `json:"invoice_date"`
-		DueDate        string  `json:"due_date"`
-		Subtotal       float64 `json:"subtotal"`
-		TaxRatePercent float64 `json:"tax_rate_percent"`
-		TaxAmount      float64 `json:"tax_amount"`
-		Discount       float64 `json:"discount"`
-		TotalDue       float64 `json:"total_due"`
+		VendorName     string                   `json:"vendor_name"`
+		InvoiceNumber  string                   `json:"invoice_number"`
+		InvoiceDate    string                   `json:"invoice_date"`
+		InvoiceTime    string                   `json:"invoice_time"`
+		DueDate        string                   `json:"due_date"`
+		Subtotal       float64                  `json:"subtotal"`
+		TaxRatePercent float64                  `json:"tax_rate_percent"`
+		TaxAmount      float64                  `json:"tax_amount"`
+		Tip            float64                  `json:"tip"`
+		ServiceCharge  float64                  `json:"service_charge"`
+		Discount       float64                  `json:"discount"`
+		TotalDue       float64                  `json:"total_due"`
+		TaxID          string                   `json:"tax_id"`
+		Alternatives   map[string][]interface{} `json:"alternatives"`
 		Items          []struct {
 			Description string   `json:"description"`
 			Details     []string `json:"details"`
@@ -71,6 +167,7 @@ func (c *Client) parseOpenRouterResponse(respBody []byte) (*domain.Invoice, erro
 		invoice := domain.NewInvoice()
 		invoice.VendorName = invoiceDTO.VendorName
 		invoice.InvoiceNumber = invoiceDTO.InvoiceNumber
+		invoice.InvoiceTime = invoiceDTO.InvoiceTime
 
 		// Parse dates
 		if invoiceDTO.InvoiceDate != "" {
@@ -90,8 +187,12 @@ func (c *Client) parseOpenRouterResponse(respBody []byte) (*domain.Invoice, erro
 		invoice.Subtotal = invoiceDTO.Subtotal
 		invoice.TaxRatePercent = invoiceDTO.TaxRatePercent
 		invoice.TaxAmount = invoiceDTO.TaxAmount
+		invoice.Tip = invoiceDTO.Tip
+		invoice.ServiceCharge = invoiceDTO.ServiceCharge
 		invoice.Discount = invoiceDTO.Discount
 		invoice.TotalDue = invoiceDTO.TotalDue
+		invoice.TaxID = domain.NormalizeTaxID(invoiceDTO.TaxID)
+		invoice.Alternatives = parseAlternatives(invoiceDTO.Alternatives)
 
 		// Convert line items
 		for _, item := range invoiceDTO.Items {
@@ -107,34 +208,41 @@ func (c *Client) parseOpenRouterResponse(respBody []byte) (*domain.Invoice, erro
 		return invoice, nil
 	}
 
-	// If direct JSON parsing fails, try to extract JSON using regex
+	// If direct JSON parsing fails, try to extract the JSON object from the surrounding text
 	log.Printf("Failed to parse response as JSON directly: %v", err)
-	log.Printf("Trying to extract JSON using regex")
+	log.Printf("Trying to extract JSON from the response text")
 	return c.extractJSONWithRegex(content)
 }
 
-// extractJSONWithRegex tries to extract JSON from text using regex
+// extractJSONWithRegex tries to extract JSON from text, falling back to regex for individual fields
 func (c *Client) extractJSONWithRegex(content string) (*domain.Invoice, error) {
 	// Replace all occurrences of ```json and ``` around the JSON content
 	content = regexp.MustCompile("```json\\s*").ReplaceAllString(content, "")
 	content = regexp.MustCompile("```\\s*").ReplaceAllString(content, "")
 
-	// Try to find a JSON object in the content
-	jsonRegex := regexp.MustCompile(`\{[\s\S]*\}`)
-	jsonMatch := jsonRegex.FindString(content)
-
+	// Try to find a JSON object in the content, closing it if the response was truncated
+	jsonMatch := findJSONObject(content)
+	if jsonMatch == "" {
+		jsonMatch = closeTruncatedJSON(content)
+	}
 	if jsonMatch != "" {
 		// Try to parse the extracted JSON
 		var invoiceDTO struct {
-			VendorName     string  `json:"vendor_name"`
-			InvoiceNumber  string  `json:"invoice_number"`
-			InvoiceDate    string  `json:"invoice_date"`
-			DueDate        string  `json:"due_date"`
-			Subtotal       float64 `json:"subtotal"`
-			TaxRatePercent float64 `json:"tax_rate_percent"`
-			TaxAmount      float64 `json:"tax_amount"`
-			Discount       float64 `json:"discount"`
-			TotalDue       float64 `json:"total_due"`
+			VendorName     string                   `json:"vendor_name"`
+			InvoiceNumber  string                   `json:"invoice_number"`
+			InvoiceDate    string                   `json:"invoice_date"`
+			InvoiceTime    string                   `json:"invoice_time"`
+			DueDate        string                   `json:"due_date"`
+			Subtotal       float64                  `json:"subtotal"`
+			TaxRatePercent float64                  `json:"tax_rate_percent"`
+			TaxAmount      float64                  `json:"tax_amount"`
+			Tip            float64                  `json:"tip"`
+			ServiceCharge  float64                  `json:"service_charge"`
+			Discount       float64                  `json:"discount"`
+			TotalDue       float64                  `json:"total_due"`
+			PaymentMethod  string                   `json:"payment_method"`
+			TaxID          string                   `json:"tax_id"`
+			Alternatives   map[string][]interface{} `json:"alternatives"`
 			Items          []struct {
 				Description string   `json:"description"`
 				Details     []string `json:"details"`
@@ -149,6 +257,7 @@ func (c *Client) extractJSONWithRegex(content string) (*domain.Invoice, error) {
 			invoice := domain.NewInvoice()
 			invoice.VendorName = invoiceDTO.VendorName
 			invoice.InvoiceNumber = invoiceDTO.InvoiceNumber
+			invoice.InvoiceTime = invoiceDTO.InvoiceTime
 
 			// Parse dates
 			if invoiceDTO.InvoiceDate != "" {
@@ -168,8 +277,13 @@ func (c *Client) extractJSONWithRegex(content string) (*domain.Invoice, error) {
 			invoice.Subtotal = invoiceDTO.Subtotal
 			invoice.TaxRatePercent = invoiceDTO.TaxRatePercent
 			invoice.TaxAmount = invoiceDTO.TaxAmount
+			invoice.Tip = invoiceDTO.Tip
+			invoice.ServiceCharge = invoiceDTO.ServiceCharge
 			invoice.Discount = invoiceDTO.Discount
 			invoice.TotalDue = invoiceDTO.TotalDue
+			invoice.PaymentMethod = invoiceDTO.PaymentMethod
+			invoice.TaxID = domain.NormalizeTaxID(invoiceDTO.TaxID)
+			invoice.Alternatives = parseAlternatives(invoiceDTO.Alternatives)
 
 			// Convert line items
 			for _, item := range invoiceDTO.Items {
@@ -218,118 +332,123 @@ func (c *Client) extractJSONWithRegex(content string) (*domain.Invoice, error) {
 		}
 	}
 
+	// Extract the seller's tax ID
+	taxIDRegex := regexp.MustCompile(`"tax_id"\s*:\s*"([^"]+)"`)
+	if matches := taxIDRegex.FindStringSubmatch(content); len(matches) > 1 {
+		invoice.TaxID = domain.NormalizeTaxID(matches[1])
+	}
+
 	// Extract subtotal
-	subtotalRegex := regexp.MustCompile(`"subtotal"\s*:\s*(\d+\.?\d*)`)
+	subtotalRegex := regexp.MustCompile(`"subtotal"\s*:\s*` + amountPattern)
 	if matches := subtotalRegex.FindStringSubmatch(content); len(matches) > 1 {
-		if subtotal, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if subtotal, err := parseAmount(matches[1]); err == nil {
 			invoice.Subtotal = subtotal
 		}
 	}
 
 	// Extract tax rate
-	taxRateRegex := regexp.MustCompile(`"tax_rate_percent"\s*:\s*(\d+\.?\d*)`)
+	taxRateRegex := regexp.MustCompile(`"tax_rate_percent"\s*:\s*` + amountPattern)
 	if matches := taxRateRegex.FindStringSubmatch(content); len(matches) > 1 {
-		if taxRate, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if taxRate, err := parseAmount(matches[1]); err == nil {
 			invoice.TaxRatePercent = taxRate
 		}
 	}
 
 	// Extract tax amount
-	taxAmountRegex := regexp.MustCompile(`"tax_amount"\s*:\s*(\d+\.?\d*)`)
+	taxAmountRegex := regexp.MustCompile(`"tax_amount"\s*:\s*` + amountPattern)
 	if matches := taxAmountRegex.FindStringSubmatch(content); len(matches) > 1 {
-		if taxAmount, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if taxAmount, err := parseAmount(matches[1]); err == nil {
 			invoice.TaxAmount = taxAmount
 		}
 	}
 
+	// Extract tip and service charge
+	tipRegex := regexp.MustCompile(`"tip"\s*:\s*` + amountPattern)
+	if matches := tipRegex.FindStringSubmatch(content); len(matches) > 1 {
+		if tip, err := parseAmount(matches[1]); err == nil {
+			invoice.Tip = tip
+		}
+	}
+	serviceChargeRegex := regexp.MustCompile(`"service_charge"\s*:\s*` + amountPattern)
+	if matches := serviceChargeRegex.FindStringSubmatch(content); len(matches) > 1 {
+		if serviceCharge, err := parseAmount(matches[1]); err == nil {
+			invoice.ServiceCharge = serviceCharge
+		}
+	}
+
 	// Extract discount
-	discountRegex := regexp.MustCompile(`"discount"\s*:\s*(\d+\.?\d*)`)
+	discountRegex := regexp.MustCompile(`"discount"\s*:\s*` + amountPattern)
 	if matches := discountRegex.FindStringSubmatch(content); len(matches) > 1 {
-		if discount, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if discount, err := parseAmount(matches[1]); err == nil {
 			invoice.Discount = discount
 		}
 	}
 
 	// Extract total due
-	totalRegex := regexp.MustCompile(`"total_due"\s*:\s*(\d+\.?\d*)`)
+	totalRegex := regexp.MustCompile(`"total_due"\s*:\s*` + amountPattern)
 	if matches := totalRegex.FindStringSubmatch(content); len(matches) > 1 {
-		if total, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if total, err := parseAmount(matches[1]); err == nil {
 			invoice.TotalDue = total
 		}
 	}
 
-	// Extract line items using a more robust approach
-	// Use a regex that can handle multiline content with the DOTALL flag
-	itemsPattern := `"items"\s*:\s*\[\s*([\s\S]*?)\s*\]`
-	itemsRegex := regexp.MustCompile(itemsPattern)
-
-	if itemsMatch := itemsRegex.FindStringSubmatch(content); len(itemsMatch) > 1 {
-		itemsContent := itemsMatch[1]
-
-		// Find each item object using a pattern that can handle multiline content
-		itemPattern := `\{\s*([\s\S]*?)\s*\}`
-		itemRegex := regexp.MustCompile(itemPattern)
-		itemMatches := itemRegex.FindAllStringSubmatch(itemsContent, -1)
-
-		for _, itemMatch := range itemMatches {
-			if len(itemMatch) > 0 {
-				itemContent := itemMatch[0] // Get the full item object including braces
-
-				// Create a new line item
-				lineItem := domain.LineItem{}
+	// Extract line items by scanning the items array for its top-level objects
+	if itemsStart := itemsArrayRegex.FindStringIndex(content); itemsStart != nil {
+		for _, itemContent := range arrayObjects(content[itemsStart[1]-1:]) {
+			// Create a new line item
+			lineItem := domain.LineItem{}
 
-				// Extract description
-				descRegex := regexp.MustCompile(`"description"\s*:\s*"([^"]+)"`)
-				if descMatches := descRegex.FindStringSubmatch(itemContent); len(descMatches) > 1 {
-					lineItem.Description = descMatches[1]
-				}
+			// Extract description
+			descRegex := regexp.MustCompile(`"description"\s*:\s*"([^"]+)"`)
+			if descMatches := descRegex.FindStringSubmatch(itemContent); len(descMatches) > 1 {
+				lineItem.Description = descMatches[1]
+			}
 
-				// Extract quantity
-				qtyRegex := regexp.MustCompile(`"quantity"\s*:\s*(\d+\.?\d*)`)
-				if qtyMatches := qtyRegex.FindStringSubmatch(itemContent); len(qtyMatches) > 1 {
-					if qty, err := strconv.ParseFloat(qtyMatches[1], 64); err == nil {
-						lineItem.Quantity = qty
-					}
+			// Extract quantity
+			qtyRegex := regexp.MustCompile(`"quantity"\s*:\s*` + amountPattern)
+			if qtyMatches := qtyRegex.FindStringSubmatch(itemContent); len(qtyMatches) > 1 {
+				if qty, err := parseAmount(qtyMatches[1]); err == nil {
+					lineItem.Quantity = qty
 				}
+			}
 
-				// Extract unit price
-				priceRegex := regexp.MustCompile(`"unit_price"\s*:\s*(\d+\.?\d*)`)
-				if priceMatches := priceRegex.FindStringSubmatch(itemContent); len(priceMatches) > 1 {
-					if price, err := strconv.ParseFloat(priceMatches[1], 64); err == nil {
-						lineItem.UnitPrice = price
-					}
+			// Extract unit price
+			priceRegex := regexp.MustCompile(`"unit_price"\s*:\s*` + amountPattern)
+			if priceMatches := priceRegex.FindStringSubmatch(itemContent); len(priceMatches) > 1 {
+				if price, err := parseAmount(priceMatches[1]); err == nil {
+					lineItem.UnitPrice = price
 				}
+			}
 
-				// Extract total
-				itemTotalRegex := regexp.MustCompile(`"total"\s*:\s*(\d+\.?\d*)`)
-				if totalMatches := itemTotalRegex.FindStringSubmatch(itemContent); len(totalMatches) > 1 {
-					if total, err := strconv.ParseFloat(totalMatches[1], 64); err == nil {
-						lineItem.Total = total
-					}
+			// Extract total
+			itemTotalRegex := regexp.MustCompile(`"total"\s*:\s*` + amountPattern)
+			if totalMatches := itemTotalRegex.FindStringSubmatch(itemContent); len(totalMatches) > 1 {
+				if total, err := parseAmount(totalMatches[1]); err == nil {
+					lineItem.Total = total
 				}
+			}
 
-				// Extract details array using a pattern that can handle multiline content
-				detailsPattern := `"details"\s*:\s*\[\s*([\s\S]*?)\s*\]`
-				detailsRegex := regexp.MustCompile(detailsPattern)
+			// Extract details array using a pattern that can handle multiline content
+			detailsPattern := `"details"\s*:\s*\[\s*([\s\S]*?)\s*\]`
+			detailsRegex := regexp.MustCompile(detailsPattern)
 
-				if detailsMatch := detailsRegex.FindStringSubmatch(itemContent); len(detailsMatch) > 1 {
-					detailsContent := detailsMatch[1]
+			if detailsMatch := detailsRegex.FindStringSubmatch(itemContent); len(detailsMatch) > 1 {
+				detailsContent := detailsMatch[1]
 
-					// Find each detail string
-					detailRegex := regexp.MustCompile(`"([^"]+)"`)
-					detailMatches := detailRegex.FindAllStringSubmatch(detailsContent, -1)
+				// Find each detail string
+				detailRegex := regexp.MustCompile(`"([^"]+)"`)
+				detailMatches := detailRegex.FindAllStringSubmatch(detailsContent, -1)
 
-					for _, detailMatch := range detailMatches {
-						if len(detailMatch) > 1 {
-							lineItem.Details = append(lineItem.Details, detailMatch[1])
-						}
+				for _, detailMatch := range detailMatches {
+					if len(detailMatch) > 1 {
+						lineItem.Details = append(lineItem.Details, detailMatch[1])
 					}
 				}
+			}
 
-				// Add the line item to the invoice if it has at least a description
-				if lineItem.Description != "" {
-					invoice.AddLineItem(lineItem)
-				}
+			// Add the line item to the invoice if it has at least a description
+			if lineItem.Description != "" {
+				invoice.AddLineItem(lineItem)
 			}
 		}
 	}
@@ -344,3 +463,144 @@ func (c *Client) extractJSONWithRegex(content string) (*domain.Invoice, error) {
 
 	return invoice, nil
 }
+
+// itemsArrayRegex finds the start of the items array, ending at its opening bracket
+var itemsArrayRegex = regexp.MustCompile(`"items"\s*:\s*\[`)
+
+// maxJSONObjectCandidates bounds how many top-level objects findJSONObject validates
+// before giving up on a response
+const maxJSONObjectCandidates = 8
+
+// alternativeFields maps the extraction fields the model may offer alternatives for to receipt fields
+var alternativeFields = map[string]string{
+	"vendor_name":    "merchant",
+	"invoice_date":   "date",
+	"invoice_time":   "time",
+	"total_due":      "total",
+	"subtotal":       "subtotal",
+	"tax_amount":     "tax",
+	"tip":            "tip",
+	"service_charge": "serviceCharge",
+	"payment_method": "paymentMethod",
+}
+
+// parseAlternatives converts the model's alternative values to strings keyed by receipt field,
+// dropping unknown fields and empty values. It returns nil when no alternatives remain.
+func parseAlternatives(raw map[string][]interface{}) map[string][]string {
+	var alternatives map[string][]string
+	for field, values := range raw {
+		receiptField, ok := alternativeFields[field]
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			var text string
+			switch v := value.(type) {
+			case string:
+				text = strings.TrimSpace(v)
+			case float64:
+				text = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			if text == "" {
+				continue
+			}
+			if alternatives == nil {
+				alternatives = map[string][]string{}
+			}
+			alternatives[receiptField] = append(alternatives[receiptField], text)
+		}
+	}
+	return alternatives
+}
+
+// findJSONObject returns the first top-level balanced JSON object in content that parses, or "".
+// Objects nested inside another candidate are never returned on their own.
+func findJSONObject(content string) string {
+	offset := 0
+	for attempt := 0; attempt < maxJSONObjectCandidates; attempt++ {
+		start := strings.IndexByte(content[offset:], '{')
+		if start < 0 {
+			return ""
+		}
+		start += offset
+
+		end := balancedEnd(content, start)
+		if end < 0 {
+			// Every later brace is nested inside this unclosed object
+			return ""
+		}
+		if candidate := content[start:end]; json.Valid([]byte(candidate)) {
+			return candidate
+		}
+		offset = end
+	}
+	return ""
+}
+
+// balancedEnd returns the index just past the JSON object or array opening at content[start],
+// ignoring brackets inside strings, or -1 if it is never closed. It runs in linear time.
+func balancedEnd(content string, start int) int {
+	depth := 0
+	inString := false
+	for i := start; i < len(content); i++ {
+		ch := content[i]
+		if inString {
+			switch ch {
+			case '\\':
+				i++ // Skip the escaped character
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// arrayObjects returns the complete objects directly inside the array opening at content[0].
+// An unclosed array, as in a truncated response, yields the objects completed before the cut.
+func arrayObjects(content string) []string {
+	var objects []string
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			// Skip over strings so brackets inside them are ignored
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return objects
+			}
+		case '{':
+			if depth != 1 {
+				continue
+			}
+			end := balancedEnd(content, i)
+			if end < 0 {
+				return objects
+			}
+			objects = append(objects, content[i:end])
+			i = end - 1
+		}
+	}
+	return objects
+}
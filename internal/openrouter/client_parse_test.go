@@ -0,0 +1,228 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenRouterResponseShapes(t *testing.T) {
+	const invoiceJSON = `{\"vendor_name\":\"Corner Cafe\",\"invoice_date\":\"2025-03-14\",\"invoice_time\":\"18:05\",\"total_due\":12.5,` +
+		`\"items\":[{\"description\":\"Sandwich\",\"quantity\":1,\"unit_price\":12.5,\"total\":12.5}]}`
+
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "string content",
+			response: `{"choices":[{"message":{"content":"` + invoiceJSON + `"}}]}`,
+		},
+		{
+			name: "array content",
+			response: `{"choices":[{"message":{"content":[` +
+				`{"type":"text","text":"` + invoiceJSON + `"},` +
+				`{"type":"image_url","image_url":{"url":"https://example.com/receipt.png"}}]}}]}`,
+		},
+		{
+			name: "tool call",
+			response: `{"choices":[{"message":{"content":null,"tool_calls":[` +
+				`{"id":"call_1","type":"function","function":{"name":"record_invoice","arguments":"` + invoiceJSON + `"}}]}}]}`,
+		},
+		{
+			name:     "legacy function call",
+			response: `{"choices":[{"message":{"content":"","function_call":{"name":"record_invoice","arguments":"` + invoiceJSON + `"}}}]}`,
+		},
+		{
+			name: "first choice empty",
+			response: `{"choices":[{"message":{"content":""}},` +
+				`{"message":{"content":"` + invoiceJSON + `"}}]}`,
+		},
+	}
+
+	client := NewClient(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice, err := client.parseOpenRouterResponse([]byte(tt.response))
+			require.NoError(t, err)
+
+			assert.Equal(t, "Corner Cafe", invoice.VendorName)
+			assert.Equal(t, "2025-03-14", invoice.InvoiceDate.Format("2006-01-02"))
+			assert.Equal(t, "18:05", invoice.InvoiceTime)
+			assert.InDelta(t, 12.5, invoice.TotalDue, 0.001)
+			require.Len(t, invoice.Items, 1)
+			assert.Equal(t, "Sandwich", invoice.Items[0].Description)
+		})
+	}
+
+	t.Run("no content in any choice", func(t *testing.T) {
+		_, err := client.parseOpenRouterResponse([]byte(`{"choices":[{"message":{"content":null}}]}`))
+		assert.ErrorContains(t, err, "no content")
+	})
+}
+
+func TestParseOpenRouterResponseTipAndServiceCharge(t *testing.T) {
+	const restaurantJSON = `{"vendor_name":"Trattoria Roma","subtotal":40,"tax_amount":3.2,"tip":8,"service_charge":2,"total_due":53.2,` +
+		`"items":[{"description":"Margherita","quantity":2,"unit_price":15,"total":30}]}`
+
+	client := NewClient(nil)
+	for name, content := range map[string]string{
+		"plain JSON":          restaurantJSON,
+		"JSON in surrounding": "Here is the receipt:\n```json\n" + restaurantJSON + "\n```",
+	} {
+		t.Run(name, func(t *testing.T) {
+			message, err := json.Marshal(content)
+			require.NoError(t, err)
+
+			invoice, err := client.parseOpenRouterResponse([]byte(`{"choices":[{"message":{"content":` + string(message) + `}}]}`))
+			require.NoError(t, err)
+			assert.InDelta(t, 8, invoice.Tip, 0.001)
+			assert.InDelta(t, 2, invoice.ServiceCharge, 0.001)
+			assert.InDelta(t, 53.2, invoice.TotalDue, 0.001)
+		})
+	}
+}
+
+func TestParseOpenRouterResponseBounds(t *testing.T) {
+	t.Run("oversized response is rejected", func(t *testing.T) {
+		client := NewClient(&Config{MaxResponseBytes: 1024})
+		response := `{"choices":[{"message":{"content":"` + strings.Repeat("x", 2048) + `"}}]}`
+
+		_, err := client.parseOpenRouterResponse([]byte(response))
+		assert.ErrorContains(t, err, "1024 byte limit")
+	})
+
+	t.Run("large malformed content fails promptly", func(t *testing.T) {
+		// Unclosed objects and brackets nested thousands deep, as from a runaway model
+		content := `Here is the invoice: {"vendor_name": ` + strings.Repeat(`{"items": [{"description": "x", `, 20000)
+		response, err := json.Marshal(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": content}}},
+		})
+		require.NoError(t, err)
+		require.Less(t, len(response), defaultMaxResponseBytes)
+
+		start := time.Now()
+		_, err = NewClient(nil).parseOpenRouterResponse(response)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("items survive a truncated response", func(t *testing.T) {
+		content := `{"vendor_name": "Corner Cafe", "items": [{"description": "Tea {hot}", "quantity": 1, "total": 3}, {"description": "Cake", "qua`
+		invoice, err := NewClient(nil).extractJSONWithRegex(content)
+		require.NoError(t, err)
+		assert.Equal(t, "Corner Cafe", invoice.VendorName)
+		require.Len(t, invoice.Items, 1)
+		assert.Equal(t, "Tea {hot}", invoice.Items[0].Description)
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{value: "1,234.56", want: 1234.56},
+		{value: "1.234,56", want: 1234.56},
+		{value: "1234.56", want: 1234.56},
+		{value: "1234,56", want: 1234.56},
+		{value: "1,234,567.89", want: 1234567.89},
+		{value: "1.234.567,89", want: 1234567.89},
+		{value: "1 234,56", want: 1234.56},
+		{value: "1'234.56", want: 1234.56},
+		{value: "1,234", want: 1234},
+		{value: "1.234.567", want: 1234567},
+		{value: "12.5", want: 12.5},
+		{value: "3", want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseAmount(tt.value)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 0.0001)
+		})
+	}
+
+	t.Run("European amounts in a malformed response", func(t *testing.T) {
+		content := `{"vendor_name": "Bäckerei Schmidt", "subtotal": "1.234,56", "total_due": "1.234,56", ` +
+			`"items": [{"description": "Torte", "quantity": 1, "unit_price": "1.234,56", "total": "1.234,56"}`
+		invoice, err := NewClient(nil).extractJSONWithRegex(content)
+		require.NoError(t, err)
+		assert.InDelta(t, 1234.56, invoice.TotalDue, 0.0001)
+		require.Len(t, invoice.Items, 1)
+		assert.InDelta(t, 1, invoice.Items[0].Quantity, 0.0001)
+		assert.InDelta(t, 1234.56, invoice.Items[0].UnitPrice, 0.0001)
+	})
+}
+
+func TestParseOpenRouterResponseTruncated(t *testing.T) {
+	const truncated = `{"vendor_name":"Fresh Mart","invoice_date":"2025-04-02","total_due":9.75,"items":[` +
+		`{"description":"Apples","details":["1kg"],"quantity":1,"unit_price":3.5,"total":3.5},` +
+		`{"description":"Bread, sliced","quantity":1,"unit_price":2.25,"total":2.25},` +
+		`{"description":"Milk","details":["2%`
+
+	body, err := json.Marshal(map[string]interface{}{
+		"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": truncated}}},
+	})
+	require.NoError(t, err)
+
+	invoice, err := NewClient(nil).parseOpenRouterResponse(body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fresh Mart", invoice.VendorName)
+	assert.InDelta(t, 9.75, invoice.TotalDue, 0.001)
+	require.Len(t, invoice.Items, 2)
+	assert.Equal(t, "Apples", invoice.Items[0].Description)
+	assert.Equal(t, []string{"1kg"}, invoice.Items[0].Details)
+	assert.Equal(t, "Bread, sliced", invoice.Items[1].Description)
+
+	t.Run("cut inside a top-level value", func(t *testing.T) {
+		assert.Equal(t, `{"vendor_name":"Fresh Mart"}`, closeTruncatedJSON(`{"vendor_name":"Fresh Mart","total_due":9.`))
+		assert.Equal(t, `{"items":[]}`, closeTruncatedJSON(`{"items":[{"description":"Apples","quantity":1`))
+		assert.Empty(t, closeTruncatedJSON(`{"vendor_name":"Fresh Mart"}`))
+	})
+}
+
+func TestParseOpenRouterResponseAlternatives(t *testing.T) {
+	const content = `{"vendor_name":"Corner Cafe","total_due":12.5,"items":[],` +
+		`"alternatives":{"vendor_name":["Comer Cafe"," "],"total_due":[12.8,"18.5"],"notes":["ignored"]}}`
+
+	body, err := json.Marshal(map[string]interface{}{
+		"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": content}}},
+	})
+	require.NoError(t, err)
+
+	invoice, err := NewClient(nil).parseOpenRouterResponse(body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"merchant": {"Comer Cafe"},
+		"total":    {"12.8", "18.5"},
+	}, invoice.Alternatives)
+
+	t.Run("absent when the model is confident", func(t *testing.T) {
+		assert.Nil(t, parseAlternatives(nil))
+		assert.Nil(t, parseAlternatives(map[string][]interface{}{"vendor_name": {""}}))
+	})
+}
+
+func TestParseOpenRouterResponseTaxID(t *testing.T) {
+	parse := func(content string) string {
+		body, err := json.Marshal(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": content}}},
+		})
+		require.NoError(t, err)
+		invoice, err := NewClient(nil).parseOpenRouterResponse(body)
+		require.NoError(t, err)
+		return invoice.TaxID
+	}
+
+	assert.Equal(t, "01.234.567.8-901.000", parse(`{"vendor_name":"PT Sumber Makmur","tax_id":" 01.234.567.8-901.000","total_due":55000}`))
+	assert.Empty(t, parse(`{"vendor_name":"Corner Cafe","total_due":12.5}`))
+
+	// The field pattern still finds it when the JSON is broken
+	assert.Equal(t, "GB123456789", parse(`Here you go: "vendor_name": "Tea Room", "tax_id": "gb123456789", "total_due": 9.5,,`))
+}
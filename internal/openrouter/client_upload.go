@@ -2,20 +2,23 @@ package openrouter
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
 )
 
 // UploadImageToSupabase uploads an image to Supabase S3-compatible storage and returns the public URL
-func (c *Client) UploadImageToSupabase(imageData []byte, filename string) (string, error) {
+func (c *Client) UploadImageToSupabase(ctx context.Context, imageData []byte, filename string) (string, error) {
 	// Check if S3 client is configured
 	if c.s3Client == nil {
 		return "", &OpenRouterError{
 			Op:  "check_s3_config",
-			Err: fmt.Errorf("S3 client is not configured. Please check SUPABASE_S3_ENDPOINT, SUPABASE_ACCESS_KEY_ID, and SUPABASE_ACCESS_KEY_SECRET"),
+			Err: fmt.Errorf("%w: S3 client is missing. Please check SUPABASE_S3_ENDPOINT, SUPABASE_ACCESS_KEY_ID, and SUPABASE_ACCESS_KEY_SECRET", domain.ErrServiceNotConfigured),
 		}
 	}
 
@@ -23,16 +26,17 @@ func (c *Client) UploadImageToSupabase(imageData []byte, filename string) (strin
 	if c.supabaseBucket == "" {
 		return "", &OpenRouterError{
 			Op:  "check_bucket_config",
-			Err: fmt.Errorf("Supabase bucket is not configured"),
+			Err: fmt.Errorf("%w: Supabase bucket is missing", domain.ErrServiceNotConfigured),
 		}
 	}
 
-	// Upload the file to S3
-	_, err := c.s3Client.PutObject(&s3.PutObjectInput{
+	// Upload the file to S3, labelled with the content type detected from the image data
+	_, contentType := imageutil.FileType(imageData)
+	_, err := c.s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:        aws.String(c.supabaseBucket),
 		Key:           aws.String(filename),
 		Body:          bytes.NewReader(imageData),
-		ContentType:   aws.String("image/png"),
+		ContentType:   aws.String(contentType),
 		ContentLength: aws.Int64(int64(len(imageData))),
 	})
 	if err != nil {
@@ -0,0 +1,106 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// maxRepairCandidates bounds how many cut points closeTruncatedJSON validates before giving up
+const maxRepairCandidates = 8
+
+// closeTruncatedJSON turns a JSON object cut off mid-way, as when the model hits its token limit,
+// into valid JSON by dropping the incomplete trailing value and closing the open brackets. It
+// only cuts before a comma or after an array bracket where every open container besides the
+// outermost object is an array, so a partly written item is dropped rather than kept with missing
+// fields. It returns "" when the content has no object, the object is already closed, or no cut
+// yields valid JSON.
+func closeTruncatedJSON(content string) string {
+	start := strings.IndexByte(content, '{')
+	if start < 0 {
+		return ""
+	}
+
+	var cuts []int
+	depth := 0
+	nestedObjects := 0 // Open objects besides the outermost one
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			i = skipString(content, i)
+		case '{':
+			if depth > 0 {
+				nestedObjects++
+			}
+			depth++
+		case '[':
+			depth++
+			if nestedObjects == 0 {
+				cuts = append(cuts, i+1)
+			}
+		case '}', ']':
+			if content[i] == '}' && depth > 1 {
+				nestedObjects--
+			}
+			depth--
+			if depth == 0 {
+				// The object is complete, so there is nothing to repair
+				return ""
+			}
+			if nestedObjects == 0 {
+				cuts = append(cuts, i+1)
+			}
+		case ',':
+			if nestedObjects == 0 {
+				cuts = append(cuts, i)
+			}
+		}
+	}
+
+	for attempt := 0; attempt < maxRepairCandidates && attempt < len(cuts); attempt++ {
+		truncated := content[start:cuts[len(cuts)-1-attempt]]
+		candidate := truncated + closingBrackets(truncated)
+		if json.Valid([]byte(candidate)) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// closingBrackets returns the brackets closing everything left open in content, innermost first
+func closingBrackets(content string) string {
+	var open []byte
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			i = skipString(content, i)
+		case '{', '[':
+			open = append(open, content[i])
+		case '}', ']':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	closers := make([]byte, len(open))
+	for i, bracket := range open {
+		closer := byte('}')
+		if bracket == '[' {
+			closer = ']'
+		}
+		closers[len(open)-1-i] = closer
+	}
+	return string(closers)
+}
+
+// skipString returns the index of the quote closing the string opening at content[start],
+// or len(content) when the string is never closed
+func skipString(content string, start int) int {
+	i := start + 1
+	for ; i < len(content) && content[i] != '"'; i++ {
+		if content[i] == '\\' {
+			i++
+		}
+	}
+	return i
+}
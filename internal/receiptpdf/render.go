@@ -0,0 +1,148 @@
+package receiptpdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
+)
+
+// thumbnailMaxDimension is the maximum width or height of the embedded receipt image
+const thumbnailMaxDimension = 600
+
+// Render produces a PDF with the receipt's merchant, date, itemized table and totals.
+// When imageData is provided, a thumbnail of the original receipt image is embedded below the totals.
+func Render(receipt *domain.Receipt, imageData []byte) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Receipt - %s", receipt.Merchant), true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// Core fonts are not UTF-8 aware, so translate text into the cp1252 code page
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr(receipt.Merchant), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Date: %s", receipt.Date.Format("2006-01-02")), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Receipt ID: %s", receipt.ID), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Itemized table
+	widths := []float64{80, 20, 25, 30, 25}
+	headers := []string{"Item", "Qty", "Price", "Category", "Amount"}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for i, header := range headers {
+		align := "R"
+		if i == 0 || i == 3 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, header, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	currency := ""
+	for _, item := range receipt.Items {
+		if currency == "" {
+			currency = item.Currency
+		}
+		pdf.CellFormat(widths[0], 7, tr(truncate(item.Name, 45)), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, fmt.Sprintf("%d", item.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 7, fmt.Sprintf("%.2f", item.Price), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, tr(truncate(item.Category, 16)), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[4], 7, fmt.Sprintf("%.2f", item.Price*float64(item.Quantity)), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Totals
+	labelWidth := widths[0] + widths[1] + widths[2] + widths[3]
+	totals := []struct {
+		label  string
+		amount float64
+	}{
+		{"Subtotal", receipt.Subtotal},
+		{"Tax", receipt.Tax},
+		{"Tip", receipt.Tip},
+		{"Service charge", receipt.ServiceCharge},
+		{"Total", receipt.Total},
+	}
+	for _, total := range totals {
+		// Tips and service charges only appear on receipts that have them
+		if total.amount == 0 && (total.label == "Tip" || total.label == "Service charge") {
+			continue
+		}
+		if total.label == "Total" {
+			pdf.SetFont("Helvetica", "B", 11)
+		}
+		label := total.label
+		if currency != "" {
+			label = fmt.Sprintf("%s (%s)", total.label, currency)
+		}
+		pdf.CellFormat(labelWidth, 7, label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, fmt.Sprintf("%.2f", total.amount), "", 1, "R", false, 0, "")
+	}
+
+	// Original image thumbnail
+	if len(imageData) > 0 {
+		if err := addThumbnail(pdf, imageData); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render receipt PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addThumbnail embeds a downscaled copy of the receipt image
+func addThumbnail(pdf *fpdf.Fpdf, imageData []byte) error {
+	thumbnail, err := imageutil.ResizeImage(imageData, &imageutil.ResizeConfig{
+		MaxDimension: thumbnailMaxDimension,
+		Quality:      80,
+		OutputFormat: "jpeg",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create receipt thumbnail: %w", err)
+	}
+
+	// Images already within the limit are returned unchanged, so detect the actual format
+	_, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+	if err != nil {
+		return fmt.Errorf("failed to read receipt thumbnail: %w", err)
+	}
+
+	options := fpdf.ImageOptions{ImageType: format, ReadDpi: false}
+	info := pdf.RegisterImageOptionsReader("receipt-image", options, bytes.NewReader(thumbnail))
+	if pdf.Err() {
+		return fmt.Errorf("failed to embed receipt image: %w", pdf.Error())
+	}
+
+	// Scale the image to a fixed 90mm width, keeping its aspect ratio
+	width := 90.0
+	height := width * info.Height() / info.Width()
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Original receipt", "", 1, "L", false, 0, "")
+	pdf.ImageOptions("receipt-image", pdf.GetX(), pdf.GetY(), width, height, true, options, 0, "")
+
+	return nil
+}
+
+// truncate shortens text to fit a table cell
+func truncate(text string, maxRunes int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxRunes {
+		return string(runes)
+	}
+	return string(runes[:maxRunes-3]) + "..."
+}
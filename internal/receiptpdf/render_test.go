@@ -0,0 +1,104 @@
+package receiptpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+func testReceipt() *domain.Receipt {
+	return &domain.Receipt{
+		ID:       "receipt-1",
+		UserID:   "user-1",
+		Merchant: "Corner Cafe",
+		Date:     domain.FlexibleDate{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		Subtotal: 12.5,
+		Tax:      1.25,
+		Total:    13.75,
+		Items: []domain.ReceiptItem{
+			{Name: "Latte", Quantity: 2, Price: 4.5, Currency: "USD", Category: "Beverages"},
+			{Name: "Croissant", Quantity: 1, Price: 3.5, Currency: "USD", Category: "Food"},
+		},
+	}
+}
+
+// pdfText inflates the compressed content streams of a PDF so its text layer can be inspected
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var text bytes.Buffer
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("stream\n"))
+		if start < 0 {
+			break
+		}
+		rest = rest[start+len("stream\n"):]
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		if reader, err := zlib.NewReader(bytes.NewReader(rest[:end])); err == nil {
+			inflated, _ := io.ReadAll(reader)
+			text.Write(inflated)
+		}
+		rest = rest[end:]
+	}
+	return text.String()
+}
+
+func pngImage(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 80))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 80; y++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestRender(t *testing.T) {
+	t.Run("without image", func(t *testing.T) {
+		data, err := Render(testReceipt(), nil)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, data)
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+		text := pdfText(t, data)
+		assert.Contains(t, text, "Corner Cafe")
+		assert.Contains(t, text, "Croissant")
+		assert.Contains(t, text, "13.75")
+	})
+
+	t.Run("with image thumbnail", func(t *testing.T) {
+		withoutImage, err := Render(testReceipt(), nil)
+		require.NoError(t, err)
+
+		data, err := Render(testReceipt(), pngImage(t))
+		require.NoError(t, err)
+
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+		assert.Contains(t, pdfText(t, data), "Corner Cafe")
+		assert.Contains(t, string(data), "/Subtype /Image")
+		assert.Greater(t, len(data), len(withoutImage))
+	})
+
+	t.Run("invalid image", func(t *testing.T) {
+		_, err := Render(testReceipt(), []byte("not an image"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ActivityRepository defines the interface for activity feed data operations
+type ActivityRepository interface {
+	RecordActivity(ctx context.Context, activity *domain.Activity) error
+	// ListActivity returns a page of the user's activity, newest first
+	ListActivity(ctx context.Context, userID string, page, limit int) (*domain.ActivityFeed, error)
+	// ListDeletedReceiptIDs returns the IDs of the user's receipts deleted after since, in order of deletion
+	ListDeletedReceiptIDs(ctx context.Context, userID string, since time.Time) ([]string, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// BackfillRepository defines the interface for filling derived fields of existing rows
+type BackfillRepository interface {
+	// CountMissing counts rows whose derived field has not been computed
+	CountMissing(ctx context.Context, field string) (int, error)
+	// ListMissing returns up to limit rows whose derived field has not been computed, oldest first
+	ListMissing(ctx context.Context, field string, limit int) ([]domain.DerivedFieldSource, error)
+	// SetDerivedValues stores computed values keyed by row ID, leaving rows that were filled meanwhile unchanged
+	SetDerivedValues(ctx context.Context, field string, values map[string]string) error
+}
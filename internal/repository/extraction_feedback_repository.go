@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ExtractionFeedbackRepository defines the interface for anonymous extraction feedback data operations
+type ExtractionFeedbackRepository interface {
+	RecordExtractionFeedback(ctx context.Context, feedback []domain.ExtractionFeedback) error
+	// GetExtractionAccuracy summarizes the recorded feedback by field and extractor
+	GetExtractionAccuracy(ctx context.Context) ([]domain.FieldAccuracy, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// MerchantRuleRepository defines the interface for merchant rule data operations
+type MerchantRuleRepository interface {
+	// UpsertMerchantRule creates a rule or replaces the category of an existing rule for the merchant
+	UpsertMerchantRule(ctx context.Context, rule *domain.MerchantRule) (*domain.MerchantRule, error)
+	ListMerchantRules(ctx context.Context, userID string) ([]domain.MerchantRule, error)
+	// GetMerchantRuleByMerchant returns nil without error when no rule matches
+	GetMerchantRuleByMerchant(ctx context.Context, userID, merchant string) (*domain.MerchantRule, error)
+	DeleteMerchantRule(ctx context.Context, userID, ruleID string) error
+	// ApplyMerchantRules recategorizes existing items of matching merchants and returns the number updated
+	ApplyMerchantRules(ctx context.Context, userID string) (int64, error)
+}
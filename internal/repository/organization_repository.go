@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// OrganizationRepository defines the interface for organization data operations
+type OrganizationRepository interface {
+	// CreateOrganization creates an organization with its owner as the first member
+	CreateOrganization(ctx context.Context, org *domain.Organization) (*domain.Organization, error)
+	GetOrganizationByID(ctx context.Context, orgID string) (*domain.Organization, error)
+	ListOrganizationsForUser(ctx context.Context, userID string) ([]domain.Organization, error)
+
+	// Membership operations
+	// AddOrganizationMember adds a user to an organization, keeping the existing role if already a member
+	AddOrganizationMember(ctx context.Context, member *domain.OrganizationMember) (*domain.OrganizationMember, error)
+	ListOrganizationMembers(ctx context.Context, orgID string) ([]domain.OrganizationMember, error)
+	// GetMemberRole returns an empty role without error when the user is not a member
+	GetMemberRole(ctx context.Context, orgID, userID string) (string, error)
+}
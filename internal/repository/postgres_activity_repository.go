@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// PostgresActivityRepository implements ActivityRepository using PostgreSQL
+type PostgresActivityRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresActivityRepository creates a new PostgreSQL activity repository
+func NewPostgresActivityRepository(db *pgxpool.Pool) ActivityRepository {
+	return &PostgresActivityRepository{db: db}
+}
+
+// RecordActivity stores an activity entry, setting its ID and creation time
+func (r *PostgresActivityRepository) RecordActivity(ctx context.Context, activity *domain.Activity) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO activity (user_id, action, receipt_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, activity.UserID, activity.Action, activity.ReceiptID).Scan(&activity.ID, &activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+	return nil
+}
+
+// ListActivity retrieves a page of the user's activity, newest first
+func (r *PostgresActivityRepository) ListActivity(ctx context.Context, userID string, page, limit int) (*domain.ActivityFeed, error) {
+	feed := &domain.ActivityFeed{
+		Data:       []domain.Activity{},
+		Pagination: domain.Pagination{CurrentPage: page, Limit: limit},
+	}
+
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM activity WHERE user_id = $1`, userID).Scan(&feed.Pagination.TotalItems); err != nil {
+		return nil, fmt.Errorf("failed to count activity: %w", err)
+	}
+	feed.Pagination.TotalPages = int(math.Ceil(float64(feed.Pagination.TotalItems) / float64(limit)))
+	if feed.Pagination.TotalItems == 0 {
+		return feed, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, action, receipt_id, created_at
+		FROM activity
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var activity domain.Activity
+		if err := rows.Scan(&activity.ID, &activity.UserID, &activity.Action, &activity.ReceiptID, &activity.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		feed.Data = append(feed.Data, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activity: %w", err)
+	}
+
+	return feed, nil
+}
+
+// ListDeletedReceiptIDs retrieves the IDs of the user's receipts deleted after since, in order of deletion
+func (r *PostgresActivityRepository) ListDeletedReceiptIDs(ctx context.Context, userID string, since time.Time) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT receipt_id
+		FROM activity
+		WHERE user_id = $1 AND action = $2 AND created_at > $3
+		GROUP BY receipt_id
+		ORDER BY MAX(created_at), receipt_id
+	`, userID, domain.ActivityReceiptDeleted, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted receipts: %w", err)
+	}
+	defer rows.Close()
+
+	deletedIDs := []string{}
+	for rows.Next() {
+		var receiptID string
+		if err := rows.Scan(&receiptID); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted receipt: %w", err)
+		}
+		deletedIDs = append(deletedIDs, receiptID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted receipts: %w", err)
+	}
+
+	return deletedIDs, nil
+}
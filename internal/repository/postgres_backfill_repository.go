@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// derivedColumn locates a derived field and the column it is computed from
+type derivedColumn struct {
+	table  string
+	column string
+	source string
+}
+
+// derivedColumns maps backfillable fields to their columns. Only these identifiers are ever interpolated into queries.
+var derivedColumns = map[string]derivedColumn{
+	domain.BackfillFieldNormalizedMerchant: {table: "receipts", column: "normalized_merchant", source: "merchant"},
+}
+
+// PostgresBackfillRepository implements BackfillRepository using PostgreSQL
+type PostgresBackfillRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresBackfillRepository creates a new PostgreSQL backfill repository
+func NewPostgresBackfillRepository(db *pgxpool.Pool) BackfillRepository {
+	return &PostgresBackfillRepository{db: db}
+}
+
+// derivedColumnFor looks up the columns of a backfillable field
+func derivedColumnFor(field string) (derivedColumn, error) {
+	column, ok := derivedColumns[field]
+	if !ok {
+		return derivedColumn{}, fmt.Errorf("unknown derived field: %s", field)
+	}
+	return column, nil
+}
+
+// CountMissing counts rows whose derived field is NULL
+func (r *PostgresBackfillRepository) CountMissing(ctx context.Context, field string) (int, error) {
+	column, err := derivedColumnFor(field)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s IS NULL`, column.table, column.column)
+	if err := r.db.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows missing %s: %w", field, err)
+	}
+	return count, nil
+}
+
+// ListMissing retrieves a batch of rows whose derived field is NULL
+func (r *PostgresBackfillRepository) ListMissing(ctx context.Context, field string, limit int) ([]domain.DerivedFieldSource, error) {
+	column, err := derivedColumnFor(field)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(%s, '')
+		FROM %s
+		WHERE %s IS NULL
+		ORDER BY id
+		LIMIT $1
+	`, column.source, column.table, column.column)
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rows missing %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	sources := []domain.DerivedFieldSource{}
+	for rows.Next() {
+		var source domain.DerivedFieldSource
+		if err := rows.Scan(&source.ID, &source.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan row missing %s: %w", field, err)
+		}
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows missing %s: %w", field, err)
+	}
+
+	return sources, nil
+}
+
+// SetDerivedValues fills the derived field of each row in one transaction
+func (r *PostgresBackfillRepository) SetDerivedValues(ctx context.Context, field string, values map[string]string) error {
+	column, err := derivedColumnFor(field)
+	if err != nil {
+		return err
+	}
+
+	// Rows written by the application since the batch was read already hold a current value
+	query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE id = $2 AND %s IS NULL`, column.table, column.column, column.column)
+
+	batch := &pgx.Batch{}
+	for id, value := range values {
+		batch.Queue(query, value, id)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Rollback if not committed
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to set %s: %w", field, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// PostgresExtractionFeedbackRepository implements ExtractionFeedbackRepository using PostgreSQL
+type PostgresExtractionFeedbackRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresExtractionFeedbackRepository creates a new PostgreSQL extraction feedback repository
+func NewPostgresExtractionFeedbackRepository(db *pgxpool.Pool) ExtractionFeedbackRepository {
+	return &PostgresExtractionFeedbackRepository{db: db}
+}
+
+// RecordExtractionFeedback stores one row per field of the feedback
+func (r *PostgresExtractionFeedbackRepository) RecordExtractionFeedback(ctx context.Context, feedback []domain.ExtractionFeedback) error {
+	fields := make([]string, len(feedback))
+	corrected := make([]bool, len(feedback))
+	methods := make([]string, len(feedback))
+	for i, f := range feedback {
+		fields[i], corrected[i], methods[i] = f.Field, f.Corrected, f.ExtractionMethod
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO extraction_feedback (field, corrected, extraction_method)
+		SELECT * FROM UNNEST($1::text[], $2::boolean[], $3::text[])
+	`, fields, corrected, methods)
+	if err != nil {
+		return fmt.Errorf("failed to record extraction feedback: %w", err)
+	}
+	return nil
+}
+
+// GetExtractionAccuracy counts the reviewed and corrected values of each field per extractor
+func (r *PostgresExtractionFeedbackRepository) GetExtractionAccuracy(ctx context.Context) ([]domain.FieldAccuracy, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT field, extraction_method, COUNT(*) as reviewed, COUNT(*) FILTER (WHERE corrected) as corrections
+		FROM extraction_feedback
+		GROUP BY field, extraction_method
+		ORDER BY field, extraction_method
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extraction accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	accuracy := []domain.FieldAccuracy{}
+	for rows.Next() {
+		var field domain.FieldAccuracy
+		if err := rows.Scan(&field.Field, &field.ExtractionMethod, &field.Reviewed, &field.Corrections); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction accuracy: %w", err)
+		}
+		if field.Reviewed > 0 {
+			field.Accuracy = 1 - float64(field.Corrections)/float64(field.Reviewed)
+		}
+		accuracy = append(accuracy, field)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating extraction accuracy: %w", err)
+	}
+
+	return accuracy, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// PostgresMerchantRuleRepository implements MerchantRuleRepository using PostgreSQL
+type PostgresMerchantRuleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresMerchantRuleRepository creates a new PostgreSQL merchant rule repository
+func NewPostgresMerchantRuleRepository(db *pgxpool.Pool) MerchantRuleRepository {
+	return &PostgresMerchantRuleRepository{db: db}
+}
+
+// UpsertMerchantRule creates a rule or updates the category of the existing rule for the merchant
+func (r *PostgresMerchantRuleRepository) UpsertMerchantRule(ctx context.Context, rule *domain.MerchantRule) (*domain.MerchantRule, error) {
+	query := `
+		INSERT INTO merchant_rules (user_id, merchant, category)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, LOWER(TRIM(merchant)))
+		DO UPDATE SET category = EXCLUDED.category, merchant = EXCLUDED.merchant
+		RETURNING id, created_at, updated_at
+	`
+
+	stored := *rule
+	err := r.db.QueryRow(ctx, query, rule.UserID, rule.Merchant, rule.Category).
+		Scan(&stored.ID, &stored.CreatedAt, &stored.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert merchant rule: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// ListMerchantRules retrieves all merchant rules for a user
+func (r *PostgresMerchantRuleRepository) ListMerchantRules(ctx context.Context, userID string) ([]domain.MerchantRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, merchant, category, created_at, updated_at
+		FROM merchant_rules
+		WHERE user_id = $1
+		ORDER BY LOWER(merchant)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []domain.MerchantRule{}
+	for rows.Next() {
+		var rule domain.MerchantRule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.Merchant, &rule.Category, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merchant rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetMerchantRuleByMerchant retrieves the rule matching a merchant name, or nil when none exists
+func (r *PostgresMerchantRuleRepository) GetMerchantRuleByMerchant(ctx context.Context, userID, merchant string) (*domain.MerchantRule, error) {
+	var rule domain.MerchantRule
+	err := r.db.QueryRow(ctx, `
+		SELECT id, user_id, merchant, category, created_at, updated_at
+		FROM merchant_rules
+		WHERE user_id = $1 AND LOWER(TRIM(merchant)) = $2
+	`, userID, domain.MerchantRuleKey(merchant)).Scan(
+		&rule.ID, &rule.UserID, &rule.Merchant, &rule.Category, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get merchant rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// DeleteMerchantRule deletes a merchant rule owned by the user
+func (r *PostgresMerchantRuleRepository) DeleteMerchantRule(ctx context.Context, userID, ruleID string) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM merchant_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant rule: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("merchant rule not found: %s", ruleID)
+	}
+
+	return nil
+}
+
+// ApplyMerchantRules sets the category of every item on receipts from ruled merchants
+func (r *PostgresMerchantRuleRepository) ApplyMerchantRules(ctx context.Context, userID string) (int64, error) {
+	commandTag, err := r.db.Exec(ctx, `
+		UPDATE receipt_items ri
+		SET category = mr.category
+		FROM receipts r
+		JOIN merchant_rules mr
+			ON mr.user_id = r.user_id AND LOWER(TRIM(mr.merchant)) = LOWER(TRIM(r.merchant))
+		WHERE ri.receipt_id = r.id
+			AND r.user_id = $1
+			AND ri.category IS DISTINCT FROM mr.category
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply merchant rules: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// uuidPattern matches the UUIDs organizations are keyed by
+var uuidPattern = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// PostgresOrganizationRepository implements OrganizationRepository using PostgreSQL
+type PostgresOrganizationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresOrganizationRepository creates a new PostgreSQL organization repository
+func NewPostgresOrganizationRepository(db *pgxpool.Pool) OrganizationRepository {
+	return &PostgresOrganizationRepository{db: db}
+}
+
+// CreateOrganization inserts an organization and its owner membership in one transaction
+func (r *PostgresOrganizationRepository) CreateOrganization(ctx context.Context, org *domain.Organization) (*domain.Organization, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Rollback if not committed
+
+	stored := *org
+	err = tx.QueryRow(ctx, `
+		INSERT INTO organizations (name, owner_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`, org.Name, org.OwnerID).Scan(&stored.ID, &stored.CreatedAt, &stored.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert organization: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, stored.ID, org.OwnerID, domain.OrganizationRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert organization owner: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// GetOrganizationByID retrieves an organization by its ID
+func (r *PostgresOrganizationRepository) GetOrganizationByID(ctx context.Context, orgID string) (*domain.Organization, error) {
+	var org domain.Organization
+	err := r.db.QueryRow(ctx, `
+		SELECT id, name, owner_id, created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`, orgID).Scan(&org.ID, &org.Name, &org.OwnerID, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("organization not found: %s", orgID)
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &org, nil
+}
+
+// ListOrganizationsForUser retrieves the organizations a user is a member of
+func (r *PostgresOrganizationRepository) ListOrganizationsForUser(ctx context.Context, userID string) ([]domain.Organization, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT o.id, o.name, o.owner_id, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY LOWER(o.name)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []domain.Organization{}
+	for rows.Next() {
+		var org domain.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.OwnerID, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// AddOrganizationMember adds a user to an organization; existing members keep their role
+func (r *PostgresOrganizationRepository) AddOrganizationMember(ctx context.Context, member *domain.OrganizationMember) (*domain.OrganizationMember, error) {
+	stored := *member
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id)
+		DO UPDATE SET role = organization_members.role
+		RETURNING role, created_at
+	`, member.OrganizationID, member.UserID, member.Role).Scan(&stored.Role, &stored.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// ListOrganizationMembers retrieves the members of an organization with their user details
+func (r *PostgresOrganizationRepository) ListOrganizationMembers(ctx context.Context, orgID string) ([]domain.OrganizationMember, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.organization_id, m.user_id, u.email, COALESCE(u.name, ''), m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.OrganizationMember{}
+	for rows.Next() {
+		var member domain.OrganizationMember
+		if err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Email, &member.Name, &member.Role, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetMemberRole retrieves a user's role in an organization, or an empty role when not a member.
+// Malformed IDs from query parameters read as non-membership rather than a database error.
+func (r *PostgresOrganizationRepository) GetMemberRole(ctx context.Context, orgID, userID string) (string, error) {
+	if !uuidPattern.MatchString(orgID) {
+		return "", nil
+	}
+
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role
+		FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2
+	`, orgID, userID).Scan(&role)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get organization member role: %w", err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMemberRoleMalformedOrganizationID(t *testing.T) {
+	// Malformed IDs are answered without querying the database
+	repo := &PostgresOrganizationRepository{}
+	for _, orgID := range []string{"", "acme", "1", "0b9a1d2e-7c4f-4a8e-9b1d-2f3e4a5b6c7d; DROP TABLE"} {
+		role, err := repo.GetMemberRole(context.Background(), orgID, "user-1")
+		require.NoError(t, err, orgID)
+		assert.Empty(t, role, orgID)
+	}
+
+	assert.True(t, uuidPattern.MatchString("0B9A1D2E-7C4F-4A8E-9B1D-2F3E4A5B6C7D"))
+}
@@ -35,17 +35,19 @@ func (r *PostgresReceiptRepository) CreateReceipt(ctx context.Context, receipt *
 	// Insert receipt
 	var receiptID string
 	err = tx.QueryRow(ctx, `
-		INSERT INTO receipts (user_id, merchant, date, total, tax, subtotal, image_url, receipt_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
-	`, receipt.UserID, receipt.Merchant, receipt.Date.Time, receipt.Total, receipt.Tax, receipt.Subtotal, receipt.ImageURL, receipt.ReceiptURL).Scan(
-		&receiptID, &receipt.CreatedAt, &receipt.UpdatedAt,
+		INSERT INTO receipts (user_id, merchant, date, total, tax, subtotal, image_url, receipt_url, source_url, status, confidence, payment_method, normalized_merchant, extraction_method, tip, service_charge, category, purchased_at, tax_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), COALESCE(NULLIF($10, ''), 'verified'), $11, NULLIF($12, ''), $13, NULLIF($14, ''), $15, $16, NULLIF($17, ''), $18, NULLIF($19, ''))
+		RETURNING id, status, created_at, updated_at
+	`, receipt.UserID, receipt.Merchant, receipt.Date.Time, receipt.Total, receipt.Tax, receipt.Subtotal, receipt.ImageURL, receipt.ReceiptURL, receipt.SourceURL, receipt.Status, receipt.Confidence, receipt.PaymentMethod,
+		domain.NormalizeMerchant(receipt.Merchant), receipt.ExtractionMethod, receipt.Tip, receipt.ServiceCharge, receipt.Category, receipt.PurchaseTime(), receipt.TaxID).Scan(
+		&receiptID, &receipt.Status, &receipt.CreatedAt, &receipt.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert receipt: %w", err)
 	}
 
 	receipt.ID = receiptID
+	receipt.PurchasedAt = receipt.PurchaseTime()
 
 	// Insert receipt items
 	for i := range receipt.Items {
@@ -75,12 +77,12 @@ func (r *PostgresReceiptRepository) GetReceiptByID(ctx context.Context, receiptI
 	// Query receipt
 	var receipt domain.Receipt
 	err := r.db.QueryRow(ctx, `
-		SELECT id, user_id, merchant, date, total, tax, subtotal, image_url, receipt_url, created_at, updated_at
+		SELECT id, user_id, merchant, date, total, tax, subtotal, image_url, receipt_url, COALESCE(source_url, ''), status, confidence, COALESCE(org_id::text, ''), COALESCE(payment_method, ''), COALESCE(extraction_method, ''), COALESCE(category, ''), tip, service_charge, created_at, updated_at, purchased_at, COALESCE(tax_id, '')
 		FROM receipts
 		WHERE id = $1
 	`, receiptID).Scan(
 		&receipt.ID, &receipt.UserID, &receipt.Merchant, &receipt.Date.Time, &receipt.Total, &receipt.Tax,
-		&receipt.Subtotal, &receipt.ImageURL, &receipt.ReceiptURL, &receipt.CreatedAt, &receipt.UpdatedAt,
+		&receipt.Subtotal, &receipt.ImageURL, &receipt.ReceiptURL, &receipt.SourceURL, &receipt.Status, &receipt.Confidence, &receipt.OrgID, &receipt.PaymentMethod, &receipt.ExtractionMethod, &receipt.Category, &receipt.Tip, &receipt.ServiceCharge, &receipt.CreatedAt, &receipt.UpdatedAt, &receipt.PurchasedAt, &receipt.TaxID,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -126,28 +128,76 @@ func (r *PostgresReceiptRepository) UpdateReceipt(ctx context.Context, receipt *
 	}
 	defer tx.Rollback(ctx) // Rollback if not committed
 
-	// Update receipt
+	// Update receipt, skipping it when the stored receipt changed after the caller's precondition.
+	// A receipt submitted without a time of purchase keeps the stored one unless its date changed.
 	var updatedAt time.Time
 	err = tx.QueryRow(ctx, `
 		UPDATE receipts
-		SET merchant = $1, date = $2, total = $3, tax = $4, subtotal = $5, image_url = $6, receipt_url = $7
-		WHERE id = $8
-		RETURNING updated_at
-	`, receipt.Merchant, receipt.Date.Time, receipt.Total, receipt.Tax, receipt.Subtotal, receipt.ImageURL, receipt.ReceiptURL, receipt.ID).Scan(&updatedAt)
+		SET merchant = $1, date = $2, total = $3, tax = $4, subtotal = $5, image_url = $6, receipt_url = $7,
+			status = COALESCE(NULLIF($8, ''), status), confidence = COALESCE($9, confidence), payment_method = NULLIF($12, ''),
+			normalized_merchant = $13, extraction_method = COALESCE(NULLIF($14, ''), extraction_method), tip = $15, service_charge = $16,
+			category = NULLIF($17, ''), tax_id = NULLIF($19, ''),
+			purchased_at = CASE WHEN $18::timestamptz IS NOT NULL THEN $18::timestamptz WHEN date = $2::date THEN purchased_at END
+		WHERE id = $10 AND ($11::timestamptz IS NULL OR date_trunc('second', updated_at) <= $11::timestamptz)
+		RETURNING user_id, status, updated_at, purchased_at
+	`, receipt.Merchant, receipt.Date.Time, receipt.Total, receipt.Tax, receipt.Subtotal, receipt.ImageURL, receipt.ReceiptURL,
+		receipt.Status, receipt.Confidence, receipt.ID, receipt.UnmodifiedSince, receipt.PaymentMethod, domain.NormalizeMerchant(receipt.Merchant),
+		receipt.ExtractionMethod, receipt.Tip, receipt.ServiceCharge, receipt.Category, receipt.PurchaseTime(), receipt.TaxID).Scan(&receipt.UserID, &receipt.Status, &updatedAt, &receipt.PurchasedAt)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, r.updateMissError(ctx, receipt.ID)
+		}
 		return nil, fmt.Errorf("failed to update receipt: %w", err)
 	}
 
 	receipt.UpdatedAt = updatedAt
 
-	// Delete existing items
-	_, err = tx.Exec(ctx, `DELETE FROM receipt_items WHERE receipt_id = $1`, receipt.ID)
+	// Diff the submitted items against the stored ones so unchanged items keep their IDs
+	rows, err := tx.Query(ctx, `
+		SELECT id, name, qty, price, currency, COALESCE(category, '')
+		FROM receipt_items
+		WHERE receipt_id = $1
+		FOR UPDATE
+	`, receipt.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to delete receipt items: %w", err)
+		return nil, fmt.Errorf("failed to query receipt items: %w", err)
+	}
+	var existing []domain.ReceiptItem
+	for rows.Next() {
+		var item domain.ReceiptItem
+		if err := rows.Scan(&item.ID, &item.Name, &item.Quantity, &item.Price, &item.Currency, &item.Category); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan receipt item: %w", err)
+		}
+		existing = append(existing, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating receipt items: %w", err)
 	}
 
-	// Insert updated items
-	for i := range receipt.Items {
+	changes := planItemChanges(existing, receipt.Items)
+
+	if len(changes.deleted) > 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM receipt_items WHERE receipt_id = $1 AND id = ANY($2)`, receipt.ID, changes.deleted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete receipt items: %w", err)
+		}
+	}
+
+	for _, i := range changes.updated {
+		item := &receipt.Items[i]
+		_, err = tx.Exec(ctx, `
+			UPDATE receipt_items
+			SET name = $1, qty = $2, price = $3, currency = $4, category = $5
+			WHERE id = $6 AND receipt_id = $7
+		`, item.Name, item.Quantity, item.Price, item.Currency, item.Category, item.ID, receipt.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update receipt item: %w", err)
+		}
+	}
+
+	for _, i := range changes.inserted {
 		item := &receipt.Items[i]
 		err = tx.QueryRow(ctx, `
 			INSERT INTO receipt_items (receipt_id, name, qty, price, currency, category)
@@ -167,6 +217,60 @@ func (r *PostgresReceiptRepository) UpdateReceipt(ctx context.Context, receipt *
 	return receipt, nil
 }
 
+// itemChanges lists how submitted items apply to the stored ones: indexes into the submitted
+// items to update or insert, and the IDs of stored items to delete
+type itemChanges struct {
+	updated  []int
+	inserted []int
+	deleted  []string
+}
+
+// planItemChanges matches submitted items to stored ones by ID. A submitted item whose ID is
+// on the receipt is updated in place, and skipped when nothing changed; one without an ID, or
+// with an ID the receipt doesn't have, is inserted and gets a new ID. Stored items that were
+// not submitted are deleted. A repeated ID is only matched once.
+func planItemChanges(existing, submitted []domain.ReceiptItem) itemChanges {
+	stored := make(map[string]domain.ReceiptItem, len(existing))
+	for _, item := range existing {
+		stored[item.ID] = item
+	}
+
+	var changes itemChanges
+	matched := make(map[string]bool, len(submitted))
+	for i, item := range submitted {
+		current, ok := stored[item.ID]
+		if !ok || matched[item.ID] {
+			changes.inserted = append(changes.inserted, i)
+			continue
+		}
+		matched[item.ID] = true
+		if current.Name != item.Name || current.Quantity != item.Quantity || current.Price != item.Price ||
+			current.Currency != item.Currency || current.Category != item.Category {
+			changes.updated = append(changes.updated, i)
+		}
+	}
+
+	for _, item := range existing {
+		if !matched[item.ID] {
+			changes.deleted = append(changes.deleted, item.ID)
+		}
+	}
+	return changes
+}
+
+// updateMissError explains an update that matched no rows: either the receipt does not
+// exist or it was modified after the update's precondition
+func (r *PostgresReceiptRepository) updateMissError(ctx context.Context, receiptID string) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM receipts WHERE id = $1)`, receiptID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check receipt: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	return domain.ErrReceiptModified
+}
+
 // DeleteReceipt deletes a receipt by its ID
 func (r *PostgresReceiptRepository) DeleteReceipt(ctx context.Context, receiptID string) error {
 	// Delete receipt (cascade will delete items)
@@ -182,6 +286,106 @@ func (r *PostgresReceiptRepository) DeleteReceipt(ctx context.Context, receiptID
 	return nil
 }
 
+// DeleteReceipts deletes the receipts in one transaction, rolling back when any of them is missing
+func (r *PostgresReceiptRepository) DeleteReceipts(ctx context.Context, receiptIDs []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Rollback if not committed
+
+	// Delete receipts (cascade will delete items)
+	commandTag, err := tx.Exec(ctx, `DELETE FROM receipts WHERE id = ANY($1::uuid[])`, receiptIDs)
+	if err != nil {
+		return fmt.Errorf("failed to delete receipts: %w", err)
+	}
+	if commandTag.RowsAffected() != int64(len(receiptIDs)) {
+		return fmt.Errorf("receipt not found: deleted %d of %d receipts", commandTag.RowsAffected(), len(receiptIDs))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetReceiptOrganization sets or clears the organization a receipt is shared with
+func (r *PostgresReceiptRepository) SetReceiptOrganization(ctx context.Context, receiptID, orgID string) error {
+	commandTag, err := r.db.Exec(ctx, `UPDATE receipts SET org_id = NULLIF($1, '')::uuid WHERE id = $2`, orgID, receiptID)
+	if err != nil {
+		return fmt.Errorf("failed to set receipt organization: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("receipt not found: %s", receiptID)
+	}
+
+	return nil
+}
+
+// MergeReceipts updates the merged receipt, moves the given items of the removed receipt onto it and
+// deletes the removed receipt with its remaining items
+func (r *PostgresReceiptRepository) MergeReceipts(ctx context.Context, merged *domain.Receipt, removedID string, movedItemIDs []string) (*domain.Receipt, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Rollback if not committed
+
+	err = tx.QueryRow(ctx, `
+		UPDATE receipts
+		SET merchant = $1, normalized_merchant = $2, total = $3, tax = $4, subtotal = $5, tip = $6, service_charge = $7,
+			payment_method = NULLIF($8, ''), category = NULLIF($9, ''), receipt_url = $10, source_url = NULLIF($11, ''),
+			purchased_at = $13, tax_id = NULLIF($14, '')
+		WHERE id = $12
+		RETURNING updated_at
+	`, merged.Merchant, domain.NormalizeMerchant(merged.Merchant), merged.Total, merged.Tax, merged.Subtotal, merged.Tip,
+		merged.ServiceCharge, merged.PaymentMethod, merged.Category, merged.ReceiptURL, merged.SourceURL, merged.ID, merged.PurchaseTime(), merged.TaxID).Scan(&merged.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("receipt not found: %s", merged.ID)
+		}
+		return nil, fmt.Errorf("failed to update merged receipt: %w", err)
+	}
+
+	if len(movedItemIDs) > 0 {
+		_, err = tx.Exec(ctx, `UPDATE receipt_items SET receipt_id = $1 WHERE receipt_id = $2 AND id = ANY($3)`,
+			merged.ID, removedID, movedItemIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move receipt items: %w", err)
+		}
+	}
+
+	// Delete the removed receipt (cascade will delete its duplicate items)
+	commandTag, err := tx.Exec(ctx, `DELETE FROM receipts WHERE id = $1`, removedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete merged receipt: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("receipt not found: %s", removedID)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return merged, nil
+}
+
+// UpdateReceiptImage sets the receipt image URL and returns the new updated_at
+func (r *PostgresReceiptRepository) UpdateReceiptImage(ctx context.Context, receiptID, receiptURL string) (time.Time, error) {
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, `UPDATE receipts SET receipt_url = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at`, receiptURL, receiptID).Scan(&updatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return time.Time{}, fmt.Errorf("receipt not found: %s", receiptID)
+		}
+		return time.Time{}, fmt.Errorf("failed to update receipt image: %w", err)
+	}
+
+	return updatedAt, nil
+}
+
 // ListReceipts retrieves receipts with optional filters and pagination
 func (r *PostgresReceiptRepository) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
 	result := &domain.PaginatedReceipts{
@@ -200,38 +404,8 @@ func (r *PostgresReceiptRepository) ListReceipts(ctx context.Context, filter dom
 		filter.Limit = 100
 	}
 
-	// Build query conditions
-	conditions := []string{}
-	args := []interface{}{}
-	argCount := 1
-
-	// Always filter by user ID for security
-	if filter.UserID != "" {
-		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argCount))
-		args = append(args, filter.UserID)
-		argCount++
-	}
-
-	if filter.StartDate != nil {
-		conditions = append(conditions, fmt.Sprintf("date >= $%d", argCount))
-		args = append(args, filter.StartDate)
-		argCount++
-	}
-	if filter.EndDate != nil {
-		conditions = append(conditions, fmt.Sprintf("date <= $%d", argCount))
-		args = append(args, filter.EndDate)
-		argCount++
-	}
-	if filter.Merchant != "" {
-		conditions = append(conditions, fmt.Sprintf("merchant ILIKE $%d", argCount))
-		args = append(args, "%"+filter.Merchant+"%") // Case-insensitive partial match
-		argCount++
-	}
-
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	}
+	whereClause, args := receiptListConditions(filter)
+	argCount := len(args) + 1
 
 	// Count total items
 	var totalItems int
@@ -258,12 +432,12 @@ func (r *PostgresReceiptRepository) ListReceipts(ctx context.Context, filter dom
 
 	// Query receipts with pagination
 	query := fmt.Sprintf(`
-		SELECT id, user_id, merchant, date, total, tax, subtotal, image_url, receipt_url, created_at, updated_at
+		SELECT id, user_id, merchant, date, total, tax, subtotal, image_url, receipt_url, COALESCE(source_url, ''), status, confidence, COALESCE(org_id::text, ''), COALESCE(payment_method, ''), COALESCE(extraction_method, ''), COALESCE(category, ''), tip, service_charge, created_at, updated_at, purchased_at, COALESCE(tax_id, '')
 		FROM receipts
 		%s
-		ORDER BY date DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argCount, argCount+1)
+	`, whereClause, receiptOrderBy(filter.SortBy, filter.SortOrder), argCount, argCount+1)
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -279,7 +453,7 @@ func (r *PostgresReceiptRepository) ListReceipts(ctx context.Context, filter dom
 		var receipt domain.Receipt
 		if err := rows.Scan(
 			&receipt.ID, &receipt.UserID, &receipt.Merchant, &receipt.Date.Time, &receipt.Total, &receipt.Tax,
-			&receipt.Subtotal, &receipt.ImageURL, &receipt.ReceiptURL, &receipt.CreatedAt, &receipt.UpdatedAt,
+			&receipt.Subtotal, &receipt.ImageURL, &receipt.ReceiptURL, &receipt.SourceURL, &receipt.Status, &receipt.Confidence, &receipt.OrgID, &receipt.PaymentMethod, &receipt.ExtractionMethod, &receipt.Category, &receipt.Tip, &receipt.ServiceCharge, &receipt.CreatedAt, &receipt.UpdatedAt, &receipt.PurchasedAt, &receipt.TaxID,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan receipt: %w", err)
 		}
@@ -348,6 +522,163 @@ func (r *PostgresReceiptRepository) ListReceipts(ctx context.Context, filter dom
 	return result, nil
 }
 
+// receiptListConditions builds the WHERE clause and arguments shared by listing and counting receipts
+func receiptListConditions(filter domain.ReceiptFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	argCount := 1
+
+	// Always filter by user ID for security, or by organization for shared receipts.
+	// Admin listings across all users are only filtered by owner when one is given.
+	if filter.OrgID != "" {
+		conditions = append(conditions, fmt.Sprintf("org_id = $%d", argCount))
+		args = append(args, filter.OrgID)
+		argCount++
+	} else if filter.UserID != "" {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argCount))
+		args = append(args, filter.UserID)
+		argCount++
+	}
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date >= $%d", argCount))
+		args = append(args, filter.StartDate)
+		argCount++
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("date <= $%d", argCount))
+		args = append(args, filter.EndDate)
+		argCount++
+	}
+	if filter.Merchant != "" {
+		conditions = append(conditions, fmt.Sprintf("merchant ILIKE $%d", argCount))
+		args = append(args, "%"+filter.Merchant+"%") // Case-insensitive partial match
+		argCount++
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM receipt_items ri WHERE ri.receipt_id = receipts.id AND LOWER(ri.category) = LOWER($%d))", argCount))
+		args = append(args, filter.Category)
+		argCount++
+	}
+	if filter.TaxID != "" {
+		conditions = append(conditions, fmt.Sprintf("tax_id = $%d", argCount))
+		args = append(args, filter.TaxID)
+		argCount++
+	}
+	if filter.NeedsReview {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCount))
+		args = append(args, domain.ReceiptStatusUnverified)
+		argCount++
+	}
+	if filter.HasImage != nil {
+		// Scans store the image as receipt_url; manually created receipts may carry an image_url
+		condition := "COALESCE(NULLIF(receipt_url, ''), NULLIF(image_url, '')) IS NULL"
+		if *filter.HasImage {
+			condition = "COALESCE(NULLIF(receipt_url, ''), NULLIF(image_url, '')) IS NOT NULL"
+		}
+		conditions = append(conditions, condition)
+	}
+	if filter.ConfidenceBelow != nil {
+		conditions = append(conditions, fmt.Sprintf("(confidence IS NULL OR confidence < $%d)", argCount))
+		args = append(args, *filter.ConfidenceBelow)
+		argCount++
+	}
+	if filter.ModifiedSince != nil {
+		conditions = append(conditions, fmt.Sprintf("updated_at > $%d", argCount))
+		args = append(args, *filter.ModifiedSince)
+		argCount++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	return whereClause, args
+}
+
+// CountReceipts counts the receipts matching the filter, ignoring pagination
+func (r *PostgresReceiptRepository) CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error) {
+	whereClause, args := receiptListConditions(filter)
+
+	var count int
+	if err := r.db.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM receipts %s`, whereClause), args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count receipts: %w", err)
+	}
+	return count, nil
+}
+
+// CountScannedReceipts counts the user's receipts created by a scan, rather than entered by hand, since the given time
+func (r *PostgresReceiptRepository) CountScannedReceipts(ctx context.Context, userID string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM receipts
+		WHERE user_id = $1 AND COALESCE(extraction_method, '') <> '' AND created_at >= $2
+	`, userID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count scanned receipts: %w", err)
+	}
+	return count, nil
+}
+
+// ListReceiptCurrencies lists the distinct currencies of the user's receipt items, most used first.
+// Receipts carry no currency of their own, so a receipt counts toward each currency its items use.
+func (r *PostgresReceiptRepository) ListReceiptCurrencies(ctx context.Context, userID string) ([]domain.CurrencyUsage, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT UPPER(ri.currency), COUNT(DISTINCT r.id), COUNT(*)
+		FROM receipt_items ri
+		JOIN receipts r ON r.id = ri.receipt_id
+		WHERE r.user_id = $1
+		GROUP BY UPPER(ri.currency)
+		ORDER BY COUNT(*) DESC, UPPER(ri.currency)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receipt currencies: %w", err)
+	}
+	defer rows.Close()
+
+	currencies := []domain.CurrencyUsage{}
+	for rows.Next() {
+		var usage domain.CurrencyUsage
+		if err := rows.Scan(&usage.Currency, &usage.ReceiptCount, &usage.ItemCount); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt currency: %w", err)
+		}
+		currencies = append(currencies, usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating receipt currencies: %w", err)
+	}
+	return currencies, nil
+}
+
+// receiptSortColumns maps the supported sort fields to receipt columns
+var receiptSortColumns = map[string]string{
+	"date":      "date",
+	"total":     "total",
+	"merchant":  "LOWER(merchant)",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+// receiptOrderBy builds the ORDER BY expression for listing, defaulting to newest date first.
+// Receipts on the same date are ordered by time of purchase, with those without one last. The
+// unique id breaks ties so receipts sharing a sort value keep the same order from page to page.
+func receiptOrderBy(sortBy, sortOrder string) string {
+	column, ok := receiptSortColumns[sortBy]
+	if !ok {
+		column = receiptSortColumns["date"]
+	}
+	direction := "DESC"
+	if sortOrder == "asc" {
+		direction = "ASC"
+	}
+	order := column + " " + direction
+	if column == "date" {
+		order += ", purchased_at " + direction + " NULLS LAST"
+	}
+	return order + ", id " + direction
+}
+
 // GetReceiptItems retrieves all items from a specific receipt
 func (r *PostgresReceiptRepository) GetReceiptItems(ctx context.Context, receiptID string) ([]domain.ReceiptItem, error) {
 	// First, check if receipt exists
@@ -411,10 +742,10 @@ func (r *PostgresReceiptRepository) GetReceiptsWithItems(ctx context.Context, fi
 
 	// Query receipts
 	receiptRows, err := r.db.Query(ctx, fmt.Sprintf(`
-		SELECT r.id, r.user_id, r.merchant, r.date, r.total, r.tax, r.subtotal, r.image_url, r.receipt_url, r.created_at, r.updated_at
+		SELECT r.id, r.user_id, r.merchant, r.date, r.total, r.tax, r.subtotal, r.image_url, r.receipt_url, COALESCE(r.source_url, ''), r.status, r.confidence, COALESCE(r.org_id::text, ''), COALESCE(r.payment_method, ''), COALESCE(r.extraction_method, ''), COALESCE(r.category, ''), r.tip, r.service_charge, r.created_at, r.updated_at, r.purchased_at, COALESCE(r.tax_id, '')
 		FROM receipts r
 		%s
-		ORDER BY r.date DESC
+		ORDER BY r.date DESC, r.purchased_at DESC NULLS LAST, r.id DESC
 	`, whereClause), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query receipts: %w", err)
@@ -432,7 +763,7 @@ func (r *PostgresReceiptRepository) GetReceiptsWithItems(ctx context.Context, fi
 		if err := receiptRows.Scan(
 			&receipt.ID, &receipt.UserID, &receipt.Merchant, &receipt.Date.Time,
 			&receipt.Total, &receipt.Tax, &receipt.Subtotal,
-			&imageURL, &receiptURL, &receipt.CreatedAt, &receipt.UpdatedAt,
+			&imageURL, &receiptURL, &receipt.SourceURL, &receipt.Status, &receipt.Confidence, &receipt.OrgID, &receipt.PaymentMethod, &receipt.ExtractionMethod, &receipt.Category, &receipt.Tip, &receipt.ServiceCharge, &receipt.CreatedAt, &receipt.UpdatedAt, &receipt.PurchasedAt, &receipt.TaxID,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan receipt: %w", err)
 		}
@@ -9,8 +9,71 @@ import (
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 )
 
+// scopeFilter returns the receipts column and value that restrict a query to the scope
+func scopeFilter(scope domain.ReceiptScope) (string, string) {
+	if scope.OrgID != "" {
+		return "org_id", scope.OrgID
+	}
+	return "user_id", scope.UserID
+}
+
+// scopeExclusions is a scope's excluded categories and merchants rendered as SQL over receipts
+// aliased r and receipt items aliased ri
+type scopeExclusions struct {
+	receipts []string // Leave out receipts from excluded merchants and receipts whose items are all in excluded categories
+	items    []string // Leave out items in excluded categories
+	spend    string   // Receipt spend: the total less its items in excluded categories
+}
+
+// exclusionFilter builds the scope's exclusions, appending their values to args and numbering
+// placeholders after the arguments already there. Without exclusions spend is just r.total.
+func exclusionFilter(scope domain.ReceiptScope, args *[]interface{}) scopeExclusions {
+	exclusions := scopeExclusions{spend: "r.total"}
+
+	if len(scope.ExcludeMerchants) > 0 {
+		*args = append(*args, lowerAll(scope.ExcludeMerchants))
+		exclusions.receipts = append(exclusions.receipts, fmt.Sprintf("LOWER(COALESCE(r.merchant, '')) <> ALL($%d)", len(*args)))
+	}
+
+	if len(scope.ExcludeCategories) > 0 {
+		*args = append(*args, lowerAll(scope.ExcludeCategories))
+		excluded := func(alias string) string {
+			return fmt.Sprintf("LOWER(COALESCE(%s.category, 'Uncategorized')) = ANY($%d)", alias, len(*args))
+		}
+		exclusions.receipts = append(exclusions.receipts, fmt.Sprintf(
+			"(EXISTS (SELECT 1 FROM receipt_items xi WHERE xi.receipt_id = r.id AND NOT %s) OR NOT EXISTS (SELECT 1 FROM receipt_items xi WHERE xi.receipt_id = r.id))",
+			excluded("xi")))
+		exclusions.items = append(exclusions.items, "NOT "+excluded("ri"))
+		exclusions.spend = fmt.Sprintf(
+			"(r.total - COALESCE((SELECT SUM(xi.qty * xi.price) FROM receipt_items xi WHERE xi.receipt_id = r.id AND %s), 0))",
+			excluded("xi"))
+	}
+
+	return exclusions
+}
+
+// merchantGrouping returns the SQL key grouping receipts by merchant in insights and the display
+// name of each group. Unless the scope asks for raw merchants, receipts group by normalized merchant,
+// falling back to the name as written for rows not yet backfilled, and each group is named after
+// its most frequent spelling.
+func merchantGrouping(scope domain.ReceiptScope) (string, string) {
+	if scope.RawMerchants {
+		return "r.merchant", "r.merchant"
+	}
+	return "COALESCE(NULLIF(r.normalized_merchant, ''), r.merchant)", "MODE() WITHIN GROUP (ORDER BY r.merchant)"
+}
+
+// lowerAll returns the values lowercased for case-insensitive matching
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
 // GetDashboardSummary retrieves summary data for the dashboard
-func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, userID string, startDateStr, endDateStr *string) (*domain.DashboardSummary, error) {
+func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, scope domain.ReceiptScope, startDateStr, endDateStr *string) (*domain.DashboardSummary, error) {
 	// Parse date strings if provided
 	var startDate, endDate *time.Time
 
@@ -35,10 +98,10 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 	args := []interface{}{}
 	argCount := 1
 
-	// Always filter by user ID
-	if userID != "" {
-		conditions = append(conditions, fmt.Sprintf("r.user_id = $%d", argCount))
-		args = append(args, userID)
+	// Always filter by user ID, or by organization for shared receipts
+	if column, value := scopeFilter(scope); value != "" {
+		conditions = append(conditions, fmt.Sprintf("r.%s = $%d", column, argCount))
+		args = append(args, value)
 		argCount++
 	}
 
@@ -53,11 +116,21 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 		argCount++
 	}
 
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	// Category totals additionally leave out items in excluded categories
+	itemConditions := append(append([]string{}, conditions...), exclusions.items...)
+	itemWhereClause := ""
+	if len(itemConditions) > 0 {
+		itemWhereClause = "WHERE " + strings.Join(itemConditions, " AND ")
+	}
+
 	// Initialize summary with default values
 	summary := &domain.DashboardSummary{
 		TopCategories: []domain.CategorySummary{},
@@ -67,11 +140,11 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 	// Get total spend, receipt count, and average spend
 	err := r.db.QueryRow(ctx, fmt.Sprintf(`
 		SELECT 
-			COALESCE(SUM(total), 0) as total_spend,
+			COALESCE(SUM(%s), 0) as total_spend,
 			COUNT(*) as receipt_count
 		FROM receipts r
 		%s
-	`, whereClause), args...).Scan(&summary.TotalSpend, &summary.ReceiptCount)
+	`, exclusions.spend, whereClause), args...).Scan(&summary.TotalSpend, &summary.ReceiptCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dashboard summary: %w", err)
 	}
@@ -89,7 +162,7 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 		SELECT 
 			ri.category, 
 			COALESCE(SUM(ri.qty * ri.price), 0) as amount,
-			COALESCE(SUM(ri.qty * ri.price) / NULLIF((SELECT SUM(total) FROM receipts r %s), 0) * 100, 0) as percentage
+			COALESCE(SUM(ri.qty * ri.price) / NULLIF((SELECT SUM(%s) FROM receipts r %s), 0) * 100, 0) as percentage
 		FROM receipt_items ri
 		JOIN receipts r ON ri.receipt_id = r.id
 		%s
@@ -97,7 +170,7 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 		HAVING ri.category IS NOT NULL
 		ORDER BY amount DESC
 		LIMIT 5
-	`, whereClause, whereClause), categoryArgs...)
+	`, exclusions.spend, whereClause, itemWhereClause), categoryArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top categories: %w", err)
 	}
@@ -118,18 +191,19 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 	// Get top merchants
 	merchantArgs := make([]interface{}, len(args))
 	copy(merchantArgs, args)
+	merchantKey, merchantName := merchantGrouping(scope)
 
 	merchantRows, err := r.db.Query(ctx, fmt.Sprintf(`
 		SELECT 
-			r.merchant, 
-			COALESCE(SUM(r.total), 0) as amount,
-			COALESCE(SUM(r.total) / NULLIF((SELECT SUM(total) FROM receipts r %s), 0) * 100, 0) as percentage
+			%s, 
+			COALESCE(SUM(%s), 0) as amount,
+			COALESCE(SUM(%s) / NULLIF((SELECT SUM(%s) FROM receipts r %s), 0) * 100, 0) as percentage
 		FROM receipts r
 		%s
-		GROUP BY r.merchant
+		GROUP BY %s
 		ORDER BY amount DESC
 		LIMIT 5
-	`, whereClause, whereClause), merchantArgs...)
+	`, merchantName, exclusions.spend, exclusions.spend, exclusions.spend, whereClause, whereClause, merchantKey), merchantArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top merchants: %w", err)
 	}
@@ -151,7 +225,7 @@ func (r *PostgresReceiptRepository) GetDashboardSummary(ctx context.Context, use
 }
 
 // GetSpendingTrends retrieves spending trends over time
-func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, userID string, period string, startDateStr, endDateStr *string) (*domain.SpendingTrends, error) {
+func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, scope domain.ReceiptScope, period string, startDateStr, endDateStr *string) (*domain.SpendingTrends, error) {
 	// Create the result object
 	trends := &domain.SpendingTrends{
 		Period: period,
@@ -169,10 +243,12 @@ func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, userI
 		return nil, fmt.Errorf("invalid period: %s", period)
 	}
 
-	// Build WHERE clause with user ID and date strings
+	// Build WHERE clause with the scope and date strings
 	conditions := []string{}
-	if userID != "" {
-		conditions = append(conditions, fmt.Sprintf("user_id = '%s'", userID))
+	args := []interface{}{}
+	if column, value := scopeFilter(scope); value != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = $1", column))
+		args = append(args, value)
 	}
 	if startDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date >= '%s'::date", *startDateStr))
@@ -180,6 +256,8 @@ func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, userI
 	if endDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date <= '%s'::date", *endDateStr))
 	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -192,46 +270,46 @@ func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, userI
 		query = fmt.Sprintf(`
 			SELECT 
 				TO_CHAR(date, 'YYYY-MM-DD') as date,
-				COALESCE(SUM(total), 0) as amount
-			FROM receipts
+				COALESCE(SUM(%s), 0) as amount
+			FROM receipts r
 			%s
 			GROUP BY date
 			ORDER BY date
-		`, whereClause)
+		`, exclusions.spend, whereClause)
 	case "weekly":
 		query = fmt.Sprintf(`
 			SELECT 
 				TO_CHAR(date, 'YYYY-"W"IW') as date,
-				COALESCE(SUM(total), 0) as amount
-			FROM receipts
+				COALESCE(SUM(%s), 0) as amount
+			FROM receipts r
 			%s
 			GROUP BY TO_CHAR(date, 'YYYY-"W"IW'), date
 			ORDER BY MIN(date)
-		`, whereClause)
+		`, exclusions.spend, whereClause)
 	case "monthly":
 		query = fmt.Sprintf(`
 			SELECT 
 				TO_CHAR(date, 'YYYY-MM') as date,
-				COALESCE(SUM(total), 0) as amount
-			FROM receipts
+				COALESCE(SUM(%s), 0) as amount
+			FROM receipts r
 			%s
 			GROUP BY TO_CHAR(date, 'YYYY-MM'), date
 			ORDER BY MIN(date)
-		`, whereClause)
+		`, exclusions.spend, whereClause)
 	case "yearly":
 		query = fmt.Sprintf(`
 			SELECT 
 				TO_CHAR(date, 'YYYY') as date,
-				COALESCE(SUM(total), 0) as amount
-			FROM receipts
+				COALESCE(SUM(%s), 0) as amount
+			FROM receipts r
 			%s
 			GROUP BY TO_CHAR(date, 'YYYY'), date
 			ORDER BY MIN(date)
-		`, whereClause)
+		`, exclusions.spend, whereClause)
 	}
 
 	// Execute the query
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spending trends: %w", err)
 	}
@@ -253,18 +331,43 @@ func (r *PostgresReceiptRepository) GetSpendingTrends(ctx context.Context, userI
 	return trends, nil
 }
 
-// GetSpendingByCategory retrieves spending breakdown by category
-func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, userID string, startDateStr, endDateStr *string) (*domain.CategorySpending, error) {
+// inheritedItemCategory is an item's category in the category breakdown: its own when set, else
+// its receipt's category, else Uncategorized
+const inheritedItemCategory = "COALESCE(NULLIF(ri.category, ''), r.category, 'Uncategorized')"
+
+// categoryItemsQuery returns the query listing spending per item name in one category of the
+// breakdown. The category name is the parameter after the receipt conditions' arguments.
+func categoryItemsQuery(receiptConditions []string, argCount int) string {
+	conditions := append(append([]string{}, receiptConditions...), fmt.Sprintf("%s = $%d", inheritedItemCategory, argCount+1))
+	return fmt.Sprintf(`
+		SELECT 
+			ri.name, 
+			COALESCE(SUM(ri.qty * ri.price), 0) as total_spent, 
+			COUNT(*) as count,
+			COALESCE(SUM(ri.qty), 0) as total_quantity
+		FROM receipt_items ri
+		JOIN receipts r ON ri.receipt_id = r.id
+		WHERE %s
+		GROUP BY ri.name
+		ORDER BY total_spent DESC
+	`, strings.Join(conditions, " AND "))
+}
+
+// GetSpendingByCategory retrieves spending breakdown by category. Items without a category
+// count towards their receipt's category when it has one.
+func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDateStr, endDateStr *string) (*domain.CategorySpending, error) {
 	// Initialize result
 	result := &domain.CategorySpending{
 		Total:      0,
 		Categories: []domain.CategorySpendingItem{},
 	}
 
-	// Build WHERE clause with user ID and date strings
+	// Build WHERE clause with the scope and date strings
 	conditions := []string{}
-	if userID != "" {
-		conditions = append(conditions, fmt.Sprintf("user_id = '%s'", userID))
+	args := []interface{}{}
+	if column, value := scopeFilter(scope); value != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = $1", column))
+		args = append(args, value)
 	}
 	if startDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date >= '%s'::date", *startDateStr))
@@ -272,6 +375,8 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 	if endDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date <= '%s'::date", *endDateStr))
 	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -279,8 +384,8 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 
 	// Build receipt WHERE clause for joining with receipt_items
 	receiptConditions := []string{}
-	if userID != "" {
-		receiptConditions = append(receiptConditions, fmt.Sprintf("r.user_id = '%s'", userID))
+	if column, value := scopeFilter(scope); value != "" {
+		receiptConditions = append(receiptConditions, fmt.Sprintf("r.%s = $1", column))
 	}
 	if startDateStr != nil {
 		receiptConditions = append(receiptConditions, fmt.Sprintf("r.date >= '%s'::date", *startDateStr))
@@ -288,6 +393,8 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 	if endDateStr != nil {
 		receiptConditions = append(receiptConditions, fmt.Sprintf("r.date <= '%s'::date", *endDateStr))
 	}
+	receiptConditions = append(receiptConditions, exclusions.receipts...)
+	receiptConditions = append(receiptConditions, exclusions.items...)
 	receiptWhereClause := ""
 	if len(receiptConditions) > 0 {
 		receiptWhereClause = "WHERE " + strings.Join(receiptConditions, " AND ")
@@ -295,12 +402,12 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 
 	// Get total spending
 	totalQuery := fmt.Sprintf(`
-		SELECT COALESCE(SUM(total), 0) 
-		FROM receipts
+		SELECT COALESCE(SUM(%s), 0) 
+		FROM receipts r
 		%s
-	`, whereClause)
+	`, exclusions.spend, whereClause)
 
-	err := r.db.QueryRow(ctx, totalQuery).Scan(&result.Total)
+	err := r.db.QueryRow(ctx, totalQuery, args...).Scan(&result.Total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total spending: %w", err)
 	}
@@ -308,16 +415,16 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 	// Get spending by category
 	categoryQuery := fmt.Sprintf(`
 		SELECT 
-			COALESCE(ri.category, 'Uncategorized') as name, 
+			%s as name, 
 			COALESCE(SUM(ri.qty * ri.price), 0) as amount
 		FROM receipt_items ri
 		JOIN receipts r ON ri.receipt_id = r.id
 		%s
-		GROUP BY ri.category
+		GROUP BY 1
 		ORDER BY amount DESC
-	`, receiptWhereClause)
+	`, inheritedItemCategory, receiptWhereClause)
 
-	categoryRows, err := r.db.Query(ctx, categoryQuery)
+	categoryRows, err := r.db.Query(ctx, categoryQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spending by category: %w", err)
 	}
@@ -354,38 +461,12 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 		return nil, fmt.Errorf("error iterating categories: %w", err)
 	}
 
-	// For each category, get top items
+	// For each category, get spending per item name. Every name is returned so the service can
+	// group name variants before keeping the top items.
+	itemQuery := categoryItemsQuery(receiptConditions, len(args))
 	for _, category := range result.Categories {
-		itemQuery := ""
-		if receiptWhereClause == "" {
-			itemQuery = fmt.Sprintf(`
-				SELECT 
-					ri.name, 
-					COALESCE(SUM(ri.qty * ri.price), 0) as total_spent, 
-					COUNT(*) as count
-				FROM receipt_items ri
-				JOIN receipts r ON ri.receipt_id = r.id
-				WHERE ri.category = '%s' OR (ri.category IS NULL AND '%s' = 'Uncategorized')
-				GROUP BY ri.name
-				ORDER BY total_spent DESC
-				LIMIT 10
-			`, category.Name, category.Name)
-		} else {
-			itemQuery = fmt.Sprintf(`
-				SELECT 
-					ri.name, 
-					COALESCE(SUM(ri.qty * ri.price), 0) as total_spent, 
-					COUNT(*) as count
-				FROM receipt_items ri
-				JOIN receipts r ON ri.receipt_id = r.id
-				%s AND (ri.category = '%s' OR (ri.category IS NULL AND '%s' = 'Uncategorized'))
-				GROUP BY ri.name
-				ORDER BY total_spent DESC
-				LIMIT 10
-			`, receiptWhereClause, category.Name, category.Name)
-		}
-
-		itemRows, err := r.db.Query(ctx, itemQuery)
+		itemArgs := append(append([]interface{}{}, args...), category.Name)
+		itemRows, err := r.db.Query(ctx, itemQuery, itemArgs...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query category items: %w", err)
 		}
@@ -393,7 +474,7 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 		var items []domain.CategorySpendingItemDetail
 		for itemRows.Next() {
 			var item domain.CategorySpendingItemDetail
-			if err := itemRows.Scan(&item.Name, &item.TotalSpent, &item.Count); err != nil {
+			if err := itemRows.Scan(&item.Name, &item.TotalSpent, &item.Count, &item.TotalQuantity); err != nil {
 				itemRows.Close()
 				return nil, fmt.Errorf("failed to scan category item: %w", err)
 			}
@@ -410,8 +491,111 @@ func (r *PostgresReceiptRepository) GetSpendingByCategory(ctx context.Context, u
 	return result, nil
 }
 
+// trendBucketFormats are the TO_CHAR formats naming each trend period's buckets
+var trendBucketFormats = map[string]string{
+	"daily":   "YYYY-MM-DD",
+	"weekly":  `YYYY-"W"IW`,
+	"monthly": "YYYY-MM",
+	"yearly":  "YYYY",
+}
+
+// categoryTrendQuery returns the query summing the item spend of one category per period bucket.
+// The category name is the parameter after the receipt conditions' arguments.
+func categoryTrendQuery(receiptConditions []string, argCount int, period string) string {
+	conditions := append(append([]string{}, receiptConditions...), fmt.Sprintf("%s = $%d", inheritedItemCategory, argCount+1))
+	return fmt.Sprintf(`
+		SELECT 
+			TO_CHAR(r.date, '%s') as date,
+			COALESCE(SUM(ri.qty * ri.price), 0) as amount
+		FROM receipt_items ri
+		JOIN receipts r ON ri.receipt_id = r.id
+		WHERE %s
+		GROUP BY 1
+		ORDER BY MIN(r.date)
+	`, trendBucketFormats[period], strings.Join(conditions, " AND "))
+}
+
+// GetCategoryTrend retrieves the spend on items in one category over time. Items without a
+// category count towards their receipt's category, as in GetSpendingByCategory.
+func (r *PostgresReceiptRepository) GetCategoryTrend(ctx context.Context, scope domain.ReceiptScope, category, period string, startDateStr, endDateStr *string) (*domain.SpendingTrends, error) {
+	if _, ok := trendBucketFormats[period]; !ok {
+		return nil, fmt.Errorf("invalid period: %s", period)
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+	if column, value := scopeFilter(scope); value != "" {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("r.%s = $%d", column, len(args)))
+	}
+	if startDateStr != nil {
+		args = append(args, *startDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date >= $%d::date", len(args)))
+	}
+	if endDateStr != nil {
+		args = append(args, *endDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date <= $%d::date", len(args)))
+	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
+	conditions = append(conditions, exclusions.items...)
+
+	query := categoryTrendQuery(conditions, len(args), period)
+	rows, err := r.db.Query(ctx, query, append(args, category)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category trend: %w", err)
+	}
+	defer rows.Close()
+
+	trends := &domain.SpendingTrends{
+		Period: period,
+		Data:   []domain.SpendingTrendDataItem{},
+	}
+	for rows.Next() {
+		var item domain.SpendingTrendDataItem
+		if err := rows.Scan(&item.Date, &item.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan category trend: %w", err)
+		}
+		trends.Data = append(trends.Data, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category trend: %w", err)
+	}
+
+	return trends, nil
+}
+
+// merchantFrequencyQuery returns the query ranking merchants by visits. Each receipt's basket,
+// its item quantity and distinct item categories leaving out excluded categories, is summed per
+// merchant alongside the spend.
+func merchantFrequencyQuery(scope domain.ReceiptScope, exclusions scopeExclusions, whereClause string, limit int) string {
+	basketConditions := append([]string{"ri.receipt_id = r.id"}, exclusions.items...)
+	merchantKey, merchantName := merchantGrouping(scope)
+	return fmt.Sprintf(`
+		SELECT 
+			COALESCE(%s, 'Unknown') as name,
+			COUNT(*) as visits,
+			COALESCE(SUM(%s), 0) as total_spent,
+			COALESCE(AVG(%s), 0) as average_spent,
+			COALESCE(SUM(b.items), 0)::int as basket_items,
+			COALESCE(SUM(b.categories), 0)::int as basket_categories
+		FROM receipts r
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(SUM(ri.qty), 0) as items, COUNT(DISTINCT %s) as categories
+			FROM receipt_items ri
+			WHERE %s
+		) b ON true
+		%s
+		GROUP BY %s
+		ORDER BY visits DESC, total_spent DESC
+		LIMIT %d
+	`, merchantName, exclusions.spend, exclusions.spend, inheritedItemCategory,
+		strings.Join(basketConditions, " AND "), whereClause, merchantKey, limit)
+}
+
 // GetMerchantFrequency retrieves data on frequently visited merchants
-func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, userID string, startDateStr, endDateStr *string, limit int) (*domain.MerchantFrequency, error) {
+func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, scope domain.ReceiptScope, startDateStr, endDateStr *string, limit int) (*domain.MerchantFrequency, error) {
 	// Validate limit
 	if limit <= 0 {
 		limit = 10 // Default
@@ -426,10 +610,12 @@ func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, us
 		Merchants:   []domain.MerchantFrequencyDetail{},
 	}
 
-	// Build WHERE clause with user ID and date strings
+	// Build WHERE clause with the scope and date strings
 	conditions := []string{}
-	if userID != "" {
-		conditions = append(conditions, fmt.Sprintf("user_id = '%s'", userID))
+	args := []interface{}{}
+	if column, value := scopeFilter(scope); value != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = $1", column))
+		args = append(args, value)
 	}
 	if startDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date >= '%s'::date", *startDateStr))
@@ -437,6 +623,8 @@ func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, us
 	if endDateStr != nil {
 		conditions = append(conditions, fmt.Sprintf("date <= '%s'::date", *endDateStr))
 	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -445,31 +633,17 @@ func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, us
 	// Get total visit count
 	visitQuery := fmt.Sprintf(`
 		SELECT COUNT(*) 
-		FROM receipts
+		FROM receipts r
 		%s
 	`, whereClause)
 
-	err := r.db.QueryRow(ctx, visitQuery).Scan(&result.TotalVisits)
+	err := r.db.QueryRow(ctx, visitQuery, args...).Scan(&result.TotalVisits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total visits: %w", err)
 	}
 
 	// Get merchant frequency with limit
-	merchantQuery := fmt.Sprintf(`
-		SELECT 
-			COALESCE(merchant, 'Unknown') as name,
-			COUNT(*) as visits,
-			COALESCE(SUM(total), 0) as total_spent,
-			COALESCE(AVG(total), 0) as average_spent
-		FROM receipts
-		%s
-		GROUP BY merchant
-		ORDER BY visits DESC, total_spent DESC
-		LIMIT %d
-	`, whereClause, limit)
-
-	// Execute the query
-	rows, err := r.db.Query(ctx, merchantQuery)
+	rows, err := r.db.Query(ctx, merchantFrequencyQuery(scope, exclusions, whereClause, limit), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query merchant frequency: %w", err)
 	}
@@ -478,14 +652,18 @@ func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, us
 	// Process results
 	for rows.Next() {
 		var merchant domain.MerchantFrequencyDetail
+		var basketItems, basketCategories int
 		if err := rows.Scan(
 			&merchant.Name,
 			&merchant.Visits,
 			&merchant.TotalSpent,
 			&merchant.AverageSpent,
+			&basketItems,
+			&basketCategories,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan merchant: %w", err)
 		}
+		merchant.SetBasket(basketItems, basketCategories)
 
 		// Calculate percentage
 		if result.TotalVisits > 0 {
@@ -505,7 +683,7 @@ func (r *PostgresReceiptRepository) GetMerchantFrequency(ctx context.Context, us
 }
 
 // GetMonthlyComparison compares spending between two months
-func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, userID string, month1, month2 string) (*domain.MonthlyComparison, error) {
+func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, scope domain.ReceiptScope, month1, month2 string) (*domain.MonthlyComparison, error) {
 	// Validate month format (YYYY-MM)
 	for _, month := range []string{month1, month2} {
 		if _, err := time.Parse("2006-01", month); err != nil {
@@ -520,22 +698,26 @@ func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, us
 		Categories: []domain.MonthlyCategoryComparison{},
 	}
 
+	column, value := scopeFilter(scope)
+
+	// Month totals share one query; exclusions are numbered after the month and scope
+	totalArgs := []interface{}{month1, value}
+	totalExclusions := exclusionFilter(scope, &totalArgs)
+	totalQuery := fmt.Sprintf(`
+		SELECT COALESCE(SUM(%s), 0)
+		FROM receipts r
+		WHERE %s
+	`, totalExclusions.spend, strings.Join(append([]string{"TO_CHAR(date, 'YYYY-MM') = $1", column + " = $2"}, totalExclusions.receipts...), " AND "))
+
 	// Get total spending for month1
-	err := r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(total), 0)
-		FROM receipts
-		WHERE TO_CHAR(date, 'YYYY-MM') = $1 AND user_id = $2
-	`, month1, userID).Scan(&result.Month1Total)
+	err := r.db.QueryRow(ctx, totalQuery, totalArgs...).Scan(&result.Month1Total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get month1 total: %w", err)
 	}
 
 	// Get total spending for month2
-	err = r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(total), 0)
-		FROM receipts
-		WHERE TO_CHAR(date, 'YYYY-MM') = $1 AND user_id = $2
-	`, month2, userID).Scan(&result.Month2Total)
+	totalArgs[0] = month2
+	err = r.db.QueryRow(ctx, totalQuery, totalArgs...).Scan(&result.Month2Total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get month2 total: %w", err)
 	}
@@ -551,14 +733,20 @@ func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, us
 	}
 
 	// Get category comparison
-	rows, err := r.db.Query(ctx, `
+	categoryArgs := []interface{}{month1, month2, value}
+	categoryExclusions := exclusionFilter(scope, &categoryArgs)
+	categoryFilter := ""
+	if extra := append(categoryExclusions.receipts, categoryExclusions.items...); len(extra) > 0 {
+		categoryFilter = " AND " + strings.Join(extra, " AND ")
+	}
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
 		WITH month1_categories AS (
 			SELECT
 				ri.category,
 				COALESCE(SUM(ri.qty * ri.price), 0) as amount
 			FROM receipt_items ri
 			JOIN receipts r ON ri.receipt_id = r.id
-			WHERE TO_CHAR(r.date, 'YYYY-MM') = $1 AND r.user_id = $3
+			WHERE TO_CHAR(r.date, 'YYYY-MM') = $1 AND r.%s = $3%s
 			GROUP BY ri.category
 			HAVING ri.category IS NOT NULL
 		),
@@ -568,7 +756,7 @@ func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, us
 				COALESCE(SUM(ri.qty * ri.price), 0) as amount
 			FROM receipt_items ri
 			JOIN receipts r ON ri.receipt_id = r.id
-			WHERE TO_CHAR(r.date, 'YYYY-MM') = $2 AND r.user_id = $3
+			WHERE TO_CHAR(r.date, 'YYYY-MM') = $2 AND r.%s = $3%s
 			GROUP BY ri.category
 			HAVING ri.category IS NOT NULL
 		),
@@ -587,7 +775,7 @@ func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, us
 		LEFT JOIN month1_categories m1 ON ac.category = m1.category
 		LEFT JOIN month2_categories m2 ON ac.category = m2.category
 		ORDER BY GREATEST(COALESCE(m1.amount, 0), COALESCE(m2.amount, 0)) DESC
-	`, month1, month2, userID)
+	`, column, categoryFilter, column, categoryFilter), categoryArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query category comparison: %w", err)
 	}
@@ -618,3 +806,274 @@ func (r *PostgresReceiptRepository) GetMonthlyComparison(ctx context.Context, us
 
 	return result, nil
 }
+
+// GetSpendingByPaymentMethod retrieves receipt totals per stored payment method, with an empty method for receipts without one
+func (r *PostgresReceiptRepository) GetSpendingByPaymentMethod(ctx context.Context, scope domain.ReceiptScope, startDateStr, endDateStr *string) ([]domain.PaymentMethodTotal, error) {
+	column, value := scopeFilter(scope)
+	conditions := []string{fmt.Sprintf("%s = $1", column)}
+	args := []interface{}{value}
+	if startDateStr != nil {
+		args = append(args, *startDateStr)
+		conditions = append(conditions, fmt.Sprintf("date >= $%d::date", len(args)))
+	}
+	if endDateStr != nil {
+		args = append(args, *endDateStr)
+		conditions = append(conditions, fmt.Sprintf("date <= $%d::date", len(args)))
+	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT
+			COALESCE(payment_method, '') as method,
+			COALESCE(SUM(%s), 0) as amount,
+			COUNT(*) as count
+		FROM receipts r
+		WHERE %s
+		GROUP BY COALESCE(payment_method, '')
+		ORDER BY amount DESC
+	`, exclusions.spend, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending by payment method: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []domain.PaymentMethodTotal{}
+	for rows.Next() {
+		var total domain.PaymentMethodTotal
+		if err := rows.Scan(&total.Method, &total.Amount, &total.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan payment method total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment method totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// spendingBreakdownQuery returns the query grouping spending by a whitelisted dimension the same way
+// the dedicated insights do: categories sum item prices, items without a category counting towards
+// their receipt's, while merchants and payment methods sum receipt spend
+func spendingBreakdownQuery(scope domain.ReceiptScope, by string, conditions []string, exclusions scopeExclusions) (string, error) {
+	switch by {
+	case domain.BreakdownByCategory:
+		itemConditions := append(append([]string{}, conditions...), exclusions.items...)
+		return fmt.Sprintf(`
+		SELECT
+			%s as name,
+			COALESCE(SUM(ri.qty * ri.price), 0) as amount,
+			COUNT(DISTINCT r.id) as count
+		FROM receipt_items ri
+		JOIN receipts r ON ri.receipt_id = r.id
+		WHERE %s
+		GROUP BY 1
+		ORDER BY amount DESC
+	`, inheritedItemCategory, strings.Join(itemConditions, " AND ")), nil
+	case domain.BreakdownByMerchant:
+		merchantKey, merchantName := merchantGrouping(scope)
+		return receiptBreakdownQuery(fmt.Sprintf("COALESCE(%s, 'Unknown')", merchantName), merchantKey, conditions, exclusions.spend), nil
+	case domain.BreakdownByPaymentMethod:
+		return receiptBreakdownQuery("COALESCE(r.payment_method, '')", "COALESCE(r.payment_method, '')", conditions, exclusions.spend), nil
+	default:
+		return "", fmt.Errorf("unsupported breakdown dimension %q", by)
+	}
+}
+
+// receiptBreakdownQuery returns the query summing receipt spend per group key, naming each group
+func receiptBreakdownQuery(name, key string, conditions []string, spend string) string {
+	return fmt.Sprintf(`
+		SELECT
+			%s as name,
+			COALESCE(SUM(%s), 0) as amount,
+			COUNT(*) as count
+		FROM receipts r
+		WHERE %s
+		GROUP BY %s
+		ORDER BY amount DESC
+	`, name, spend, strings.Join(conditions, " AND "), key)
+}
+
+// GetSpendingBreakdown retrieves spending grouped by one of domain.BreakdownDimensions, with the
+// total spend of the receipts in scope
+func (r *PostgresReceiptRepository) GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDateStr, endDateStr *string) (*domain.SpendingBreakdown, error) {
+	column, value := scopeFilter(scope)
+	conditions := []string{fmt.Sprintf("r.%s = $1", column)}
+	args := []interface{}{value}
+	if startDateStr != nil {
+		args = append(args, *startDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date >= $%d::date", len(args)))
+	}
+	if endDateStr != nil {
+		args = append(args, *endDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date <= $%d::date", len(args)))
+	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
+
+	query, err := spendingBreakdownQuery(scope, by, conditions, exclusions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.SpendingBreakdown{
+		By:     by,
+		Groups: []domain.SpendingBreakdownGroup{},
+	}
+	totalQuery := fmt.Sprintf("SELECT COALESCE(SUM(%s), 0) FROM receipts r WHERE %s", exclusions.spend, strings.Join(conditions, " AND "))
+	if err := r.db.QueryRow(ctx, totalQuery, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("failed to get total spending: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group domain.SpendingBreakdownGroup
+		if err := rows.Scan(&group.Name, &group.Amount, &group.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan spending breakdown group: %w", err)
+		}
+		result.Groups = append(result.Groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating spending breakdown: %w", err)
+	}
+
+	return result, nil
+}
+
+// spendingByHourQuery returns the query totalling receipt spend by hour of purchase. The time zone
+// the hours are read in is the parameter after the conditions' arguments.
+func spendingByHourQuery(conditions []string, argCount int, spend string) string {
+	return fmt.Sprintf(`
+		SELECT
+			EXTRACT(HOUR FROM r.purchased_at AT TIME ZONE $%d)::int as hour,
+			COALESCE(SUM(%s), 0) as amount,
+			COUNT(*) as count
+		FROM receipts r
+		WHERE %s
+		GROUP BY 1
+		ORDER BY 1
+	`, argCount+1, spend, strings.Join(append(append([]string{}, conditions...), "r.purchased_at IS NOT NULL"), " AND "))
+}
+
+// GetSpendingByHour retrieves the spending per hour of day, in the given time zone, of receipts
+// with a time of purchase
+func (r *PostgresReceiptRepository) GetSpendingByHour(ctx context.Context, scope domain.ReceiptScope, timezone string, startDateStr, endDateStr *string) ([]domain.HourlySpend, error) {
+	column, value := scopeFilter(scope)
+	conditions := []string{fmt.Sprintf("r.%s = $1", column)}
+	args := []interface{}{value}
+	if startDateStr != nil {
+		args = append(args, *startDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date >= $%d::date", len(args)))
+	}
+	if endDateStr != nil {
+		args = append(args, *endDateStr)
+		conditions = append(conditions, fmt.Sprintf("r.date <= $%d::date", len(args)))
+	}
+	exclusions := exclusionFilter(scope, &args)
+	conditions = append(conditions, exclusions.receipts...)
+
+	query := spendingByHourQuery(conditions, len(args), exclusions.spend)
+	rows, err := r.db.Query(ctx, query, append(args, timezone)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending by hour: %w", err)
+	}
+	defer rows.Close()
+
+	hours := []domain.HourlySpend{}
+	for rows.Next() {
+		var hour domain.HourlySpend
+		if err := rows.Scan(&hour.Hour, &hour.Total, &hour.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly spending: %w", err)
+		}
+		hours = append(hours, hour)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hourly spending: %w", err)
+	}
+
+	return hours, nil
+}
+
+// GetMonthlySpendTotals retrieves total spending per month for an inclusive range of months (YYYY-MM)
+func (r *PostgresReceiptRepository) GetMonthlySpendTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlySpendTotal, error) {
+	column, value := scopeFilter(scope)
+	args := []interface{}{value, startMonth, endMonth}
+	exclusions := exclusionFilter(scope, &args)
+	conditions := append([]string{column + " = $1", "TO_CHAR(date, 'YYYY-MM') BETWEEN $2 AND $3"}, exclusions.receipts...)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT
+			TO_CHAR(date, 'YYYY-MM') as month,
+			COALESCE(SUM(%s), 0) as amount
+		FROM receipts r
+		WHERE %s
+		GROUP BY TO_CHAR(date, 'YYYY-MM')
+		ORDER BY month
+	`, exclusions.spend, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly spend totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []domain.MonthlySpendTotal{}
+	for rows.Next() {
+		var total domain.MonthlySpendTotal
+		if err := rows.Scan(&total.Month, &total.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly spend total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monthly spend totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// GetMonthlyCategoryTotals retrieves spending per category per month for an inclusive range of months (YYYY-MM)
+func (r *PostgresReceiptRepository) GetMonthlyCategoryTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlyCategorySpend, error) {
+	column, value := scopeFilter(scope)
+	args := []interface{}{value, startMonth, endMonth}
+	exclusions := exclusionFilter(scope, &args)
+	conditions := append([]string{"r." + column + " = $1", "TO_CHAR(r.date, 'YYYY-MM') BETWEEN $2 AND $3"}, exclusions.receipts...)
+	conditions = append(conditions, exclusions.items...)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT
+			TO_CHAR(r.date, 'YYYY-MM') as month,
+			COALESCE(ri.category, 'Uncategorized') as category,
+			COALESCE(SUM(ri.qty * ri.price), 0) as amount
+		FROM receipt_items ri
+		JOIN receipts r ON ri.receipt_id = r.id
+		WHERE %s
+		GROUP BY TO_CHAR(r.date, 'YYYY-MM'), COALESCE(ri.category, 'Uncategorized')
+		ORDER BY month, category
+	`, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly category totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []domain.MonthlyCategorySpend{}
+	for rows.Next() {
+		var total domain.MonthlyCategorySpend
+		if err := rows.Scan(&total.Month, &total.Category, &total.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly category total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monthly category totals: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+func TestPlanItemChanges(t *testing.T) {
+	existing := []domain.ReceiptItem{
+		{ID: "item-1", Name: "Coffee", Quantity: 1, Price: 3.5, Currency: "USD", Category: "Food"},
+		{ID: "item-2", Name: "Bagel", Quantity: 1, Price: 2.25, Currency: "USD", Category: "Food"},
+		{ID: "item-3", Name: "Newspaper", Quantity: 1, Price: 1.5, Currency: "USD"},
+	}
+
+	t.Run("unchanged items keep their IDs", func(t *testing.T) {
+		submitted := []domain.ReceiptItem{
+			existing[0],
+			{ID: "item-2", Name: "Bagel", Quantity: 2, Price: 2.25, Currency: "USD", Category: "Food"},
+			existing[2],
+		}
+
+		changes := planItemChanges(existing, submitted)
+		assert.Equal(t, []int{1}, changes.updated)
+		assert.Empty(t, changes.inserted)
+		assert.Empty(t, changes.deleted)
+		assert.Equal(t, "item-1", submitted[0].ID)
+	})
+
+	t.Run("new, foreign and removed items", func(t *testing.T) {
+		submitted := []domain.ReceiptItem{
+			existing[0],
+			{Name: "Muffin", Quantity: 1, Price: 2.75, Currency: "USD"},
+			{ID: "other-receipt-item", Name: "Tea", Quantity: 1, Price: 2, Currency: "USD"},
+			existing[0],
+		}
+
+		changes := planItemChanges(existing, submitted)
+		assert.Empty(t, changes.updated)
+		assert.Equal(t, []int{1, 2, 3}, changes.inserted)
+		assert.Equal(t, []string{"item-2", "item-3"}, changes.deleted)
+	})
+}
+
+func TestReceiptListConditionsHasImage(t *testing.T) {
+	withImage, withoutImage := true, false
+
+	where, args := receiptListConditions(domain.ReceiptFilter{UserID: "user-1", HasImage: &withImage})
+	assert.Equal(t, "WHERE user_id = $1 AND COALESCE(NULLIF(receipt_url, ''), NULLIF(image_url, '')) IS NOT NULL", where)
+	assert.Equal(t, []interface{}{"user-1"}, args)
+
+	where, _ = receiptListConditions(domain.ReceiptFilter{UserID: "user-1", HasImage: &withoutImage})
+	assert.Equal(t, "WHERE user_id = $1 AND COALESCE(NULLIF(receipt_url, ''), NULLIF(image_url, '')) IS NULL", where)
+
+	where, _ = receiptListConditions(domain.ReceiptFilter{UserID: "user-1"})
+	assert.NotContains(t, where, "receipt_url")
+}
+
+func TestReceiptListConditionsModifiedSince(t *testing.T) {
+	since := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	where, args := receiptListConditions(domain.ReceiptFilter{UserID: "user-1", ModifiedSince: &since})
+	assert.Equal(t, "WHERE user_id = $1 AND updated_at > $2", where)
+	assert.Equal(t, []interface{}{"user-1", since}, args)
+	assert.Equal(t, "updated_at ASC, id ASC", receiptOrderBy("updatedAt", "asc"))
+}
+
+func TestCategoryItemsQueryInheritsReceiptCategory(t *testing.T) {
+	query := categoryItemsQuery([]string{"r.user_id = $1"}, 1)
+
+	// Uncategorized items fall under their receipt's category, explicit item categories win
+	assert.Equal(t, "COALESCE(NULLIF(ri.category, ''), r.category, 'Uncategorized')", inheritedItemCategory)
+	assert.Contains(t, query, "WHERE r.user_id = $1 AND "+inheritedItemCategory+" = $2")
+
+	query = categoryItemsQuery(nil, 0)
+	assert.Contains(t, query, "WHERE "+inheritedItemCategory+" = $1")
+}
+
+func TestMerchantGrouping(t *testing.T) {
+	// Both Walmart spellings share a normalized merchant
+	assert.Equal(t, domain.NormalizeMerchant("Walmart"), domain.NormalizeMerchant("WALMART #1234"))
+
+	key, name := merchantGrouping(domain.ReceiptScope{UserID: "user-1"})
+	assert.Equal(t, "COALESCE(NULLIF(r.normalized_merchant, ''), r.merchant)", key)
+	assert.Equal(t, "MODE() WITHIN GROUP (ORDER BY r.merchant)", name)
+
+	// Raw grouping keeps each spelling separate
+	key, name = merchantGrouping(domain.ReceiptScope{UserID: "user-1", RawMerchants: true})
+	assert.Equal(t, "r.merchant", key)
+	assert.Equal(t, "r.merchant", name)
+}
+
+func TestCategoryTrendQuery(t *testing.T) {
+	query := categoryTrendQuery([]string{"r.user_id = $1", "r.date >= $2::date"}, 2, "monthly")
+
+	// Only items in the requested category are summed into each month
+	assert.Contains(t, query, "TO_CHAR(r.date, 'YYYY-MM') as date")
+	assert.Contains(t, query, "SUM(ri.qty * ri.price)")
+	assert.Contains(t, query, "WHERE r.user_id = $1 AND r.date >= $2::date AND "+inheritedItemCategory+" = $3")
+	assert.Contains(t, query, "GROUP BY 1")
+
+	assert.Contains(t, categoryTrendQuery(nil, 0, "weekly"), `TO_CHAR(r.date, 'YYYY-"W"IW')`)
+}
+
+func TestReceiptOrderByBreaksTiesByID(t *testing.T) {
+	tests := []struct {
+		sortBy, sortOrder string
+		want              string
+	}{
+		{"", "", "date DESC, purchased_at DESC NULLS LAST, id DESC"},
+		{"total", "asc", "total ASC, id ASC"},
+		{"merchant", "desc", "LOWER(merchant) DESC, id DESC"},
+		{"createdAt", "asc", "created_at ASC, id ASC"},
+		{"unknown", "asc", "date ASC, purchased_at ASC NULLS LAST, id ASC"},
+	}
+	for _, tt := range tests {
+		// Same-date receipts must page in a fixed order, so every ordering ends on the unique id
+		assert.Equal(t, tt.want, receiptOrderBy(tt.sortBy, tt.sortOrder))
+	}
+}
+
+func TestSpendingByHourQuery(t *testing.T) {
+	query := spendingByHourQuery([]string{"r.user_id = $1", "r.date >= $2::date"}, 2, "r.total")
+
+	// Hours are read in the time zone passed after the conditions' arguments
+	assert.Contains(t, query, "EXTRACT(HOUR FROM r.purchased_at AT TIME ZONE $3)")
+	assert.Contains(t, query, "WHERE r.user_id = $1 AND r.date >= $2::date AND r.purchased_at IS NOT NULL")
+	assert.Contains(t, query, "SUM(r.total)")
+}
+
+func TestMerchantFrequencyQuery(t *testing.T) {
+	args := []interface{}{"user-1"}
+	scope := domain.ReceiptScope{UserID: "user-1", ExcludeCategories: []string{"Tobacco"}}
+	query := merchantFrequencyQuery(scope, exclusionFilter(scope, &args), "WHERE user_id = $1", 10)
+
+	// Each receipt's basket counts item quantity and distinct categories outside the exclusions
+	assert.Contains(t, query, "COALESCE(SUM(ri.qty), 0) as items, COUNT(DISTINCT "+inheritedItemCategory+") as categories")
+	assert.Contains(t, query, "WHERE ri.receipt_id = r.id AND NOT LOWER(COALESCE(ri.category, 'Uncategorized')) = ANY($2)")
+	assert.Contains(t, query, "LEFT JOIN LATERAL")
+	assert.Contains(t, query, "COALESCE(SUM(b.items), 0)::int as basket_items")
+	assert.Contains(t, query, "LIMIT 10")
+}
+
+func TestSpendingBreakdownQuery(t *testing.T) {
+	args := []interface{}{"user-1"}
+	scope := domain.ReceiptScope{UserID: "user-1", ExcludeCategories: []string{"Tobacco"}}
+	exclusions := exclusionFilter(scope, &args)
+	conditions := append([]string{"r.user_id = $1"}, exclusions.receipts...)
+
+	// Categories group items the way the category breakdown does, leaving out excluded items
+	query, err := spendingBreakdownQuery(scope, domain.BreakdownByCategory, conditions, exclusions)
+	require.NoError(t, err)
+	assert.Contains(t, query, inheritedItemCategory+" as name")
+	assert.Contains(t, query, "COALESCE(SUM(ri.qty * ri.price), 0) as amount")
+	assert.Contains(t, query, "NOT LOWER(COALESCE(ri.category, 'Uncategorized')) = ANY($2)")
+
+	// Merchants group receipt spend by the merchant frequency's key and name
+	query, err = spendingBreakdownQuery(scope, domain.BreakdownByMerchant, conditions, exclusions)
+	require.NoError(t, err)
+	merchantKey, merchantName := merchantGrouping(scope)
+	frequency := merchantFrequencyQuery(scope, exclusions, "WHERE r.user_id = $1", 10)
+	assert.Contains(t, query, "COALESCE("+merchantName+", 'Unknown') as name")
+	assert.Contains(t, query, "GROUP BY "+merchantKey)
+	assert.Contains(t, frequency, "GROUP BY "+merchantKey)
+	assert.Contains(t, query, "COALESCE(SUM("+exclusions.spend+"), 0) as amount")
+	assert.Contains(t, frequency, "COALESCE(SUM("+exclusions.spend+"), 0) as total_spent")
+
+	query, err = spendingBreakdownQuery(scope, domain.BreakdownByPaymentMethod, conditions, exclusions)
+	require.NoError(t, err)
+	assert.Contains(t, query, "GROUP BY COALESCE(r.payment_method, '')")
+
+	// Only whitelisted dimensions reach the SQL
+	_, err = spendingBreakdownQuery(scope, "r.merchant; DROP TABLE receipts", conditions, exclusions)
+	assert.Error(t, err)
+}
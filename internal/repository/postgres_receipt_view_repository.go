@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// PostgresReceiptViewRepository implements ReceiptViewRepository using PostgreSQL
+type PostgresReceiptViewRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresReceiptViewRepository creates a new PostgreSQL receipt view repository
+func NewPostgresReceiptViewRepository(db *pgxpool.Pool) ReceiptViewRepository {
+	return &PostgresReceiptViewRepository{db: db}
+}
+
+// UpsertReceiptView creates a view or updates the filters of the existing view with the same name
+func (r *PostgresReceiptViewRepository) UpsertReceiptView(ctx context.Context, view *domain.ReceiptView) (*domain.ReceiptView, error) {
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode receipt view filters: %w", err)
+	}
+
+	stored := *view
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO receipt_views (user_id, name, filters)
+		VALUES ($1, $2, $3::jsonb)
+		ON CONFLICT (user_id, LOWER(name))
+		DO UPDATE SET filters = EXCLUDED.filters, name = EXCLUDED.name
+		RETURNING id, created_at, updated_at
+	`, view.UserID, view.Name, string(filters)).Scan(&stored.ID, &stored.CreatedAt, &stored.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert receipt view: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// ListReceiptViews retrieves all saved views for a user
+func (r *PostgresReceiptViewRepository) ListReceiptViews(ctx context.Context, userID string) ([]domain.ReceiptView, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, name, filters, created_at, updated_at
+		FROM receipt_views
+		WHERE user_id = $1
+		ORDER BY LOWER(name)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipt views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []domain.ReceiptView{}
+	for rows.Next() {
+		var view domain.ReceiptView
+		if err := rows.Scan(&view.ID, &view.UserID, &view.Name, &view.Filters, &view.CreatedAt, &view.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt view: %w", err)
+		}
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating receipt views: %w", err)
+	}
+
+	return views, nil
+}
+
+// GetReceiptViewByName retrieves a user's view by name, or nil when none exists
+func (r *PostgresReceiptViewRepository) GetReceiptViewByName(ctx context.Context, userID, name string) (*domain.ReceiptView, error) {
+	var view domain.ReceiptView
+	err := r.db.QueryRow(ctx, `
+		SELECT id, user_id, name, filters, created_at, updated_at
+		FROM receipt_views
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2)
+	`, userID, name).Scan(&view.ID, &view.UserID, &view.Name, &view.Filters, &view.CreatedAt, &view.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get receipt view: %w", err)
+	}
+
+	return &view, nil
+}
+
+// DeleteReceiptView deletes a view owned by the user
+func (r *PostgresReceiptViewRepository) DeleteReceiptView(ctx context.Context, userID, viewID string) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM receipt_views WHERE id = $1 AND user_id = $2`, viewID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete receipt view: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("receipt view not found: %s", viewID)
+	}
+
+	return nil
+}
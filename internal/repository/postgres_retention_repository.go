@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// PostgresRetentionRepository implements RetentionRepository using PostgreSQL
+type PostgresRetentionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRetentionRepository creates a new PostgreSQL retention repository
+func NewPostgresRetentionRepository(db *pgxpool.Pool) RetentionRepository {
+	return &PostgresRetentionRepository{db: db}
+}
+
+// ListReceiptsCreatedBefore retrieves a batch of receipts created before the given time
+func (r *PostgresRetentionRepository) ListReceiptsCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error) {
+	return r.listCreatedBefore(ctx, "", before, afterID, limit)
+}
+
+// ListImagesCreatedBefore retrieves a batch of receipts with a stored image created before the given time
+func (r *PostgresRetentionRepository) ListImagesCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error) {
+	return r.listCreatedBefore(ctx, "AND COALESCE(receipt_url, '') <> ''", before, afterID, limit)
+}
+
+// listCreatedBefore pages through receipts created before the given time by ID, with an extra condition
+func (r *PostgresRetentionRepository) listCreatedBefore(ctx context.Context, condition string, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error) {
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(receipt_url, ''), created_at
+		FROM receipts
+		WHERE created_at < $1 AND ($2::text = '' OR id > $2::uuid) %s
+		ORDER BY id
+		LIMIT $3
+	`, condition)
+
+	rows, err := r.db.Query(ctx, query, before, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receipts past retention: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []domain.RetentionCandidate{}
+	for rows.Next() {
+		var candidate domain.RetentionCandidate
+		if err := rows.Scan(&candidate.ID, &candidate.ReceiptURL, &candidate.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt past retention: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating receipts past retention: %w", err)
+	}
+	return candidates, nil
+}
+
+// DeleteReceipts deletes the receipts, whose items are removed by cascade, and records each deletion
+// in its owner's activity in the same statement so syncing clients learn the receipt is gone
+func (r *PostgresRetentionRepository) DeleteReceipts(ctx context.Context, receiptIDs []string) (int, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM receipts WHERE id = ANY($1::uuid[])
+			RETURNING id, user_id
+		), recorded AS (
+			INSERT INTO activity (user_id, action, receipt_id)
+			SELECT user_id, $2, id FROM deleted WHERE user_id IS NOT NULL
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var deleted int
+	if err := r.db.QueryRow(ctx, query, receiptIDs, domain.ActivityReceiptDeleted).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("failed to delete receipts: %w", err)
+	}
+	return deleted, nil
+}
+
+// ClearReceiptImages clears the stored image URL of the receipts
+func (r *PostgresRetentionRepository) ClearReceiptImages(ctx context.Context, receiptIDs []string) error {
+	_, err := r.db.Exec(ctx, `UPDATE receipts SET receipt_url = '', updated_at = CURRENT_TIMESTAMP WHERE id = ANY($1::uuid[])`, receiptIDs)
+	if err != nil {
+		return fmt.Errorf("failed to clear receipt images: %w", err)
+	}
+	return nil
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 )
@@ -73,7 +77,7 @@ func (r *PostgresUserRepository) CreateUserWithPassword(ctx context.Context, use
 // GetUserByID retrieves a user by their ID
 func (r *PostgresUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
 	query := `
-		SELECT id, email, name, picture_url, email_verified, is_active, created_at, updated_at
+		SELECT id, email, name, picture_url, email_verified, is_active, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -86,6 +90,7 @@ func (r *PostgresUserRepository) GetUserByID(ctx context.Context, userID string)
 		&user.PictureURL,
 		&user.EmailVerified,
 		&user.IsActive,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -100,9 +105,9 @@ func (r *PostgresUserRepository) GetUserByID(ctx context.Context, userID string)
 // GetUserByEmail retrieves a user by their email
 func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, name, picture_url, email_verified, is_active, created_at, updated_at
+		SELECT id, email, name, picture_url, email_verified, is_active, role, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE LOWER(email) = LOWER($1)
 	`
 
 	user := &domain.User{}
@@ -113,6 +118,7 @@ func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email strin
 		&user.PictureURL,
 		&user.EmailVerified,
 		&user.IsActive,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -124,12 +130,71 @@ func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email strin
 	return user, nil
 }
 
+// GetUserByEmailIngestToken retrieves the user owning a forwarding address token, or nil when none does
+func (r *PostgresUserRepository) GetUserByEmailIngestToken(ctx context.Context, token string) (*domain.User, error) {
+	query := `
+		SELECT id, email, name, picture_url, email_verified, is_active, role, created_at, updated_at
+		FROM users
+		WHERE email_ingest_token = $1
+	`
+
+	user := &domain.User{}
+	err := r.db.QueryRow(ctx, query, token).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.PictureURL,
+		&user.EmailVerified,
+		&user.IsActive,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by email ingest token: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetEmailIngestToken retrieves the user's forwarding address token
+func (r *PostgresUserRepository) GetEmailIngestToken(ctx context.Context, userID string) (string, error) {
+	var token string
+	err := r.db.QueryRow(ctx, `SELECT email_ingest_token FROM users WHERE id = $1`, userID).Scan(&token)
+	if err != nil {
+		return "", fmt.Errorf("failed to get email ingest token: %w", err)
+	}
+	return token, nil
+}
+
+// SetEmailIngestToken replaces the user's forwarding address token
+func (r *PostgresUserRepository) SetEmailIngestToken(ctx context.Context, userID, token string) error {
+	query := `
+		UPDATE users
+		SET email_ingest_token = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	commandTag, err := r.db.Exec(ctx, query, userID, token)
+	if err != nil {
+		return fmt.Errorf("failed to set email ingest token: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found: %s", userID)
+	}
+	return nil
+}
+
 // GetUserByEmailWithPassword retrieves a user by their email including password hash
 func (r *PostgresUserRepository) GetUserByEmailWithPassword(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, name, COALESCE(password_hash, ''), picture_url, email_verified, is_active, created_at, updated_at
+		SELECT id, email, name, COALESCE(password_hash, ''), picture_url, email_verified, is_active, role, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE LOWER(email) = LOWER($1)
 	`
 
 	user := &domain.User{}
@@ -141,6 +206,7 @@ func (r *PostgresUserRepository) GetUserByEmailWithPassword(ctx context.Context,
 		&user.PictureURL,
 		&user.EmailVerified,
 		&user.IsActive,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -178,6 +244,108 @@ func (r *PostgresUserRepository) UpdateUser(ctx context.Context, user *domain.Us
 	return nil
 }
 
+// userListConditions builds the WHERE clause and its arguments for the user listing filter
+func userListConditions(filter domain.UserFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Email != "" {
+		args = append(args, "%"+filter.Email+"%")
+		conditions = append(conditions, fmt.Sprintf("email ILIKE $%d", len(args)))
+	}
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.Active != nil {
+		args = append(args, *filter.Active)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListUsers retrieves users matching the filter, newest first, with pagination
+func (r *PostgresUserRepository) ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.PaginatedUsers, error) {
+	result := &domain.PaginatedUsers{
+		Data: []domain.User{},
+	}
+
+	// Set default pagination values if not provided
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	whereClause, args := userListConditions(filter)
+
+	var totalItems int
+	if err := r.db.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM users %s`, whereClause), args...).Scan(&totalItems); err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	result.Pagination = domain.Pagination{
+		TotalItems:  totalItems,
+		TotalPages:  int(math.Ceil(float64(totalItems) / float64(filter.Limit))),
+		CurrentPage: filter.Page,
+		Limit:       filter.Limit,
+	}
+	if totalItems == 0 {
+		return result, nil
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, email, name, picture_url, email_verified, is_active, role, created_at, updated_at
+		FROM users
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.Name,
+			&user.PictureURL,
+			&user.EmailVerified,
+			&user.IsActive,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		result.Data = append(result.Data, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return result, nil
+}
+
 // CreateOAuthProvider creates a new OAuth provider record
 func (r *PostgresUserRepository) CreateOAuthProvider(ctx context.Context, provider *domain.OAuthProvider) error {
 	// Convert provider data to JSON
@@ -321,3 +489,97 @@ func (r *PostgresUserRepository) UpdateOAuthProvider(ctx context.Context, provid
 
 	return nil
 }
+
+// GetLoginAttempts retrieves a user's failed login tracking, or nil when there is none
+func (r *PostgresUserRepository) GetLoginAttempts(ctx context.Context, userID string) (*domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	err := r.db.QueryRow(ctx, `
+		SELECT failed_count, locked_until
+		FROM login_attempts
+		WHERE user_id = $1
+	`, userID).Scan(&attempts.FailedCount, &attempts.LockedUntil)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	return &attempts, nil
+}
+
+// RecordFailedLogin counts a failed login in one statement, so concurrent failures are all
+// counted. Reaching maxFailed failures sets the lock and resets the count.
+func (r *PostgresUserRepository) RecordFailedLogin(ctx context.Context, userID string, maxFailed int, lockUntil time.Time) (*domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_attempts (user_id, failed_count, locked_until, updated_at)
+		VALUES ($1, 1, NULL, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			failed_count = login_attempts.failed_count + 1,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING failed_count, locked_until
+	`, userID).Scan(&attempts.FailedCount, &attempts.LockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+	if attempts.FailedCount < maxFailed {
+		return &attempts, nil
+	}
+
+	err = r.db.QueryRow(ctx, `
+		UPDATE login_attempts
+		SET failed_count = 0, locked_until = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		RETURNING failed_count, locked_until
+	`, userID, lockUntil).Scan(&attempts.FailedCount, &attempts.LockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	return &attempts, nil
+}
+
+// ClearLoginAttempts removes a user's failed login tracking
+func (r *PostgresUserRepository) ClearLoginAttempts(ctx context.Context, userID string) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM login_attempts WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+	return nil
+}
+
+// GetUserPreferences retrieves a user's saved preferences, or nil when none have been saved
+func (r *PostgresUserRepository) GetUserPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	var preferences domain.UserPreferences
+	var shareFeedback bool
+	err := r.db.QueryRow(ctx, `
+		SELECT default_currency, timezone, share_extraction_feedback, number_locale
+		FROM user_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&preferences.DefaultCurrency, &preferences.Timezone, &shareFeedback, &preferences.NumberLocale)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	preferences.ShareExtractionFeedback = &shareFeedback
+
+	return &preferences, nil
+}
+
+// SaveUserPreferences creates or replaces a user's preferences
+func (r *PostgresUserRepository) SaveUserPreferences(ctx context.Context, userID string, preferences domain.UserPreferences) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_preferences (user_id, default_currency, timezone, share_extraction_feedback, number_locale)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET default_currency = EXCLUDED.default_currency, timezone = EXCLUDED.timezone,
+			share_extraction_feedback = EXCLUDED.share_extraction_feedback, number_locale = EXCLUDED.number_locale
+	`, userID, preferences.DefaultCurrency, preferences.Timezone, preferences.SharesExtractionFeedback(), preferences.NumberLocale)
+	if err != nil {
+		return fmt.Errorf("failed to save user preferences: %w", err)
+	}
+
+	return nil
+}
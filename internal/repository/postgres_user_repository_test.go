@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+func TestUserListConditions(t *testing.T) {
+	active := false
+	where, args := userListConditions(domain.UserFilter{Email: "acme", Role: domain.UserRoleAdmin, Active: &active})
+
+	assert.Equal(t, "WHERE email ILIKE $1 AND role = $2 AND is_active = $3", where)
+	assert.Equal(t, []interface{}{"%acme%", "admin", false}, args)
+
+	where, args = userListConditions(domain.UserFilter{})
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+}
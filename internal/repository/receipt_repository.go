@@ -21,16 +21,40 @@ type ReceiptRepository interface {
 	GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error)
 	UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error)
 	DeleteReceipt(ctx context.Context, receiptID string) error
+	// DeleteReceipts deletes all of the receipts in one transaction, or none of them if any is missing
+	DeleteReceipts(ctx context.Context, receiptIDs []string) error
+	// SetReceiptOrganization shares a receipt with an organization, or makes it personal again when orgID is empty
+	SetReceiptOrganization(ctx context.Context, receiptID, orgID string) error
+	// MergeReceipts saves the merged receipt's fields, moves the listed items of the removed receipt onto
+	// it and deletes the removed receipt, all in one transaction
+	MergeReceipts(ctx context.Context, merged *domain.Receipt, removedID string, movedItemIDs []string) (*domain.Receipt, error)
+	// UpdateReceiptImage replaces the stored image URL of a receipt, leaving its data unchanged
+	UpdateReceiptImage(ctx context.Context, receiptID, receiptURL string) (time.Time, error)
 
 	// Receipt querying operations
 	ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error)
+	CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error)
+	// CountScannedReceipts counts the user's receipts created by a scan since the given time
+	CountScannedReceipts(ctx context.Context, userID string, since time.Time) (int, error)
 	GetReceiptItems(ctx context.Context, receiptID string) ([]domain.ReceiptItem, error)
 	GetReceiptsWithItems(ctx context.Context, filter ReceiptFilterWithItems) ([]domain.Receipt, error)
+	// ListReceiptCurrencies lists the distinct currencies of the user's receipt items with usage counts
+	ListReceiptCurrencies(ctx context.Context, userID string) ([]domain.CurrencyUsage, error)
 
-	// Dashboard and insights operations
-	GetDashboardSummary(ctx context.Context, userID string, startDate, endDate *string) (*domain.DashboardSummary, error)
-	GetSpendingTrends(ctx context.Context, userID string, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
-	GetSpendingByCategory(ctx context.Context, userID string, startDate, endDate *string) (*domain.CategorySpending, error)
-	GetMerchantFrequency(ctx context.Context, userID string, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error)
-	GetMonthlyComparison(ctx context.Context, userID string, month1, month2 string) (*domain.MonthlyComparison, error)
+	// Dashboard and insights operations, covering the receipts in scope
+	GetDashboardSummary(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.DashboardSummary, error)
+	GetSpendingTrends(ctx context.Context, scope domain.ReceiptScope, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
+	GetCategoryTrend(ctx context.Context, scope domain.ReceiptScope, category, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
+	GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.CategorySpending, error)
+	GetMerchantFrequency(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error)
+	GetMonthlyComparison(ctx context.Context, scope domain.ReceiptScope, month1, month2 string) (*domain.MonthlyComparison, error)
+	GetSpendingByPaymentMethod(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) ([]domain.PaymentMethodTotal, error)
+	// GetSpendingBreakdown totals spending grouped by one of domain.BreakdownDimensions. Payment methods
+	// are grouped as stored, leaving their normalization to the caller.
+	GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDate, endDate *string) (*domain.SpendingBreakdown, error)
+	// GetSpendingByHour totals receipts with a time of purchase by hour of day in the IANA time zone,
+	// returning only the hours with spending
+	GetSpendingByHour(ctx context.Context, scope domain.ReceiptScope, timezone string, startDate, endDate *string) ([]domain.HourlySpend, error)
+	GetMonthlySpendTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlySpendTotal, error)
+	GetMonthlyCategoryTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlyCategorySpend, error)
 }
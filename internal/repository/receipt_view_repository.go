@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ReceiptViewRepository defines the interface for saved receipt view data operations
+type ReceiptViewRepository interface {
+	// UpsertReceiptView creates a view or replaces the filters of the user's view with the same name
+	UpsertReceiptView(ctx context.Context, view *domain.ReceiptView) (*domain.ReceiptView, error)
+	ListReceiptViews(ctx context.Context, userID string) ([]domain.ReceiptView, error)
+	// GetReceiptViewByName returns nil without error when no view matches
+	GetReceiptViewByName(ctx context.Context, userID, name string) (*domain.ReceiptView, error)
+	DeleteReceiptView(ctx context.Context, userID, viewID string) error
+}
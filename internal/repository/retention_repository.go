@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// RetentionRepository defines the interface for finding and removing receipts past their retention
+type RetentionRepository interface {
+	// ListReceiptsCreatedBefore returns up to limit receipts created before the time, ordered by ID
+	// and starting after afterID, so batches can page through them whether or not they are deleted
+	ListReceiptsCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error)
+	// ListImagesCreatedBefore is ListReceiptsCreatedBefore limited to receipts with a stored image
+	ListImagesCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error)
+	// DeleteReceipts deletes the receipts with their items, recording a deleted activity for each,
+	// and returns how many were deleted
+	DeleteReceipts(ctx context.Context, receiptIDs []string) (int, error)
+	// ClearReceiptImages removes the stored image reference from the receipts
+	ClearReceiptImages(ctx context.Context, receiptIDs []string) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 )
@@ -15,10 +16,30 @@ type UserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUserByEmailWithPassword(ctx context.Context, email string) (*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
+	// ListUsers returns a page of users matching the filter, newest first, with the total count
+	ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.PaginatedUsers, error)
+	// GetUserByEmailIngestToken returns the user owning a forwarding address token, or nil when none does
+	GetUserByEmailIngestToken(ctx context.Context, token string) (*domain.User, error)
+	// GetEmailIngestToken returns the user's forwarding address token
+	GetEmailIngestToken(ctx context.Context, userID string) (string, error)
+	// SetEmailIngestToken replaces the user's forwarding address token
+	SetEmailIngestToken(ctx context.Context, userID, token string) error
 
 	// OAuth provider operations
 	CreateOAuthProvider(ctx context.Context, provider *domain.OAuthProvider) error
 	GetOAuthProvider(ctx context.Context, providerName, providerUserID string) (*domain.OAuthProvider, error)
 	GetOAuthProvidersByUserID(ctx context.Context, userID string) ([]domain.OAuthProvider, error)
 	UpdateOAuthProvider(ctx context.Context, provider *domain.OAuthProvider) error
+
+	// Login attempt operations
+	// GetLoginAttempts returns the user's failed login tracking, or nil when there is none
+	GetLoginAttempts(ctx context.Context, userID string) (*domain.LoginAttempts, error)
+	// RecordFailedLogin counts a failed login. Reaching maxFailed failures locks the user until
+	// lockUntil and starts the count again.
+	RecordFailedLogin(ctx context.Context, userID string, maxFailed int, lockUntil time.Time) (*domain.LoginAttempts, error)
+	ClearLoginAttempts(ctx context.Context, userID string) error
+
+	// Preference operations
+	GetUserPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error)
+	SaveUserPreferences(ctx context.Context, userID string, preferences domain.UserPreferences) error
 }
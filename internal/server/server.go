@@ -4,10 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +24,7 @@ type Server struct {
 	receiptHandler *handler.ReceiptHandler
 	receiptService service.ReceiptService
 	config         *config.Config
+	routeTimeouts  map[string]time.Duration // Per-route deadlines read by the timeout middleware
 }
 
 // NewServer creates and configures a new server instance
@@ -35,16 +34,29 @@ func NewServer(cfg *config.Config) *Server {
 
 	// Add middleware
 	router.Use(gin.Recovery())
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}))
 	router.Use(middleware.RequestResponseLogger(middleware.LoggerConfig{
-		Format: cfg.LogFormat,
-		Level:  cfg.LogLevel,
+		Format:            cfg.LogFormat,
+		Level:             cfg.LogLevel,
+		ExcludePaths:      cfg.LogExcludePaths,
+		LogExcludedErrors: cfg.LogExcludedErrors,
+	}))
+	// Scan routes are added to the overrides once registered, see SetScanRoutes
+	routeTimeouts := map[string]time.Duration{}
+	router.Use(middleware.Timeout(middleware.TimeoutConfig{
+		Default:   cfg.RequestTimeout,
+		Overrides: routeTimeouts,
 	}))
 
 	// Create server
 	server := &Server{
-		router: router,
-		config: cfg,
+		router:        router,
+		config:        cfg,
+		routeTimeouts: routeTimeouts,
 		httpServer: &http.Server{
 			Addr:         fmt.Sprintf(":%d", cfg.Port),
 			Handler:      router,
@@ -52,6 +64,7 @@ func NewServer(cfg *config.Config) *Server {
 			WriteTimeout: cfg.WriteTimeout,
 		},
 	}
+	server.fitWriteTimeout(cfg.RequestTimeout)
 
 	// Configure routes
 	server.setupRoutes()
@@ -74,6 +87,37 @@ func (s *Server) GetRouter() *gin.Engine {
 	return s.router
 }
 
+// SetScanRoutes gives the registered routes among paths the scan request timeout instead of the
+// default one. Paths that are not registered, such as optional routes left disabled, are ignored.
+// It must be called after the routes are registered and before the server starts.
+func (s *Server) SetScanRoutes(paths ...string) {
+	scanPaths := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		scanPaths[path] = true
+	}
+
+	for _, route := range s.router.Routes() {
+		if scanPaths[route.Path] {
+			s.routeTimeouts[route.Path] = s.config.ScanRequestTimeout
+			s.fitWriteTimeout(s.config.ScanRequestTimeout)
+		}
+	}
+}
+
+// writeTimeoutMargin is the time left after a request deadline to write the 504 response
+const writeTimeoutMargin = 5 * time.Second
+
+// fitWriteTimeout raises the server's write timeout above a request deadline, so the connection
+// isn't closed before the deadline's 504 can be written
+func (s *Server) fitWriteTimeout(deadline time.Duration) {
+	if deadline <= 0 || s.httpServer.WriteTimeout <= 0 || s.httpServer.WriteTimeout >= deadline+writeTimeoutMargin {
+		return
+	}
+	log.Printf("Raising the write timeout from %s to %s to fit the %s request deadline",
+		s.httpServer.WriteTimeout, deadline+writeTimeoutMargin, deadline)
+	s.httpServer.WriteTimeout = deadline + writeTimeoutMargin
+}
+
 // setupRoutes configures all application routes
 func (s *Server) setupRoutes() {
 	// Health check endpoint
@@ -102,47 +146,52 @@ func (s *Server) RegisterReceiptRoutes() {
 	log.Println("Note: RegisterReceiptRoutes is deprecated, routes should be registered in main.go")
 }
 
-// Start begins listening for requests and handles graceful shutdown
-func (s *Server) Start() error {
-	// Channel to listen for interrupt signals
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Server listening on port %d", s.config.Port)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+// defaultShutdownTimeout applies when the configuration does not set a shutdown timeout
+const defaultShutdownTimeout = 10 * time.Second
 
-	// Wait for interrupt signal
-	<-quit
-	log.Println("Shutting down server...")
+// Start listens on the configured port and serves requests until Shutdown is called.
+// Signal handling is left to the caller, which triggers Shutdown.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
 
-	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	log.Printf("Server listening on port %d", s.config.Port)
+	return s.Serve(listener)
+}
 
-	// Shutdown server gracefully
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+// Serve serves requests on the listener until Shutdown is called
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server error: %w", err)
 	}
-
-	log.Println("Server exited gracefully")
 	return nil
 }
 
-// Shutdown gracefully stops the server
+// Shutdown gracefully stops the server. In-flight requests and receipt scans get the configured
+// shutdown timeout to finish; connections still open after that are closed.
 func (s *Server) Shutdown() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Shutdown receipt service if available (if it implements a shutdown method)
-	if shutdownable, ok := s.receiptService.(interface{ Shutdown() }); ok {
-		shutdownable.Shutdown()
+	// Stop accepting requests and wait for in-flight ones
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.httpServer.Close()
+		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
-	// Shutdown server
-	return s.httpServer.Shutdown(ctx)
+	// Drain scans still holding workers, if the receipt service supports it
+	if shutdownable, ok := s.receiptService.(interface{ Shutdown(context.Context) error }); ok {
+		if err := shutdownable.Shutdown(ctx); err != nil {
+			return fmt.Errorf("receipt service forced to shutdown: %w", err)
+		}
+	}
+
+	log.Println("Server exited gracefully")
+	return nil
 }
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/config"
+)
+
+// startTestServer serves a route that takes the given time on a local port
+func startTestServer(t *testing.T, shutdownTimeout, requestDuration time.Duration) (*Server, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	srv := NewServer(&config.Config{ShutdownTimeout: shutdownTimeout})
+	srv.GetRouter().GET("/slow", func(c *gin.Context) {
+		select {
+		case <-time.After(requestDuration):
+			c.String(http.StatusOK, "done")
+		case <-c.Request.Context().Done():
+		}
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(listener)
+
+	return srv, "http://" + listener.Addr().String()
+}
+
+// requestDuringShutdown starts a slow request and shuts the server down once it is in flight
+func requestDuringShutdown(t *testing.T, srv *Server, baseURL string) (*http.Response, error, error, time.Duration) {
+	t.Helper()
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		done <- result{resp, err}
+	}()
+
+	// Give the request time to reach the handler
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	shutdownErr := srv.Shutdown()
+	elapsed := time.Since(start)
+
+	res := <-done
+	return res.resp, res.err, shutdownErr, elapsed
+}
+
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+	srv, baseURL := startTestServer(t, 2*time.Second, 200*time.Millisecond)
+
+	resp, err, shutdownErr, _ := requestDuringShutdown(t, srv, baseURL)
+
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, shutdownErr)
+}
+
+func TestShutdownCutsRequestAtTimeout(t *testing.T) {
+	srv, baseURL := startTestServer(t, 200*time.Millisecond, 10*time.Second)
+
+	resp, err, shutdownErr, elapsed := requestDuringShutdown(t, srv, baseURL)
+
+	if resp != nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err)
+	assert.ErrorIs(t, shutdownErr, context.DeadlineExceeded)
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestSetScanRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	srv := NewServer(&config.Config{
+		WriteTimeout:       30 * time.Second,
+		RequestTimeout:     20 * time.Millisecond,
+		ScanRequestTimeout: 200 * time.Millisecond,
+	})
+	handler := func(c *gin.Context) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			c.String(http.StatusOK, "done")
+		case <-c.Request.Context().Done():
+		}
+	}
+	srv.GetRouter().POST("/v1/receipts/scan", handler)
+	srv.GetRouter().GET("/v1/receipts", handler)
+	srv.SetScanRoutes("/v1/receipts/scan", "/v1/receipts/scan/debug")
+
+	// Unregistered scan routes are left out
+	assert.Equal(t, map[string]time.Duration{"/v1/receipts/scan": 200 * time.Millisecond}, srv.routeTimeouts)
+
+	w := httptest.NewRecorder()
+	srv.GetRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/receipts/scan", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	srv.GetRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/receipts", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestWriteTimeoutFitsScanTimeout(t *testing.T) {
+	srv := NewServer(&config.Config{
+		WriteTimeout:       30 * time.Second,
+		RequestTimeout:     30 * time.Second,
+		ScanRequestTimeout: 120 * time.Second,
+	})
+	assert.Equal(t, 35*time.Second, srv.httpServer.WriteTimeout)
+
+	srv.GetRouter().POST("/v1/receipts/scan", func(c *gin.Context) {})
+	srv.SetScanRoutes("/v1/receipts/scan")
+	assert.Equal(t, 125*time.Second, srv.httpServer.WriteTimeout)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LoginLockoutPolicy locks password logins after repeated failures
+type LoginLockoutPolicy struct {
+	MaxFailedAttempts int           // Consecutive failures that lock the account, 0 disables lockout
+	Duration          time.Duration // How long a locked account refuses password logins
+}
+
+// enabled reports whether the policy ever locks accounts
+func (p LoginLockoutPolicy) enabled() bool {
+	return p.MaxFailedAttempts > 0 && p.Duration > 0
+}
+
+// AccountLockedError is returned by Login while an account is locked after too many failed logins
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked until %s after too many failed logins", e.Until.Format(time.RFC3339))
+}
+
+// checkLoginLock returns an AccountLockedError while the user's password logins are locked
+func (s *authService) checkLoginLock(ctx context.Context, userID string) error {
+	if !s.loginLockout.enabled() {
+		return nil
+	}
+
+	attempts, err := s.userRepo.GetLoginAttempts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if attempts.LockedAt(s.clock.Now()) {
+		return &AccountLockedError{Until: *attempts.LockedUntil}
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed login and returns the error to report for it:
+// an AccountLockedError when it locked the account, otherwise ErrInvalidCredentials
+func (s *authService) recordFailedLogin(ctx context.Context, userID string) error {
+	if !s.loginLockout.enabled() {
+		return ErrInvalidCredentials
+	}
+
+	now := s.clock.Now()
+	attempts, err := s.userRepo.RecordFailedLogin(ctx, userID, s.loginLockout.MaxFailedAttempts, now.Add(s.loginLockout.Duration))
+	if err != nil {
+		// The credentials were still wrong, so report that rather than a server error
+		log.Printf("Warning: failed to record failed login for user %s: %v", userID, err)
+		return ErrInvalidCredentials
+	}
+	if attempts.LockedAt(now) {
+		return &AccountLockedError{Until: *attempts.LockedUntil}
+	}
+	return ErrInvalidCredentials
+}
+
+// clearLoginAttempts resets the failure count after a successful login
+func (s *authService) clearLoginAttempts(ctx context.Context, userID string) {
+	if !s.loginLockout.enabled() {
+		return
+	}
+	if err := s.userRepo.ClearLoginAttempts(ctx, userID); err != nil {
+		log.Printf("Warning: failed to clear login attempts for user %s: %v", userID, err)
+	}
+}
@@ -44,6 +44,9 @@ type AuthService interface {
 
 	// User operations
 	GetUserByID(ctx context.Context, userID string) (*domain.User, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.PaginatedUsers, error)
+	GetUserProfile(ctx context.Context, userID string) (*domain.UserProfile, error)
+	UpdateUserPreferences(ctx context.Context, userID string, update domain.UserPreferences) (*domain.UserPreferences, error)
 }
 
 // AuthResponse contains authentication response data
@@ -77,6 +80,12 @@ type authService struct {
 	jwtSecret             []byte
 	jwtAccessExpiration   time.Duration
 	jwtRefreshExpiration  time.Duration
+	jwtIssuer             string
+	jwtAudience           string
+	passwordPolicy        PasswordPolicy
+	loginLockout          LoginLockoutPolicy
+	clock                 Clock
+	userCache             *userCache
 }
 
 // AuthServiceConfig holds configuration for auth service
@@ -90,6 +99,13 @@ type AuthServiceConfig struct {
 	JWTSecret             string
 	JWTAccessExpiration   time.Duration
 	JWTRefreshExpiration  time.Duration
+	JWTIssuer             string             // Optional, set as iss in issued tokens and required of validated ones
+	JWTAudience           string             // Optional, set as aud in issued tokens and required of validated ones
+	PasswordPolicy        PasswordPolicy     // Optional, defaults to DefaultPasswordPolicy
+	LoginLockout          LoginLockoutPolicy // Optional, the zero value never locks accounts
+	Clock                 Clock              // Optional, defaults to the system clock
+	UserCacheSize         int                // Users kept in memory for GetUserByID, 0 disables the cache
+	UserCacheTTL          time.Duration      // How long a cached user is served before it is read again, 0 disables the cache
 }
 
 // NewAuthService creates a new auth service
@@ -105,6 +121,16 @@ func NewAuthService(config AuthServiceConfig) AuthService {
 		Endpoint: google.Endpoint,
 	}
 
+	passwordPolicy := config.PasswordPolicy
+	if passwordPolicy.MinLength <= 0 && len(passwordPolicy.RequiredClasses) == 0 {
+		passwordPolicy = DefaultPasswordPolicy
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
 	return &authService{
 		userRepo:              config.UserRepo,
 		googleOAuthConfig:     googleOAuthConfig,
@@ -113,6 +139,12 @@ func NewAuthService(config AuthServiceConfig) AuthService {
 		jwtSecret:             []byte(config.JWTSecret),
 		jwtAccessExpiration:   config.JWTAccessExpiration,
 		jwtRefreshExpiration:  config.JWTRefreshExpiration,
+		jwtIssuer:             config.JWTIssuer,
+		jwtAudience:           config.JWTAudience,
+		passwordPolicy:        passwordPolicy,
+		loginLockout:          config.LoginLockout,
+		clock:                 clock,
+		userCache:             newUserCache(config.UserCacheSize, config.UserCacheTTL, clock),
 	}
 }
 
@@ -151,7 +183,7 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string) (*A
 			// Update user info from Google
 			user.PictureURL = googleUser.Picture
 			user.EmailVerified = googleUser.VerifiedEmail
-			if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			if err := s.updateUser(ctx, user); err != nil {
 				return nil, fmt.Errorf("failed to update user: %w", err)
 			}
 
@@ -215,7 +247,7 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string) (*A
 			user.PictureURL = googleUser.Picture
 			user.EmailVerified = googleUser.VerifiedEmail
 
-			if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			if err := s.updateUser(ctx, user); err != nil {
 				return nil, fmt.Errorf("failed to update user: %w", err)
 			}
 		}
@@ -325,7 +357,7 @@ func (s *authService) HandleGoogleMobileAuth(ctx context.Context, idToken string
 			user.PictureURL = googleUser.Picture
 			user.EmailVerified = googleUser.VerifiedEmail
 
-			if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			if err := s.updateUser(ctx, user); err != nil {
 				return nil, fmt.Errorf("failed to update user: %w", err)
 			}
 		}
@@ -345,8 +377,15 @@ func (s *authService) HandleGoogleMobileAuth(ctx context.Context, idToken string
 	}, nil
 }
 
-// Register creates a new user with email and password
+// Register creates a new user with email and password.
+// It returns ValidationErrors when the email, name or password is unacceptable.
 func (s *authService) Register(ctx context.Context, email, password, name string) (*AuthResponse, error) {
+	// Normalize and validate input
+	email, name, err := validateRegistration(email, password, name, s.passwordPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err == nil && existingUser != nil {
@@ -389,7 +428,7 @@ func (s *authService) Register(ctx context.Context, email, password, name string
 // Login authenticates a user with email and password
 func (s *authService) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
 	// Get user by email with password hash
-	user, err := s.userRepo.GetUserByEmailWithPassword(ctx, email)
+	user, err := s.userRepo.GetUserByEmailWithPassword(ctx, normalizeEmail(email))
 	if err != nil {
 		return nil, ErrInvalidCredentials
 	}
@@ -399,10 +438,16 @@ func (s *authService) Login(ctx context.Context, email, password string) (*AuthR
 		return nil, ErrInvalidCredentials
 	}
 
+	// Refuse locked accounts before checking the password, so guesses during the lock are not tested
+	if err := s.checkLoginLock(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.recordFailedLogin(ctx, user.ID)
 	}
+	s.clearLoginAttempts(ctx, user.ID)
 
 	// Generate JWT tokens
 	tokens, err := s.GenerateTokens(user.ID)
@@ -481,20 +526,17 @@ func (s *authService) verifyGoogleIDToken(ctx context.Context, idToken string) (
 // GenerateTokens generates access and refresh tokens
 func (s *authService) GenerateTokens(userID string) (*TokenPair, error) {
 	// Get user to include email in claims
-	user, err := s.userRepo.GetUserByID(context.Background(), userID)
+	user, err := s.GetUserByID(context.Background(), userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Generate access token
+	now := s.clock.Now()
 	accessClaims := &Claims{
-		UserID: userID,
-		Email:  user.Email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtAccessExpiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
-		},
+		UserID:           userID,
+		Email:            user.Email,
+		RegisteredClaims: s.registeredClaims(userID, now, s.jwtAccessExpiration),
 	}
 
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
@@ -505,13 +547,9 @@ func (s *authService) GenerateTokens(userID string) (*TokenPair, error) {
 
 	// Generate refresh token
 	refreshClaims := &Claims{
-		UserID: userID,
-		Email:  user.Email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtRefreshExpiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
-		},
+		UserID:           userID,
+		Email:            user.Email,
+		RegisteredClaims: s.registeredClaims(userID, now, s.jwtRefreshExpiration),
 	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
@@ -527,14 +565,38 @@ func (s *authService) GenerateTokens(userID string) (*TokenPair, error) {
 	}, nil
 }
 
-// ValidateAccessToken validates and parses an access token
+// registeredClaims returns the standard claims of a token for the user issued at now, including the
+// configured issuer and audience
+func (s *authService) registeredClaims(userID string, now time.Time, expiration time.Duration) jwt.RegisteredClaims {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Subject:   userID,
+		Issuer:    s.jwtIssuer,
+	}
+	if s.jwtAudience != "" {
+		claims.Audience = jwt.ClaimStrings{s.jwtAudience}
+	}
+	return claims
+}
+
+// ValidateAccessToken validates and parses an access token. When an issuer or audience is
+// configured, tokens that don't carry it are rejected.
 func (s *authService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	var options []jwt.ParserOption
+	if s.jwtIssuer != "" {
+		options = append(options, jwt.WithIssuer(s.jwtIssuer))
+	}
+	if s.jwtAudience != "" {
+		options = append(options, jwt.WithAudience(s.jwtAudience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return s.jwtSecret, nil
-	})
+	}, options...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -559,7 +621,103 @@ func (s *authService) RefreshAccessToken(refreshToken string) (*TokenPair, error
 	return s.GenerateTokens(claims.UserID)
 }
 
-// GetUserByID retrieves a user by ID
+// GetUserByID retrieves a user by ID, serving recently read users from the user cache
 func (s *authService) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
-	return s.userRepo.GetUserByID(ctx, userID)
+	if user, ok := s.userCache.get(userID); ok {
+		return user, nil
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.userCache.put(user)
+	return user, nil
+}
+
+// updateUser saves the user and drops it from the user cache
+func (s *authService) updateUser(ctx context.Context, user *domain.User) error {
+	defer s.userCache.invalidate(user.ID)
+	return s.userRepo.UpdateUser(ctx, user)
+}
+
+// ListUsers retrieves a page of users for the admin listing
+func (s *authService) ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.PaginatedUsers, error) {
+	return s.userRepo.ListUsers(ctx, filter)
+}
+
+// GetUserProfile composes the user's account, linked OAuth providers and preferences
+func (s *authService) GetUserProfile(ctx context.Context, userID string) (*domain.UserProfile, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	providers, err := s.userRepo.GetOAuthProvidersByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.getUserPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &domain.UserProfile{
+		User:        user,
+		Providers:   make([]domain.LinkedProvider, 0, len(providers)),
+		Preferences: preferences,
+	}
+	for _, provider := range providers {
+		profile.Providers = append(profile.Providers, domain.LinkedProvider{
+			Provider: provider.Provider,
+			Email:    provider.ProviderEmail,
+			LinkedAt: provider.CreatedAt,
+		})
+	}
+
+	return profile, nil
+}
+
+// UpdateUserPreferences saves the non-empty fields of update over the user's current preferences.
+// It returns ValidationErrors for an unknown currency code, time zone or number locale.
+func (s *authService) UpdateUserPreferences(ctx context.Context, userID string, update domain.UserPreferences) (*domain.UserPreferences, error) {
+	preferences, err := s.getUserPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DefaultCurrency != "" {
+		preferences.DefaultCurrency = update.DefaultCurrency
+	}
+	if update.Timezone != "" {
+		preferences.Timezone = update.Timezone
+	}
+	if update.ShareExtractionFeedback != nil {
+		preferences.ShareExtractionFeedback = update.ShareExtractionFeedback
+	}
+	if update.NumberLocale != "" {
+		preferences.NumberLocale = update.NumberLocale
+	}
+
+	preferences, err = validatePreferences(preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SaveUserPreferences(ctx, userID, preferences); err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// getUserPreferences returns the user's saved preferences, or the defaults when none are saved
+func (s *authService) getUserPreferences(ctx context.Context, userID string) (domain.UserPreferences, error) {
+	preferences, err := s.userRepo.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return domain.UserPreferences{}, err
+	}
+	if preferences == nil {
+		return domain.DefaultUserPreferences(), nil
+	}
+	return *preferences, nil
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// registeringUserRepository stores registered users in memory
+type registeringUserRepository struct {
+	repository.UserRepository
+	users []*domain.User
+}
+
+func (r *registeringUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *registeringUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *registeringUserRepository) CreateUserWithPassword(ctx context.Context, user *domain.User) error {
+	user.ID = "user-1"
+	r.users = append(r.users, user)
+	return nil
+}
+
+func newTestAuthService(repo repository.UserRepository) AuthService {
+	return NewAuthService(AuthServiceConfig{
+		UserRepo:            repo,
+		JWTSecret:           "test-secret",
+		JWTAccessExpiration: time.Hour,
+	})
+}
+
+// fieldErrors returns the validation messages keyed by field
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var validationErrs ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	fields := map[string]string{}
+	for _, fieldErr := range validationErrs {
+		fields[fieldErr.Field] = fieldErr.Message
+	}
+	return fields
+}
+
+func TestRegisterValidation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("over-length name", func(t *testing.T) {
+		repo := &registeringUserRepository{}
+		_, err := newTestAuthService(repo).Register(ctx, "jane@example.com", "secret123", strings.Repeat("a", 10*1024))
+
+		fields := fieldErrors(t, err)
+		assert.Contains(t, fields["name"], "at most 100 characters")
+		assert.Empty(t, repo.users)
+	})
+
+	t.Run("whitespace-only name", func(t *testing.T) {
+		_, err := newTestAuthService(&registeringUserRepository{}).Register(ctx, "jane@example.com", "secret123", "   \t ")
+
+		assert.Equal(t, "Name is required", fieldErrors(t, err)["name"])
+	})
+
+	t.Run("mixed-case email is normalized", func(t *testing.T) {
+		repo := &registeringUserRepository{}
+		resp, err := newTestAuthService(repo).Register(ctx, "  Jane.Doe@Example.COM ", "secret123", "  Jane   Doe ")
+		require.NoError(t, err)
+
+		assert.Equal(t, "jane.doe@example.com", resp.User.Email)
+		assert.Equal(t, "Jane Doe", resp.User.Name)
+		require.Len(t, repo.users, 1)
+		assert.Equal(t, "jane.doe@example.com", repo.users[0].Email)
+
+		// The same address in another case is a duplicate
+		_, err = newTestAuthService(repo).Register(ctx, "JANE.DOE@example.com", "secret123", "Jane")
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	})
+
+	t.Run("invalid and disposable emails", func(t *testing.T) {
+		svc := newTestAuthService(&registeringUserRepository{})
+
+		_, err := svc.Register(ctx, "Jane <jane@example.com>", "secret123", "Jane")
+		assert.Equal(t, "Email address is invalid", fieldErrors(t, err)["email"])
+
+		_, err = svc.Register(ctx, "jane@localhost", "secret123", "Jane")
+		assert.Equal(t, "Email domain is invalid", fieldErrors(t, err)["email"])
+
+		_, err = svc.Register(ctx, "jane@mailinator.com", "secret123", "Jane")
+		assert.Equal(t, "Disposable email addresses are not allowed", fieldErrors(t, err)["email"])
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		svc := newTestAuthService(&registeringUserRepository{})
+
+		_, err := svc.Register(ctx, "jane@example.com", "abc12", "Jane")
+		assert.Equal(t, "Password must be at least 8 characters", fieldErrors(t, err)["password"])
+
+		_, err = svc.Register(ctx, "jane@example.com", "onlyletters", "Jane")
+		assert.Equal(t, "Password must contain at least one digit", fieldErrors(t, err)["password"])
+	})
+
+	t.Run("configured policy", func(t *testing.T) {
+		svc := NewAuthService(AuthServiceConfig{
+			UserRepo:       &registeringUserRepository{},
+			JWTSecret:      "test-secret",
+			PasswordPolicy: PasswordPolicy{MinLength: 10, RequiredClasses: []string{PasswordClassUpper, PasswordClassSymbol}},
+		})
+
+		_, err := svc.Register(ctx, "jane@example.com", "lowercase12", "Jane")
+		assert.Equal(t, "Password must contain at least one uppercase letter", fieldErrors(t, err)["password"])
+
+		_, err = svc.Register(ctx, "jane@example.com", "Uppercase12!", "Jane")
+		assert.NoError(t, err)
+	})
+}
+
+// lockoutUserRepository holds one password user and their failed login tracking in memory
+type lockoutUserRepository struct {
+	repository.UserRepository
+	user     domain.User
+	attempts *domain.LoginAttempts
+}
+
+func (r *lockoutUserRepository) GetUserByEmailWithPassword(ctx context.Context, email string) (*domain.User, error) {
+	if !strings.EqualFold(email, r.user.Email) {
+		return nil, ErrUserNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *lockoutUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	user := r.user
+	return &user, nil
+}
+
+func (r *lockoutUserRepository) GetLoginAttempts(ctx context.Context, userID string) (*domain.LoginAttempts, error) {
+	return r.attempts, nil
+}
+
+func (r *lockoutUserRepository) RecordFailedLogin(ctx context.Context, userID string, maxFailed int, lockUntil time.Time) (*domain.LoginAttempts, error) {
+	if r.attempts == nil {
+		r.attempts = &domain.LoginAttempts{}
+	}
+	r.attempts.FailedCount++
+	if r.attempts.FailedCount >= maxFailed {
+		r.attempts.FailedCount = 0
+		r.attempts.LockedUntil = &lockUntil
+	}
+	attempts := *r.attempts
+	return &attempts, nil
+}
+
+func (r *lockoutUserRepository) ClearLoginAttempts(ctx context.Context, userID string) error {
+	r.attempts = nil
+	return nil
+}
+
+func TestLoginLockout(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &lockoutUserRepository{user: domain.User{ID: "user-1", Email: "jane@example.com", PasswordHash: string(hash)}}
+
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	svc := NewAuthService(AuthServiceConfig{
+		UserRepo:            repo,
+		JWTSecret:           "test-secret",
+		JWTAccessExpiration: time.Hour,
+		LoginLockout:        LoginLockoutPolicy{MaxFailedAttempts: 3, Duration: 15 * time.Minute},
+		Clock:               clock,
+	})
+	ctx := context.Background()
+
+	// Failures below the limit are ordinary invalid credentials
+	for i := 0; i < 2; i++ {
+		_, err := svc.Login(ctx, "jane@example.com", "wrong-password")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// The failure reaching the limit locks the account
+	_, err = svc.Login(ctx, "jane@example.com", "wrong-password")
+	var lockedErr *AccountLockedError
+	require.ErrorAs(t, err, &lockedErr)
+	assert.Equal(t, clock.now.Add(15*time.Minute), lockedErr.Until)
+
+	// While locked even the right password is refused
+	clock.Advance(10 * time.Minute)
+	_, err = svc.Login(ctx, "jane@example.com", "secret123")
+	require.ErrorAs(t, err, &lockedErr)
+
+	// Once the lock expires the right password works and clears the tracking
+	clock.Advance(5 * time.Minute)
+	resp, err := svc.Login(ctx, "jane@example.com", "secret123")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", resp.User.ID)
+	assert.Nil(t, repo.attempts)
+
+	t.Run("lockout disabled", func(t *testing.T) {
+		repo := &lockoutUserRepository{user: repo.user}
+		svc := newTestAuthService(repo)
+		for i := 0; i < 10; i++ {
+			_, err := svc.Login(ctx, "jane@example.com", "wrong-password")
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+		assert.Nil(t, repo.attempts)
+	})
+}
+
+func TestValidateAccessTokenChecksIssuerAndAudience(t *testing.T) {
+	repo := &lockoutUserRepository{user: domain.User{ID: "user-1", Email: "jane@example.com"}}
+	newService := func(audience string) AuthService {
+		return NewAuthService(AuthServiceConfig{
+			UserRepo:            repo,
+			JWTSecret:           "test-secret",
+			JWTAccessExpiration: time.Hour,
+			JWTIssuer:           "receipt-scanner",
+			JWTAudience:         audience,
+		})
+	}
+	svc := newService("receipt-scanner-app")
+
+	tokens, err := svc.GenerateTokens("user-1")
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "receipt-scanner", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"receipt-scanner-app"}, claims.Audience)
+
+	t.Run("wrong audience is rejected", func(t *testing.T) {
+		otherTokens, err := newService("another-service").GenerateTokens("user-1")
+		require.NoError(t, err)
+
+		_, err = svc.ValidateAccessToken(otherTokens.AccessToken)
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("tokens without the configured claims are rejected", func(t *testing.T) {
+		unbound := NewAuthService(AuthServiceConfig{UserRepo: repo, JWTSecret: "test-secret", JWTAccessExpiration: time.Hour})
+		unboundTokens, err := unbound.GenerateTokens("user-1")
+		require.NoError(t, err)
+
+		_, err = svc.ValidateAccessToken(unboundTokens.AccessToken)
+		assert.Error(t, err)
+	})
+}
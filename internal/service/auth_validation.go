@@ -0,0 +1,240 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zone validation must not depend on the host's zone database
+	"unicode"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+const (
+	// maxNameLength bounds the display name stored for a user, in characters
+	maxNameLength = 100
+	// maxEmailLength is the longest email address accepted (RFC 5321 path limit)
+	maxEmailLength = 254
+)
+
+// disposableEmailDomains are throwaway mailbox providers that cannot be used to register
+var disposableEmailDomains = map[string]bool{
+	"mailinator.com":    true,
+	"guerrillamail.com": true,
+	"10minutemail.com":  true,
+	"tempmail.com":      true,
+	"temp-mail.org":     true,
+	"yopmail.com":       true,
+	"trashmail.com":     true,
+	"sharklasers.com":   true,
+	"getnada.com":       true,
+	"dispostable.com":   true,
+}
+
+// Password character classes a PasswordPolicy can require
+const (
+	PasswordClassLetter = "letter"
+	PasswordClassLower  = "lower"
+	PasswordClassUpper  = "upper"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+// PasswordPolicy defines the complexity rules for user passwords
+type PasswordPolicy struct {
+	MinLength       int      // Minimum number of characters
+	RequiredClasses []string // Character classes that must each appear at least once
+}
+
+// DefaultPasswordPolicy requires 8 characters with at least one letter and one digit
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:       8,
+	RequiredClasses: []string{PasswordClassLetter, PasswordClassDigit},
+}
+
+// ValidationError describes an invalid input field
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors collects the invalid fields of a request
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validate returns a message describing the first rule the password breaks, or "" if it complies
+func (p PasswordPolicy) Validate(password string) string {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Sprintf("Password must be at least %d characters", p.MinLength)
+	}
+
+	for _, class := range p.RequiredClasses {
+		if !containsPasswordClass(password, class) {
+			return fmt.Sprintf("Password must contain at least one %s", passwordClassDescription(class))
+		}
+	}
+	return ""
+}
+
+// containsPasswordClass reports whether the password has a character of the class
+func containsPasswordClass(password, class string) bool {
+	for _, r := range password {
+		switch class {
+		case PasswordClassLetter:
+			if unicode.IsLetter(r) {
+				return true
+			}
+		case PasswordClassLower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case PasswordClassUpper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case PasswordClassDigit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case PasswordClassSymbol:
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) {
+				return true
+			}
+		default:
+			// Unknown classes are ignored so a typo in configuration cannot lock out registration
+			return true
+		}
+	}
+	return false
+}
+
+// passwordClassDescription names a character class for error messages
+func passwordClassDescription(class string) string {
+	switch class {
+	case PasswordClassLower:
+		return "lowercase letter"
+	case PasswordClassUpper:
+		return "uppercase letter"
+	case PasswordClassSymbol:
+		return "symbol"
+	default:
+		return class
+	}
+}
+
+// normalizeEmail trims and lowercases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeName trims a display name and collapses runs of whitespace
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// validateRegistration normalizes the registration fields and checks them against the policy.
+// It returns the normalized email and name, or ValidationErrors for every invalid field.
+func validateRegistration(email, password, name string, policy PasswordPolicy) (string, string, error) {
+	var errs ValidationErrors
+
+	email = normalizeEmail(email)
+	if message := validateEmail(email); message != "" {
+		errs = append(errs, ValidationError{Field: "email", Message: message})
+	}
+
+	name = normalizeName(name)
+	switch {
+	case name == "":
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	case len([]rune(name)) > maxNameLength:
+		errs = append(errs, ValidationError{Field: "name", Message: fmt.Sprintf("Name must be at most %d characters", maxNameLength)})
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		errs = append(errs, ValidationError{Field: "name", Message: "Name contains invalid characters"})
+	}
+
+	if message := policy.Validate(password); message != "" {
+		errs = append(errs, ValidationError{Field: "password", Message: message})
+	}
+
+	if len(errs) > 0 {
+		return "", "", errs
+	}
+	return email, name, nil
+}
+
+// validateEmail returns a message describing why a normalized email is unacceptable, or ""
+func validateEmail(email string) string {
+	if email == "" {
+		return "Email is required"
+	}
+	if len(email) > maxEmailLength {
+		return fmt.Sprintf("Email must be at most %d characters", maxEmailLength)
+	}
+
+	// Reject display names, comments and anything else beyond a bare address
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "Email address is invalid"
+	}
+
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "Email domain is invalid"
+	}
+	if disposableEmailDomains[domain] {
+		return "Disposable email addresses are not allowed"
+	}
+	return ""
+}
+
+// validatePreferences normalizes the currency code to upper case and the number locale to its
+// canonical tag, and checks the time zone exists.
+// It returns ValidationErrors for every invalid field.
+func validatePreferences(preferences domain.UserPreferences) (domain.UserPreferences, error) {
+	var errs ValidationErrors
+
+	preferences.DefaultCurrency = strings.ToUpper(strings.TrimSpace(preferences.DefaultCurrency))
+	if !isCurrencyCode(preferences.DefaultCurrency) {
+		errs = append(errs, ValidationError{Field: "defaultCurrency", Message: "Default currency must be a 3-letter ISO 4217 code"})
+	}
+
+	preferences.Timezone = strings.TrimSpace(preferences.Timezone)
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil || preferences.Timezone == "" || preferences.Timezone == "Local" {
+		errs = append(errs, ValidationError{Field: "timezone", Message: "Timezone must be an IANA time zone name, e.g. Asia/Jakarta"})
+	}
+
+	if preferences.NumberLocale != "" {
+		if format, ok := domain.LookupNumberFormat(preferences.NumberLocale); ok {
+			preferences.NumberLocale = format.Locale
+		} else {
+			errs = append(errs, ValidationError{Field: "numberLocale", Message: "Number locale is not supported, e.g. en-US, id-ID or de-DE"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return preferences, errs
+	}
+	return preferences, nil
+}
+
+// isCurrencyCode reports whether code is three upper-case ASCII letters
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// ErrUnknownBackfillField is returned when a backfill names a field that cannot be derived
+var ErrUnknownBackfillField = errors.New("unknown backfill field")
+
+const (
+	defaultBackfillBatchSize  = 500
+	defaultBackfillMaxBatches = 10
+)
+
+// backfillDerivations computes each backfillable field from its source value
+var backfillDerivations = map[string]func(string) string{
+	domain.BackfillFieldNormalizedMerchant: domain.NormalizeMerchant,
+}
+
+// BackfillFields lists the derived fields a backfill can recompute
+func BackfillFields() []string {
+	fields := make([]string, 0, len(backfillDerivations))
+	for field := range backfillDerivations {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// BackfillService defines the interface for filling derived fields of existing rows
+type BackfillService interface {
+	// Backfill fills up to maxBatches batches of rows missing the field. Only rows still missing it are
+	// touched, so calls are idempotent and a later call resumes where an interrupted one stopped.
+	Backfill(ctx context.Context, field string, batchSize, maxBatches int) (*domain.BackfillProgress, error)
+}
+
+// backfillService implements BackfillService
+type backfillService struct {
+	repository repository.BackfillRepository
+}
+
+// NewBackfillService creates a new BackfillService
+func NewBackfillService(repo repository.BackfillRepository) BackfillService {
+	return &backfillService{repository: repo}
+}
+
+// Backfill computes the field for batches of rows missing it and reports the progress made
+func (s *backfillService) Backfill(ctx context.Context, field string, batchSize, maxBatches int) (*domain.BackfillProgress, error) {
+	derive, ok := backfillDerivations[field]
+	if !ok {
+		return nil, ErrUnknownBackfillField
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+	if maxBatches <= 0 {
+		maxBatches = defaultBackfillMaxBatches
+	}
+
+	progress := &domain.BackfillProgress{Field: field}
+	for batch := 0; batch < maxBatches; batch++ {
+		// Stop between batches when the request is cancelled; filled rows stay filled
+		if ctx.Err() != nil {
+			break
+		}
+
+		sources, err := s.repository.ListMissing(ctx, field, batchSize)
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "list_backfill_batch",
+				Err: err,
+			}
+		}
+		if len(sources) == 0 {
+			break
+		}
+
+		values := make(map[string]string, len(sources))
+		for _, source := range sources {
+			values[source.ID] = derive(source.Source)
+		}
+		if err := s.repository.SetDerivedValues(ctx, field, values); err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "store_backfill_batch",
+				Err: err,
+			}
+		}
+		progress.Processed += len(sources)
+
+		if len(sources) < batchSize {
+			break
+		}
+	}
+
+	remaining, err := s.repository.CountMissing(ctx, field)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "count_backfill_remaining",
+			Err: err,
+		}
+	}
+	progress.Remaining = remaining
+	progress.Done = remaining == 0
+
+	return progress, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// memoryBackfillRepository holds merchants and their normalized values, nil when not yet derived
+type memoryBackfillRepository struct {
+	merchants  map[string]string
+	normalized map[string]*string
+}
+
+func (r *memoryBackfillRepository) missingIDs() []string {
+	var ids []string
+	for id := range r.merchants {
+		if r.normalized[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *memoryBackfillRepository) CountMissing(ctx context.Context, field string) (int, error) {
+	return len(r.missingIDs()), nil
+}
+
+func (r *memoryBackfillRepository) ListMissing(ctx context.Context, field string, limit int) ([]domain.DerivedFieldSource, error) {
+	sources := []domain.DerivedFieldSource{}
+	for _, id := range r.missingIDs() {
+		if len(sources) == limit {
+			break
+		}
+		sources = append(sources, domain.DerivedFieldSource{ID: id, Source: r.merchants[id]})
+	}
+	return sources, nil
+}
+
+func (r *memoryBackfillRepository) SetDerivedValues(ctx context.Context, field string, values map[string]string) error {
+	for id, value := range values {
+		if r.normalized[id] == nil {
+			value := value
+			r.normalized[id] = &value
+		}
+	}
+	return nil
+}
+
+func TestBackfillNormalizedMerchant(t *testing.T) {
+	current := "corner shop"
+	repo := &memoryBackfillRepository{
+		merchants: map[string]string{
+			"r1": "WALMART #1234",
+			"r2": "Walmart",
+			"r3": "Corner Shop",
+			"r4": "Starbucks Coffee - 0042",
+			"r5": "Trader Joe's",
+		},
+		// r3 was written after the column was added
+		normalized: map[string]*string{"r3": &current},
+	}
+	svc := NewBackfillService(repo)
+	ctx := context.Background()
+
+	// Stop after one batch of two to leave work for a resumed call
+	progress, err := svc.Backfill(ctx, domain.BackfillFieldNormalizedMerchant, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.Processed)
+	assert.Equal(t, 2, progress.Remaining)
+	assert.False(t, progress.Done)
+
+	progress, err = svc.Backfill(ctx, domain.BackfillFieldNormalizedMerchant, 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.Processed)
+	assert.Zero(t, progress.Remaining)
+	assert.True(t, progress.Done)
+
+	assert.Equal(t, "walmart", *repo.normalized["r1"])
+	assert.Equal(t, "walmart", *repo.normalized["r2"])
+	assert.Equal(t, "starbucks coffee", *repo.normalized["r4"])
+	assert.Equal(t, "trader joes", *repo.normalized["r5"])
+
+	// Running again once complete changes nothing
+	progress, err = svc.Backfill(ctx, domain.BackfillFieldNormalizedMerchant, 0, 0)
+	require.NoError(t, err)
+	assert.Zero(t, progress.Processed)
+	assert.True(t, progress.Done)
+
+	_, err = svc.Backfill(ctx, "locale", 0, 0)
+	assert.ErrorIs(t, err, ErrUnknownBackfillField)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// GetSpendingBreakdown retrieves spending grouped by one of domain.BreakdownDimensions. Payment
+// methods are merged as in GetSpendingByPaymentMethod, so both report the same groups.
+func (s *ReceiptServiceImpl) GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDate, endDate *string) (*domain.SpendingBreakdown, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.repository.GetSpendingBreakdown(ctx, scope, by, startDate, endDate)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_spending_breakdown",
+			Err: err,
+		}
+	}
+
+	if by == domain.BreakdownByPaymentMethod {
+		breakdown.Groups = mergePaymentMethodGroups(breakdown.Groups)
+	}
+	for i := range breakdown.Groups {
+		if breakdown.Total > 0 {
+			breakdown.Groups[i].Percentage = breakdown.Groups[i].Amount / breakdown.Total * 100
+		}
+	}
+	return breakdown, nil
+}
+
+// mergePaymentMethodGroups merges groups of stored payment methods by their normalized method,
+// largest first. Receipts without a payment method are grouped as domain.PaymentMethodUnknown.
+func mergePaymentMethodGroups(groups []domain.SpendingBreakdownGroup) []domain.SpendingBreakdownGroup {
+	merged := []domain.SpendingBreakdownGroup{}
+	indices := make(map[string]int)
+	for _, group := range groups {
+		method := normalizePaymentMethod(group.Name)
+		if method == "" {
+			method = domain.PaymentMethodUnknown
+		}
+
+		i, ok := indices[method]
+		if !ok {
+			i = len(merged)
+			indices[method] = i
+			merged = append(merged, domain.SpendingBreakdownGroup{Name: method})
+		}
+		merged[i].Amount += group.Amount
+		merged[i].Count += group.Count
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Amount > merged[j].Amount
+	})
+	return merged
+}
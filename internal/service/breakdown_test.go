@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// GetSpendingBreakdown groups the stored receipts by payment method like the Postgres repository
+func (r *paymentMethodRepository) GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDate, endDate *string) (*domain.SpendingBreakdown, error) {
+	totals, _ := r.GetSpendingByPaymentMethod(ctx, scope, startDate, endDate)
+	breakdown := &domain.SpendingBreakdown{By: by, Groups: []domain.SpendingBreakdownGroup{}}
+	for _, total := range totals {
+		breakdown.Total += total.Amount
+		breakdown.Groups = append(breakdown.Groups, domain.SpendingBreakdownGroup{Name: total.Method, Amount: total.Amount, Count: total.Count})
+	}
+	return breakdown, nil
+}
+
+func TestSpendingBreakdownByPaymentMethod(t *testing.T) {
+	repo := &paymentMethodRepository{
+		receipts: []domain.Receipt{
+			{UserID: "user-1", Total: 20, PaymentMethod: domain.PaymentMethodCash},
+			{UserID: "user-1", Total: 40, PaymentMethod: domain.PaymentMethodCard},
+			{UserID: "user-1", Total: 15, PaymentMethod: "Mastercard"},
+			{UserID: "user-1", Total: 25},
+		},
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+	ctx := context.Background()
+	scope := domain.ReceiptScope{UserID: "user-1"}
+
+	breakdown, err := svc.GetSpendingBreakdown(ctx, scope, domain.BreakdownByPaymentMethod, nil, nil)
+	require.NoError(t, err)
+	spending, err := svc.GetSpendingByPaymentMethod(ctx, scope, nil, nil)
+	require.NoError(t, err)
+
+	// The breakdown reports the same merged methods as the dedicated payment method insight
+	assert.Equal(t, spending.Total, breakdown.Total)
+	require.Len(t, breakdown.Groups, len(spending.Methods))
+	for i, method := range spending.Methods {
+		assert.Equal(t, domain.SpendingBreakdownGroup{
+			Name:       method.Method,
+			Amount:     method.Amount,
+			Count:      method.Count,
+			Percentage: method.Percentage,
+		}, breakdown.Groups[i])
+	}
+	assert.Equal(t, domain.PaymentMethodCard, breakdown.Groups[0].Name)
+}
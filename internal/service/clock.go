@@ -0,0 +1,30 @@
+package service
+
+import (
+	"strconv"
+	"time"
+)
+
+// Clock tells the current time, so time-dependent behavior can be tested with a fixed time
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator produces unique identifiers, such as the names of uploaded images
+type IDGenerator interface {
+	NewID() string
+}
+
+// systemClock is the Clock reading the system time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// nanoIDGenerator generates identifiers from the current Unix time in nanoseconds
+type nanoIDGenerator struct{}
+
+func (nanoIDGenerator) NewID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// fakeClock is a Clock that only moves when advanced
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// sequentialIDs generates id-1, id-2, ...
+type sequentialIDs struct {
+	next int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.next++
+	return fmt.Sprintf("id-%d", g.next)
+}
+
+// clockedExtractor advances the clock by the extraction time before returning its invoice
+type clockedExtractor struct {
+	clock    *fakeClock
+	duration time.Duration
+}
+
+func (e *clockedExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.clock.Advance(e.duration)
+	invoice := domain.NewInvoice()
+	invoice.VendorName = "Corner Cafe"
+	return invoice, nil
+}
+
+func TestReceiptServiceUsesClockAndIDs(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	repo := &recordingReceiptRepository{}
+	images := &memoryImageStore{images: map[string][]byte{}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		OpenAIClient:   &clockedExtractor{clock: clock, duration: 1500 * time.Millisecond},
+		MLXClient:      &failingURLExtractor{},
+		S3Uploader:     images,
+		UseMLXService:  true,
+		MLXFallback:    true,
+		MaxScanWorkers: 1,
+		Clock:          clock,
+		IDGenerator:    &sequentialIDs{},
+	})
+	ctx := context.Background()
+
+	scanned, err := svc.ScanReceipt(ctx, []byte("not-an-image"), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://storage.example.com/invoice_id-1.bin", scanned.ReceiptURL)
+	assert.Equal(t, 1500*time.Millisecond, scanned.Extraction.Latency)
+	assert.Equal(t, clock.now, scanned.CreatedAt)
+	assert.Equal(t, clock.now, scanned.UpdatedAt)
+
+	clock.Advance(time.Hour)
+	created, err := svc.CreateReceipt(ctx, &domain.Receipt{UserID: "user-1", Merchant: "Bakery"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 13, 0, 1, 500_000_000, time.UTC), created.CreatedAt)
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// ErrUnknownIngestAddress is returned when no recipient of an inbound email carries a known ingest token
+var ErrUnknownIngestAddress = errors.New("unknown ingest address")
+
+// scannableAttachmentTypes are the attachment content types run through the scan pipeline
+var scannableAttachmentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// quotaSkipReason is reported for receipt images left unscanned because the user's scan quota is used up
+const quotaSkipReason = "monthly scan quota used up, resend the receipt once the quota resets"
+
+// ReceiptScanner scans a single receipt image for a user
+type ReceiptScanner interface {
+	ScanReceipt(ctx context.Context, imageData []byte, userID string) (*domain.Receipt, error)
+	GetScanUsage(ctx context.Context, userID string) (*domain.ScanUsage, error)
+}
+
+// EmailIngestService defines the interface for creating receipts from forwarded emails
+type EmailIngestService interface {
+	// IngestEmail queues the receipt attachments of an email to be scanned in the background for
+	// the user its address belongs to
+	IngestEmail(ctx context.Context, email domain.InboundEmail) (*domain.EmailIngestResult, error)
+	// GetIngestAddress returns the user's forwarding address
+	GetIngestAddress(ctx context.Context, userID string) (*domain.EmailIngestAddress, error)
+	// RotateIngestAddress gives the user a new forwarding address token, so the old address stops working
+	RotateIngestAddress(ctx context.Context, userID string) (*domain.EmailIngestAddress, error)
+}
+
+// emailIngestService implements EmailIngestService
+type emailIngestService struct {
+	userRepo      repository.UserRepository
+	scanner       ReceiptScanner
+	inboundDomain string
+}
+
+// NewEmailIngestService creates a new EmailIngestService. inboundDomain is the domain of the
+// forwarding addresses reported to users; when empty only the token is reported.
+func NewEmailIngestService(userRepo repository.UserRepository, scanner ReceiptScanner, inboundDomain string) EmailIngestService {
+	return &emailIngestService{
+		userRepo:      userRepo,
+		scanner:       scanner,
+		inboundDomain: inboundDomain,
+	}
+}
+
+// IngestEmail finds the user from the ingest token in a recipient address, such as
+// receipts+<token>@example.com, and queues each image attachment to be scanned into a receipt in
+// the background, so the mail provider gets a reply well before the scans finish. Attachments of
+// other types, and images beyond the user's remaining scan quota, are reported as skipped.
+// ErrUnknownIngestAddress is returned when no recipient carries the token of an active user.
+// Queued scans are kept in memory and are lost if the server stops before they finish.
+func (s *emailIngestService) IngestEmail(ctx context.Context, email domain.InboundEmail) (*domain.EmailIngestResult, error) {
+	user, err := s.findRecipient(ctx, email.To)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.EmailIngestResult{
+		UserID:  user.ID,
+		Queued:  []string{},
+		Skipped: []domain.SkippedAttachment{},
+	}
+	var queued []domain.EmailAttachment
+	for _, attachment := range email.Attachments {
+		if len(attachment.Content) == 0 {
+			result.Skipped = append(result.Skipped, domain.SkippedAttachment{Filename: attachment.Filename, Reason: "attachment is empty"})
+			continue
+		}
+
+		contentType := attachmentContentType(attachment)
+		if !scannableAttachmentTypes[contentType] {
+			reason := fmt.Sprintf("content type %s is not a receipt image", contentType)
+			if contentType == "application/pdf" {
+				reason = "PDF attachments cannot be scanned yet, forward the receipt as an image"
+			}
+			result.Skipped = append(result.Skipped, domain.SkippedAttachment{Filename: attachment.Filename, Reason: reason})
+			continue
+		}
+
+		queued = append(queued, attachment)
+	}
+	if len(queued) == 0 {
+		return result, nil
+	}
+
+	// Skip the images the user has no scans left for rather than failing them in the background
+	usage, err := s.scanner.GetScanUsage(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if remaining := usage.Remaining(); remaining >= 0 && remaining < len(queued) {
+		for _, attachment := range queued[remaining:] {
+			result.Skipped = append(result.Skipped, domain.SkippedAttachment{Filename: attachment.Filename, Reason: quotaSkipReason})
+		}
+		queued = queued[:remaining]
+	}
+	if len(queued) == 0 {
+		return result, nil
+	}
+
+	for _, attachment := range queued {
+		result.Queued = append(result.Queued, attachment.Filename)
+	}
+	// The scans outlive the request that queued them
+	go s.scanAttachments(context.WithoutCancel(ctx), user.ID, queued)
+
+	return result, nil
+}
+
+// scanAttachments scans queued attachments into receipts one at a time, logging the ones that fail
+func (s *emailIngestService) scanAttachments(ctx context.Context, userID string, attachments []domain.EmailAttachment) {
+	for _, attachment := range attachments {
+		if _, err := s.scanner.ScanReceipt(ctx, attachment.Content, userID); err != nil {
+			log.Printf("Warning: skipped emailed attachment %q for user %s: %s: %v", attachment.Filename, userID, scanSkipReason(err), err)
+		}
+	}
+}
+
+// scanSkipReason describes why an emailed attachment could not be scanned
+func scanSkipReason(err error) string {
+	switch {
+	case errors.Is(err, ErrScanQuotaExceeded):
+		return quotaSkipReason
+	case errors.Is(err, domain.ErrImageUndecodable):
+		return "image could not be decoded, please resend it"
+	case errors.Is(err, domain.ErrServiceNotConfigured):
+		return "scanning is not configured"
+	default:
+		return "unable to extract receipt data"
+	}
+}
+
+// GetIngestAddress returns the user's forwarding address
+func (s *emailIngestService) GetIngestAddress(ctx context.Context, userID string) (*domain.EmailIngestAddress, error) {
+	token, err := s.userRepo.GetEmailIngestToken(ctx, userID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_ingest_token",
+			Err: err,
+		}
+	}
+	return s.ingestAddress(token), nil
+}
+
+// RotateIngestAddress replaces the user's forwarding address token with a new random one
+func (s *emailIngestService) RotateIngestAddress(ctx context.Context, userID string) (*domain.EmailIngestAddress, error) {
+	token, err := newIngestToken()
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "generate_ingest_token",
+			Err: err,
+		}
+	}
+	if err := s.userRepo.SetEmailIngestToken(ctx, userID, token); err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "set_ingest_token",
+			Err: err,
+		}
+	}
+	return s.ingestAddress(token), nil
+}
+
+// ingestAddress builds the forwarding address for a token
+func (s *emailIngestService) ingestAddress(token string) *domain.EmailIngestAddress {
+	address := &domain.EmailIngestAddress{Token: token}
+	if s.inboundDomain != "" {
+		address.Address = fmt.Sprintf("receipts+%s@%s", token, s.inboundDomain)
+	}
+	return address
+}
+
+// newIngestToken generates a forwarding address token in the format migration 018 gives existing users
+func newIngestToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// findRecipient returns the active user whose ingest token appears in one of the recipient addresses
+func (s *emailIngestService) findRecipient(ctx context.Context, recipients []string) (*domain.User, error) {
+	for _, recipient := range recipients {
+		token := ingestToken(recipient)
+		if token == "" {
+			continue
+		}
+
+		user, err := s.userRepo.GetUserByEmailIngestToken(ctx, token)
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "find_ingest_user",
+				Err: err,
+			}
+		}
+		if user != nil && user.IsActive {
+			return user, nil
+		}
+	}
+	return nil, ErrUnknownIngestAddress
+}
+
+// ingestToken returns the part of an address's local part after its last "+", or "" when there is none
+func ingestToken(recipient string) string {
+	address, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return ""
+	}
+
+	local, _, found := strings.Cut(address.Address, "@")
+	if !found {
+		return ""
+	}
+	plus := strings.LastIndex(local, "+")
+	if plus < 0 {
+		return ""
+	}
+	return local[plus+1:]
+}
+
+// attachmentContentType returns the declared media type of an attachment without parameters,
+// detecting it from the content when none was declared
+func attachmentContentType(attachment domain.EmailAttachment) string {
+	if attachment.ContentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(attachment.ContentType); err == nil {
+			return mediaType
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(attachment.Content))
+	return mediaType
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// GetExtractionAccuracy summarizes, per field and extractor, how often consenting users corrected
+// scanned values on their first edit of a receipt
+func (s *ReceiptServiceImpl) GetExtractionAccuracy(ctx context.Context) ([]domain.FieldAccuracy, error) {
+	if s.feedbackRepo == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "check_extraction_feedback",
+			Err: fmt.Errorf("%w: extraction feedback is disabled", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	accuracy, err := s.feedbackRepo.GetExtractionAccuracy(ctx)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_extraction_accuracy",
+			Err: err,
+		}
+	}
+	return accuracy, nil
+}
+
+// scannedReceiptForFeedback returns the stored receipt when an update is the first edit of a
+// scanned receipt whose owner consented to extraction feedback, or nil when no feedback should be
+// recorded. Receipts are unedited while updated_at still equals created_at.
+func (s *ReceiptServiceImpl) scannedReceiptForFeedback(ctx context.Context, receiptID string) *domain.Receipt {
+	if s.feedbackRepo == nil || s.userRepo == nil {
+		return nil
+	}
+
+	stored, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		// The update reports the missing receipt itself
+		return nil
+	}
+	if stored.ExtractionMethod == "" || !stored.UpdatedAt.Equal(stored.CreatedAt) {
+		return nil
+	}
+
+	preferences, err := s.userRepo.GetUserPreferences(ctx, stored.UserID)
+	if err != nil {
+		log.Printf("Warning: failed to check extraction feedback consent of user %s: %v", stored.UserID, err)
+		return nil
+	}
+	if preferences == nil || !preferences.SharesExtractionFeedback() {
+		return nil
+	}
+	return stored
+}
+
+// recordExtractionFeedback records which scanned fields the edit corrected. The edit is already
+// saved, so a failure to record is only logged.
+func (s *ReceiptServiceImpl) recordExtractionFeedback(ctx context.Context, scanned, edited *domain.Receipt) {
+	if err := s.feedbackRepo.RecordExtractionFeedback(ctx, domain.ScanCorrections(scanned, edited)); err != nil {
+		log.Printf("Warning: failed to record extraction feedback: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// editedReceiptRepository stores a single receipt and saves updates over it
+type editedReceiptRepository struct {
+	repository.ReceiptRepository
+	receipt domain.Receipt
+}
+
+func (r *editedReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	receipt := r.receipt
+	return &receipt, nil
+}
+
+func (r *editedReceiptRepository) UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	r.receipt = *receipt
+	return receipt, nil
+}
+
+// consentUserRepository returns preferences with the given extraction feedback consent
+type consentUserRepository struct {
+	repository.UserRepository
+	consent bool
+}
+
+func (r *consentUserRepository) GetUserPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	return &domain.UserPreferences{ShareExtractionFeedback: &r.consent}, nil
+}
+
+// recordingFeedbackRepository keeps recorded extraction feedback in memory
+type recordingFeedbackRepository struct {
+	repository.ExtractionFeedbackRepository
+	recorded []domain.ExtractionFeedback
+}
+
+func (r *recordingFeedbackRepository) RecordExtractionFeedback(ctx context.Context, feedback []domain.ExtractionFeedback) error {
+	r.recorded = append(r.recorded, feedback...)
+	return nil
+}
+
+func TestUpdateReceiptRecordsExtractionFeedback(t *testing.T) {
+	scannedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	scanned := domain.Receipt{
+		ID:               "receipt-1",
+		UserID:           "user-1",
+		Merchant:         "C0rner Mrkt",
+		Date:             domain.FlexibleDate{Time: scannedAt},
+		Items:            []domain.ReceiptItem{{ID: "item-1", Name: "Bread", Quantity: 1, Price: 3, Currency: "USD"}},
+		Total:            3,
+		ExtractionMethod: "openrouter",
+		CreatedAt:        scannedAt,
+		UpdatedAt:        scannedAt,
+	}
+	newService := func(consent bool) (ReceiptService, *recordingFeedbackRepository) {
+		feedback := &recordingFeedbackRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:         &editedReceiptRepository{receipt: scanned},
+			UserRepository:     &consentUserRepository{consent: consent},
+			FeedbackRepository: feedback,
+		})
+		return svc, feedback
+	}
+	editMerchant := func(merchant string) *domain.Receipt {
+		edit := scanned
+		edit.Items = append([]domain.ReceiptItem(nil), scanned.Items...)
+		edit.Merchant = merchant
+		return &edit
+	}
+	ctx := context.Background()
+
+	t.Run("merchant edit after a scan records a merchant correction", func(t *testing.T) {
+		svc, feedback := newService(true)
+		_, err := svc.UpdateReceipt(ctx, editMerchant("Corner Market"))
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []domain.ExtractionFeedback{
+			{Field: domain.FeedbackFieldMerchant, Corrected: true, ExtractionMethod: "openrouter"},
+			{Field: domain.FeedbackFieldDate, Corrected: false, ExtractionMethod: "openrouter"},
+			{Field: domain.FeedbackFieldTotal, Corrected: false, ExtractionMethod: "openrouter"},
+			{Field: domain.FeedbackFieldItems, Corrected: false, ExtractionMethod: "openrouter"},
+		}, feedback.recorded)
+
+		// Later edits no longer describe the scan
+		_, err = svc.UpdateReceipt(ctx, editMerchant("Corner Market & Deli"))
+		require.NoError(t, err)
+		assert.Len(t, feedback.recorded, 4)
+	})
+
+	t.Run("nothing is recorded without consent", func(t *testing.T) {
+		svc, feedback := newService(false)
+		_, err := svc.UpdateReceipt(ctx, editMerchant("Corner Market"))
+		require.NoError(t, err)
+		assert.Empty(t, feedback.recorded)
+	})
+}
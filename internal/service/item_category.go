@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// validateItemCategories checks the receipt and item categories against domain.ItemCategories in
+// strict mode, rewriting matches to the taxonomy's spelling so "food " is stored as "Food". Empty
+// categories are allowed. In lenient mode any category is accepted unchanged.
+func (s *ReceiptServiceImpl) validateItemCategories(receipt *domain.Receipt) error {
+	if !s.strictCategories {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if receipt.Category != "" {
+		if category, ok := canonicalItemCategory(receipt.Category); ok {
+			receipt.Category = category
+		} else {
+			errs = append(errs, ValidationError{
+				Field:   "category",
+				Message: fmt.Sprintf("Unknown category '%s'. Allowed: %s", receipt.Category, strings.Join(domain.ItemCategories, ", ")),
+			})
+		}
+	}
+
+	items := receipt.Items
+	for i := range items {
+		if items[i].Category == "" {
+			continue
+		}
+		category, ok := canonicalItemCategory(items[i].Category)
+		if !ok {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("items[%d].category", i),
+				Message: fmt.Sprintf("Unknown category '%s'. Allowed: %s", items[i].Category, strings.Join(domain.ItemCategories, ", ")),
+			})
+			continue
+		}
+		items[i].Category = category
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// canonicalItemCategory returns the taxonomy category matching the input, ignoring case and surrounding whitespace
+func canonicalItemCategory(category string) (string, bool) {
+	category = strings.TrimSpace(category)
+	for _, known := range domain.ItemCategories {
+		if strings.EqualFold(known, category) {
+			return known, true
+		}
+	}
+	return "", false
+}
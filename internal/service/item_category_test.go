@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+func TestItemCategoryValidation(t *testing.T) {
+	newReceipt := func(categories ...string) *domain.Receipt {
+		receipt := &domain.Receipt{UserID: "user-1", Merchant: "Cafe"}
+		for _, category := range categories {
+			receipt.Items = append(receipt.Items, domain.ReceiptItem{Name: "Item", Quantity: 1, Price: 5, Category: category})
+		}
+		return receipt
+	}
+
+	t.Run("strict rejects categories outside the taxonomy", func(t *testing.T) {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, StrictCategories: true})
+
+		_, err := svc.CreateReceipt(context.Background(), newReceipt("Food", "foods"))
+		var validationErrs ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Len(t, validationErrs, 1)
+		assert.Equal(t, "items[1].category", validationErrs[0].Field)
+		assert.Contains(t, validationErrs[0].Message, "Office Supplies")
+		assert.Empty(t, repo.created)
+
+		_, err = svc.UpdateReceipt(context.Background(), newReceipt("foods"))
+		assert.ErrorAs(t, err, &validationErrs)
+	})
+
+	t.Run("strict stores the taxonomy spelling", func(t *testing.T) {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, StrictCategories: true})
+
+		receipt, err := svc.CreateReceipt(context.Background(), newReceipt("food ", "office supplies", ""))
+		require.NoError(t, err)
+		assert.Equal(t, "Food", receipt.Items[0].Category)
+		assert.Equal(t, "Office Supplies", receipt.Items[1].Category)
+		assert.Empty(t, receipt.Items[2].Category)
+	})
+
+	t.Run("lenient accepts any category", func(t *testing.T) {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+		receipt, err := svc.CreateReceipt(context.Background(), newReceipt("foods"))
+		require.NoError(t, err)
+		assert.Equal(t, "foods", receipt.Items[0].Category)
+	})
+}
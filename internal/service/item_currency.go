@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
+)
+
+// checkItemCurrencies normalizes item currency codes and warns about codes the exchange rate
+// provider does not support, since conversions of those items would fail. The receipt is still
+// saved. The check is skipped when no catalog is configured or it cannot be loaded.
+func (s *ReceiptServiceImpl) checkItemCurrencies(ctx context.Context, receipt *domain.Receipt) {
+	for i := range receipt.Items {
+		if receipt.Items[i].Currency != "" {
+			receipt.Items[i].Currency = currency.NormalizeCurrency(receipt.Items[i].Currency)
+		}
+	}
+
+	if s.currencyCatalog == nil {
+		return
+	}
+
+	var codes []string
+	seen := map[string]bool{}
+	for _, item := range receipt.Items {
+		if item.Currency != "" && !seen[item.Currency] {
+			seen[item.Currency] = true
+			codes = append(codes, item.Currency)
+		}
+	}
+	if len(codes) == 0 {
+		return
+	}
+
+	supportedCodes, err := s.currencyCatalog.GetSupportedCurrencies(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to load supported currencies, skipping currency check: %v", err)
+		return
+	}
+	supported := make(map[string]bool, len(supportedCodes))
+	for _, code := range supportedCodes {
+		supported[code] = true
+	}
+
+	for _, code := range codes {
+		if !supported[code] {
+			receipt.Warnings = append(receipt.Warnings, fmt.Sprintf(
+				"Currency '%s' is not supported by the exchange rate provider; amounts in it cannot be converted", code,
+			))
+		}
+	}
+}
+
+// roundToCurrencyPrecision rounds scanned item prices to the decimal places of their currency, so
+// a misread such as IDR 42000.5 is stored as a whole amount. When every item shares a currency the
+// receipt's subtotal, tax, tip, service charge and total are rounded to it too. Items without a
+// currency are left as extracted, and each correction is logged.
+func (s *ReceiptServiceImpl) roundToCurrencyPrecision(receipt *domain.Receipt) {
+	if !s.currencyRounding {
+		return
+	}
+	round := func(field string, amount *float64, code string) {
+		policy := money.Policy{Precision: currency.Decimals(code), Rounding: s.moneyPolicy.Rounding}
+		rounded := policy.ToFloat(policy.FromFloat(*amount))
+		if rounded != *amount {
+			log.Printf("Rounded scanned %s from %v to %v %s", field, *amount, rounded, code)
+			*amount = rounded
+		}
+	}
+
+	receiptCurrency := ""
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		if item.Currency == "" {
+			receiptCurrency = ""
+			break
+		}
+		if i == 0 {
+			receiptCurrency = item.Currency
+		} else if item.Currency != receiptCurrency {
+			receiptCurrency = ""
+			break
+		}
+	}
+	for i := range receipt.Items {
+		if item := &receipt.Items[i]; item.Currency != "" {
+			round(fmt.Sprintf("price of %q", item.Name), &item.Price, item.Currency)
+		}
+	}
+	if receiptCurrency == "" {
+		return
+	}
+	round("subtotal", &receipt.Subtotal, receiptCurrency)
+	round("tax", &receipt.Tax, receiptCurrency)
+	round("tip", &receipt.Tip, receiptCurrency)
+	round("service charge", &receipt.ServiceCharge, receiptCurrency)
+	round("total", &receipt.Total, receiptCurrency)
+}
+
+// ConvertReceiptItems sets the converted price of each item in the target currency, using the
+// rates published on the receipt's date. Original prices are left untouched, and items without
+// a currency are skipped since their amounts cannot be converted.
+func (s *ReceiptServiceImpl) ConvertReceiptItems(ctx context.Context, receipt *domain.Receipt, toCurrency string) error {
+	toCurrency = currency.NormalizeCurrency(toCurrency)
+	if !isCurrencyCode(toCurrency) {
+		return ValidationErrors{{Field: "convertTo", Message: "Currency must be a 3-letter ISO 4217 code"}}
+	}
+	if s.currencyConverter == nil {
+		return &ReceiptServiceError{
+			Op:  "convert_receipt_items",
+			Err: fmt.Errorf("%w: currency converter is missing", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	// Convert a copy so items shared with the stored receipt are left as they are
+	items := make([]domain.ReceiptItem, len(receipt.Items))
+	copy(items, receipt.Items)
+	for i := range items {
+		item := &items[i]
+		if item.Currency == "" {
+			continue
+		}
+
+		conversion, err := s.currencyConverter.ConvertOn(ctx, item.Price, item.Currency, toCurrency, receipt.Date.Time)
+		if err != nil {
+			return &ReceiptServiceError{
+				Op:  "convert_receipt_items",
+				Err: err,
+			}
+		}
+
+		converted := s.moneyPolicy.ToFloat(s.moneyPolicy.FromFloat(conversion.Amount))
+		item.ConvertedPrice = &converted
+		item.ConvertedCurrency = toCurrency
+	}
+	receipt.Items = items
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// staticCurrencyCatalog supports a fixed list of currency codes
+type staticCurrencyCatalog []string
+
+func (c staticCurrencyCatalog) GetSupportedCurrencies(ctx context.Context) ([]string, error) {
+	return c, nil
+}
+
+func TestItemCurrencyCheck(t *testing.T) {
+	catalog := staticCurrencyCatalog{"EUR", "USD", "IDR"}
+
+	t.Run("unsupported currency warns and still persists", func(t *testing.T) {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, CurrencyCatalog: catalog})
+
+		receipt, err := svc.CreateReceipt(context.Background(), &domain.Receipt{
+			UserID:   "user-1",
+			Merchant: "Bazaar",
+			Items: []domain.ReceiptItem{
+				{Name: "Tea", Quantity: 1, Price: 3, Currency: "usd"},
+				{Name: "Rug", Quantity: 1, Price: 90, Currency: "XYZ"},
+				{Name: "Lamp", Quantity: 1, Price: 40, Currency: "XYZ"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+
+		require.Len(t, receipt.Warnings, 1)
+		assert.Contains(t, receipt.Warnings[0], "'XYZ'")
+		assert.Equal(t, "USD", receipt.Items[0].Currency)
+		assert.Equal(t, "XYZ", receipt.Items[1].Currency)
+	})
+
+	t.Run("symbols map to supported codes", func(t *testing.T) {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:      repo,
+			CurrencyCatalog: catalog,
+			MaxScanWorkers:  1,
+			OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+				VendorName: "Warung",
+				Items: []domain.LineItem{
+					{Description: "Nasi goreng", Quantity: 1, UnitPrice: 25000, Currency: "Rp"},
+					{Description: "Croissant", Quantity: 1, UnitPrice: 2, Currency: "€"},
+				},
+			}},
+		})
+
+		receipt, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, receipt.Warnings)
+		assert.Equal(t, "IDR", receipt.Items[0].Currency)
+		assert.Equal(t, "EUR", receipt.Items[1].Currency)
+	})
+}
+
+func TestScanCurrencyRounding(t *testing.T) {
+	scan := func(rounding bool, items []domain.LineItem) *domain.Receipt {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:       repo,
+			CurrencyRounding: rounding,
+			MaxScanWorkers:   1,
+			OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+				VendorName: "Warung",
+				Subtotal:   42000.5,
+				TaxAmount:  4620.4,
+				TotalDue:   46620.9,
+				Items:      items,
+			}},
+		})
+
+		_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+		return repo.created[0]
+	}
+	rupiah := []domain.LineItem{
+		{Description: "Nasi goreng", Quantity: 1, UnitPrice: 25000.5, Currency: "Rp"},
+		{Description: "Es teh", Quantity: 1, UnitPrice: 17000, Currency: "IDR"},
+	}
+
+	t.Run("IDR amounts are saved as whole rupiah", func(t *testing.T) {
+		receipt := scan(true, rupiah)
+
+		assert.Equal(t, 25001.0, receipt.Items[0].Price)
+		assert.Equal(t, 17000.0, receipt.Items[1].Price)
+		assert.Equal(t, 42001.0, receipt.Subtotal)
+		assert.Equal(t, 4620.0, receipt.Tax)
+		assert.Equal(t, 46621.0, receipt.Total)
+	})
+
+	t.Run("mixed currencies only round item prices", func(t *testing.T) {
+		receipt := scan(true, []domain.LineItem{
+			{Description: "Nasi goreng", Quantity: 1, UnitPrice: 25000.5, Currency: "IDR"},
+			{Description: "Croissant", Quantity: 1, UnitPrice: 2.499, Currency: "EUR"},
+		})
+
+		assert.Equal(t, 25001.0, receipt.Items[0].Price)
+		assert.Equal(t, 2.5, receipt.Items[1].Price)
+		assert.Equal(t, 46620.9, receipt.Total)
+	})
+
+	t.Run("disabled rounding keeps extracted amounts", func(t *testing.T) {
+		receipt := scan(false, rupiah)
+		assert.Equal(t, 25000.5, receipt.Items[0].Price)
+		assert.Equal(t, 46620.9, receipt.Total)
+	})
+}
+
+// stubURLExtractor returns a fixed invoice for any stored image URL
+type stubURLExtractor struct {
+	invoice *domain.Invoice
+}
+
+func (e *stubURLExtractor) ExtractInvoiceData(ctx context.Context, imageURL string) (*domain.Invoice, error) {
+	return e.invoice, nil
+}
+
+func TestRetryScanCurrencyRounding(t *testing.T) {
+	repo := &editedReceiptRepository{receipt: domain.Receipt{
+		ID:         "receipt-1",
+		UserID:     "user-1",
+		ReceiptURL: "https://storage.example.com/invoice_1.jpg",
+	}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:       repo,
+		UseMLXService:    true,
+		CurrencyRounding: true,
+		MaxScanWorkers:   1,
+		MLXClient: &stubURLExtractor{invoice: &domain.Invoice{
+			VendorName: "Ramen Ya",
+			TotalDue:   1980.4,
+			Items:      []domain.LineItem{{Description: "Shoyu ramen", Quantity: 1, UnitPrice: 1980.4, Currency: "JPY"}},
+		}},
+	})
+
+	receipt, err := svc.RetryScanReceipt(context.Background(), "receipt-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1980.0, receipt.Items[0].Price)
+	assert.Equal(t, 1980.0, receipt.Total)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// maxCategoryItems is the number of items reported per category in the spending breakdown
+const maxCategoryItems = 10
+
+// groupCategoryItems merges item name variants that share a normalized name, summing their
+// spend and counts, and returns the largest groups first. Each group is named after its
+// highest-spending variant.
+func (s *ReceiptServiceImpl) groupCategoryItems(items []domain.CategorySpendingItemDetail) []domain.CategorySpendingItemDetail {
+	grouped := []domain.CategorySpendingItemDetail{}
+	groupIndex := map[string]int{}
+	representativeSpend := map[string]float64{}
+
+	for _, item := range items {
+		key := s.itemNameRules.NormalizeItemName(item.Name)
+		idx, ok := groupIndex[key]
+		if !ok {
+			groupIndex[key] = len(grouped)
+			representativeSpend[key] = item.TotalSpent
+			grouped = append(grouped, item)
+			continue
+		}
+
+		group := &grouped[idx]
+		if item.TotalSpent > representativeSpend[key] {
+			representativeSpend[key] = item.TotalSpent
+			group.Name = item.Name
+		}
+		group.TotalSpent = s.moneyPolicy.Sum(group.TotalSpent, item.TotalSpent)
+		group.Count += item.Count
+		group.TotalQuantity += item.TotalQuantity
+	}
+
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return grouped[i].TotalSpent > grouped[j].TotalSpent
+	})
+	if len(grouped) > maxCategoryItems {
+		grouped = grouped[:maxCategoryItems]
+	}
+	return grouped
+}
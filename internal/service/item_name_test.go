@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// categorySpendingRepository returns a fixed category breakdown with per-name item totals
+type categorySpendingRepository struct {
+	repository.ReceiptRepository
+	spending domain.CategorySpending
+}
+
+func (r *categorySpendingRepository) GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.CategorySpending, error) {
+	spending := r.spending
+	spending.Categories = append([]domain.CategorySpendingItem(nil), r.spending.Categories...)
+	return &spending, nil
+}
+
+func TestSpendingByCategoryGroupsItemNames(t *testing.T) {
+	repo := &categorySpendingRepository{spending: domain.CategorySpending{
+		Total: 20,
+		Categories: []domain.CategorySpendingItem{{
+			Name:   "Food",
+			Amount: 20,
+			Items: []domain.CategorySpendingItemDetail{
+				{Name: "Bread", TotalSpent: 6, Count: 2, TotalQuantity: 2},
+				{Name: "MILK 2%", TotalSpent: 5.5, Count: 2, TotalQuantity: 2},
+				{Name: "milk 2 percent", TotalSpent: 4.25, Count: 1, TotalQuantity: 2},
+				{Name: "Milk", TotalSpent: 2.5, Count: 1, TotalQuantity: 1},
+				{Name: "Kirkland Eggs 12 pcs", TotalSpent: 1.75, Count: 1, TotalQuantity: 1},
+			},
+		}},
+	}}
+	ctx := context.Background()
+	scope := domain.ReceiptScope{UserID: "user-1"}
+
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+	spending, err := svc.GetSpendingByCategory(ctx, scope, nil, nil)
+	require.NoError(t, err)
+
+	items := spending.Categories[0].Items
+	require.Len(t, items, 3)
+	assert.Equal(t, domain.CategorySpendingItemDetail{Name: "MILK 2%", TotalSpent: 12.25, Count: 4, TotalQuantity: 5}, items[0])
+	assert.Equal(t, "Bread", items[1].Name)
+	assert.Equal(t, "Kirkland Eggs 12 pcs", items[2].Name)
+
+	t.Run("rules are configurable", func(t *testing.T) {
+		rules := domain.ItemNameRules{StripQuantities: false, IgnoredWords: []string{"kirkland"}}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, ItemNameRules: &rules})
+		spending, err := svc.GetSpendingByCategory(ctx, scope, nil, nil)
+		require.NoError(t, err)
+
+		// Without quantity stripping each milk variant is its own item
+		assert.Len(t, spending.Categories[0].Items, 5)
+		assert.Equal(t, "eggs 12 pcs", rules.NormalizeItemName("Kirkland Eggs 12 pcs"))
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// MerchantRuleService defines the interface for merchant categorization rules
+type MerchantRuleService interface {
+	CreateMerchantRule(ctx context.Context, userID, merchant, category string) (*domain.MerchantRule, error)
+	ListMerchantRules(ctx context.Context, userID string) ([]domain.MerchantRule, error)
+	DeleteMerchantRule(ctx context.Context, userID, ruleID string) error
+	ApplyMerchantRules(ctx context.Context, userID string) (int64, error)
+	// ClassifyText previews the category scanned items get for the text, as a merchant and as an item description
+	ClassifyText(ctx context.Context, userID, text string) (*domain.CategoryClassification, error)
+}
+
+// merchantRuleService implements MerchantRuleService
+type merchantRuleService struct {
+	repository repository.MerchantRuleRepository
+}
+
+// NewMerchantRuleService creates a new MerchantRuleService
+func NewMerchantRuleService(repo repository.MerchantRuleRepository) MerchantRuleService {
+	return &merchantRuleService{repository: repo}
+}
+
+// CreateMerchantRule creates or replaces the rule for a merchant
+func (s *merchantRuleService) CreateMerchantRule(ctx context.Context, userID, merchant, category string) (*domain.MerchantRule, error) {
+	rule, err := s.repository.UpsertMerchantRule(ctx, &domain.MerchantRule{
+		UserID:   userID,
+		Merchant: strings.TrimSpace(merchant),
+		Category: strings.TrimSpace(category),
+	})
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "create_merchant_rule",
+			Err: err,
+		}
+	}
+	return rule, nil
+}
+
+// ListMerchantRules retrieves all rules for a user
+func (s *merchantRuleService) ListMerchantRules(ctx context.Context, userID string) ([]domain.MerchantRule, error) {
+	rules, err := s.repository.ListMerchantRules(ctx, userID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_merchant_rules",
+			Err: err,
+		}
+	}
+	return rules, nil
+}
+
+// DeleteMerchantRule deletes a rule owned by the user
+func (s *merchantRuleService) DeleteMerchantRule(ctx context.Context, userID, ruleID string) error {
+	if err := s.repository.DeleteMerchantRule(ctx, userID, ruleID); err != nil {
+		return &ReceiptServiceError{
+			Op:  "delete_merchant_rule",
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// ApplyMerchantRules recategorizes existing items for merchants with a rule
+func (s *merchantRuleService) ApplyMerchantRules(ctx context.Context, userID string) (int64, error) {
+	updated, err := s.repository.ApplyMerchantRules(ctx, userID)
+	if err != nil {
+		return 0, &ReceiptServiceError{
+			Op:  "apply_merchant_rules",
+			Err: err,
+		}
+	}
+	return updated, nil
+}
+
+// ClassifyText runs the text through the same classification scanned items get: the user's rule
+// for a merchant of that name first, then the built-in description keywords
+func (s *merchantRuleService) ClassifyText(ctx context.Context, userID, text string) (*domain.CategoryClassification, error) {
+	rule, err := s.repository.GetMerchantRuleByMerchant(ctx, userID, text)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "classify_text",
+			Err: err,
+		}
+	}
+	if rule != nil {
+		return &domain.CategoryClassification{
+			Category: rule.Category,
+			Source:   domain.ClassificationMerchantRule,
+			RuleID:   rule.ID,
+			Match:    rule.Merchant,
+		}, nil
+	}
+
+	category, keyword := matchCategoryKeyword(text)
+	if keyword == "" {
+		return &domain.CategoryClassification{Category: category, Source: domain.ClassificationDefault}, nil
+	}
+	return &domain.CategoryClassification{
+		Category: category,
+		Source:   domain.ClassificationKeyword,
+		Match:    keyword,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// stubMerchantRuleRepository serves rules keyed by normalized merchant name
+type stubMerchantRuleRepository struct {
+	repository.MerchantRuleRepository
+	rules map[string]domain.MerchantRule
+}
+
+func (r *stubMerchantRuleRepository) GetMerchantRuleByMerchant(ctx context.Context, userID, merchant string) (*domain.MerchantRule, error) {
+	rule, ok := r.rules[domain.MerchantRuleKey(merchant)]
+	if !ok || rule.UserID != userID {
+		return nil, nil
+	}
+	return &rule, nil
+}
+
+func TestBuildReceiptItemsAppliesMerchantRule(t *testing.T) {
+	rules := &stubMerchantRuleRepository{
+		rules: map[string]domain.MerchantRule{
+			"starbucks": {UserID: "user-1", Merchant: "Starbucks", Category: "Food"},
+		},
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{MerchantRuleRepository: rules}).(*ReceiptServiceImpl)
+
+	lineItems := []domain.LineItem{
+		{Description: "Office mug", Quantity: 1, UnitPrice: 12},
+		{Description: "Latte", Quantity: 2, UnitPrice: 5, Category: "Beverages"},
+	}
+
+	t.Run("ruled merchant categorizes items without a category", func(t *testing.T) {
+		items := svc.buildReceiptItems(context.Background(), "user-1", "  STARBUCKS ", lineItems)
+
+		assert.Equal(t, "Food", items[0].Category)
+		assert.Equal(t, "Beverages", items[1].Category, "extracted category takes precedence")
+		assert.Equal(t, "USD", items[0].Currency)
+	})
+
+	t.Run("unruled merchant falls back to keyword inference", func(t *testing.T) {
+		items := svc.buildReceiptItems(context.Background(), "user-1", "Stationery World", lineItems)
+
+		assert.Equal(t, "Office Supplies", items[0].Category)
+	})
+
+	t.Run("rules of other users are ignored", func(t *testing.T) {
+		items := svc.buildReceiptItems(context.Background(), "user-2", "Starbucks", lineItems)
+
+		assert.Equal(t, "Office Supplies", items[0].Category)
+	})
+}
+
+func TestClassifyTextReportsMatchingRule(t *testing.T) {
+	rules := &stubMerchantRuleRepository{
+		rules: map[string]domain.MerchantRule{
+			"starbucks": {ID: "rule-1", UserID: "user-1", Merchant: "Starbucks", Category: "Food"},
+		},
+	}
+	svc := NewMerchantRuleService(rules)
+	ctx := context.Background()
+
+	classification, err := svc.ClassifyText(ctx, "user-1", "Airport taxi fare")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CategoryClassification{Category: "Transport", Source: domain.ClassificationKeyword, Match: "taxi"}, classification)
+
+	classification, err = svc.ClassifyText(ctx, "user-1", "starbucks ")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CategoryClassification{Category: "Food", Source: domain.ClassificationMerchantRule, RuleID: "rule-1", Match: "Starbucks"}, classification)
+
+	classification, err = svc.ClassifyText(ctx, "user-1", "Garden hose")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CategoryClassification{Category: "Other", Source: domain.ClassificationDefault}, classification)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// Organization errors
+var (
+	ErrNotOrganizationMember = errors.New("user is not a member of the organization")
+	ErrNotOrganizationOwner  = errors.New("only the organization owner can manage members")
+	ErrOrganizationsDisabled = errors.New("organizations are not available")
+)
+
+// OrganizationService defines the interface for organizations that share receipts
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userID, name string) (*domain.Organization, error)
+	ListOrganizations(ctx context.Context, userID string) ([]domain.Organization, error)
+	InviteMember(ctx context.Context, orgID, inviterID, email string) (*domain.OrganizationMember, error)
+	ListMembers(ctx context.Context, orgID, userID string) ([]domain.OrganizationMember, error)
+}
+
+// organizationService implements OrganizationService
+type organizationService struct {
+	repository repository.OrganizationRepository
+	userRepo   repository.UserRepository
+}
+
+// NewOrganizationService creates a new OrganizationService
+func NewOrganizationService(repo repository.OrganizationRepository, userRepo repository.UserRepository) OrganizationService {
+	return &organizationService{
+		repository: repo,
+		userRepo:   userRepo,
+	}
+}
+
+// CreateOrganization creates an organization owned by the user
+func (s *organizationService) CreateOrganization(ctx context.Context, userID, name string) (*domain.Organization, error) {
+	org, err := s.repository.CreateOrganization(ctx, &domain.Organization{
+		Name:    strings.TrimSpace(name),
+		OwnerID: userID,
+	})
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "create_organization",
+			Err: err,
+		}
+	}
+	return org, nil
+}
+
+// ListOrganizations retrieves the organizations the user is a member of
+func (s *organizationService) ListOrganizations(ctx context.Context, userID string) ([]domain.Organization, error) {
+	orgs, err := s.repository.ListOrganizationsForUser(ctx, userID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_organizations",
+			Err: err,
+		}
+	}
+	return orgs, nil
+}
+
+// InviteMember adds the user registered with the email to the organization.
+// Only the organization owner can invite members.
+func (s *organizationService) InviteMember(ctx context.Context, orgID, inviterID, email string) (*domain.OrganizationMember, error) {
+	role, err := s.repository.GetMemberRole(ctx, orgID, inviterID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_member_role",
+			Err: err,
+		}
+	}
+	if role == "" {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_organization_membership",
+			Err: ErrNotOrganizationMember,
+		}
+	}
+	if role != domain.OrganizationRoleOwner {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_organization_owner",
+			Err: ErrNotOrganizationOwner,
+		}
+	}
+
+	// Only registered users can be invited
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil || user == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_invited_user",
+			Err: ErrUserNotFound,
+		}
+	}
+
+	member, err := s.repository.AddOrganizationMember(ctx, &domain.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Role:           domain.OrganizationRoleMember,
+	})
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "add_organization_member",
+			Err: err,
+		}
+	}
+	member.Email = user.Email
+	member.Name = user.Name
+
+	return member, nil
+}
+
+// ListMembers retrieves the members of an organization the user belongs to
+func (s *organizationService) ListMembers(ctx context.Context, orgID, userID string) ([]domain.OrganizationMember, error) {
+	if err := verifyOrganizationMember(ctx, s.repository, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repository.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_organization_members",
+			Err: err,
+		}
+	}
+	return members, nil
+}
+
+// verifyOrganizationMember returns ErrNotOrganizationMember unless the user belongs to the organization
+func verifyOrganizationMember(ctx context.Context, repo repository.OrganizationRepository, orgID, userID string) error {
+	if repo == nil {
+		return &ReceiptServiceError{
+			Op:  "verify_organization_membership",
+			Err: ErrOrganizationsDisabled,
+		}
+	}
+
+	role, err := repo.GetMemberRole(ctx, orgID, userID)
+	if err != nil {
+		return &ReceiptServiceError{
+			Op:  "verify_organization_membership",
+			Err: fmt.Errorf("failed to check membership: %w", err),
+		}
+	}
+	if role == "" {
+		return &ReceiptServiceError{
+			Op:  "verify_organization_membership",
+			Err: ErrNotOrganizationMember,
+		}
+	}
+	return nil
+}
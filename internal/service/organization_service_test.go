@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// memoryOrganizationRepository keeps member roles keyed by organization and user
+type memoryOrganizationRepository struct {
+	repository.OrganizationRepository
+	roles map[string]map[string]string
+}
+
+func (r *memoryOrganizationRepository) GetMemberRole(ctx context.Context, orgID, userID string) (string, error) {
+	return r.roles[orgID][userID], nil
+}
+
+func (r *memoryOrganizationRepository) AddOrganizationMember(ctx context.Context, member *domain.OrganizationMember) (*domain.OrganizationMember, error) {
+	if r.roles[member.OrganizationID] == nil {
+		r.roles[member.OrganizationID] = map[string]string{}
+	}
+	r.roles[member.OrganizationID][member.UserID] = member.Role
+	stored := *member
+	return &stored, nil
+}
+
+// memoryReceiptRepository lists receipts by owner or organization like the Postgres repository
+type memoryReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts []domain.Receipt
+}
+
+func (r *memoryReceiptRepository) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
+	result := &domain.PaginatedReceipts{Data: []domain.Receipt{}}
+	for _, receipt := range r.receipts {
+		if (filter.OrgID != "" && receipt.OrgID == filter.OrgID) || (filter.OrgID == "" && receipt.UserID == filter.UserID) {
+			result.Data = append(result.Data, receipt)
+		}
+	}
+	result.Pagination.TotalItems = len(result.Data)
+	return result, nil
+}
+
+func (r *memoryReceiptRepository) GetDashboardSummary(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.DashboardSummary, error) {
+	summary := &domain.DashboardSummary{}
+	for _, receipt := range r.receipts {
+		if (scope.OrgID != "" && receipt.OrgID == scope.OrgID) || (scope.OrgID == "" && receipt.UserID == scope.UserID) {
+			summary.TotalSpend += receipt.Total
+			summary.ReceiptCount++
+		}
+	}
+	return summary, nil
+}
+
+// memoryUserRepository finds users by email
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []domain.User
+}
+
+func (r *memoryUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for i := range r.users {
+		if r.users[i].Email == email {
+			return &r.users[i], nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func TestOrganizationScopedReceipts(t *testing.T) {
+	orgs := &memoryOrganizationRepository{
+		roles: map[string]map[string]string{
+			"org-1": {"owner": domain.OrganizationRoleOwner, "member": domain.OrganizationRoleMember},
+		},
+	}
+	receipts := &memoryReceiptRepository{
+		receipts: []domain.Receipt{
+			{ID: "shared", UserID: "owner", OrgID: "org-1", Total: 40},
+			{ID: "personal", UserID: "owner", Total: 15},
+			{ID: "outsider-own", UserID: "outsider", Total: 5},
+		},
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: receipts, OrganizationRepository: orgs})
+	ctx := context.Background()
+
+	t.Run("member sees org receipts", func(t *testing.T) {
+		result, err := svc.ListReceipts(ctx, domain.ReceiptFilter{UserID: "member", OrgID: "org-1"})
+		require.NoError(t, err)
+
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "shared", result.Data[0].ID)
+
+		summary, err := svc.GetDashboardSummary(ctx, domain.ReceiptScope{UserID: "member", OrgID: "org-1"}, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.ReceiptCount)
+		assert.InDelta(t, 40, summary.TotalSpend, 0.001)
+	})
+
+	t.Run("non-member does not see org receipts", func(t *testing.T) {
+		result, err := svc.ListReceipts(ctx, domain.ReceiptFilter{UserID: "outsider", OrgID: "org-1"})
+		assert.ErrorIs(t, err, ErrNotOrganizationMember)
+		assert.Nil(t, result)
+
+		summary, err := svc.GetDashboardSummary(ctx, domain.ReceiptScope{UserID: "outsider", OrgID: "org-1"}, nil, nil)
+		assert.ErrorIs(t, err, ErrNotOrganizationMember)
+		assert.Nil(t, summary)
+	})
+
+	t.Run("personal scope only covers own receipts", func(t *testing.T) {
+		result, err := svc.ListReceipts(ctx, domain.ReceiptFilter{UserID: "member"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Data)
+	})
+}
+
+func TestInviteMember(t *testing.T) {
+	orgs := &memoryOrganizationRepository{
+		roles: map[string]map[string]string{
+			"org-1": {"owner": domain.OrganizationRoleOwner, "member": domain.OrganizationRoleMember},
+		},
+	}
+	users := &memoryUserRepository{
+		users: []domain.User{{ID: "invitee", Email: "invitee@example.com", Name: "Invitee"}},
+	}
+	svc := NewOrganizationService(orgs, users)
+	ctx := context.Background()
+
+	t.Run("owner invites registered user", func(t *testing.T) {
+		member, err := svc.InviteMember(ctx, "org-1", "owner", "invitee@example.com")
+		require.NoError(t, err)
+
+		assert.Equal(t, "invitee", member.UserID)
+		assert.Equal(t, domain.OrganizationRoleMember, member.Role)
+		assert.Equal(t, domain.OrganizationRoleMember, orgs.roles["org-1"]["invitee"])
+	})
+
+	t.Run("member cannot invite", func(t *testing.T) {
+		_, err := svc.InviteMember(ctx, "org-1", "member", "invitee@example.com")
+		assert.ErrorIs(t, err, ErrNotOrganizationOwner)
+	})
+
+	t.Run("non-member cannot invite", func(t *testing.T) {
+		_, err := svc.InviteMember(ctx, "org-1", "outsider", "invitee@example.com")
+		assert.ErrorIs(t, err, ErrNotOrganizationMember)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		_, err := svc.InviteMember(ctx, "org-1", "owner", "nobody@example.com")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
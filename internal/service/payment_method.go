@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// cardPaymentKeywords identify card payments in extracted or entered payment methods
+var cardPaymentKeywords = []string{"card", "credit", "debit", "visa", "mastercard", "amex", "american express"}
+
+// GetSpendingByPaymentMethod retrieves spending breakdown by payment method
+func (s *ReceiptServiceImpl) GetSpendingByPaymentMethod(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.PaymentMethodSpending, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repository.GetSpendingByPaymentMethod(ctx, scope, startDate, endDate)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_spending_by_payment_method",
+			Err: err,
+		}
+	}
+
+	return buildPaymentMethodSpending(totals), nil
+}
+
+// buildPaymentMethodSpending merges stored payment method totals into a breakdown sorted by amount.
+// Receipts without a payment method are reported as domain.PaymentMethodUnknown.
+func buildPaymentMethodSpending(totals []domain.PaymentMethodTotal) *domain.PaymentMethodSpending {
+	spending := &domain.PaymentMethodSpending{Methods: []domain.PaymentMethodSpendingItem{}}
+
+	indices := make(map[string]int)
+	for _, total := range totals {
+		method := normalizePaymentMethod(total.Method)
+		if method == "" {
+			method = domain.PaymentMethodUnknown
+		}
+
+		i, ok := indices[method]
+		if !ok {
+			i = len(spending.Methods)
+			indices[method] = i
+			spending.Methods = append(spending.Methods, domain.PaymentMethodSpendingItem{Method: method})
+		}
+		spending.Methods[i].Amount += total.Amount
+		spending.Methods[i].Count += total.Count
+		spending.Total += total.Amount
+	}
+
+	for i := range spending.Methods {
+		if spending.Total > 0 {
+			spending.Methods[i].Percentage = spending.Methods[i].Amount / spending.Total * 100
+		}
+	}
+
+	sort.SliceStable(spending.Methods, func(i, j int) bool {
+		return spending.Methods[i].Amount > spending.Methods[j].Amount
+	})
+
+	return spending
+}
+
+// normalizePaymentMethod maps cash and card payments to domain.PaymentMethodCash and domain.PaymentMethodCard
+// and lowercases any other method, so variants like "VISA" and "Credit Card" group together
+func normalizePaymentMethod(method string) string {
+	method = strings.ToLower(strings.TrimSpace(method))
+	if method == "" {
+		return ""
+	}
+	if strings.Contains(method, "cash") {
+		return domain.PaymentMethodCash
+	}
+	for _, keyword := range cardPaymentKeywords {
+		if strings.Contains(method, keyword) {
+			return domain.PaymentMethodCard
+		}
+	}
+	return method
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// paymentMethodRepository groups stored receipts by payment method like the Postgres repository
+type paymentMethodRepository struct {
+	repository.ReceiptRepository
+	receipts []domain.Receipt
+}
+
+func (r *paymentMethodRepository) GetSpendingByPaymentMethod(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) ([]domain.PaymentMethodTotal, error) {
+	indices := map[string]int{}
+	totals := []domain.PaymentMethodTotal{}
+	for _, receipt := range r.receipts {
+		if receipt.UserID != scope.UserID {
+			continue
+		}
+		i, ok := indices[receipt.PaymentMethod]
+		if !ok {
+			i = len(totals)
+			indices[receipt.PaymentMethod] = i
+			totals = append(totals, domain.PaymentMethodTotal{Method: receipt.PaymentMethod})
+		}
+		totals[i].Amount += receipt.Total
+		totals[i].Count++
+	}
+	return totals, nil
+}
+
+func TestGetSpendingByPaymentMethod(t *testing.T) {
+	repo := &paymentMethodRepository{
+		receipts: []domain.Receipt{
+			{UserID: "user-1", Total: 20, PaymentMethod: domain.PaymentMethodCash},
+			{UserID: "user-1", Total: 10, PaymentMethod: domain.PaymentMethodCash},
+			{UserID: "user-1", Total: 50, PaymentMethod: domain.PaymentMethodCard},
+			// Stored before normalization, groups with card
+			{UserID: "user-1", Total: 10, PaymentMethod: "VISA"},
+			{UserID: "user-1", Total: 10},
+			{UserID: "user-2", Total: 99, PaymentMethod: domain.PaymentMethodCard},
+		},
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+	spending, err := svc.GetSpendingByPaymentMethod(context.Background(), domain.ReceiptScope{UserID: "user-1"}, nil, nil)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 100, spending.Total, 0.001)
+	require.Len(t, spending.Methods, 3)
+
+	assert.Equal(t, domain.PaymentMethodCard, spending.Methods[0].Method)
+	assert.InDelta(t, 60, spending.Methods[0].Amount, 0.001)
+	assert.Equal(t, 2, spending.Methods[0].Count)
+	assert.InDelta(t, 60, spending.Methods[0].Percentage, 0.001)
+
+	assert.Equal(t, domain.PaymentMethodCash, spending.Methods[1].Method)
+	assert.InDelta(t, 30, spending.Methods[1].Amount, 0.001)
+	assert.Equal(t, 2, spending.Methods[1].Count)
+
+	assert.Equal(t, domain.PaymentMethodUnknown, spending.Methods[2].Method)
+	assert.InDelta(t, 10, spending.Methods[2].Amount, 0.001)
+	assert.Equal(t, 1, spending.Methods[2].Count)
+}
+
+func TestNormalizePaymentMethod(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"  Cash ":      domain.PaymentMethodCash,
+		"CASH PAYMENT": domain.PaymentMethodCash,
+		"Credit Card":  domain.PaymentMethodCard,
+		"debit":        domain.PaymentMethodCard,
+		"Mastercard":   domain.PaymentMethodCard,
+		"GoPay":        "gopay",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, normalizePaymentMethod(input), input)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ListActivity retrieves a page of the user's activity feed, newest first
+func (s *ReceiptServiceImpl) ListActivity(ctx context.Context, userID string, page, limit int) (*domain.ActivityFeed, error) {
+	if s.activityRepo == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "check_activity",
+			Err: fmt.Errorf("%w: activity repository is missing", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	feed, err := s.activityRepo.ListActivity(ctx, userID, page, limit)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_activity",
+			Err: err,
+		}
+	}
+	return feed, nil
+}
+
+// recordActivity adds an action on a receipt to the user's feed when activity is enabled.
+// The action has already happened, so a failure to record it is only logged.
+func (s *ReceiptServiceImpl) recordActivity(ctx context.Context, userID, action, receiptID string) {
+	if s.activityRepo == nil || userID == "" {
+		return
+	}
+
+	err := s.activityRepo.RecordActivity(ctx, &domain.Activity{
+		UserID:    userID,
+		Action:    action,
+		ReceiptID: receiptID,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to record %s activity for receipt %s: %v", action, receiptID, err)
+	}
+}
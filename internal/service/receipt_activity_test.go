@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// memoryActivityRepository keeps activity in order of recording and lists it newest first
+type memoryActivityRepository struct {
+	entries []domain.Activity
+}
+
+func (r *memoryActivityRepository) RecordActivity(ctx context.Context, activity *domain.Activity) error {
+	activity.CreatedAt = time.Now()
+	r.entries = append(r.entries, *activity)
+	return nil
+}
+
+func (r *memoryActivityRepository) ListActivity(ctx context.Context, userID string, page, limit int) (*domain.ActivityFeed, error) {
+	feed := &domain.ActivityFeed{Pagination: domain.Pagination{CurrentPage: page, Limit: limit}}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].UserID == userID {
+			feed.Data = append(feed.Data, r.entries[i])
+		}
+	}
+	feed.Pagination.TotalItems = len(feed.Data)
+	return feed, nil
+}
+
+func (r *memoryActivityRepository) ListDeletedReceiptIDs(ctx context.Context, userID string, since time.Time) ([]string, error) {
+	deletedIDs := []string{}
+	for _, entry := range r.entries {
+		if entry.UserID == userID && entry.Action == domain.ActivityReceiptDeleted && entry.CreatedAt.After(since) {
+			deletedIDs = append(deletedIDs, entry.ReceiptID)
+		}
+	}
+	return deletedIDs, nil
+}
+
+// deletableReceiptRepository stores receipts in memory so they can be created, fetched and deleted
+type deletableReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts map[string]*domain.Receipt
+}
+
+func (r *deletableReceiptRepository) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	receipt.ID = "receipt-1"
+	r.receipts[receipt.ID] = receipt
+	return receipt, nil
+}
+
+func (r *deletableReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	receipt, ok := r.receipts[receiptID]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return receipt, nil
+}
+
+func (r *deletableReceiptRepository) DeleteReceipt(ctx context.Context, receiptID string) error {
+	delete(r.receipts, receiptID)
+	return nil
+}
+
+func TestActivityFeedRecordsCreateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	activity := &memoryActivityRepository{}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:         &deletableReceiptRepository{receipts: map[string]*domain.Receipt{}},
+		ActivityRepository: activity,
+	})
+
+	created, err := svc.CreateReceipt(ctx, &domain.Receipt{UserID: "user-1", Merchant: "Bakery"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteReceipt(ctx, created.ID))
+
+	feed, err := svc.ListActivity(ctx, "user-1", 1, 20)
+	require.NoError(t, err)
+	require.Len(t, feed.Data, 2)
+	assert.Equal(t, domain.ActivityReceiptDeleted, feed.Data[0].Action)
+	assert.Equal(t, domain.ActivityReceiptCreated, feed.Data[1].Action)
+	assert.Equal(t, created.ID, feed.Data[0].ReceiptID)
+	assert.Equal(t, created.ID, feed.Data[1].ReceiptID)
+
+	other, err := svc.ListActivity(ctx, "user-2", 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, other.Data)
+
+	t.Run("feed is unavailable without a repository", func(t *testing.T) {
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: &deletableReceiptRepository{}})
+		_, err := svc.ListActivity(ctx, "user-1", 1, 20)
+		assert.ErrorIs(t, err, domain.ErrServiceNotConfigured)
+	})
+}
+
+// modifiedReceiptRepository lists its receipts updated after the filter's ModifiedSince
+type modifiedReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts []domain.Receipt
+}
+
+func (r *modifiedReceiptRepository) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
+	result := &domain.PaginatedReceipts{Data: []domain.Receipt{}}
+	for _, receipt := range r.receipts {
+		if filter.ModifiedSince == nil || receipt.UpdatedAt.After(*filter.ModifiedSince) {
+			result.Data = append(result.Data, receipt)
+		}
+	}
+	return result, nil
+}
+
+func TestListReceiptsModifiedSince(t *testing.T) {
+	ctx := context.Background()
+	lastSync := time.Now()
+	activity := &memoryActivityRepository{entries: []domain.Activity{
+		{UserID: "user-1", Action: domain.ActivityReceiptDeleted, ReceiptID: "deleted-before-sync", CreatedAt: lastSync.Add(-time.Hour)},
+		{UserID: "user-1", Action: domain.ActivityReceiptDeleted, ReceiptID: "deleted-after-sync", CreatedAt: lastSync.Add(time.Minute)},
+		{UserID: "user-2", Action: domain.ActivityReceiptDeleted, ReceiptID: "other-user", CreatedAt: lastSync.Add(time.Minute)},
+	}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository: &modifiedReceiptRepository{receipts: []domain.Receipt{
+			{ID: "updated-before-sync", UserID: "user-1", UpdatedAt: lastSync.Add(-time.Hour)},
+			{ID: "updated-after-sync", UserID: "user-1", UpdatedAt: lastSync.Add(time.Minute)},
+		}},
+		ActivityRepository: activity,
+	})
+
+	receipts, err := svc.ListReceipts(ctx, domain.ReceiptFilter{UserID: "user-1", ModifiedSince: &lastSync})
+	require.NoError(t, err)
+	require.Len(t, receipts.Data, 1)
+	assert.Equal(t, "updated-after-sync", receipts.Data[0].ID)
+	assert.Equal(t, []string{"deleted-after-sync"}, receipts.DeletedIDs)
+
+	t.Run("deletions are only listed when syncing", func(t *testing.T) {
+		receipts, err := svc.ListReceipts(ctx, domain.ReceiptFilter{UserID: "user-1"})
+		require.NoError(t, err)
+		assert.Len(t, receipts.Data, 2)
+		assert.Nil(t, receipts.DeletedIDs)
+	})
+}
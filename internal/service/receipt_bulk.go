@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// Reasons reported for receipts a bulk operation did not apply to
+const (
+	bulkReasonNotFound = "receipt not found"
+	bulkReasonFailed   = "failed to delete receipt"
+	bulkReasonSkipped  = "not deleted because another receipt in the request failed"
+)
+
+// DeleteReceipts deletes the user's receipts with the given IDs and reports the outcome for each.
+// Receipts that are missing or belong to someone else are reported as failed without stopping
+// the rest. When atomic is set, nothing is deleted unless every receipt can be, and the receipts
+// are then deleted in one transaction.
+func (s *ReceiptServiceImpl) DeleteReceipts(ctx context.Context, userID string, receiptIDs []string, atomic bool) (*domain.BulkResult, error) {
+	result := domain.NewBulkResult()
+
+	seen := make(map[string]bool, len(receiptIDs))
+	var owned []string
+	for _, id := range receiptIDs {
+		id = strings.TrimSpace(id)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		receipt, err := s.repository.GetReceiptByID(ctx, id)
+		if err != nil {
+			if !strings.Contains(err.Error(), "not found") {
+				return nil, &ReceiptServiceError{
+					Op:  "get_receipt_for_delete",
+					Err: err,
+				}
+			}
+			result.Fail(id, bulkReasonNotFound)
+			continue
+		}
+		// Receipts of other users are reported as missing so their existence isn't revealed
+		if receipt.UserID != userID {
+			result.Fail(id, bulkReasonNotFound)
+			continue
+		}
+		owned = append(owned, id)
+	}
+
+	if atomic {
+		if len(result.Failed) > 0 {
+			for _, id := range owned {
+				result.Fail(id, bulkReasonSkipped)
+			}
+			return result, nil
+		}
+		if len(owned) > 0 {
+			if err := s.repository.DeleteReceipts(ctx, owned); err != nil {
+				return nil, &ReceiptServiceError{
+					Op:  "delete_receipts",
+					Err: err,
+				}
+			}
+		}
+		for _, id := range owned {
+			s.recordActivity(ctx, userID, domain.ActivityReceiptDeleted, id)
+		}
+		result.Succeeded = append(result.Succeeded, owned...)
+		return result, nil
+	}
+
+	for _, id := range owned {
+		if err := s.repository.DeleteReceipt(ctx, id); err != nil {
+			reason := bulkReasonFailed
+			if strings.Contains(err.Error(), "not found") {
+				// Deleted by another request since it was checked
+				reason = bulkReasonNotFound
+			}
+			result.Fail(id, reason)
+			continue
+		}
+		s.recordActivity(ctx, userID, domain.ActivityReceiptDeleted, id)
+		result.Succeeded = append(result.Succeeded, id)
+	}
+	return result, nil
+}
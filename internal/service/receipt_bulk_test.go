@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// deletingReceiptRepository keeps receipts in memory and deletes them on request
+type deletingReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts map[string]domain.Receipt
+}
+
+func (r *deletingReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	receipt, ok := r.receipts[receiptID]
+	if !ok {
+		return nil, fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	return &receipt, nil
+}
+
+func (r *deletingReceiptRepository) DeleteReceipt(ctx context.Context, receiptID string) error {
+	if _, ok := r.receipts[receiptID]; !ok {
+		return fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	delete(r.receipts, receiptID)
+	return nil
+}
+
+func (r *deletingReceiptRepository) DeleteReceipts(ctx context.Context, receiptIDs []string) error {
+	for _, id := range receiptIDs {
+		if _, ok := r.receipts[id]; !ok {
+			return fmt.Errorf("receipt not found: %s", id)
+		}
+	}
+	for _, id := range receiptIDs {
+		delete(r.receipts, id)
+	}
+	return nil
+}
+
+func TestDeleteReceiptsReportsPerReceiptResults(t *testing.T) {
+	newRepo := func() *deletingReceiptRepository {
+		return &deletingReceiptRepository{receipts: map[string]domain.Receipt{
+			"receipt-1": {ID: "receipt-1", UserID: "user-1"},
+			"receipt-2": {ID: "receipt-2", UserID: "user-1"},
+			"receipt-3": {ID: "receipt-3", UserID: "user-2"},
+		}}
+	}
+	ids := []string{"receipt-1", "missing", "receipt-2", "receipt-3", "receipt-1"}
+	ctx := context.Background()
+
+	t.Run("valid receipts are deleted despite invalid ones", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+		result, err := svc.DeleteReceipts(ctx, "user-1", ids, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"receipt-1", "receipt-2"}, result.Succeeded)
+		assert.Equal(t, []domain.BulkFailure{
+			{ID: "missing", Reason: "receipt not found"},
+			{ID: "receipt-3", Reason: "receipt not found"},
+		}, result.Failed)
+		assert.Equal(t, []string{"receipt-3"}, remainingReceiptIDs(repo.receipts))
+	})
+
+	t.Run("atomic requests delete nothing when any receipt fails", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+		result, err := svc.DeleteReceipts(ctx, "user-1", ids, true)
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Succeeded)
+		assert.Equal(t, []domain.BulkFailure{
+			{ID: "missing", Reason: "receipt not found"},
+			{ID: "receipt-3", Reason: "receipt not found"},
+			{ID: "receipt-1", Reason: "not deleted because another receipt in the request failed"},
+			{ID: "receipt-2", Reason: "not deleted because another receipt in the request failed"},
+		}, result.Failed)
+		assert.Len(t, repo.receipts, 3)
+
+		result, err = svc.DeleteReceipts(ctx, "user-1", []string{"receipt-1", "receipt-2"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"receipt-1", "receipt-2"}, result.Succeeded)
+		assert.Empty(t, result.Failed)
+		assert.Len(t, repo.receipts, 1)
+	})
+}
+
+// remainingReceiptIDs returns the receipt IDs left in the repository
+func remainingReceiptIDs(receipts map[string]domain.Receipt) []string {
+	ids := make([]string, 0, len(receipts))
+	for id := range receipts {
+		ids = append(ids, id)
+	}
+	return ids
+}
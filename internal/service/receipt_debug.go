@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// RawInvoiceExtractor is an InvoiceExtractor that can also return its raw response for diagnostics
+type RawInvoiceExtractor interface {
+	ExtractInvoiceDataWithRaw(ctx context.Context, imageData []byte) (*domain.Invoice, string, error)
+}
+
+// DebugScanReceipt runs an image through preprocessing and OpenRouter extraction without storing
+// a receipt, returning each stage. Extraction failures are reported in the result rather than as
+// an error so the raw output can still be inspected. MLX extraction is not used because it reads
+// the image from storage.
+func (s *ReceiptServiceImpl) DebugScanReceipt(ctx context.Context, imageData []byte) (*domain.ScanDebug, error) {
+	if s.openAIClient == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "check_debug_extractor",
+			Err: fmt.Errorf("%w: OpenRouter extraction is required for scan diagnostics", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	select {
+	case s.workerPool <- struct{}{}:
+		defer func() { <-s.workerPool }()
+	case <-ctx.Done():
+		return nil, &ReceiptServiceError{
+			Op:  "acquire_worker",
+			Err: ctx.Err(),
+		}
+	}
+
+	debug := &domain.ScanDebug{
+		OriginalSize:     len(imageData),
+		ExtractionMethod: domain.ExtractionMethodOpenRouter,
+	}
+
+	// Preprocess as extractPage does, keeping the original when the image can't be resized
+	resizedData, err := s.resizeImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	debug.PreprocessedImage = resizedData
+	debug.PreprocessedSize = len(resizedData)
+
+	var invoice *domain.Invoice
+	if raw, ok := s.openAIClient.(RawInvoiceExtractor); ok {
+		invoice, debug.RawOutput, err = raw.ExtractInvoiceDataWithRaw(ctx, imageData)
+	} else {
+		invoice, err = s.openAIClient.ExtractInvoiceData(ctx, imageData)
+	}
+	if errors.Is(err, domain.ErrServiceNotConfigured) {
+		return nil, &ReceiptServiceError{
+			Op:  "extract_debug_scan",
+			Err: err,
+		}
+	}
+	if err != nil {
+		debug.ExtractionError = err.Error()
+		return debug, nil
+	}
+	debug.Invoice = invoice
+	return debug, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
+)
+
+// ReplaceReceiptImage stores a new image for a receipt owned by the user without re-extracting it.
+// The receipt's merchant, items and totals are left as they are, and the previous image is deleted.
+func (s *ReceiptServiceImpl) ReplaceReceiptImage(ctx context.Context, receiptID, userID string, imageData []byte) (*domain.Receipt, error) {
+	if s.s3Uploader == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "replace_receipt_image",
+			Err: fmt.Errorf("image storage: %w", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_for_image",
+			Err: err,
+		}
+	}
+
+	// Verify ownership
+	if receipt.UserID != userID {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_receipt_ownership",
+			Err: fmt.Errorf("receipt does not belong to user"),
+		}
+	}
+
+	// Store the image the same way scans do
+	resizedData, err := s.resizeImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	imageURL, err := s.s3Uploader.UploadImage(resizedData, s.imageFilename(resizedData))
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "upload_image_to_s3",
+			Err: err,
+		}
+	}
+
+	updatedAt, err := s.repository.UpdateReceiptImage(ctx, receiptID, imageURL)
+	if err != nil {
+		// Don't leave the new upload orphaned when the receipt still points at the old image
+		if deleteErr := s.s3Uploader.DeleteImage(imageURL); deleteErr != nil {
+			log.Printf("Warning: failed to delete unused image %s: %v", imageURL, deleteErr)
+		}
+		return nil, &ReceiptServiceError{
+			Op:  "update_receipt_image",
+			Err: err,
+		}
+	}
+
+	// The receipt is already updated, so a leftover old image is only logged
+	if receipt.ReceiptURL != "" {
+		if err := s.s3Uploader.DeleteImage(receipt.ReceiptURL); err != nil {
+			log.Printf("Warning: failed to delete previous image of receipt %s: %v", receiptID, err)
+		}
+	}
+
+	receipt.ReceiptURL = imageURL
+	receipt.UpdatedAt = updatedAt
+	return receipt, nil
+}
+
+// resizeImage resizes an image for storage and extraction as configured. Images that can't be
+// resized, such as formats without a decoder, are used as they are; corrupt image data fails
+// with domain.ErrImageUndecodable.
+func (s *ReceiptServiceImpl) resizeImage(imageData []byte) ([]byte, error) {
+	resizedData, err := imageutil.ResizeImage(imageData, s.imageStorage)
+	if errors.Is(err, imageutil.ErrCorruptImage) {
+		return nil, &ReceiptServiceError{
+			Op:  "decode_image",
+			Err: fmt.Errorf("%w: %v", domain.ErrImageUndecodable, err),
+		}
+	}
+	if err != nil {
+		log.Printf("Warning: failed to resize image, using original: %v", err)
+		return imageData, nil
+	}
+	return resizedData, nil
+}
+
+// imageFilename names an image upload uniquely, with the extension of the image's encoded format
+func (s *ReceiptServiceImpl) imageFilename(imageData []byte) string {
+	extension, _ := imageutil.FileType(imageData)
+	return fmt.Sprintf("invoice_%s%s", s.idGenerator.NewID(), extension)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// memoryImageStore keeps uploaded images keyed by public URL
+type memoryImageStore struct {
+	images map[string][]byte
+}
+
+func (s *memoryImageStore) UploadImage(imageData []byte, filename string) (string, error) {
+	url := "https://storage.example.com/" + filename
+	s.images[url] = imageData
+	return url, nil
+}
+
+func (s *memoryImageStore) DeleteImage(imageURL string) error {
+	if _, ok := s.images[imageURL]; !ok {
+		return fmt.Errorf("image not found: %s", imageURL)
+	}
+	delete(s.images, imageURL)
+	return nil
+}
+
+// imageReceiptRepository stores a single receipt and applies image updates to it
+type imageReceiptRepository struct {
+	repository.ReceiptRepository
+	receipt domain.Receipt
+}
+
+func (r *imageReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	if receiptID != r.receipt.ID {
+		return nil, fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	receipt := r.receipt
+	return &receipt, nil
+}
+
+func (r *imageReceiptRepository) UpdateReceiptImage(ctx context.Context, receiptID, receiptURL string) (time.Time, error) {
+	r.receipt.ReceiptURL = receiptURL
+	r.receipt.UpdatedAt = time.Now()
+	return r.receipt.UpdatedAt, nil
+}
+
+func TestReplaceReceiptImage(t *testing.T) {
+	oldURL := "https://storage.example.com/invoice_1.png"
+	images := &memoryImageStore{images: map[string][]byte{oldURL: []byte("old")}}
+	items := []domain.ReceiptItem{{ID: "item-1", Name: "Coffee", Quantity: 2, Price: 4.5, Category: "Food"}}
+	repo := &imageReceiptRepository{receipt: domain.Receipt{
+		ID:         "receipt-1",
+		UserID:     "user-1",
+		Merchant:   "Corrected Cafe",
+		Total:      9,
+		Items:      items,
+		ReceiptURL: oldURL,
+	}}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, S3Uploader: images})
+	ctx := context.Background()
+
+	t.Run("other users cannot replace the image", func(t *testing.T) {
+		_, err := svc.ReplaceReceiptImage(ctx, "receipt-1", "user-2", []byte("new"))
+		assert.ErrorContains(t, err, "does not belong")
+		assert.Equal(t, oldURL, repo.receipt.ReceiptURL)
+	})
+
+	t.Run("owner replaces the image and keeps the data", func(t *testing.T) {
+		receipt, err := svc.ReplaceReceiptImage(ctx, "receipt-1", "user-1", []byte("new"))
+		require.NoError(t, err)
+
+		assert.NotEqual(t, oldURL, receipt.ReceiptURL)
+		assert.Equal(t, receipt.ReceiptURL, repo.receipt.ReceiptURL)
+		assert.Equal(t, "Corrected Cafe", receipt.Merchant)
+		assert.Equal(t, items, receipt.Items)
+		assert.InDelta(t, 9, receipt.Total, 0.001)
+
+		// The old object is deleted and only the new one remains
+		assert.NotContains(t, images.images, oldURL)
+		assert.Contains(t, images.images, receipt.ReceiptURL)
+	})
+
+	t.Run("storage not configured", func(t *testing.T) {
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+		_, err := svc.ReplaceReceiptImage(ctx, "receipt-1", "user-1", []byte("new"))
+		assert.ErrorIs(t, err, domain.ErrServiceNotConfigured)
+	})
+}
+
+func TestScanStoresImageInItsOwnFormat(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1600, 1200))
+	for x := 0; x < 1600; x++ {
+		img.Set(x, x%1200, color.RGBA{R: 200, A: 255})
+	}
+	var photo bytes.Buffer
+	require.NoError(t, jpeg.Encode(&photo, img, nil))
+
+	images := &memoryImageStore{images: map[string][]byte{}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     &recordingReceiptRepository{},
+		OpenAIClient:   &stubExtractor{invoice: domain.NewInvoice()},
+		S3Uploader:     images,
+		MaxScanWorkers: 1,
+	})
+
+	receipt, err := svc.ScanReceipt(context.Background(), photo.Bytes(), "user-1")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasSuffix(receipt.ReceiptURL, ".jpg"), receipt.ReceiptURL)
+	stored := images.images[receipt.ReceiptURL]
+	_, contentType := imageutil.FileType(stored)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Less(t, len(stored), len(photo.Bytes()))
+}
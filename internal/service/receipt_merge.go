@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// MergeReceipts merges two receipts of the user that record the same purchase. The receipt created
+// first is kept, gaining the other's items it doesn't already have and any fields it is missing, and
+// the other receipt is deleted.
+func (s *ReceiptServiceImpl) MergeReceipts(ctx context.Context, receiptID, mergeWithID, userID string) (*domain.Receipt, error) {
+	if receiptID == mergeWithID {
+		return nil, &ReceiptServiceError{
+			Op:  "merge_receipts",
+			Err: fmt.Errorf("cannot merge a receipt with itself"),
+		}
+	}
+
+	var receipts [2]*domain.Receipt
+	for i, id := range []string{receiptID, mergeWithID} {
+		receipt, err := s.repository.GetReceiptByID(ctx, id)
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "get_receipt_for_merge",
+				Err: err,
+			}
+		}
+
+		// Verify ownership
+		if receipt.UserID != userID {
+			return nil, &ReceiptServiceError{
+				Op:  "verify_receipt_ownership",
+				Err: fmt.Errorf("receipt does not belong to user"),
+			}
+		}
+		receipts[i] = receipt
+	}
+
+	kept, removed := receipts[0], receipts[1]
+	if removed.CreatedAt.Before(kept.CreatedAt) {
+		kept, removed = removed, kept
+	}
+
+	merged, moved := domain.MergeReceipts(*kept, *removed)
+	s.recalculateTotals(&merged)
+
+	movedIDs := make([]string, len(moved))
+	for i, item := range moved {
+		movedIDs[i] = item.ID
+	}
+
+	result, err := s.repository.MergeReceipts(ctx, &merged, removed.ID, movedIDs)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "merge_receipts",
+			Err: err,
+		}
+	}
+	s.recordActivity(ctx, userID, domain.ActivityReceiptUpdated, result.ID)
+	s.recordActivity(ctx, userID, domain.ActivityReceiptDeleted, removed.ID)
+
+	return result, nil
+}
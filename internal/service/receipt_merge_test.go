@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// mergeReceiptRepository keeps receipts in memory and applies merges to them
+type mergeReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts map[string]domain.Receipt
+}
+
+func (r *mergeReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	receipt, ok := r.receipts[receiptID]
+	if !ok {
+		return nil, fmt.Errorf("receipt not found: %s", receiptID)
+	}
+	return &receipt, nil
+}
+
+func (r *mergeReceiptRepository) MergeReceipts(ctx context.Context, merged *domain.Receipt, removedID string, movedItemIDs []string) (*domain.Receipt, error) {
+	if _, ok := r.receipts[removedID]; !ok {
+		return nil, fmt.Errorf("receipt not found: %s", removedID)
+	}
+	delete(r.receipts, removedID)
+	r.receipts[merged.ID] = *merged
+	return merged, nil
+}
+
+func TestMergeReceipts(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	newRepo := func() *mergeReceiptRepository {
+		return &mergeReceiptRepository{receipts: map[string]domain.Receipt{
+			"receipt-1": {
+				ID:        "receipt-1",
+				UserID:    "user-1",
+				Merchant:  "Corner Market",
+				CreatedAt: created.Add(time.Hour),
+				Items: []domain.ReceiptItem{
+					{ID: "item-1", Name: "Bread", Quantity: 1, Price: 3},
+					{ID: "item-2", Name: "Milk", Quantity: 2, Price: 1.5},
+				},
+			},
+			"receipt-2": {
+				ID:            "receipt-2",
+				UserID:        "user-1",
+				CreatedAt:     created,
+				Tax:           0.5,
+				PaymentMethod: domain.PaymentMethodCard,
+				Items: []domain.ReceiptItem{
+					{ID: "item-3", Name: "milk ", Quantity: 2, Price: 1.5},
+					{ID: "item-4", Name: "Eggs", Quantity: 1, Price: 4},
+				},
+			},
+			"receipt-3": {ID: "receipt-3", UserID: "user-2", CreatedAt: created},
+		}}
+	}
+	ctx := context.Background()
+
+	t.Run("items from both receipts appear once and the later receipt is removed", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+		merged, err := svc.MergeReceipts(ctx, "receipt-1", "receipt-2", "user-1")
+		require.NoError(t, err)
+
+		// The receipt created first is kept, filling in the merchant it was missing
+		assert.Equal(t, "receipt-2", merged.ID)
+		assert.Equal(t, "Corner Market", merged.Merchant)
+		assert.Equal(t, domain.PaymentMethodCard, merged.PaymentMethod)
+
+		var names []string
+		for _, item := range merged.Items {
+			names = append(names, item.Name)
+		}
+		assert.Equal(t, []string{"milk ", "Eggs", "Bread"}, names)
+		assert.Equal(t, 10.0, merged.Subtotal)
+		assert.Equal(t, 10.5, merged.Total)
+
+		_, err = svc.GetReceiptByID(ctx, "receipt-1")
+		assert.ErrorContains(t, err, "not found")
+		assert.Len(t, repo.receipts, 2)
+	})
+
+	t.Run("both receipts must belong to the user", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+		_, err := svc.MergeReceipts(ctx, "receipt-1", "receipt-3", "user-1")
+		assert.ErrorContains(t, err, "does not belong")
+		assert.Len(t, repo.receipts, 3)
+	})
+
+	t.Run("a receipt cannot be merged with itself", func(t *testing.T) {
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: newRepo()})
+
+		_, err := svc.MergeReceipts(ctx, "receipt-1", "receipt-1", "user-1")
+		assert.Error(t, err)
+	})
+}
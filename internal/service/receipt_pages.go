@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// defaultMaxScanPages is the number of page images a scan may upload when no limit is configured
+const defaultMaxScanPages = 10
+
+// ScanReceiptPages processes the selected pages of a multi-page document as a single receipt.
+// pages is a comma-separated list of 1-based page numbers or ranges such as "1,3" or "2-4";
+// an empty selection processes every page. Without storeImage no page image is kept.
+// Uploads of more pages than the configured maximum are rejected, as each page is a model call
+// while the scan counts once against the quota.
+func (s *ReceiptServiceImpl) ScanReceiptPages(ctx context.Context, pageImages [][]byte, pages string, userID string, storeImage bool) (*domain.Receipt, error) {
+	if len(pageImages) > s.maxScanPages {
+		return nil, ValidationErrors{{Field: "receiptImage", Message: fmt.Sprintf("At most %d pages can be scanned at once, got %d", s.maxScanPages, len(pageImages))}}
+	}
+
+	selected, err := parsePageSelection(pages, len(pageImages))
+	if err != nil {
+		return nil, err
+	}
+
+	selectedImages := make([][]byte, len(selected))
+	for i, page := range selected {
+		selectedImages[i] = pageImages[page-1]
+	}
+
+	return s.scanReceipt(ctx, selectedImages, userID, "", storeImage)
+}
+
+// parsePageSelection returns the selected 1-based page numbers in document order, without duplicates.
+// It returns ValidationErrors when the selection is malformed or names a page outside the document.
+func parsePageSelection(pages string, pageCount int) ([]int, error) {
+	if pageCount == 0 {
+		return nil, ValidationErrors{{Field: "receiptImage", Message: "At least one page is required"}}
+	}
+
+	pages = strings.TrimSpace(pages)
+	if pages == "" {
+		all := make([]int, pageCount)
+		for i := range all {
+			all[i] = i + 1
+		}
+		return all, nil
+	}
+
+	invalid := func(message string) error {
+		return ValidationErrors{{Field: "pages", Message: message}}
+	}
+
+	included := make([]bool, pageCount+1)
+	for _, part := range strings.Split(pages, ",") {
+		part = strings.TrimSpace(part)
+		first, last := part, part
+		if start, end, isRange := strings.Cut(part, "-"); isRange {
+			first, last = strings.TrimSpace(start), strings.TrimSpace(end)
+		}
+
+		from, fromErr := strconv.Atoi(first)
+		to, toErr := strconv.Atoi(last)
+		if fromErr != nil || toErr != nil || from > to {
+			return nil, invalid(fmt.Sprintf("Invalid page selection '%s', use page numbers like \"1,3\" or \"2-4\"", part))
+		}
+		if from < 1 || to > pageCount {
+			return nil, invalid(fmt.Sprintf("Page selection '%s' is outside the document's %d pages", part, pageCount))
+		}
+		for page := from; page <= to; page++ {
+			included[page] = true
+		}
+	}
+
+	var selected []int
+	for page := 1; page <= pageCount; page++ {
+		if included[page] {
+			selected = append(selected, page)
+		}
+	}
+	return selected, nil
+}
+
+// mergePageInvoices combines the invoices extracted from each page into one.
+// Line items are concatenated in page order. Header fields come from the first page that has them,
+// while totals come from the last page that reports them, as they are printed at the end.
+// The merged confidence is the lowest reported by any page.
+func mergePageInvoices(pageInvoices []*domain.Invoice) *domain.Invoice {
+	if len(pageInvoices) == 1 {
+		return pageInvoices[0]
+	}
+
+	merged := domain.NewInvoice()
+	for _, page := range pageInvoices {
+		merged.Items = append(merged.Items, page.Items...)
+
+		if merged.VendorName == "" {
+			merged.VendorName = page.VendorName
+		}
+		if merged.InvoiceNumber == "" {
+			merged.InvoiceNumber = page.InvoiceNumber
+		}
+		if merged.InvoiceDate.Time.IsZero() {
+			merged.InvoiceDate = page.InvoiceDate
+		}
+		if merged.InvoiceTime == "" {
+			merged.InvoiceTime = page.InvoiceTime
+		}
+		if merged.DueDate.Time.IsZero() {
+			merged.DueDate = page.DueDate
+		}
+		if merged.PaymentMethod == "" {
+			merged.PaymentMethod = page.PaymentMethod
+		}
+		if merged.TaxID == "" {
+			merged.TaxID = page.TaxID
+		}
+
+		if page.Subtotal != 0 {
+			merged.Subtotal = page.Subtotal
+		}
+		if page.TaxRatePercent != 0 {
+			merged.TaxRatePercent = page.TaxRatePercent
+		}
+		if page.TaxAmount != 0 {
+			merged.TaxAmount = page.TaxAmount
+		}
+		if page.Tip != 0 {
+			merged.Tip = page.Tip
+		}
+		if page.ServiceCharge != 0 {
+			merged.ServiceCharge = page.ServiceCharge
+		}
+		if page.Discount != 0 {
+			merged.Discount = page.Discount
+		}
+		if page.TotalDue != 0 {
+			merged.TotalDue = page.TotalDue
+		}
+
+		if page.Confidence != nil && (merged.Confidence == nil || *page.Confidence < *merged.Confidence) {
+			merged.Confidence = page.Confidence
+		}
+		for field, values := range page.Alternatives {
+			if merged.Alternatives == nil {
+				merged.Alternatives = map[string][]string{}
+			}
+			merged.Alternatives[field] = append(merged.Alternatives[field], values...)
+		}
+	}
+	return merged
+}
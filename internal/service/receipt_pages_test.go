@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// pageExtractor returns the invoice for each page keyed by its image bytes
+type pageExtractor struct {
+	pages     map[string]*domain.Invoice
+	extracted []string
+}
+
+func (e *pageExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.extracted = append(e.extracted, string(imageData))
+	return e.pages[string(imageData)], nil
+}
+
+func TestScanReceiptPages(t *testing.T) {
+	document := [][]byte{[]byte("page-1"), []byte("page-2"), []byte("page-3")}
+	newService := func() (ReceiptService, *pageExtractor, *recordingReceiptRepository) {
+		extractor := &pageExtractor{pages: map[string]*domain.Invoice{
+			"page-1": {
+				VendorName: "Corner Cafe",
+				Items:      []domain.LineItem{{Description: "Sandwich", Quantity: 1, UnitPrice: 8}},
+			},
+			// An unrelated page, such as a loyalty flyer scanned with the receipt
+			"page-2": {
+				VendorName: "Loyalty Club",
+				Items:      []domain.LineItem{{Description: "Membership", Quantity: 1, UnitPrice: 30}},
+				TotalDue:   30,
+			},
+			"page-3": {
+				Items:    []domain.LineItem{{Description: "Coffee", Quantity: 2, UnitPrice: 2}},
+				TotalDue: 12,
+			},
+		}}
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, OpenAIClient: extractor, MaxScanWorkers: 1})
+		return svc, extractor, repo
+	}
+
+	t.Run("only selected pages contribute line items", func(t *testing.T) {
+		svc, extractor, _ := newService()
+
+		receipt, err := svc.ScanReceiptPages(context.Background(), document, "1,3", "user-1", true)
+		require.NoError(t, err)
+
+		require.Len(t, receipt.Items, 2)
+		assert.Equal(t, "Sandwich", receipt.Items[0].Name)
+		assert.Equal(t, "Coffee", receipt.Items[1].Name)
+		assert.Equal(t, "Corner Cafe", receipt.Merchant)
+		assert.InDelta(t, 12, receipt.Total, 0.001)
+		assert.Equal(t, []string{"page-1", "page-3"}, extractor.extracted)
+	})
+
+	t.Run("all pages by default", func(t *testing.T) {
+		svc, _, _ := newService()
+
+		receipt, err := svc.ScanReceiptPages(context.Background(), document, "", "user-1", true)
+		require.NoError(t, err)
+		assert.Len(t, receipt.Items, 3)
+	})
+
+	t.Run("selection is validated against the page count", func(t *testing.T) {
+		for _, pages := range []string{"4", "0", "1,x", "3-1", "2-5"} {
+			svc, extractor, repo := newService()
+
+			_, err := svc.ScanReceiptPages(context.Background(), document, pages, "user-1", true)
+			var validationErrs ValidationErrors
+			require.ErrorAs(t, err, &validationErrs, pages)
+			assert.Equal(t, "pages", validationErrs[0].Field)
+			assert.Empty(t, extractor.extracted)
+			assert.Empty(t, repo.created)
+		}
+	})
+}
+
+func TestParsePageSelection(t *testing.T) {
+	selected, err := parsePageSelection(" 3, 1-2 ,2", 4)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, selected)
+}
+
+func TestScanReceiptPagesWithMLXKeepsOnlyFirstImage(t *testing.T) {
+	images := &memoryImageStore{images: map[string][]byte{}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     &recordingReceiptRepository{},
+		MLXClient:      &stubURLExtractor{invoice: &domain.Invoice{VendorName: "Corner Cafe", TotalDue: 8}},
+		S3Uploader:     images,
+		UseMLXService:  true,
+		MaxScanWorkers: 1,
+	})
+
+	receipt, err := svc.ScanReceiptPages(context.Background(), [][]byte{[]byte("page-1"), []byte("page-2")}, "", "user-1", true)
+	require.NoError(t, err)
+
+	require.Len(t, images.images, 1)
+	assert.Contains(t, images.images, receipt.ReceiptURL)
+	assert.Equal(t, []byte("page-1"), images.images[receipt.ReceiptURL])
+}
+
+func TestScanReceiptPagesLimit(t *testing.T) {
+	extractor := &pageExtractor{pages: map[string]*domain.Invoice{}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     &recordingReceiptRepository{},
+		OpenAIClient:   extractor,
+		MaxScanWorkers: 1,
+		MaxScanPages:   2,
+	})
+
+	_, err := svc.ScanReceiptPages(context.Background(), [][]byte{[]byte("page-1"), []byte("page-2"), []byte("page-3")}, "1", "user-1", true)
+	var validationErrs ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "receiptImage", validationErrs[0].Field)
+	assert.Empty(t, extractor.extracted)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ErrReprocessRunning is returned when a reprocess is started while another is still running
+var ErrReprocessRunning = errors.New("a reprocess job is already running")
+
+// reprocessPageSize is the number of matching receipts listed per query when a reprocess starts
+const reprocessPageSize = 100
+
+// StartReprocess selects the receipts with a stored image matching the filter and re-runs extraction
+// over them in the background, one scan worker at a time. A receipt is only updated when the new
+// extraction is more confident than the stored one. Jobs are kept in memory, so progress is only
+// visible on the server instance that started the job and is lost on restart.
+func (s *ReceiptServiceImpl) StartReprocess(ctx context.Context, filter domain.ReprocessFilter) (*domain.ReprocessJob, error) {
+	if !s.useMLXService && s.openAIClient == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "check_reprocess_extractor",
+			Err: fmt.Errorf("%w: no extraction service is configured", domain.ErrServiceNotConfigured),
+		}
+	}
+
+	s.reprocessMu.Lock()
+	running := s.reprocessRunning()
+	s.reprocessMu.Unlock()
+	if running {
+		return nil, ErrReprocessRunning
+	}
+
+	// List outside the lock so progress reads and the running job's updates aren't held up
+	receiptIDs, err := s.listReprocessCandidates(ctx, filter)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_reprocess_receipts",
+			Err: err,
+		}
+	}
+
+	s.reprocessMu.Lock()
+	defer s.reprocessMu.Unlock()
+	// Another job may have started while listing
+	if s.reprocessRunning() {
+		return nil, ErrReprocessRunning
+	}
+
+	job := &domain.ReprocessJob{
+		ID:        s.idGenerator.NewID(),
+		Status:    domain.ReprocessStatusRunning,
+		Matched:   len(receiptIDs),
+		StartedAt: s.clock.Now(),
+	}
+	s.reprocessJobs[job.ID] = job
+	snapshot := *job
+
+	// The job outlives the request that started it
+	go s.runReprocess(context.Background(), job, filter, receiptIDs)
+
+	return &snapshot, nil
+}
+
+// reprocessRunning reports whether a reprocess job is running on this server instance.
+// The caller must hold reprocessMu.
+func (s *ReceiptServiceImpl) reprocessRunning() bool {
+	for _, job := range s.reprocessJobs {
+		if job.Status == domain.ReprocessStatusRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReprocessJob reports the progress of a reprocess job started on this server instance
+func (s *ReceiptServiceImpl) GetReprocessJob(ctx context.Context, jobID string) (*domain.ReprocessJob, error) {
+	s.reprocessMu.Lock()
+	defer s.reprocessMu.Unlock()
+
+	job, ok := s.reprocessJobs[jobID]
+	if !ok {
+		return nil, &ReceiptServiceError{
+			Op:  "get_reprocess_job",
+			Err: fmt.Errorf("reprocess job not found: %s", jobID),
+		}
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// listReprocessCandidates lists the IDs of every receipt with a stored image matching the filter, oldest first
+func (s *ReceiptServiceImpl) listReprocessCandidates(ctx context.Context, filter domain.ReprocessFilter) ([]string, error) {
+	hasImage := true
+	listFilter := domain.ReceiptFilter{
+		AllUsers:        true,
+		StartDate:       filter.StartDate,
+		EndDate:         filter.EndDate,
+		HasImage:        &hasImage,
+		ConfidenceBelow: &filter.ConfidenceBelow,
+		SortBy:          "createdAt",
+		SortOrder:       "asc",
+		Limit:           reprocessPageSize,
+	}
+
+	var receiptIDs []string
+	for page := 1; ; page++ {
+		listFilter.Page = page
+		result, err := s.repository.ListReceipts(ctx, listFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, receipt := range result.Data {
+			receiptIDs = append(receiptIDs, receipt.ID)
+		}
+		if len(result.Data) < reprocessPageSize {
+			return receiptIDs, nil
+		}
+	}
+}
+
+// runReprocess re-extracts each receipt of the job and records the outcome in the job
+func (s *ReceiptServiceImpl) runReprocess(ctx context.Context, job *domain.ReprocessJob, filter domain.ReprocessFilter, receiptIDs []string) {
+	for _, receiptID := range receiptIDs {
+		outcome := s.reprocessReceipt(ctx, receiptID, filter.ConfidenceBelow)
+
+		s.reprocessMu.Lock()
+		job.Processed++
+		switch outcome {
+		case reprocessImproved:
+			job.Improved++
+		case reprocessUnchanged:
+			job.Unchanged++
+		case reprocessSkipped:
+			job.Skipped++
+		default:
+			job.Failed++
+		}
+		s.reprocessMu.Unlock()
+	}
+
+	finishedAt := s.clock.Now()
+	s.reprocessMu.Lock()
+	job.Status = domain.ReprocessStatusCompleted
+	job.FinishedAt = &finishedAt
+	s.reprocessMu.Unlock()
+	log.Printf("Reprocess job %s completed: %d improved, %d unchanged, %d skipped, %d failed of %d receipts",
+		job.ID, job.Improved, job.Unchanged, job.Skipped, job.Failed, job.Matched)
+}
+
+// Outcomes of reprocessing a single receipt
+const (
+	reprocessImproved = iota
+	reprocessUnchanged
+	reprocessSkipped
+	reprocessFailed
+)
+
+// reprocessReceipt extracts a receipt's stored image again and saves the result when it is more
+// confident than the stored extraction. The receipt is read again first, so receipts edited above
+// the confidence threshold or deleted since the job started are skipped.
+func (s *ReceiptServiceImpl) reprocessReceipt(ctx context.Context, receiptID string, confidenceBelow float64) int {
+	receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return reprocessSkipped
+		}
+		log.Printf("Warning: failed to load receipt %s for reprocessing: %v", receiptID, err)
+		return reprocessFailed
+	}
+	imageURL := receipt.ReceiptURL
+	if imageURL == "" {
+		imageURL = receipt.ImageURL
+	}
+	if imageURL == "" || (receipt.Confidence != nil && *receipt.Confidence >= confidenceBelow) {
+		return reprocessSkipped
+	}
+
+	// Share the scan workers so reprocessing doesn't starve user scans of extraction capacity
+	select {
+	case s.workerPool <- struct{}{}:
+		defer func() { <-s.workerPool }()
+	case <-ctx.Done():
+		return reprocessFailed
+	}
+
+	invoiceData, extractionMethod, err := s.extractStoredImage(ctx, imageURL)
+	if err != nil {
+		log.Printf("Warning: failed to reprocess receipt %s: %v", receiptID, err)
+		return reprocessFailed
+	}
+	if invoiceData.Confidence == nil || (receipt.Confidence != nil && *invoiceData.Confidence <= *receipt.Confidence) {
+		return reprocessUnchanged
+	}
+
+	// Keep edits the user made while the image was being extracted
+	unmodifiedSince := receipt.UpdatedAt
+	receipt.UnmodifiedSince = &unmodifiedSince
+	s.applyRescan(ctx, receipt, invoiceData, extractionMethod)
+	if _, err := s.repository.UpdateReceipt(ctx, receipt); err != nil {
+		if errors.Is(err, domain.ErrReceiptModified) {
+			return reprocessSkipped
+		}
+		log.Printf("Warning: failed to save reprocessed receipt %s: %v", receiptID, err)
+		return reprocessFailed
+	}
+	s.recordActivity(ctx, receipt.UserID, domain.ActivityReceiptScanned, receiptID)
+	return reprocessImproved
+}
+
+// extractStoredImage extracts invoice data from an image already in storage, handing MLX the URL and
+// downloading the image for OpenRouter
+func (s *ReceiptServiceImpl) extractStoredImage(ctx context.Context, imageURL string) (*domain.Invoice, string, error) {
+	if s.useMLXService && s.mlxClient != nil {
+		invoiceData, err := s.mlxClient.ExtractInvoiceData(ctx, imageURL)
+		return invoiceData, domain.ExtractionMethodMLX, err
+	}
+
+	imageData, err := s.imageFetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download stored image: %w", err)
+	}
+	invoiceData, err := s.openAIClient.ExtractInvoiceData(ctx, imageData)
+	return invoiceData, domain.ExtractionMethodOpenRouter, err
+}
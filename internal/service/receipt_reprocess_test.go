@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// reprocessReceiptRepository lists every stored receipt regardless of the filter, leaving the
+// confidence check to the service, and saves updates in memory
+type reprocessReceiptRepository struct {
+	repository.ReceiptRepository
+	mu       sync.Mutex
+	receipts []domain.Receipt
+}
+
+func (r *reprocessReceiptRepository) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &domain.PaginatedReceipts{Data: append([]domain.Receipt(nil), r.receipts...)}, nil
+}
+
+func (r *reprocessReceiptRepository) GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, receipt := range r.receipts {
+		if receipt.ID == receiptID {
+			return &receipt, nil
+		}
+	}
+	return nil, fmt.Errorf("receipt not found: %s", receiptID)
+}
+
+func (r *reprocessReceiptRepository) UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.receipts {
+		if r.receipts[i].ID != receipt.ID {
+			continue
+		}
+		if receipt.UnmodifiedSince != nil && r.receipts[i].UpdatedAt.After(*receipt.UnmodifiedSince) {
+			return nil, domain.ErrReceiptModified
+		}
+		r.receipts[i] = *receipt
+	}
+	return receipt, nil
+}
+
+// edit changes a stored receipt's merchant as a user edit would, moving its updated time on
+func (r *reprocessReceiptRepository) edit(receiptID, merchant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.receipts {
+		if r.receipts[i].ID == receiptID {
+			r.receipts[i].Merchant = merchant
+			r.receipts[i].UpdatedAt = r.receipts[i].UpdatedAt.Add(time.Minute)
+		}
+	}
+}
+
+func (r *reprocessReceiptRepository) receipt(receiptID string) domain.Receipt {
+	receipt, _ := r.GetReceiptByID(context.Background(), receiptID)
+	return *receipt
+}
+
+// recordingURLExtractor returns a fixed invoice and records the image URLs it was given
+type recordingURLExtractor struct {
+	mu        sync.Mutex
+	invoice   domain.Invoice
+	urls      []string
+	onExtract func(imageURL string) // Optional, called during each extraction
+}
+
+func (e *recordingURLExtractor) ExtractInvoiceData(ctx context.Context, imageURL string) (*domain.Invoice, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.urls = append(e.urls, imageURL)
+	if e.onExtract != nil {
+		e.onExtract(imageURL)
+	}
+	invoice := e.invoice
+	return &invoice, nil
+}
+
+func TestReprocessReExtractsLowConfidenceReceipts(t *testing.T) {
+	low, high, better := 0.4, 0.95, 0.9
+	repo := &reprocessReceiptRepository{receipts: []domain.Receipt{
+		{ID: "receipt-low", UserID: "user-1", Merchant: "C0rner", ReceiptURL: "https://images.example.com/low.jpg", Confidence: &low},
+		{ID: "receipt-high", UserID: "user-1", Merchant: "Grocer", ReceiptURL: "https://images.example.com/high.jpg", Confidence: &high},
+	}}
+	extractor := &recordingURLExtractor{invoice: domain.Invoice{
+		VendorName:  "Corner Market",
+		InvoiceDate: domain.DateOnly{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		TotalDue:    4.5,
+		Confidence:  &better,
+		Items:       []domain.LineItem{{Description: "Bread", Quantity: 1, UnitPrice: 4.5, Total: 4.5, Currency: "USD"}},
+	}}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		MLXClient:      extractor,
+		UseMLXService:  true,
+		MaxScanWorkers: 1,
+	})
+	ctx := context.Background()
+
+	job, err := svc.StartReprocess(ctx, domain.ReprocessFilter{ConfidenceBelow: 0.6})
+	require.NoError(t, err)
+	assert.Equal(t, 2, job.Matched)
+
+	require.Eventually(t, func() bool {
+		job, err = svc.GetReprocessJob(ctx, job.ID)
+		return err == nil && job.Status == domain.ReprocessStatusCompleted
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 1, job.Improved)
+	assert.Equal(t, 1, job.Skipped)
+	assert.Equal(t, []string{"https://images.example.com/low.jpg"}, extractor.urls)
+
+	reprocessed := repo.receipt("receipt-low")
+	assert.Equal(t, "Corner Market", reprocessed.Merchant)
+	assert.Equal(t, &better, reprocessed.Confidence)
+	require.Len(t, reprocessed.Items, 1)
+	assert.Equal(t, "Bread", reprocessed.Items[0].Name)
+	assert.Equal(t, "Grocer", repo.receipt("receipt-high").Merchant)
+
+	t.Run("a less confident extraction leaves the receipt unchanged", func(t *testing.T) {
+		worse := 0.3
+		extractor.invoice.Confidence = &worse
+		job, err := svc.StartReprocess(ctx, domain.ReprocessFilter{ConfidenceBelow: 0.95})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			job, err = svc.GetReprocessJob(ctx, job.ID)
+			return err == nil && job.Status == domain.ReprocessStatusCompleted
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, 1, job.Unchanged)
+		assert.Equal(t, &better, repo.receipt("receipt-low").Confidence)
+	})
+}
+
+func TestReprocessKeepsReceiptsEditedDuringExtraction(t *testing.T) {
+	low, better := 0.4, 0.9
+	updatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &reprocessReceiptRepository{receipts: []domain.Receipt{
+		{ID: "receipt-1", UserID: "user-1", Merchant: "C0rner", ReceiptURL: "https://images.example.com/1.jpg", Confidence: &low, UpdatedAt: updatedAt},
+	}}
+	extractor := &recordingURLExtractor{
+		invoice: domain.Invoice{VendorName: "Corner Market", TotalDue: 4.5, Confidence: &better},
+		// The user corrects the merchant while the image is being extracted
+		onExtract: func(string) { repo.edit("receipt-1", "Corner Shop") },
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		MLXClient:      extractor,
+		UseMLXService:  true,
+		MaxScanWorkers: 1,
+	})
+	ctx := context.Background()
+
+	job, err := svc.StartReprocess(ctx, domain.ReprocessFilter{ConfidenceBelow: 0.6})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err = svc.GetReprocessJob(ctx, job.ID)
+		return err == nil && job.Status == domain.ReprocessStatusCompleted
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 1, job.Skipped)
+	assert.Zero(t, job.Failed)
+	assert.Equal(t, "Corner Shop", repo.receipt("receipt-1").Merchant)
+}
@@ -2,17 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ridwanfathin/invoice-processor-service/internal/currency"
 	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
 	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
-	"github.com/ridwanfathin/invoice-processor-service/internal/mlxclient"
-	"github.com/ridwanfathin/invoice-processor-service/internal/openrouter"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
+	"github.com/ridwanfathin/invoice-processor-service/internal/receiptpdf"
 	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
-	"github.com/ridwanfathin/invoice-processor-service/internal/storage"
 )
 
 // ReceiptServiceError represents an error in the receipt service
@@ -28,52 +30,288 @@ func (e *ReceiptServiceError) Error() string {
 	return e.Op
 }
 
+func (e *ReceiptServiceError) Unwrap() error {
+	return e.Err
+}
+
+// InvoiceExtractor extracts structured invoice data from image bytes
+type InvoiceExtractor interface {
+	ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error)
+}
+
+// InlineInvoiceExtractor extracts structured invoice data sending the image inline with the
+// request, without storing it anywhere
+type InlineInvoiceExtractor interface {
+	ExtractInvoiceDataInline(ctx context.Context, imageData []byte) (*domain.Invoice, error)
+}
+
+// URLInvoiceExtractor extracts structured invoice data from a stored image URL
+type URLInvoiceExtractor interface {
+	ExtractInvoiceData(ctx context.Context, imageURL string) (*domain.Invoice, error)
+}
+
+// ImageStore stores receipt images and serves them by public URL
+type ImageStore interface {
+	UploadImage(imageData []byte, filename string) (string, error)
+	DeleteImage(imageURL string) error
+}
+
+// CurrencyCatalog lists the currency codes the exchange rate provider supports
+type CurrencyCatalog interface {
+	GetSupportedCurrencies(ctx context.Context) ([]string, error)
+}
+
+// CurrencyConverter converts amounts using the exchange rates published on a date
+type CurrencyConverter interface {
+	ConvertOn(ctx context.Context, amount float64, fromCurrency, toCurrency string, date time.Time) (*currency.Conversion, error)
+}
+
 // ReceiptService defines the interface for receipt-related business logic
 type ReceiptService interface {
 	// CRUD operations
 	ScanReceipt(ctx context.Context, imageData []byte, userID string) (*domain.Receipt, error)
+	ScanReceiptPages(ctx context.Context, pageImages [][]byte, pages string, userID string, storeImage bool) (*domain.Receipt, error)
+	ScanReceiptFromURL(ctx context.Context, imageURL string, userID string, storeImage bool) (*domain.Receipt, error)
 	RetryScanReceipt(ctx context.Context, receiptID string, userID string) (*domain.Receipt, error)
+	DebugScanReceipt(ctx context.Context, imageData []byte) (*domain.ScanDebug, error)
 	CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error)
 	GetReceiptByID(ctx context.Context, receiptID string) (*domain.Receipt, error)
+	ConvertReceiptItems(ctx context.Context, receipt *domain.Receipt, toCurrency string) error
+	ExportReceiptPDF(ctx context.Context, receiptID string, userID string) ([]byte, error)
 	UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error)
 	DeleteReceipt(ctx context.Context, receiptID string) error
+	DeleteReceipts(ctx context.Context, userID string, receiptIDs []string, atomic bool) (*domain.BulkResult, error)
+	SetReceiptOrganization(ctx context.Context, receiptID, userID, orgID string) (*domain.Receipt, error)
+	MergeReceipts(ctx context.Context, receiptID, mergeWithID, userID string) (*domain.Receipt, error)
+	ReplaceReceiptImage(ctx context.Context, receiptID, userID string, imageData []byte) (*domain.Receipt, error)
 
 	// Query operations
 	ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error)
+	CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error)
 	GetReceiptItems(ctx context.Context, receiptID string) ([]domain.ReceiptItem, error)
+	GetReceiptStats(ctx context.Context, receiptID string, userID string) (*domain.ReceiptStats, error)
+	ListReceiptCurrencies(ctx context.Context, userID string) ([]domain.CurrencyUsage, error)
+
+	// Saved view operations
+	SaveReceiptView(ctx context.Context, userID, name string, filters map[string]string) (*domain.ReceiptView, error)
+	ListReceiptViews(ctx context.Context, userID string) ([]domain.ReceiptView, error)
+	GetReceiptView(ctx context.Context, userID, name string) (*domain.ReceiptView, error)
+	DeleteReceiptView(ctx context.Context, userID, viewID string) error
+
+	// UserNumberFormat returns the user's preferred number format for display amounts, or nil when they chose none
+	UserNumberFormat(ctx context.Context, userID string) *domain.NumberFormat
+
+	// ListActivity retrieves a page of the user's scanned, created, updated and deleted receipts, newest first
+	ListActivity(ctx context.Context, userID string, page, limit int) (*domain.ActivityFeed, error)
+
+	// GetScanUsage reports the user's scans this month against the monthly scan quota
+	GetScanUsage(ctx context.Context, userID string) (*domain.ScanUsage, error)
 
-	// Dashboard and insights operations
-	GetDashboardSummary(ctx context.Context, userID string, startDate, endDate *string) (*domain.DashboardSummary, error)
-	GetSpendingTrends(ctx context.Context, userID string, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
-	GetSpendingByCategory(ctx context.Context, userID string, startDate, endDate *string) (*domain.CategorySpending, error)
-	GetMerchantFrequency(ctx context.Context, userID string, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error)
-	GetMonthlyComparison(ctx context.Context, userID string, month1, month2 string) (*domain.MonthlyComparison, error)
+	// Dashboard and insights operations, covering the receipts in scope
+	GetDashboardSummary(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.DashboardSummary, error)
+	GetSpendingTrends(ctx context.Context, scope domain.ReceiptScope, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
+	GetSpendingTrendsWindow(ctx context.Context, scope domain.ReceiptScope, period string, window domain.TrendWindow) (*domain.SpendingTrends, error)
+	GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.CategorySpending, error)
+	GetCategoryTrend(ctx context.Context, scope domain.ReceiptScope, category, period string, startDate, endDate *string) (*domain.SpendingTrends, error)
+	GetSpendingByHour(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.HourlySpending, error)
+	GetMerchantFrequency(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error)
+	GetMonthlyComparison(ctx context.Context, scope domain.ReceiptScope, month1, month2 string) (*domain.MonthlyComparison, error)
+	GetSpendingByPaymentMethod(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.PaymentMethodSpending, error)
+	GetSpendingBreakdown(ctx context.Context, scope domain.ReceiptScope, by string, startDate, endDate *string) (*domain.SpendingBreakdown, error)
+	GetSpendingAnomaly(ctx context.Context, scope domain.ReceiptScope, month string) (*domain.SpendingAnomaly, error)
+
+	// Admin operations
+	GetExtractionAccuracy(ctx context.Context) ([]domain.FieldAccuracy, error)
+	StartReprocess(ctx context.Context, filter domain.ReprocessFilter) (*domain.ReprocessJob, error)
+	GetReprocessJob(ctx context.Context, jobID string) (*domain.ReprocessJob, error)
 }
 
 // ReceiptServiceImpl implements the ReceiptService interface
 type ReceiptServiceImpl struct {
-	repository    repository.ReceiptRepository
-	openAIClient  *openrouter.Client
-	mlxClient     *mlxclient.Client
-	s3Uploader    *storage.S3Uploader
-	useMLXService bool
-	workerPool    chan struct{}
+	repository             repository.ReceiptRepository
+	merchantRuleRepo       repository.MerchantRuleRepository
+	organizationRepo       repository.OrganizationRepository
+	viewRepo               repository.ReceiptViewRepository
+	activityRepo           repository.ActivityRepository
+	userRepo               repository.UserRepository
+	feedbackRepo           repository.ExtractionFeedbackRepository
+	openAIClient           InvoiceExtractor
+	mlxClient              URLInvoiceExtractor
+	s3Uploader             ImageStore
+	currencyCatalog        CurrencyCatalog
+	currencyConverter      CurrencyConverter
+	imageFetcher           *imageutil.Fetcher
+	imageStorage           *imageutil.ResizeConfig
+	useMLXService          bool
+	mlxFallback            bool
+	inlineImages           bool
+	keepFailedScanImages   bool
+	scanDeadline           time.Duration
+	workerPool             chan struct{}
+	maxScanPages           int
+	anomalyZScoreThreshold float64
+	minConfidenceAutosave  float64
+	moneyPolicy            money.Policy
+	currencyRounding       bool
+	monthlyScanQuota       int
+	strictCategories       bool
+	itemNameRules          domain.ItemNameRules
+	clock                  Clock
+	idGenerator            IDGenerator
+	reprocessMu            sync.Mutex
+	reprocessJobs          map[string]*domain.ReprocessJob
+}
+
+// ReceiptServiceConfig holds configuration for the receipt service
+type ReceiptServiceConfig struct {
+	Repository             repository.ReceiptRepository
+	MerchantRuleRepository repository.MerchantRuleRepository       // Optional, applies merchant categories during scan
+	OrganizationRepository repository.OrganizationRepository       // Optional, enables organization-scoped receipts
+	ReceiptViewRepository  repository.ReceiptViewRepository        // Optional, enables saved listing views
+	ActivityRepository     repository.ActivityRepository           // Optional, records receipt actions for the activity feed
+	UserRepository         repository.UserRepository               // Optional, places rolling insight windows in the user's time zone instead of UTC
+	FeedbackRepository     repository.ExtractionFeedbackRepository // Optional, records anonymous scan accuracy feedback from consenting users; needs UserRepository
+	OpenAIClient           InvoiceExtractor
+	MLXClient              URLInvoiceExtractor
+	S3Uploader             ImageStore              // Optional, nil disables image storage
+	CurrencyCatalog        CurrencyCatalog         // Optional, warns about item currencies without exchange rates
+	CurrencyConverter      CurrencyConverter       // Optional, enables converting item prices for display
+	ImageFetcher           *imageutil.Fetcher      // Optional, defaults to imageutil.NewFetcher(nil)
+	ImageStorage           *imageutil.ResizeConfig // Optional, how images are resized and encoded before upload, defaults to imageutil.DefaultConfig()
+	UseMLXService          bool
+	MLXFallback            bool          // Retries failed MLX extractions with OpenRouter instead of failing the scan
+	InlineImages           bool          // Sends images to OpenRouter inline rather than uploading them, when the extractor supports it
+	KeepFailedScanImages   bool          // Keeps images uploaded by scans that fail before the receipt is stored, deleted by default
+	ScanDeadline           time.Duration // Extraction time after which the remaining pages of a scan are skipped, zero disables it
+	MaxScanWorkers         int           // Scans extracting at once, sizing the worker pool that bounds concurrent model calls
+	MaxScanPages           int           // Page images a single scan may upload, defaults to defaultMaxScanPages
+	AnomalyZScoreThreshold float64
+	MinConfidenceAutosave  float64               // Extractions below this confidence are saved unverified, zero disables the check
+	MoneyPolicy            money.Policy          // Defaults to two decimals rounded half-up when unset
+	CurrencyRounding       bool                  // Rounds scanned amounts to the decimal places of their currency, e.g. whole rupiah
+	MonthlyScanQuota       int                   // Scans allowed per user per calendar month, zero allows any number
+	StrictCategories       bool                  // Rejects created or updated item categories outside domain.ItemCategories
+	ItemNameRules          *domain.ItemNameRules // Groups item name variants in insights, defaults to domain.DefaultItemNameRules()
+	Clock                  Clock                 // Optional, defaults to the system clock
+	IDGenerator            IDGenerator           // Optional, names uploaded images, defaults to the current Unix time in nanoseconds
 }
 
 // NewReceiptService creates a new ReceiptService
-func NewReceiptService(repo repository.ReceiptRepository, openAIClient *openrouter.Client, mlxClient *mlxclient.Client, s3Uploader *storage.S3Uploader, useMLXService bool, maxWorkers int) ReceiptService {
+func NewReceiptService(config ReceiptServiceConfig) ReceiptService {
+	anomalyThreshold := config.AnomalyZScoreThreshold
+	if anomalyThreshold <= 0 {
+		anomalyThreshold = defaultAnomalyZScoreThreshold
+	}
+
+	maxScanPages := config.MaxScanPages
+	if maxScanPages <= 0 {
+		maxScanPages = defaultMaxScanPages
+	}
+
+	imageFetcher := config.ImageFetcher
+	if imageFetcher == nil {
+		imageFetcher = imageutil.NewFetcher(nil)
+	}
+
+	imageStorage := config.ImageStorage
+	if imageStorage == nil {
+		imageStorage = imageutil.DefaultConfig()
+	}
+
+	moneyPolicy := config.MoneyPolicy
+	if moneyPolicy.Rounding == "" {
+		moneyPolicy = money.DefaultPolicy()
+	}
+
+	itemNameRules := domain.DefaultItemNameRules()
+	if config.ItemNameRules != nil {
+		itemNameRules = *config.ItemNameRules
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	idGenerator := config.IDGenerator
+	if idGenerator == nil {
+		idGenerator = nanoIDGenerator{}
+	}
+
 	return &ReceiptServiceImpl{
-		repository:    repo,
-		openAIClient:  openAIClient,
-		mlxClient:     mlxClient,
-		s3Uploader:    s3Uploader,
-		useMLXService: useMLXService,
-		workerPool:    make(chan struct{}, maxWorkers),
+		repository:             config.Repository,
+		merchantRuleRepo:       config.MerchantRuleRepository,
+		organizationRepo:       config.OrganizationRepository,
+		viewRepo:               config.ReceiptViewRepository,
+		activityRepo:           config.ActivityRepository,
+		userRepo:               config.UserRepository,
+		feedbackRepo:           config.FeedbackRepository,
+		openAIClient:           config.OpenAIClient,
+		mlxClient:              config.MLXClient,
+		s3Uploader:             config.S3Uploader,
+		currencyCatalog:        config.CurrencyCatalog,
+		currencyConverter:      config.CurrencyConverter,
+		imageFetcher:           imageFetcher,
+		imageStorage:           imageStorage,
+		useMLXService:          config.UseMLXService,
+		mlxFallback:            config.MLXFallback,
+		inlineImages:           config.InlineImages,
+		keepFailedScanImages:   config.KeepFailedScanImages,
+		scanDeadline:           config.ScanDeadline,
+		workerPool:             make(chan struct{}, config.MaxScanWorkers),
+		maxScanPages:           maxScanPages,
+		anomalyZScoreThreshold: anomalyThreshold,
+		minConfidenceAutosave:  config.MinConfidenceAutosave,
+		moneyPolicy:            moneyPolicy,
+		currencyRounding:       config.CurrencyRounding,
+		monthlyScanQuota:       config.MonthlyScanQuota,
+		strictCategories:       config.StrictCategories,
+		itemNameRules:          itemNameRules,
+		clock:                  clock,
+		idGenerator:            idGenerator,
+		reprocessJobs:          map[string]*domain.ReprocessJob{},
 	}
 }
 
+// Shutdown waits for in-flight scans to release their workers, holding every worker so no new
+// scan starts. It returns the context's error if scans are still running when the context ends.
+func (s *ReceiptServiceImpl) Shutdown(ctx context.Context) error {
+	for i := 0; i < cap(s.workerPool); i++ {
+		select {
+		case s.workerPool <- struct{}{}:
+		case <-ctx.Done():
+			return fmt.Errorf("scans still running at shutdown deadline: %w", ctx.Err())
+		}
+	}
+	return nil
+}
+
 // ScanReceipt processes an image to extract receipt data
 func (s *ReceiptServiceImpl) ScanReceipt(ctx context.Context, imageData []byte, userID string) (*domain.Receipt, error) {
+	return s.scanReceipt(ctx, [][]byte{imageData}, userID, "", true)
+}
+
+// ScanReceiptFromURL fetches a remote image and processes it to extract receipt data.
+// Without storeImage the receipt is saved with only its structured data and no stored image.
+func (s *ReceiptServiceImpl) ScanReceiptFromURL(ctx context.Context, imageURL string, userID string, storeImage bool) (*domain.Receipt, error) {
+	imageData, err := s.imageFetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "fetch_image_url",
+			Err: err,
+		}
+	}
+
+	return s.scanReceipt(ctx, [][]byte{imageData}, userID, imageURL, storeImage)
+}
+
+// scanReceipt runs the extraction pipeline on the page images of one receipt, recording the source URL when scanned by URL.
+// The first page's image is stored as the receipt image unless storeImage is false, when no image is kept.
+func (s *ReceiptServiceImpl) scanReceipt(ctx context.Context, pageImages [][]byte, userID string, sourceURL string, storeImage bool) (*domain.Receipt, error) {
+	if err := s.checkScanQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	// Acquire worker from pool
 	select {
 	case s.workerPool <- struct{}{}:
@@ -90,65 +328,51 @@ func (s *ReceiptServiceImpl) ScanReceipt(ctx context.Context, imageData []byte,
 		}
 	}
 
-	// Resize image before processing to reduce memory usage and upload size
-	originalSize := len(imageData)
-	resizedData, resizeErr := imageutil.ResizeImage(imageData, nil) // Uses default 1024px max
-	if resizeErr != nil {
-		log.Printf("Warning: failed to resize image, using original: %v", resizeErr)
-		resizedData = imageData
-	} else if len(resizedData) < originalSize {
-		log.Printf("Image resized: %d bytes -> %d bytes (%.1f%% reduction)",
-			originalSize, len(resizedData), float64(originalSize-len(resizedData))/float64(originalSize)*100)
-	}
-
-	// Extract invoice data using MLX or OpenRouter
-	var invoiceData *domain.Invoice
-	var err error
-	var receiptURL string
-
-	if s.useMLXService && s.mlxClient != nil && s.s3Uploader != nil {
-		// Upload resized image to S3 first
-		timestamp := time.Now().UnixNano()
-		filename := fmt.Sprintf("invoice_%d.png", timestamp)
-		imageURL, uploadErr := s.s3Uploader.UploadImage(resizedData, filename)
-		if uploadErr != nil {
-			return nil, &ReceiptServiceError{
-				Op:  "upload_image_to_s3",
-				Err: uploadErr,
-			}
+	// Extract each page, keeping the first page's stored image as the receipt URL.
+	// The receipt is tagged as a fallback extraction if any page needed one.
+	var receiptURL, extractionMethod string
+	stats := &domain.ExtractionStats{}
+	extractionStart := s.clock.Now()
+	pageInvoices := make([]*domain.Invoice, 0, len(pageImages))
+	for i, imageData := range pageImages {
+		// Past the deadline the pages extracted so far are kept and the rest skipped.
+		// The first page is always extracted, and a page already extracting is not interrupted.
+		if i > 0 && s.scanDeadline > 0 && s.clock.Now().Sub(extractionStart) >= s.scanDeadline {
+			stats.Partial = true
+			break
 		}
-
-		// Store the S3 URL as receipt URL for retry scanning
-		receiptURL = imageURL
-
-		// Use MLX service with the S3 URL
-		invoiceData, err = s.mlxClient.ExtractInvoiceData(imageURL)
+		// Only the first page's image is kept, so later pages uploaded for MLX are deleted once read
+		pageInvoice, imageURL, method, err := s.extractPage(ctx, imageData, i == 0 && storeImage, !storeImage || i > 0)
 		if err != nil {
-			return nil, &ReceiptServiceError{
-				Op:  "extract_receipt_data_mlx",
-				Err: err,
-			}
+			s.discardScanImage(receiptURL)
+			return nil, err
 		}
-	} else {
-		// Upload resized image to S3 for receipt URL storage
-		if s.s3Uploader != nil {
-			timestamp := time.Now().UnixNano()
-			filename := fmt.Sprintf("invoice_%d.png", timestamp)
-			imageURL, uploadErr := s.s3Uploader.UploadImage(resizedData, filename)
-			if uploadErr == nil {
-				receiptURL = imageURL
+		if i == 0 {
+			receiptURL = imageURL
+		}
+		if extractionMethod == "" || method == domain.ExtractionMethodOpenRouterFallback {
+			extractionMethod = method
+		}
+		if pageInvoice.Usage != nil {
+			if stats.Usage == nil {
+				stats.Usage = &domain.TokenUsage{}
 			}
+			stats.Usage.Add(*pageInvoice.Usage)
 		}
+		pageInvoices = append(pageInvoices, pageInvoice)
+	}
+	stats.Latency = s.clock.Now().Sub(extractionStart)
 
-		// Use OpenRouter to extract invoice data
-		invoiceData, err = s.openAIClient.ExtractInvoiceData(imageData)
-		if err != nil {
-			return nil, &ReceiptServiceError{
-				Op:  "extract_receipt_data_openrouter",
-				Err: err,
-			}
+	// A request that timed out or was abandoned during extraction gets no receipt
+	if err := ctx.Err(); err != nil {
+		s.discardScanImage(receiptURL)
+		return nil, &ReceiptServiceError{
+			Op:  "extract_receipt_data",
+			Err: err,
 		}
 	}
+	invoiceData := mergePageInvoices(pageInvoices)
+	now := s.clock.Now()
 
 	// Convert domain.Invoice to domain.Receipt
 	receipt := &domain.Receipt{
@@ -159,44 +383,178 @@ func (s *ReceiptServiceImpl) ScanReceipt(ctx context.Context, imageData []byte,
 		Tax:        invoiceData.TaxAmount,
 		Subtotal:   invoiceData.Subtotal,
 		ReceiptURL: receiptURL,
-		Items:      make([]domain.ReceiptItem, 0, len(invoiceData.Items)),
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		SourceURL:  sourceURL,
+		Confidence: invoiceData.Confidence,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+
+		Tip:           invoiceData.Tip,
+		ServiceCharge: invoiceData.ServiceCharge,
+
+		PaymentMethod:    normalizePaymentMethod(invoiceData.PaymentMethod),
+		ExtractionMethod: extractionMethod,
+		TaxID:            domain.NormalizeTaxID(invoiceData.TaxID),
+	}
+	if invoiceData.InvoiceTime != "" {
+		receipt.PurchasedAt = domain.DateWithTimeOfDay(invoiceData.InvoiceDate.Time, invoiceData.InvoiceTime, s.userLocation(ctx, userID))
 	}
 
 	// Convert invoice items to receipt items
-	for _, item := range invoiceData.Items {
-		category := inferCategory(item.Description)
-		if item.Category != "" {
-			category = item.Category // prefer LLM if present
-		}
-		// Default to USD if currency is not provided
-		currency := item.Currency
-		if currency == "" {
-			currency = "USD"
-		}
-		receiptItem := domain.ReceiptItem{
-			Name:     item.Description,
-			Quantity: int(item.Quantity), // Convert float64 to int
-			Price:    item.UnitPrice,
-			Currency: currency,
-			Category: category,
-		}
-		receipt.Items = append(receipt.Items, receiptItem)
+	receipt.Items = s.buildReceiptItems(ctx, userID, receipt.Merchant, invoiceData.Items)
+	s.checkItemCurrencies(ctx, receipt)
+	s.roundToCurrencyPrecision(receipt)
+
+	// Flag low-confidence extractions for review instead of auto-verifying them
+	s.applyConfidencePolicy(receipt)
+	if stats.Partial {
+		receipt.Status = domain.ReceiptStatusUnverified
+		receipt.Warnings = append(receipt.Warnings, fmt.Sprintf(
+			"Only %d of %d pages were extracted before the %s processing deadline; please review this receipt",
+			len(pageInvoices), len(pageImages), s.scanDeadline,
+		))
 	}
 
 	// Save receipt to database
 	storedReceipt, err := s.repository.CreateReceipt(ctx, receipt)
 	if err != nil {
+		s.discardScanImage(receiptURL)
 		return nil, &ReceiptServiceError{
 			Op:  "store_receipt",
 			Err: err,
 		}
 	}
+	s.recordActivity(ctx, userID, domain.ActivityReceiptScanned, storedReceipt.ID)
 
+	storedReceipt.Extraction = stats
+	storedReceipt.Alternatives = invoiceData.Alternatives
 	return storedReceipt, nil
 }
 
+// extractPage resizes a page image and extracts its invoice data using MLX or OpenRouter.
+// It returns the stored image URL, uploading the image when MLX needs it or storeImage is set,
+// and the domain.ExtractionMethod used. A transient image is never kept: it is sent inline to
+// OpenRouter and deleted once MLX has read it.
+func (s *ReceiptServiceImpl) extractPage(ctx context.Context, imageData []byte, storeImage, transient bool) (*domain.Invoice, string, string, error) {
+	// Resize image before processing to reduce memory usage and upload size
+	originalSize := len(imageData)
+	resizedData, resizeErr := s.resizeImage(imageData)
+	if resizeErr != nil {
+		return nil, "", "", resizeErr
+	}
+	if len(resizedData) < originalSize {
+		log.Printf("Image resized: %d bytes -> %d bytes (%.1f%% reduction)",
+			originalSize, len(resizedData), float64(originalSize-len(resizedData))/float64(originalSize)*100)
+	}
+
+	if s.useMLXService && s.mlxClient != nil && s.s3Uploader != nil {
+		// Upload resized image to S3 first, as MLX reads it from there
+		imageURL, uploadErr := s.s3Uploader.UploadImage(resizedData, s.imageFilename(resizedData))
+		if uploadErr != nil {
+			return nil, "", "", &ReceiptServiceError{
+				Op:  "upload_image_to_s3",
+				Err: uploadErr,
+			}
+		}
+
+		invoiceData, method, err := s.extractWithMLX(ctx, imageData, resizedData, imageURL, transient)
+		if err != nil {
+			if transient {
+				s.deleteScanImage(imageURL)
+			} else {
+				s.discardScanImage(imageURL)
+			}
+			return nil, "", "", err
+		}
+
+		// A transient image was only uploaded for MLX to read
+		if transient {
+			s.deleteScanImage(imageURL)
+			imageURL = ""
+		}
+		return invoiceData, imageURL, method, nil
+	}
+
+	// Upload resized image to S3 for receipt URL storage
+	var receiptURL string
+	if storeImage && s.s3Uploader != nil {
+		imageURL, uploadErr := s.s3Uploader.UploadImage(resizedData, s.imageFilename(resizedData))
+		if uploadErr == nil {
+			receiptURL = imageURL
+		}
+	}
+
+	// Use OpenRouter to extract invoice data
+	invoiceData, err := s.extractWithOpenRouter(ctx, imageData, resizedData, transient)
+	if err != nil {
+		s.discardScanImage(receiptURL)
+		return nil, "", "", &ReceiptServiceError{
+			Op:  "extract_receipt_data_openrouter",
+			Err: err,
+		}
+	}
+	return invoiceData, receiptURL, domain.ExtractionMethodOpenRouter, nil
+}
+
+// extractWithMLX extracts invoice data from an image uploaded for MLX, retrying with OpenRouter
+// when fallback is enabled, and returns the domain.ExtractionMethod used
+func (s *ReceiptServiceImpl) extractWithMLX(ctx context.Context, imageData, resizedData []byte, imageURL string, transient bool) (*domain.Invoice, string, error) {
+	invoiceData, err := s.mlxClient.ExtractInvoiceData(ctx, imageURL)
+	if err == nil {
+		return invoiceData, domain.ExtractionMethodMLX, nil
+	}
+	if !s.mlxFallback || s.openAIClient == nil {
+		return nil, "", &ReceiptServiceError{
+			Op:  "extract_receipt_data_mlx",
+			Err: err,
+		}
+	}
+
+	// Retry with OpenRouter, keeping the already stored image
+	log.Printf("Warning: MLX extraction failed, falling back to OpenRouter: %v", err)
+	invoiceData, fallbackErr := s.extractWithOpenRouter(ctx, imageData, resizedData, transient)
+	if fallbackErr != nil {
+		// Report the MLX failure when OpenRouter is not set up, rather than a configuration error
+		if errors.Is(fallbackErr, domain.ErrServiceNotConfigured) {
+			fallbackErr = err
+		}
+		return nil, "", &ReceiptServiceError{
+			Op:  "extract_receipt_data_openrouter_fallback",
+			Err: fallbackErr,
+		}
+	}
+	return invoiceData, domain.ExtractionMethodOpenRouterFallback, nil
+}
+
+// extractWithOpenRouter extracts invoice data with OpenRouter. Transient images, and every image
+// with InlineImages, are sent inline and resized when the extractor supports it, skipping
+// OpenRouter's own upload.
+func (s *ReceiptServiceImpl) extractWithOpenRouter(ctx context.Context, imageData, resizedData []byte, transient bool) (*domain.Invoice, error) {
+	if inline, ok := s.openAIClient.(InlineInvoiceExtractor); ok && (transient || s.inlineImages) {
+		return inline.ExtractInvoiceDataInline(ctx, resizedData)
+	}
+	return s.openAIClient.ExtractInvoiceData(ctx, imageData)
+}
+
+// discardScanImage deletes an image uploaded by a scan that failed before its receipt was stored,
+// so it isn't left orphaned in storage. With KeepFailedScanImages the image is kept and logged instead.
+func (s *ReceiptServiceImpl) discardScanImage(imageURL string) {
+	if imageURL != "" && s.keepFailedScanImages {
+		log.Printf("Keeping image %s of failed scan", imageURL)
+		return
+	}
+	s.deleteScanImage(imageURL)
+}
+
+// deleteScanImage deletes an image uploaded during a scan, logging a failure to delete it
+func (s *ReceiptServiceImpl) deleteScanImage(imageURL string) {
+	if imageURL == "" || s.s3Uploader == nil {
+		return
+	}
+	if err := s.s3Uploader.DeleteImage(imageURL); err != nil {
+		log.Printf("Warning: failed to delete scan image %s: %v", imageURL, err)
+	}
+}
+
 // RetryScanReceipt re-processes an existing receipt using its stored receipt URL
 func (s *ReceiptServiceImpl) RetryScanReceipt(ctx context.Context, receiptID string, userID string) (*domain.Receipt, error) {
 	// Get the existing receipt
@@ -244,7 +602,7 @@ func (s *ReceiptServiceImpl) RetryScanReceipt(ctx context.Context, receiptID str
 	var invoiceData *domain.Invoice
 	if s.useMLXService && s.mlxClient != nil {
 		// Use MLX service with the stored URL
-		invoiceData, err = s.mlxClient.ExtractInvoiceData(existingReceipt.ReceiptURL)
+		invoiceData, err = s.mlxClient.ExtractInvoiceData(ctx, existingReceipt.ReceiptURL)
 		if err != nil {
 			return nil, &ReceiptServiceError{
 				Op:  "extract_receipt_data_mlx_retry",
@@ -260,80 +618,174 @@ func (s *ReceiptServiceImpl) RetryScanReceipt(ctx context.Context, receiptID str
 	}
 
 	// Update the existing receipt with new extracted data
-	existingReceipt.Merchant = invoiceData.VendorName
-	existingReceipt.Date = domain.FlexibleDate{Time: invoiceData.InvoiceDate.Time}
-	existingReceipt.Total = invoiceData.TotalDue
-	existingReceipt.Tax = invoiceData.TaxAmount
-	existingReceipt.Subtotal = invoiceData.Subtotal
-	existingReceipt.UpdatedAt = time.Now()
+	s.applyRescan(ctx, existingReceipt, invoiceData, domain.ExtractionMethodMLX)
+
+	// Update receipt in database
+	updatedReceipt, err := s.repository.UpdateReceipt(ctx, existingReceipt)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "update_receipt_after_retry",
+			Err: err,
+		}
+	}
+	s.recordActivity(ctx, userID, domain.ActivityReceiptScanned, receiptID)
+
+	return updatedReceipt, nil
+}
+
+// applyRescan replaces the extracted data of a stored receipt with a new extraction of its image
+func (s *ReceiptServiceImpl) applyRescan(ctx context.Context, receipt *domain.Receipt, invoiceData *domain.Invoice, extractionMethod string) {
+	receipt.Merchant = invoiceData.VendorName
+	receipt.Date = domain.FlexibleDate{Time: invoiceData.InvoiceDate.Time}
+	receipt.PurchasedAt = nil
+	if invoiceData.InvoiceTime != "" {
+		receipt.PurchasedAt = domain.DateWithTimeOfDay(invoiceData.InvoiceDate.Time, invoiceData.InvoiceTime, s.userLocation(ctx, receipt.UserID))
+	}
+	receipt.Total = invoiceData.TotalDue
+	receipt.Tax = invoiceData.TaxAmount
+	receipt.Subtotal = invoiceData.Subtotal
+	receipt.Tip = invoiceData.Tip
+	receipt.ServiceCharge = invoiceData.ServiceCharge
+	receipt.Confidence = invoiceData.Confidence
+	receipt.PaymentMethod = normalizePaymentMethod(invoiceData.PaymentMethod)
+	receipt.TaxID = domain.NormalizeTaxID(invoiceData.TaxID)
+	receipt.ExtractionMethod = extractionMethod
+	receipt.UpdatedAt = s.clock.Now()
 
 	// Convert invoice items to receipt items
-	existingReceipt.Items = make([]domain.ReceiptItem, 0, len(invoiceData.Items))
-	for _, item := range invoiceData.Items {
-		category := inferCategory(item.Description)
-		if item.Category != "" {
-			category = item.Category
+	receipt.Items = s.buildReceiptItems(ctx, receipt.UserID, receipt.Merchant, invoiceData.Items)
+	s.checkItemCurrencies(ctx, receipt)
+	s.roundToCurrencyPrecision(receipt)
+	s.applyConfidencePolicy(receipt)
+}
+
+// applyConfidencePolicy marks a scanned receipt verified or unverified based on its extraction confidence.
+// When a minimum is configured, a missing confidence is treated as below it.
+func (s *ReceiptServiceImpl) applyConfidencePolicy(receipt *domain.Receipt) {
+	receipt.Status = domain.ReceiptStatusVerified
+	if s.minConfidenceAutosave <= 0 {
+		return
+	}
+
+	if receipt.Confidence == nil {
+		receipt.Status = domain.ReceiptStatusUnverified
+		receipt.Warnings = append(receipt.Warnings, "Extraction confidence was not reported; please review this receipt")
+		return
+	}
+
+	if *receipt.Confidence < s.minConfidenceAutosave {
+		receipt.Status = domain.ReceiptStatusUnverified
+		receipt.Warnings = append(receipt.Warnings, fmt.Sprintf(
+			"Extraction confidence %.2f is below the %.2f minimum; please review this receipt",
+			*receipt.Confidence, s.minConfidenceAutosave,
+		))
+	}
+}
+
+// buildReceiptItems converts extracted line items to receipt items.
+// Categories come from the LLM when present, then the user's merchant rule, then keyword inference.
+func (s *ReceiptServiceImpl) buildReceiptItems(ctx context.Context, userID, merchant string, lineItems []domain.LineItem) []domain.ReceiptItem {
+	var ruleCategory string
+	if s.merchantRuleRepo != nil && merchant != "" {
+		rule, err := s.merchantRuleRepo.GetMerchantRuleByMerchant(ctx, userID, merchant)
+		if err != nil {
+			log.Printf("Warning: failed to look up merchant rule for %q: %v", merchant, err)
+		} else if rule != nil {
+			ruleCategory = rule.Category
+		}
+	}
+
+	items := make([]domain.ReceiptItem, 0, len(lineItems))
+	for _, item := range lineItems {
+		category := item.Category // prefer LLM if present
+		if category == "" {
+			category = ruleCategory
+		}
+		if category == "" {
+			category = inferCategory(item.Description)
 		}
 		// Default to USD if currency is not provided
 		currency := item.Currency
 		if currency == "" {
 			currency = "USD"
 		}
-		receiptItem := domain.ReceiptItem{
+		items = append(items, domain.ReceiptItem{
 			Name:     item.Description,
-			Quantity: int(item.Quantity),
+			Quantity: int(item.Quantity), // Convert float64 to int
 			Price:    item.UnitPrice,
 			Currency: currency,
 			Category: category,
-		}
-		existingReceipt.Items = append(existingReceipt.Items, receiptItem)
+		})
 	}
 
-	// Update receipt in database
-	updatedReceipt, err := s.repository.UpdateReceipt(ctx, existingReceipt)
-	if err != nil {
-		return nil, &ReceiptServiceError{
-			Op:  "update_receipt_after_retry",
-			Err: err,
-		}
-	}
+	return items
+}
 
-	return updatedReceipt, nil
+// categoryKeywords maps keywords found in item descriptions to categories, checked in order
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{"Transport", []string{"taxi", "uber", "grab"}},
+	{"Travel", []string{"flight", "airfare"}},
+	{"Accommodation", []string{"hotel", "inn"}},
+	{"Food", []string{"meal", "food", "restaurant"}},
+	{"Office Supplies", []string{"office", "stationery"}},
+	{"Professional Services", []string{"consult", "service"}},
 }
 
+// defaultCategory is given to descriptions no keyword matches
+const defaultCategory = "Other"
+
 // inferCategory maps item descriptions to categories using keywords
 func inferCategory(description string) string {
+	category, _ := matchCategoryKeyword(description)
+	return category
+}
+
+// matchCategoryKeyword returns the category inferred for a description and the keyword that matched,
+// or the default category and an empty keyword when none does
+func matchCategoryKeyword(description string) (string, string) {
 	desc := strings.ToLower(description)
-	switch {
-	case strings.Contains(desc, "taxi") || strings.Contains(desc, "uber") || strings.Contains(desc, "grab"):
-		return "Transport"
-	case strings.Contains(desc, "flight") || strings.Contains(desc, "airfare"):
-		return "Travel"
-	case strings.Contains(desc, "hotel") || strings.Contains(desc, "inn"):
-		return "Accommodation"
-	case strings.Contains(desc, "meal") || strings.Contains(desc, "food") || strings.Contains(desc, "restaurant"):
-		return "Food"
-	case strings.Contains(desc, "office") || strings.Contains(desc, "stationery"):
-		return "Office Supplies"
-	case strings.Contains(desc, "consult") || strings.Contains(desc, "service"):
-		return "Professional Services"
-	default:
-		return "Other"
+	for _, rule := range categoryKeywords {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(desc, keyword) {
+				return rule.category, keyword
+			}
+		}
 	}
+	return defaultCategory, ""
+}
+
+// recalculateTotals sets the receipt subtotal from its items and the total as subtotal + tax +
+// tip + service charge, using fixed-point money math
+func (s *ReceiptServiceImpl) recalculateTotals(receipt *domain.Receipt) {
+	var subtotal money.Amount
+	for _, item := range receipt.Items {
+		subtotal = subtotal.Add(s.moneyPolicy.FromFloat(item.Price).Mul(item.Quantity))
+	}
+	receipt.Subtotal = s.moneyPolicy.ToFloat(subtotal)
+	total := subtotal.Add(s.moneyPolicy.FromFloat(receipt.Tax)).
+		Add(s.moneyPolicy.FromFloat(receipt.Tip)).
+		Add(s.moneyPolicy.FromFloat(receipt.ServiceCharge))
+	receipt.Total = s.moneyPolicy.ToFloat(total)
 }
 
 // CreateReceipt saves a new receipt
 func (s *ReceiptServiceImpl) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
-	// Recalculate subtotal and total from items
-	subtotal := 0.0
-	for _, item := range receipt.Items {
-		subtotal += item.Price * float64(item.Quantity)
+	if err := s.validateItemCategories(receipt); err != nil {
+		return nil, err
 	}
-	receipt.Subtotal = subtotal
-	receipt.Total = subtotal + receipt.Tax
+
+	// Recalculate subtotal and total from items
+	s.recalculateTotals(receipt)
+	receipt.PaymentMethod = normalizePaymentMethod(receipt.PaymentMethod)
+	receipt.TaxID = domain.NormalizeTaxID(receipt.TaxID)
+	receipt.Category = strings.TrimSpace(receipt.Category)
+	s.checkItemCurrencies(ctx, receipt)
 
 	// Set timestamps
-	now := time.Now()
+	now := s.clock.Now()
 	receipt.CreatedAt = now
 	receipt.UpdatedAt = now
 
@@ -345,6 +797,7 @@ func (s *ReceiptServiceImpl) CreateReceipt(ctx context.Context, receipt *domain.
 			Err: err,
 		}
 	}
+	s.recordActivity(ctx, storedReceipt.UserID, domain.ActivityReceiptCreated, storedReceipt.ID)
 
 	return storedReceipt, nil
 }
@@ -361,18 +814,68 @@ func (s *ReceiptServiceImpl) GetReceiptByID(ctx context.Context, receiptID strin
 	return receipt, nil
 }
 
+// ExportReceiptPDF renders a receipt owned by the user as a PDF document.
+// The stored receipt image is embedded as a thumbnail when it can be fetched.
+func (s *ReceiptServiceImpl) ExportReceiptPDF(ctx context.Context, receiptID string, userID string) ([]byte, error) {
+	receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_for_export",
+			Err: err,
+		}
+	}
+
+	// Verify ownership
+	if receipt.UserID != userID {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_receipt_ownership",
+			Err: fmt.Errorf("receipt does not belong to user"),
+		}
+	}
+
+	// The image is optional, so a failed download still produces a PDF without it
+	var imageData []byte
+	if receipt.ReceiptURL != "" {
+		imageData, err = s.imageFetcher.Fetch(ctx, receipt.ReceiptURL)
+		if err != nil {
+			log.Printf("Warning: failed to fetch image for receipt %s PDF: %v", receiptID, err)
+			imageData = nil
+		}
+	}
+
+	pdfData, err := receiptpdf.Render(receipt, imageData)
+	if err != nil && imageData != nil {
+		log.Printf("Warning: failed to embed image in receipt %s PDF: %v", receiptID, err)
+		pdfData, err = receiptpdf.Render(receipt, nil)
+	}
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "render_receipt_pdf",
+			Err: err,
+		}
+	}
+
+	return pdfData, nil
+}
+
 // UpdateReceipt updates an existing receipt
 func (s *ReceiptServiceImpl) UpdateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
-	// Recalculate subtotal and total from items
-	subtotal := 0.0
-	for _, item := range receipt.Items {
-		subtotal += item.Price * float64(item.Quantity)
+	if err := s.validateItemCategories(receipt); err != nil {
+		return nil, err
 	}
-	receipt.Subtotal = subtotal
-	receipt.Total = subtotal + receipt.Tax
+
+	// Recalculate subtotal and total from items
+	s.recalculateTotals(receipt)
+	receipt.PaymentMethod = normalizePaymentMethod(receipt.PaymentMethod)
+	receipt.TaxID = domain.NormalizeTaxID(receipt.TaxID)
+	receipt.Category = strings.TrimSpace(receipt.Category)
+	s.checkItemCurrencies(ctx, receipt)
 
 	// Update timestamp
-	receipt.UpdatedAt = time.Now()
+	receipt.UpdatedAt = s.clock.Now()
+
+	// Keep the scanned values to compare against before the update overwrites them
+	scanned := s.scannedReceiptForFeedback(ctx, receipt.ID)
 
 	// Update in repository
 	updatedReceipt, err := s.repository.UpdateReceipt(ctx, receipt)
@@ -382,12 +885,29 @@ func (s *ReceiptServiceImpl) UpdateReceipt(ctx context.Context, receipt *domain.
 			Err: err,
 		}
 	}
+	s.recordActivity(ctx, updatedReceipt.UserID, domain.ActivityReceiptUpdated, updatedReceipt.ID)
+	if scanned != nil {
+		s.recordExtractionFeedback(ctx, scanned, updatedReceipt)
+	}
 
 	return updatedReceipt, nil
 }
 
 // DeleteReceipt deletes a receipt
 func (s *ReceiptServiceImpl) DeleteReceipt(ctx context.Context, receiptID string) error {
+	// The owner is only needed to record the deletion in their activity feed
+	var userID string
+	if s.activityRepo != nil {
+		receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+		if err != nil {
+			return &ReceiptServiceError{
+				Op:  "get_receipt_for_delete",
+				Err: err,
+			}
+		}
+		userID = receipt.UserID
+	}
+
 	err := s.repository.DeleteReceipt(ctx, receiptID)
 	if err != nil {
 		return &ReceiptServiceError{
@@ -395,11 +915,68 @@ func (s *ReceiptServiceImpl) DeleteReceipt(ctx context.Context, receiptID string
 			Err: err,
 		}
 	}
+	s.recordActivity(ctx, userID, domain.ActivityReceiptDeleted, receiptID)
 	return nil
 }
 
+// SetReceiptOrganization shares a receipt owned by the user with an organization the user belongs to.
+// An empty orgID makes the receipt personal again.
+func (s *ReceiptServiceImpl) SetReceiptOrganization(ctx context.Context, receiptID, userID, orgID string) (*domain.Receipt, error) {
+	receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_for_organization",
+			Err: err,
+		}
+	}
+
+	// Verify ownership
+	if receipt.UserID != userID {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_receipt_ownership",
+			Err: fmt.Errorf("receipt does not belong to user"),
+		}
+	}
+
+	if orgID != "" {
+		if err := verifyOrganizationMember(ctx, s.organizationRepo, orgID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repository.SetReceiptOrganization(ctx, receiptID, orgID); err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "set_receipt_organization",
+			Err: err,
+		}
+	}
+	receipt.OrgID = orgID
+
+	return receipt, nil
+}
+
+// authorizeScope verifies the user belongs to the organization of an organization scope
+func (s *ReceiptServiceImpl) authorizeScope(ctx context.Context, scope domain.ReceiptScope) error {
+	if scope.OrgID == "" {
+		return nil
+	}
+	return verifyOrganizationMember(ctx, s.organizationRepo, scope.OrgID, scope.UserID)
+}
+
 // ListReceipts retrieves a paginated list of receipts
 func (s *ReceiptServiceImpl) ListReceipts(ctx context.Context, filter domain.ReceiptFilter) (*domain.PaginatedReceipts, error) {
+	// Only admin listings may span users; anything else without an owner is a caller bug
+	if filter.UserID == "" && filter.OrgID == "" && !filter.AllUsers {
+		return nil, &ReceiptServiceError{
+			Op:  "list_receipts",
+			Err: fmt.Errorf("receipt listing requires a user or organization scope"),
+		}
+	}
+
+	if err := s.authorizeScope(ctx, domain.ReceiptScope{UserID: filter.UserID, OrgID: filter.OrgID}); err != nil {
+		return nil, err
+	}
+
 	receipts, err := s.repository.ListReceipts(ctx, filter)
 	if err != nil {
 		return nil, &ReceiptServiceError{
@@ -407,9 +984,48 @@ func (s *ReceiptServiceImpl) ListReceipts(ctx context.Context, filter domain.Rec
 			Err: err,
 		}
 	}
+
+	// Syncing clients also learn which of their own receipts were deleted, from the activity feed
+	if filter.ModifiedSince != nil && filter.OrgID == "" && filter.UserID != "" && s.activityRepo != nil {
+		receipts.DeletedIDs, err = s.activityRepo.ListDeletedReceiptIDs(ctx, filter.UserID, *filter.ModifiedSince)
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "list_deleted_receipts",
+				Err: err,
+			}
+		}
+	}
 	return receipts, nil
 }
 
+// CountReceipts counts the receipts matching the filter, ignoring pagination
+func (s *ReceiptServiceImpl) CountReceipts(ctx context.Context, filter domain.ReceiptFilter) (int, error) {
+	if err := s.authorizeScope(ctx, domain.ReceiptScope{UserID: filter.UserID, OrgID: filter.OrgID}); err != nil {
+		return 0, err
+	}
+
+	count, err := s.repository.CountReceipts(ctx, filter)
+	if err != nil {
+		return 0, &ReceiptServiceError{
+			Op:  "count_receipts",
+			Err: err,
+		}
+	}
+	return count, nil
+}
+
+// ListReceiptCurrencies lists the currencies used across the user's receipts, most used first
+func (s *ReceiptServiceImpl) ListReceiptCurrencies(ctx context.Context, userID string) ([]domain.CurrencyUsage, error) {
+	currencies, err := s.repository.ListReceiptCurrencies(ctx, userID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_receipt_currencies",
+			Err: err,
+		}
+	}
+	return currencies, nil
+}
+
 // GetReceiptItems retrieves items for a specific receipt
 func (s *ReceiptServiceImpl) GetReceiptItems(ctx context.Context, receiptID string) ([]domain.ReceiptItem, error) {
 	items, err := s.repository.GetReceiptItems(ctx, receiptID)
@@ -423,8 +1039,12 @@ func (s *ReceiptServiceImpl) GetReceiptItems(ctx context.Context, receiptID stri
 }
 
 // GetDashboardSummary retrieves summary data for the dashboard
-func (s *ReceiptServiceImpl) GetDashboardSummary(ctx context.Context, userID string, startDate, endDate *string) (*domain.DashboardSummary, error) {
-	summary, err := s.repository.GetDashboardSummary(ctx, userID, startDate, endDate)
+func (s *ReceiptServiceImpl) GetDashboardSummary(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.DashboardSummary, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repository.GetDashboardSummary(ctx, scope, startDate, endDate)
 	if err != nil {
 		return nil, &ReceiptServiceError{
 			Op:  "get_dashboard_summary",
@@ -435,8 +1055,12 @@ func (s *ReceiptServiceImpl) GetDashboardSummary(ctx context.Context, userID str
 }
 
 // GetSpendingTrends retrieves spending trends over time
-func (s *ReceiptServiceImpl) GetSpendingTrends(ctx context.Context, userID string, period string, startDate, endDate *string) (*domain.SpendingTrends, error) {
-	trends, err := s.repository.GetSpendingTrends(ctx, userID, period, startDate, endDate)
+func (s *ReceiptServiceImpl) GetSpendingTrends(ctx context.Context, scope domain.ReceiptScope, period string, startDate, endDate *string) (*domain.SpendingTrends, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	trends, err := s.repository.GetSpendingTrends(ctx, scope, period, startDate, endDate)
 	if err != nil {
 		return nil, &ReceiptServiceError{
 			Op:  "get_spending_trends",
@@ -446,21 +1070,130 @@ func (s *ReceiptServiceImpl) GetSpendingTrends(ctx context.Context, userID strin
 	return trends, nil
 }
 
+// GetSpendingTrendsWindow retrieves spending trends over a rolling window ending today in the
+// requesting user's time zone. An empty period uses the granularity suited to the window.
+func (s *ReceiptServiceImpl) GetSpendingTrendsWindow(ctx context.Context, scope domain.ReceiptScope, period string, window domain.TrendWindow) (*domain.SpendingTrends, error) {
+	if period == "" {
+		period = window.Period()
+	}
+
+	start, end := window.Range(s.clock.Now(), s.userLocation(ctx, scope.UserID))
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+	return s.GetSpendingTrends(ctx, scope, period, &startDate, &endDate)
+}
+
+// userLocation returns the user's preferred time zone, or UTC when it is unknown
+func (s *ReceiptServiceImpl) userLocation(ctx context.Context, userID string) *time.Location {
+	if s.userRepo == nil {
+		return time.UTC
+	}
+	preferences, err := s.userRepo.GetUserPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("Warning: failed to get preferences of user %s, using UTC: %v", userID, err)
+		return time.UTC
+	}
+	if preferences == nil {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// UserNumberFormat returns the number format of the user's preferred number locale, or nil when
+// they chose none or it is unknown
+func (s *ReceiptServiceImpl) UserNumberFormat(ctx context.Context, userID string) *domain.NumberFormat {
+	if s.userRepo == nil {
+		return nil
+	}
+	preferences, err := s.userRepo.GetUserPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("Warning: failed to get preferences of user %s, leaving amounts unformatted: %v", userID, err)
+		return nil
+	}
+	if preferences == nil {
+		return nil
+	}
+	format, ok := domain.LookupNumberFormat(preferences.NumberLocale)
+	if !ok {
+		return nil
+	}
+	return &format
+}
+
 // GetSpendingByCategory retrieves spending breakdown by category
-func (s *ReceiptServiceImpl) GetSpendingByCategory(ctx context.Context, userID string, startDate, endDate *string) (*domain.CategorySpending, error) {
-	categorySpending, err := s.repository.GetSpendingByCategory(ctx, userID, startDate, endDate)
+func (s *ReceiptServiceImpl) GetSpendingByCategory(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.CategorySpending, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	categorySpending, err := s.repository.GetSpendingByCategory(ctx, scope, startDate, endDate)
 	if err != nil {
 		return nil, &ReceiptServiceError{
 			Op:  "get_spending_by_category",
 			Err: err,
 		}
 	}
+
+	for i := range categorySpending.Categories {
+		categorySpending.Categories[i].Items = s.groupCategoryItems(categorySpending.Categories[i].Items)
+	}
 	return categorySpending, nil
 }
 
+// GetCategoryTrend retrieves the spend on one category over time
+func (s *ReceiptServiceImpl) GetCategoryTrend(ctx context.Context, scope domain.ReceiptScope, category, period string, startDate, endDate *string) (*domain.SpendingTrends, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	trend, err := s.repository.GetCategoryTrend(ctx, scope, category, period, startDate, endDate)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_category_trend",
+			Err: err,
+		}
+	}
+	return trend, nil
+}
+
+// GetSpendingByHour retrieves the spending in each hour of the day, read in the user's time zone.
+// Every hour is listed, with zero spending when there were no purchases in it.
+func (s *ReceiptServiceImpl) GetSpendingByHour(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string) (*domain.HourlySpending, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	loc := s.userLocation(ctx, scope.UserID)
+	totals, err := s.repository.GetSpendingByHour(ctx, scope, loc.String(), startDate, endDate)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_spending_by_hour",
+			Err: err,
+		}
+	}
+
+	spending := &domain.HourlySpending{Timezone: loc.String(), Hours: make([]domain.HourlySpend, 24)}
+	for hour := range spending.Hours {
+		spending.Hours[hour].Hour = hour
+	}
+	for _, total := range totals {
+		if total.Hour >= 0 && total.Hour < 24 {
+			spending.Hours[total.Hour] = total
+		}
+	}
+	return spending, nil
+}
+
 // GetMerchantFrequency retrieves data on frequently visited merchants
-func (s *ReceiptServiceImpl) GetMerchantFrequency(ctx context.Context, userID string, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error) {
-	merchantFrequency, err := s.repository.GetMerchantFrequency(ctx, userID, startDate, endDate, limit)
+func (s *ReceiptServiceImpl) GetMerchantFrequency(ctx context.Context, scope domain.ReceiptScope, startDate, endDate *string, limit int) (*domain.MerchantFrequency, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	merchantFrequency, err := s.repository.GetMerchantFrequency(ctx, scope, startDate, endDate, limit)
 	if err != nil {
 		return nil, &ReceiptServiceError{
 			Op:  "get_merchant_frequency",
@@ -471,8 +1204,12 @@ func (s *ReceiptServiceImpl) GetMerchantFrequency(ctx context.Context, userID st
 }
 
 // GetMonthlyComparison compares spending between two months
-func (s *ReceiptServiceImpl) GetMonthlyComparison(ctx context.Context, userID string, month1, month2 string) (*domain.MonthlyComparison, error) {
-	comparison, err := s.repository.GetMonthlyComparison(ctx, userID, month1, month2)
+func (s *ReceiptServiceImpl) GetMonthlyComparison(ctx context.Context, scope domain.ReceiptScope, month1, month2 string) (*domain.MonthlyComparison, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	comparison, err := s.repository.GetMonthlyComparison(ctx, scope, month1, month2)
 	if err != nil {
 		return nil, &ReceiptServiceError{
 			Op:  "get_monthly_comparison",
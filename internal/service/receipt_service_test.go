@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// stubExtractor returns a fixed invoice for any image
+type stubExtractor struct {
+	invoice *domain.Invoice
+	err     error
+}
+
+func (e *stubExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	return e.invoice, e.err
+}
+
+// recordingReceiptRepository records receipts passed to CreateReceipt
+type recordingReceiptRepository struct {
+	repository.ReceiptRepository
+	created []*domain.Receipt
+}
+
+func (r *recordingReceiptRepository) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	receipt.ID = "receipt-1"
+	r.created = append(r.created, receipt)
+	return receipt, nil
+}
+
+func confidence(value float64) *float64 {
+	return &value
+}
+
+func TestScanReceiptConfidencePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		minConfidence float64
+		confidence    *float64
+		wantStatus    string
+		wantWarning   bool
+	}{
+		{name: "low confidence is flagged for review", minConfidence: 0.8, confidence: confidence(0.42), wantStatus: domain.ReceiptStatusUnverified, wantWarning: true},
+		{name: "high confidence is auto-verified", minConfidence: 0.8, confidence: confidence(0.95), wantStatus: domain.ReceiptStatusVerified},
+		{name: "missing confidence is flagged when a minimum is set", minConfidence: 0.8, wantStatus: domain.ReceiptStatusUnverified, wantWarning: true},
+		{name: "check disabled", minConfidence: 0, confidence: confidence(0.1), wantStatus: domain.ReceiptStatusVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingReceiptRepository{}
+			svc := NewReceiptService(ReceiptServiceConfig{
+				Repository: repo,
+				OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+					VendorName: "Corner Cafe",
+					TotalDue:   12.5,
+					Items:      []domain.LineItem{{Description: "Sandwich", Quantity: 1, UnitPrice: 12.5}},
+					Confidence: tt.confidence,
+				}},
+				MaxScanWorkers:        1,
+				MinConfidenceAutosave: tt.minConfidence,
+			})
+
+			receipt, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+			require.NoError(t, err)
+			require.Len(t, repo.created, 1)
+
+			assert.Equal(t, tt.wantStatus, repo.created[0].Status)
+			assert.Equal(t, tt.wantStatus, receipt.Status)
+			if tt.wantWarning {
+				assert.NotEmpty(t, receipt.Warnings)
+			} else {
+				assert.Empty(t, receipt.Warnings)
+			}
+		})
+	}
+}
+
+func TestRestaurantReceiptTip(t *testing.T) {
+	repo := &recordingReceiptRepository{}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository: repo,
+		OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+			VendorName:    "Trattoria Roma",
+			Subtotal:      40,
+			TaxAmount:     3.2,
+			Tip:           8,
+			ServiceCharge: 2,
+			TotalDue:      53.2,
+			Items: []domain.LineItem{
+				{Description: "Margherita", Quantity: 2, UnitPrice: 15},
+				{Description: "Tiramisu", Quantity: 1, UnitPrice: 10},
+			},
+		}},
+		MaxScanWorkers: 1,
+	})
+
+	scanned, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, scanned.Tip)
+	assert.Equal(t, 2.0, scanned.ServiceCharge)
+
+	// Recalculating the totals keeps the tip and service charge in the total
+	scanned.Items[1].Price = 12
+	receipt, err := svc.CreateReceipt(context.Background(), scanned)
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, receipt.Subtotal)
+	assert.Equal(t, 55.2, receipt.Total)
+}
+
+func TestScanReceiptStoresTaxID(t *testing.T) {
+	scan := func(taxID string) *domain.Receipt {
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository: repo,
+			OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+				VendorName: "PT Sumber Makmur",
+				TotalDue:   55000,
+				TaxID:      taxID,
+			}},
+			MaxScanWorkers: 1,
+		})
+
+		_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+		return repo.created[0]
+	}
+
+	// The NPWP is stored tidied but with its punctuation
+	assert.Equal(t, "01.234.567.8-901.000", scan("  01.234.567.8-901.000 ").TaxID)
+	assert.Equal(t, "DE 123456789", scan("de  123456789").TaxID)
+	assert.Empty(t, scan("").TaxID)
+}
+
+func TestShutdownDrainsWorkers(t *testing.T) {
+	svc := NewReceiptService(ReceiptServiceConfig{MaxScanWorkers: 2}).(*ReceiptServiceImpl)
+
+	// A scan holds a worker past the first deadline
+	svc.workerPool <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, svc.Shutdown(ctx), context.DeadlineExceeded)
+
+	// Once the scan releases its worker, shutdown completes
+	svc = NewReceiptService(ReceiptServiceConfig{MaxScanWorkers: 2}).(*ReceiptServiceImpl)
+	svc.workerPool <- struct{}{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-svc.workerPool
+	}()
+	assert.NoError(t, svc.Shutdown(context.Background()))
+}
+
+// concurrencyExtractor records the most extractions it has seen running at once
+type concurrencyExtractor struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (e *concurrencyExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.mu.Lock()
+	e.inFlight++
+	e.peak = max(e.peak, e.inFlight)
+	e.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	e.mu.Lock()
+	e.inFlight--
+	e.mu.Unlock()
+	return domain.NewInvoice(), nil
+}
+
+// discardingReceiptRepository returns created receipts without keeping them, safe for concurrent scans
+type discardingReceiptRepository struct {
+	repository.ReceiptRepository
+}
+
+func (r *discardingReceiptRepository) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	return receipt, nil
+}
+
+func TestScanWorkersBoundConcurrentExtractions(t *testing.T) {
+	extractor := &concurrencyExtractor{}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     &discardingReceiptRepository{},
+		OpenAIClient:   extractor,
+		MaxScanWorkers: 2,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, extractor.peak)
+}
+
+// failingURLExtractor fails every extraction, like an unreachable MLX service
+type failingURLExtractor struct {
+	calls int
+}
+
+func (e *failingURLExtractor) ExtractInvoiceData(ctx context.Context, imageURL string) (*domain.Invoice, error) {
+	e.calls++
+	return nil, errors.New("MLX service error (status 502): bad gateway")
+}
+
+func TestScanReceiptMLXFallback(t *testing.T) {
+	newService := func(fallback bool) (ReceiptService, *failingURLExtractor, *recordingReceiptRepository) {
+		mlx := &failingURLExtractor{}
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:     repo,
+			MLXClient:      mlx,
+			S3Uploader:     &memoryImageStore{images: map[string][]byte{}},
+			UseMLXService:  true,
+			MLXFallback:    fallback,
+			MaxScanWorkers: 1,
+			OpenAIClient: &stubExtractor{invoice: &domain.Invoice{
+				VendorName: "Corner Cafe",
+				TotalDue:   8,
+				Items:      []domain.LineItem{{Description: "Sandwich", Quantity: 1, UnitPrice: 8}},
+			}},
+		})
+		return svc, mlx, repo
+	}
+
+	t.Run("OpenRouter extracts when MLX fails", func(t *testing.T) {
+		svc, mlx, repo := newService(true)
+
+		receipt, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+
+		assert.Equal(t, 1, mlx.calls)
+		assert.Equal(t, "Corner Cafe", receipt.Merchant)
+		require.Len(t, receipt.Items, 1)
+		assert.Equal(t, domain.ExtractionMethodOpenRouterFallback, receipt.ExtractionMethod)
+		// The image uploaded for MLX is still kept as the receipt image
+		assert.NotEmpty(t, receipt.ReceiptURL)
+	})
+
+	t.Run("disabled fallback fails the scan", func(t *testing.T) {
+		svc, _, repo := newService(false)
+
+		_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		assert.ErrorContains(t, err, "extract_receipt_data_mlx")
+		assert.Empty(t, repo.created)
+	})
+}
+
+func TestScanReceiptFailedExtractionImage(t *testing.T) {
+	newService := func(keep bool) (ReceiptService, *memoryImageStore) {
+		images := &memoryImageStore{images: map[string][]byte{}}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:           &recordingReceiptRepository{},
+			MLXClient:            &failingURLExtractor{},
+			S3Uploader:           images,
+			UseMLXService:        true,
+			KeepFailedScanImages: keep,
+			MaxScanWorkers:       1,
+		})
+		return svc, images
+	}
+
+	t.Run("uploaded image is deleted when extraction fails", func(t *testing.T) {
+		svc, images := newService(false)
+
+		_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		assert.ErrorContains(t, err, "extract_receipt_data_mlx")
+		assert.Empty(t, images.images)
+	})
+
+	t.Run("uploaded image is kept when configured", func(t *testing.T) {
+		svc, images := newService(true)
+
+		_, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+		assert.ErrorContains(t, err, "extract_receipt_data_mlx")
+		assert.Len(t, images.images, 1)
+	})
+}
+
+// inlineExtractor records whether images were sent inline or through OpenRouter's own upload
+type inlineExtractor struct {
+	stubExtractor
+	inlineCalls, uploadCalls int
+}
+
+func (e *inlineExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.uploadCalls++
+	return e.stubExtractor.ExtractInvoiceData(ctx, imageData)
+}
+
+func (e *inlineExtractor) ExtractInvoiceDataInline(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.inlineCalls++
+	return e.stubExtractor.ExtractInvoiceData(ctx, imageData)
+}
+
+func TestScanReceiptWithoutStoringImage(t *testing.T) {
+	invoice := &domain.Invoice{
+		VendorName: "Corner Cafe",
+		TotalDue:   8,
+		Items:      []domain.LineItem{{Description: "Sandwich", Quantity: 1, UnitPrice: 8}},
+	}
+	pages := [][]byte{[]byte("page 1"), []byte("page 2")}
+
+	t.Run("OpenRouter receives the image inline", func(t *testing.T) {
+		images := &memoryImageStore{images: map[string][]byte{}}
+		extractor := &inlineExtractor{stubExtractor: stubExtractor{invoice: invoice}}
+		repo := &recordingReceiptRepository{}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:     repo,
+			OpenAIClient:   extractor,
+			S3Uploader:     images,
+			MaxScanWorkers: 1,
+		})
+
+		receipt, err := svc.ScanReceiptPages(context.Background(), pages, "", "user-1", false)
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+
+		assert.Empty(t, images.images)
+		assert.Empty(t, receipt.ReceiptURL)
+		assert.Equal(t, 2, extractor.inlineCalls)
+		assert.Zero(t, extractor.uploadCalls)
+		assert.Equal(t, "Corner Cafe", receipt.Merchant)
+	})
+
+	t.Run("image uploaded for MLX is deleted after a fallback extraction", func(t *testing.T) {
+		images := &memoryImageStore{images: map[string][]byte{}}
+		extractor := &inlineExtractor{stubExtractor: stubExtractor{invoice: invoice}}
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:     &recordingReceiptRepository{},
+			MLXClient:      &failingURLExtractor{},
+			OpenAIClient:   extractor,
+			S3Uploader:     images,
+			UseMLXService:  true,
+			MLXFallback:    true,
+			MaxScanWorkers: 1,
+		})
+
+		receipt, err := svc.ScanReceiptPages(context.Background(), pages, "", "user-1", false)
+		require.NoError(t, err)
+
+		assert.Empty(t, images.images)
+		assert.Empty(t, receipt.ReceiptURL)
+		assert.Equal(t, 2, extractor.inlineCalls)
+	})
+}
+
+func TestScanReceiptInlineImages(t *testing.T) {
+	extractor := &inlineExtractor{stubExtractor: stubExtractor{invoice: &domain.Invoice{
+		VendorName: "Corner Cafe",
+		TotalDue:   8,
+		Items:      []domain.LineItem{{Description: "Sandwich", Quantity: 1, UnitPrice: 8}},
+	}}}
+	repo := &recordingReceiptRepository{}
+	// Without S3 configured the scan still extracts, saving the receipt without an image
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		OpenAIClient:   extractor,
+		InlineImages:   true,
+		MaxScanWorkers: 1,
+	})
+
+	receipt, err := svc.ScanReceipt(context.Background(), []byte("not-an-image"), "user-1")
+	require.NoError(t, err)
+	require.Len(t, repo.created, 1)
+
+	assert.Equal(t, 1, extractor.inlineCalls)
+	assert.Zero(t, extractor.uploadCalls)
+	assert.Empty(t, receipt.ReceiptURL)
+	assert.Equal(t, domain.ExtractionMethodOpenRouter, receipt.ExtractionMethod)
+}
+
+// slowPageExtractor extracts one item per page, advancing the clock as if each page took perPage
+type slowPageExtractor struct {
+	clock   *fakeClock
+	perPage time.Duration
+	calls   int
+}
+
+func (e *slowPageExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	e.calls++
+	e.clock.Advance(e.perPage)
+	return &domain.Invoice{
+		VendorName: "Hardware Depot",
+		TotalDue:   10,
+		Items:      []domain.LineItem{{Description: string(imageData), Quantity: 1, UnitPrice: 10}},
+	}, nil
+}
+
+func TestScanReceiptProcessingDeadline(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	extractor := &slowPageExtractor{clock: clock, perPage: 20 * time.Second}
+	repo := &recordingReceiptRepository{}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		OpenAIClient:   extractor,
+		ScanDeadline:   30 * time.Second,
+		Clock:          clock,
+		MaxScanWorkers: 1,
+	})
+
+	pages := [][]byte{[]byte("page 1"), []byte("page 2"), []byte("page 3"), []byte("page 4")}
+	receipt, err := svc.ScanReceiptPages(context.Background(), pages, "", "user-1", true)
+	require.NoError(t, err)
+	require.Len(t, repo.created, 1)
+
+	// The deadline passes while the second page extracts, so the last two are skipped
+	assert.Equal(t, 2, extractor.calls)
+	require.Len(t, receipt.Items, 2)
+	assert.Equal(t, "page 2", receipt.Items[1].Name)
+	assert.True(t, receipt.Extraction.Partial)
+	assert.Equal(t, domain.ReceiptStatusUnverified, receipt.Status)
+	assert.Contains(t, receipt.Warnings[len(receipt.Warnings)-1], "Only 2 of 4 pages")
+}
+
+// deadlineExtractor waits for the request context to end before returning its invoice, like an
+// extraction still finishing when the request timed out
+type deadlineExtractor struct {
+	invoice *domain.Invoice
+}
+
+func (e *deadlineExtractor) ExtractInvoiceData(ctx context.Context, imageData []byte) (*domain.Invoice, error) {
+	<-ctx.Done()
+	return e.invoice, nil
+}
+
+func TestScanReceiptStopsAfterTimeout(t *testing.T) {
+	repo := &recordingReceiptRepository{}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		OpenAIClient:   &deadlineExtractor{invoice: &domain.Invoice{VendorName: "Corner Cafe", TotalDue: 4.5}},
+		MaxScanWorkers: 1,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := svc.ScanReceipt(ctx, []byte("receipt"), "user-1")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, repo.created)
+}
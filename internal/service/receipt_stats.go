@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
+)
+
+// uncategorizedCategory names the group of items without a category, as in the spending insights
+const uncategorizedCategory = "Uncategorized"
+
+// GetReceiptStats computes item metrics for a receipt owned by the user
+func (s *ReceiptServiceImpl) GetReceiptStats(ctx context.Context, receiptID string, userID string) (*domain.ReceiptStats, error) {
+	receipt, err := s.repository.GetReceiptByID(ctx, receiptID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_for_stats",
+			Err: err,
+		}
+	}
+
+	// Verify ownership
+	if receipt.UserID != userID {
+		return nil, &ReceiptServiceError{
+			Op:  "verify_receipt_ownership",
+			Err: fmt.Errorf("receipt does not belong to user"),
+		}
+	}
+
+	return calculateReceiptStats(receipt, s.moneyPolicy), nil
+}
+
+// calculateReceiptStats derives the stats from the receipt's items. The subtotal is summed from
+// the items in minor units, so the category subtotals always add up to it exactly.
+func calculateReceiptStats(receipt *domain.Receipt, policy money.Policy) *domain.ReceiptStats {
+	stats := &domain.ReceiptStats{
+		ReceiptID:  receipt.ID,
+		ItemCount:  len(receipt.Items),
+		Categories: []domain.ReceiptCategorySubtotal{},
+	}
+
+	var subtotal money.Amount
+	var units int
+	categorySubtotals := map[string]money.Amount{}
+	categoryCounts := map[string]int{}
+	for _, item := range receipt.Items {
+		lineTotal := policy.FromFloat(item.Price).Mul(item.Quantity)
+		subtotal = subtotal.Add(lineTotal)
+		units += item.Quantity
+
+		category := item.Category
+		if category == "" {
+			category = uncategorizedCategory
+		}
+		categorySubtotals[category] = categorySubtotals[category].Add(lineTotal)
+		categoryCounts[category]++
+
+		if item.Price > stats.MaxItemPrice {
+			stats.MaxItemPrice = item.Price
+		}
+	}
+
+	for category, amount := range categorySubtotals {
+		stats.Categories = append(stats.Categories, domain.ReceiptCategorySubtotal{
+			Category:  category,
+			Subtotal:  policy.ToFloat(amount),
+			ItemCount: categoryCounts[category],
+		})
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		if stats.Categories[i].Subtotal != stats.Categories[j].Subtotal {
+			return stats.Categories[i].Subtotal > stats.Categories[j].Subtotal
+		}
+		return stats.Categories[i].Category < stats.Categories[j].Category
+	})
+
+	stats.Subtotal = policy.ToFloat(subtotal)
+	if units > 0 {
+		stats.AverageItemPrice = stats.Subtotal / float64(units)
+	}
+	if stats.Subtotal != 0 {
+		stats.TaxRatio = receipt.Tax / stats.Subtotal
+	}
+
+	return stats
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/money"
+)
+
+func TestGetReceiptStats(t *testing.T) {
+	repo := &imageReceiptRepository{receipt: domain.Receipt{
+		ID:     "receipt-1",
+		UserID: "user-1",
+		Tax:    1.1,
+		Items: []domain.ReceiptItem{
+			{Name: "Coffee", Quantity: 3, Price: 0.1, Category: "Food"},
+			{Name: "Bagel", Quantity: 1, Price: 0.2, Category: "Food"},
+			{Name: "Pens", Quantity: 2, Price: 3.35, Category: "Office Supplies"},
+			{Name: "Bag", Quantity: 1, Price: 3.8},
+		},
+	}}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+	ctx := context.Background()
+
+	stats, err := svc.GetReceiptStats(ctx, "receipt-1", "user-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, stats.ItemCount)
+	assert.Equal(t, 11.0, stats.Subtotal)
+	assert.Equal(t, []domain.ReceiptCategorySubtotal{
+		{Category: "Office Supplies", Subtotal: 6.7, ItemCount: 1},
+		{Category: "Uncategorized", Subtotal: 3.8, ItemCount: 1},
+		{Category: "Food", Subtotal: 0.5, ItemCount: 2},
+	}, stats.Categories)
+
+	// Category subtotals add up to the subtotal exactly in minor units
+	policy := money.DefaultPolicy()
+	var sum money.Amount
+	for _, category := range stats.Categories {
+		sum = sum.Add(policy.FromFloat(category.Subtotal))
+	}
+	assert.Equal(t, policy.FromFloat(stats.Subtotal), sum)
+
+	assert.InDelta(t, 11.0/7, stats.AverageItemPrice, 0.0001)
+	assert.Equal(t, 3.8, stats.MaxItemPrice)
+	assert.InDelta(t, 0.1, stats.TaxRatio, 0.0001)
+
+	_, err = svc.GetReceiptStats(ctx, "receipt-1", "user-2")
+	assert.ErrorContains(t, err, "does not belong")
+}
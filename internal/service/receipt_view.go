@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// SaveReceiptView creates a named view or replaces the filters of the user's view with the same name
+func (s *ReceiptServiceImpl) SaveReceiptView(ctx context.Context, userID, name string, filters map[string]string) (*domain.ReceiptView, error) {
+	if err := s.checkReceiptViewsEnabled(); err != nil {
+		return nil, err
+	}
+
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	view, err := s.viewRepo.UpsertReceiptView(ctx, &domain.ReceiptView{
+		UserID:  userID,
+		Name:    strings.TrimSpace(name),
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "save_receipt_view",
+			Err: err,
+		}
+	}
+	return view, nil
+}
+
+// ListReceiptViews retrieves the user's saved views
+func (s *ReceiptServiceImpl) ListReceiptViews(ctx context.Context, userID string) ([]domain.ReceiptView, error) {
+	if err := s.checkReceiptViewsEnabled(); err != nil {
+		return nil, err
+	}
+
+	views, err := s.viewRepo.ListReceiptViews(ctx, userID)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "list_receipt_views",
+			Err: err,
+		}
+	}
+	return views, nil
+}
+
+// GetReceiptView retrieves the user's view with the given name
+func (s *ReceiptServiceImpl) GetReceiptView(ctx context.Context, userID, name string) (*domain.ReceiptView, error) {
+	if err := s.checkReceiptViewsEnabled(); err != nil {
+		return nil, err
+	}
+
+	view, err := s.viewRepo.GetReceiptViewByName(ctx, userID, strings.TrimSpace(name))
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_view",
+			Err: err,
+		}
+	}
+	if view == nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_receipt_view",
+			Err: fmt.Errorf("receipt view not found: %s", name),
+		}
+	}
+	return view, nil
+}
+
+// DeleteReceiptView deletes a view owned by the user
+func (s *ReceiptServiceImpl) DeleteReceiptView(ctx context.Context, userID, viewID string) error {
+	if err := s.checkReceiptViewsEnabled(); err != nil {
+		return err
+	}
+
+	if err := s.viewRepo.DeleteReceiptView(ctx, userID, viewID); err != nil {
+		return &ReceiptServiceError{
+			Op:  "delete_receipt_view",
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// checkReceiptViewsEnabled reports an error when no view repository is configured
+func (s *ReceiptServiceImpl) checkReceiptViewsEnabled() error {
+	if s.viewRepo == nil {
+		return &ReceiptServiceError{
+			Op:  "check_receipt_views",
+			Err: fmt.Errorf("%w: receipt view repository is missing", domain.ErrServiceNotConfigured),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+const defaultRetentionBatchSize = 500
+
+// RetentionService removes receipts and stored images older than the retention policy allows
+type RetentionService interface {
+	// Run applies the retention policy once, in batches, and reports what was removed
+	Run(ctx context.Context) (*domain.RetentionResult, error)
+	// Start runs the policy now and then every interval in the background until the context is cancelled
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// retentionService implements RetentionService
+type retentionService struct {
+	repository repository.RetentionRepository
+	imageStore ImageStore
+	policy     domain.RetentionPolicy
+	clock      Clock
+}
+
+// NewRetentionService creates a new RetentionService. Without an image store, receipts with a
+// stored image are left in place rather than orphaning the image.
+func NewRetentionService(repo repository.RetentionRepository, imageStore ImageStore, policy domain.RetentionPolicy, clock Clock) RetentionService {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = defaultRetentionBatchSize
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &retentionService{
+		repository: repo,
+		imageStore: imageStore,
+		policy:     policy,
+		clock:      clock,
+	}
+}
+
+// Start runs the retention policy on a ticker, logging each run's outcome
+func (s *retentionService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			result, err := s.Run(ctx)
+			if err != nil {
+				log.Printf("Warning: receipt retention run failed: %v", err)
+			} else {
+				log.Printf("Receipt retention run finished: deleted %d receipts and %d images, %d failed (dry run: %t)",
+					result.ReceiptsDeleted, result.ImagesDeleted, result.Failed, result.DryRun)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run deletes expired receipts first, so their images are not purged separately, then purges
+// the images of the receipts kept
+func (s *retentionService) Run(ctx context.Context) (*domain.RetentionResult, error) {
+	result := &domain.RetentionResult{DryRun: s.policy.DryRun}
+	now := s.clock.Now()
+
+	if s.policy.ReceiptMaxAge > 0 {
+		err := s.eachBatch(ctx, now.Add(-s.policy.ReceiptMaxAge), s.repository.ListReceiptsCreatedBefore, func(batch []domain.RetentionCandidate) error {
+			return s.deleteReceipts(ctx, batch, result)
+		})
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "apply_receipt_retention",
+				Err: err,
+			}
+		}
+	}
+
+	if s.policy.ImageMaxAge > 0 {
+		err := s.eachBatch(ctx, now.Add(-s.policy.ImageMaxAge), s.repository.ListImagesCreatedBefore, func(batch []domain.RetentionCandidate) error {
+			return s.purgeImages(ctx, batch, result)
+		})
+		if err != nil {
+			return nil, &ReceiptServiceError{
+				Op:  "apply_image_retention",
+				Err: err,
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// eachBatch pages through the receipts created before the cutoff and handles them a batch at a time
+func (s *retentionService) eachBatch(
+	ctx context.Context,
+	before time.Time,
+	list func(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error),
+	handle func(batch []domain.RetentionCandidate) error,
+) error {
+	afterID := ""
+	for {
+		// Stop between batches when shutting down; the next run continues
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		batch, err := list(ctx, before, afterID, s.policy.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := handle(batch); err != nil {
+			return err
+		}
+		if len(batch) < s.policy.BatchSize {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// deleteReceipts deletes a batch of expired receipts along with their stored images. Receipts
+// whose image can't be deleted are kept so a later run retries them.
+func (s *retentionService) deleteReceipts(ctx context.Context, batch []domain.RetentionCandidate, result *domain.RetentionResult) error {
+	ids := make([]string, 0, len(batch))
+	for _, receipt := range batch {
+		if s.policy.DryRun {
+			log.Printf("Retention dry run: would delete receipt %s created %s", receipt.ID, receipt.CreatedAt.Format(time.RFC3339))
+			result.ReceiptsDeleted++
+			continue
+		}
+		if !s.deleteImage(receipt) {
+			result.Failed++
+			continue
+		}
+		ids = append(ids, receipt.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	deleted, err := s.repository.DeleteReceipts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	log.Printf("Retention: deleted %d receipts", deleted)
+	result.ReceiptsDeleted += deleted
+	return nil
+}
+
+// purgeImages deletes the stored images of a batch of receipts and clears their references
+func (s *retentionService) purgeImages(ctx context.Context, batch []domain.RetentionCandidate, result *domain.RetentionResult) error {
+	ids := make([]string, 0, len(batch))
+	for _, receipt := range batch {
+		if s.policy.DryRun {
+			log.Printf("Retention dry run: would delete image %s of receipt %s", receipt.ReceiptURL, receipt.ID)
+			result.ImagesDeleted++
+			continue
+		}
+		if !s.deleteImage(receipt) {
+			result.Failed++
+			continue
+		}
+		ids = append(ids, receipt.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.repository.ClearReceiptImages(ctx, ids); err != nil {
+		return err
+	}
+	log.Printf("Retention: deleted %d receipt images", len(ids))
+	result.ImagesDeleted += len(ids)
+	return nil
+}
+
+// deleteImage deletes a receipt's stored image, if any, reporting whether the receipt no longer has one
+func (s *retentionService) deleteImage(receipt domain.RetentionCandidate) bool {
+	if receipt.ReceiptURL == "" {
+		return true
+	}
+	if s.imageStore == nil {
+		log.Printf("Warning: retention skipped receipt %s: image storage is not configured", receipt.ID)
+		return false
+	}
+	if err := s.imageStore.DeleteImage(receipt.ReceiptURL); err != nil {
+		log.Printf("Warning: retention failed to delete image of receipt %s: %v", receipt.ID, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// memoryRetentionRepository keeps receipts past or within retention keyed by ID
+type memoryRetentionRepository struct {
+	receipts map[string]domain.RetentionCandidate
+}
+
+func (r *memoryRetentionRepository) list(before time.Time, afterID string, limit int, withImage bool) []domain.RetentionCandidate {
+	var matched []domain.RetentionCandidate
+	for _, receipt := range r.receipts {
+		if receipt.CreatedAt.Before(before) && receipt.ID > afterID && (!withImage || receipt.ReceiptURL != "") {
+			matched = append(matched, receipt)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched[:min(limit, len(matched))]
+}
+
+func (r *memoryRetentionRepository) ListReceiptsCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error) {
+	return r.list(before, afterID, limit, false), nil
+}
+
+func (r *memoryRetentionRepository) ListImagesCreatedBefore(ctx context.Context, before time.Time, afterID string, limit int) ([]domain.RetentionCandidate, error) {
+	return r.list(before, afterID, limit, true), nil
+}
+
+func (r *memoryRetentionRepository) DeleteReceipts(ctx context.Context, receiptIDs []string) (int, error) {
+	for _, id := range receiptIDs {
+		delete(r.receipts, id)
+	}
+	return len(receiptIDs), nil
+}
+
+func (r *memoryRetentionRepository) ClearReceiptImages(ctx context.Context, receiptIDs []string) error {
+	for _, id := range receiptIDs {
+		receipt := r.receipts[id]
+		receipt.ReceiptURL = ""
+		r.receipts[id] = receipt
+	}
+	return nil
+}
+
+func TestRetentionRun(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	daysAgo := func(days int) time.Time { return clock.now.AddDate(0, 0, -days) }
+	newFixture := func() (*memoryRetentionRepository, *memoryImageStore) {
+		store := &memoryImageStore{images: map[string][]byte{
+			"https://storage.example.com/old.png":    []byte("old"),
+			"https://storage.example.com/aging.png":  []byte("aging"),
+			"https://storage.example.com/recent.png": []byte("recent"),
+		}}
+		repo := &memoryRetentionRepository{receipts: map[string]domain.RetentionCandidate{
+			"receipt-1": {ID: "receipt-1", ReceiptURL: "https://storage.example.com/old.png", CreatedAt: daysAgo(45)},
+			"receipt-2": {ID: "receipt-2", CreatedAt: daysAgo(31)},
+			"receipt-3": {ID: "receipt-3", ReceiptURL: "https://storage.example.com/aging.png", CreatedAt: daysAgo(20)},
+			"receipt-4": {ID: "receipt-4", ReceiptURL: "https://storage.example.com/recent.png", CreatedAt: daysAgo(2)},
+		}}
+		return repo, store
+	}
+	policy := domain.RetentionPolicy{ReceiptMaxAge: 30 * 24 * time.Hour, ImageMaxAge: 14 * 24 * time.Hour, BatchSize: 1}
+	ctx := context.Background()
+
+	t.Run("receipts past the window are purged and recent ones kept", func(t *testing.T) {
+		repo, store := newFixture()
+		result, err := NewRetentionService(repo, store, policy, clock).Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, &domain.RetentionResult{ReceiptsDeleted: 2, ImagesDeleted: 1}, result)
+		assert.NotContains(t, repo.receipts, "receipt-1")
+		assert.NotContains(t, repo.receipts, "receipt-2")
+
+		// The 20 day old receipt is kept without its image, the recent one untouched
+		assert.Empty(t, repo.receipts["receipt-3"].ReceiptURL)
+		assert.Equal(t, "https://storage.example.com/recent.png", repo.receipts["receipt-4"].ReceiptURL)
+		assert.Equal(t, map[string][]byte{"https://storage.example.com/recent.png": []byte("recent")}, store.images)
+	})
+
+	t.Run("dry run removes nothing", func(t *testing.T) {
+		repo, store := newFixture()
+		dryRun := policy
+		dryRun.DryRun = true
+		result, err := NewRetentionService(repo, store, dryRun, clock).Run(ctx)
+		require.NoError(t, err)
+
+		// The image of the receipt that would be deleted counts towards both
+		assert.Equal(t, &domain.RetentionResult{ReceiptsDeleted: 2, ImagesDeleted: 2, DryRun: true}, result)
+		assert.Len(t, repo.receipts, 4)
+		assert.Len(t, store.images, 3)
+	})
+
+	t.Run("receipts whose image can't be deleted are kept", func(t *testing.T) {
+		repo, store := newFixture()
+		delete(store.images, "https://storage.example.com/old.png")
+		result, err := NewRetentionService(repo, store, domain.RetentionPolicy{ReceiptMaxAge: policy.ReceiptMaxAge}, clock).Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.ReceiptsDeleted)
+		assert.Equal(t, 1, result.Failed)
+		assert.Contains(t, repo.receipts, "receipt-1")
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// ErrScanQuotaExceeded is returned by scans once the user has used up this month's scan quota
+var ErrScanQuotaExceeded = errors.New("monthly scan quota exceeded")
+
+// GetScanUsage reports the user's scans this calendar month (UTC) against the monthly quota.
+// Scans are counted from the scanned receipts saved this month.
+func (s *ReceiptServiceImpl) GetScanUsage(ctx context.Context, userID string) (*domain.ScanUsage, error) {
+	now := s.clock.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	used, err := s.repository.CountScannedReceipts(ctx, userID, monthStart)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "count_scanned_receipts",
+			Err: err,
+		}
+	}
+
+	return &domain.ScanUsage{
+		MonthlyQuota: s.monthlyScanQuota,
+		Used:         used,
+		ResetsAt:     monthStart.AddDate(0, 1, 0),
+	}, nil
+}
+
+// checkScanQuota returns ErrScanQuotaExceeded when the user has no scans left this month
+func (s *ReceiptServiceImpl) checkScanQuota(ctx context.Context, userID string) error {
+	if s.monthlyScanQuota <= 0 {
+		return nil
+	}
+
+	usage, err := s.GetScanUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if usage.Remaining() == 0 {
+		return ErrScanQuotaExceeded
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// countingReceiptRepository records created receipts and counts the scanned ones
+type countingReceiptRepository struct {
+	recordingReceiptRepository
+	clock *fakeClock
+}
+
+func (r *countingReceiptRepository) CreateReceipt(ctx context.Context, receipt *domain.Receipt) (*domain.Receipt, error) {
+	receipt.CreatedAt = r.clock.Now()
+	return r.recordingReceiptRepository.CreateReceipt(ctx, receipt)
+}
+
+func (r *countingReceiptRepository) CountScannedReceipts(ctx context.Context, userID string, since time.Time) (int, error) {
+	count := 0
+	for _, receipt := range r.created {
+		if receipt.UserID == userID && receipt.ExtractionMethod != "" && !receipt.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestScanQuota(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)}
+	repo := &countingReceiptRepository{clock: clock}
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:       repo,
+		OpenAIClient:     &stubExtractor{invoice: &domain.Invoice{VendorName: "Corner Cafe", TotalDue: 8}},
+		MonthlyScanQuota: 2,
+		Clock:            clock,
+		MaxScanWorkers:   1,
+	})
+	ctx := context.Background()
+
+	usage, err := svc.GetScanUsage(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Remaining())
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), usage.ResetsAt)
+
+	// Each scan uses up one of the month's scans
+	for _, remaining := range []int{1, 0} {
+		_, err := svc.ScanReceipt(ctx, []byte("not-an-image"), "user-1")
+		require.NoError(t, err)
+		usage, err := svc.GetScanUsage(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, remaining, usage.Remaining())
+	}
+
+	_, err = svc.ScanReceipt(ctx, []byte("not-an-image"), "user-1")
+	assert.ErrorIs(t, err, ErrScanQuotaExceeded)
+	assert.Len(t, repo.created, 2)
+
+	// Other users and the next month have their own quota
+	_, err = svc.ScanReceipt(ctx, []byte("not-an-image"), "user-2")
+	assert.NoError(t, err)
+	clock.Advance(2 * time.Hour)
+	_, err = svc.ScanReceipt(ctx, []byte("not-an-image"), "user-1")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+const (
+	// anomalyBaselineMonths is the number of trailing months used as the spending baseline
+	anomalyBaselineMonths = 6
+	// defaultAnomalyZScoreThreshold is the default number of standard deviations that flags an anomaly
+	defaultAnomalyZScoreThreshold = 2.0
+)
+
+// GetSpendingAnomaly compares a month's spending against the trailing baseline months
+func (s *ReceiptServiceImpl) GetSpendingAnomaly(ctx context.Context, scope domain.ReceiptScope, month string) (*domain.SpendingAnomaly, error) {
+	if err := s.authorizeScope(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	monthStart, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "parse_anomaly_month",
+			Err: fmt.Errorf("invalid month format %s: %w", month, err),
+		}
+	}
+
+	baselineMonths := make([]string, anomalyBaselineMonths)
+	for i := range baselineMonths {
+		baselineMonths[i] = monthStart.AddDate(0, i-anomalyBaselineMonths, 0).Format("2006-01")
+	}
+
+	totals, err := s.repository.GetMonthlySpendTotals(ctx, scope, baselineMonths[0], month)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_monthly_spend_totals",
+			Err: err,
+		}
+	}
+
+	categoryTotals, err := s.repository.GetMonthlyCategoryTotals(ctx, scope, baselineMonths[0], month)
+	if err != nil {
+		return nil, &ReceiptServiceError{
+			Op:  "get_monthly_category_totals",
+			Err: err,
+		}
+	}
+
+	return buildSpendingAnomaly(month, baselineMonths, totals, categoryTotals, s.anomalyZScoreThreshold), nil
+}
+
+// buildSpendingAnomaly computes z-scores for a month's total and categories against the baseline months.
+// Baseline months without any spending count as zero.
+func buildSpendingAnomaly(month string, baselineMonths []string, totals []domain.MonthlySpendTotal, categoryTotals []domain.MonthlyCategorySpend, threshold float64) *domain.SpendingAnomaly {
+	monthIndex := make(map[string]int, len(baselineMonths))
+	for i, m := range baselineMonths {
+		monthIndex[m] = i
+	}
+
+	// Collect the total series
+	totalHistory := make([]float64, len(baselineMonths))
+	var monthTotal float64
+	for _, total := range totals {
+		if total.Month == month {
+			monthTotal = total.Amount
+		} else if idx, ok := monthIndex[total.Month]; ok {
+			totalHistory[idx] = total.Amount
+		}
+	}
+
+	// Collect the per-category series
+	categoryHistory := make(map[string][]float64)
+	categoryMonth := make(map[string]float64)
+	for _, total := range categoryTotals {
+		if _, ok := categoryHistory[total.Category]; !ok {
+			categoryHistory[total.Category] = make([]float64, len(baselineMonths))
+		}
+		if total.Month == month {
+			categoryMonth[total.Category] = total.Amount
+		} else if idx, ok := monthIndex[total.Month]; ok {
+			categoryHistory[total.Category][idx] = total.Amount
+		}
+	}
+
+	result := &domain.SpendingAnomaly{
+		Month:          month,
+		BaselineMonths: len(baselineMonths),
+		Threshold:      threshold,
+		Total:          compareToBaseline(monthTotal, totalHistory, threshold),
+		Categories:     []domain.CategoryAnomaly{},
+	}
+
+	for category, history := range categoryHistory {
+		result.Categories = append(result.Categories, domain.CategoryAnomaly{
+			Name:          category,
+			AnomalyMetric: compareToBaseline(categoryMonth[category], history, threshold),
+		})
+	}
+
+	// Most anomalous categories first
+	sort.Slice(result.Categories, func(i, j int) bool {
+		if result.Categories[i].ZScore != result.Categories[j].ZScore {
+			return result.Categories[i].ZScore > result.Categories[j].ZScore
+		}
+		return result.Categories[i].Name < result.Categories[j].Name
+	})
+
+	return result
+}
+
+// compareToBaseline computes the mean, standard deviation and z-score of an amount against its history.
+// When the history has no variance, any amount above the baseline is flagged with a zero z-score.
+func compareToBaseline(amount float64, history []float64, threshold float64) domain.AnomalyMetric {
+	metric := domain.AnomalyMetric{Amount: amount}
+	if len(history) == 0 {
+		return metric
+	}
+
+	var sum float64
+	for _, value := range history {
+		sum += value
+	}
+	mean := sum / float64(len(history))
+
+	var variance float64
+	for _, value := range history {
+		variance += (value - mean) * (value - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(history)))
+
+	metric.Baseline = mean
+	metric.StdDev = stdDev
+	if stdDev == 0 {
+		metric.IsAnomaly = amount > mean
+		return metric
+	}
+
+	metric.ZScore = (amount - mean) / stdDev
+	metric.IsAnomaly = metric.ZScore > threshold
+	return metric
+}
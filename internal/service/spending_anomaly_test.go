@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// anomalyRepository serves fixed monthly totals for anomaly tests
+type anomalyRepository struct {
+	repository.ReceiptRepository
+	totals         []domain.MonthlySpendTotal
+	categoryTotals []domain.MonthlyCategorySpend
+	startMonth     string
+	endMonth       string
+}
+
+func (r *anomalyRepository) GetMonthlySpendTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlySpendTotal, error) {
+	r.startMonth, r.endMonth = startMonth, endMonth
+	return r.totals, nil
+}
+
+func (r *anomalyRepository) GetMonthlyCategoryTotals(ctx context.Context, scope domain.ReceiptScope, startMonth, endMonth string) ([]domain.MonthlyCategorySpend, error) {
+	return r.categoryTotals, nil
+}
+
+func TestGetSpendingAnomaly(t *testing.T) {
+	repo := &anomalyRepository{
+		totals: []domain.MonthlySpendTotal{
+			{Month: "2024-01", Amount: 300},
+			{Month: "2024-02", Amount: 320},
+			{Month: "2024-03", Amount: 280},
+			{Month: "2024-04", Amount: 300},
+			{Month: "2024-05", Amount: 310},
+			{Month: "2024-06", Amount: 290},
+			{Month: "2024-07", Amount: 700},
+		},
+		categoryTotals: []domain.MonthlyCategorySpend{
+			// Food spikes in the target month
+			{Month: "2024-01", Category: "Food", Amount: 100},
+			{Month: "2024-02", Category: "Food", Amount: 110},
+			{Month: "2024-03", Category: "Food", Amount: 90},
+			{Month: "2024-04", Category: "Food", Amount: 100},
+			{Month: "2024-05", Category: "Food", Amount: 105},
+			{Month: "2024-06", Category: "Food", Amount: 95},
+			{Month: "2024-07", Category: "Food", Amount: 500},
+			// Transport stays within its usual range
+			{Month: "2024-01", Category: "Transport", Amount: 200},
+			{Month: "2024-02", Category: "Transport", Amount: 210},
+			{Month: "2024-03", Category: "Transport", Amount: 190},
+			{Month: "2024-04", Category: "Transport", Amount: 200},
+			{Month: "2024-05", Category: "Transport", Amount: 205},
+			{Month: "2024-06", Category: "Transport", Amount: 195},
+			{Month: "2024-07", Category: "Transport", Amount: 200},
+		},
+	}
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+
+	anomaly, err := svc.GetSpendingAnomaly(context.Background(), domain.ReceiptScope{UserID: "user-1"}, "2024-07")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-01", repo.startMonth)
+	assert.Equal(t, "2024-07", repo.endMonth)
+	assert.Equal(t, anomalyBaselineMonths, anomaly.BaselineMonths)
+	assert.Equal(t, defaultAnomalyZScoreThreshold, anomaly.Threshold)
+
+	assert.InDelta(t, 300, anomaly.Total.Baseline, 0.001)
+	assert.True(t, anomaly.Total.IsAnomaly)
+
+	require.Len(t, anomaly.Categories, 2)
+
+	food := anomaly.Categories[0]
+	assert.Equal(t, "Food", food.Name)
+	assert.InDelta(t, 500, food.Amount, 0.001)
+	assert.InDelta(t, 100, food.Baseline, 0.001)
+	assert.InDelta(t, 6.455, food.StdDev, 0.001)
+	assert.InDelta(t, 61.97, food.ZScore, 0.01)
+	assert.True(t, food.IsAnomaly)
+
+	transport := anomaly.Categories[1]
+	assert.Equal(t, "Transport", transport.Name)
+	assert.InDelta(t, 200, transport.Baseline, 0.001)
+	assert.InDelta(t, 0, transport.ZScore, 0.001)
+	assert.False(t, transport.IsAnomaly)
+}
+
+func TestBuildSpendingAnomaly(t *testing.T) {
+	baseline := []string{"2024-01", "2024-02", "2024-03", "2024-04", "2024-05", "2024-06"}
+
+	tests := []struct {
+		name           string
+		categoryTotals []domain.MonthlyCategorySpend
+		wantBaseline   float64
+		wantAnomaly    bool
+	}{
+		{
+			name: "missing months count as zero",
+			categoryTotals: []domain.MonthlyCategorySpend{
+				{Month: "2024-01", Category: "Travel", Amount: 600},
+				{Month: "2024-07", Category: "Travel", Amount: 150},
+			},
+			wantBaseline: 100,
+			wantAnomaly:  false,
+		},
+		{
+			name: "new category is flagged",
+			categoryTotals: []domain.MonthlyCategorySpend{
+				{Month: "2024-07", Category: "Travel", Amount: 150},
+			},
+			wantBaseline: 0,
+			wantAnomaly:  true,
+		},
+		{
+			name: "flat history at same amount is not flagged",
+			categoryTotals: []domain.MonthlyCategorySpend{
+				{Month: "2024-01", Category: "Travel", Amount: 50},
+				{Month: "2024-02", Category: "Travel", Amount: 50},
+				{Month: "2024-03", Category: "Travel", Amount: 50},
+				{Month: "2024-04", Category: "Travel", Amount: 50},
+				{Month: "2024-05", Category: "Travel", Amount: 50},
+				{Month: "2024-06", Category: "Travel", Amount: 50},
+				{Month: "2024-07", Category: "Travel", Amount: 50},
+			},
+			wantBaseline: 50,
+			wantAnomaly:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomaly := buildSpendingAnomaly("2024-07", baseline, nil, tt.categoryTotals, 2.0)
+			require.Len(t, anomaly.Categories, 1)
+			assert.InDelta(t, tt.wantBaseline, anomaly.Categories[0].Baseline, 0.001)
+			assert.Equal(t, tt.wantAnomaly, anomaly.Categories[0].IsAnomaly)
+		})
+	}
+}
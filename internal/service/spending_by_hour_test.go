@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// hourlyReceiptRepository buckets receipts by hour of purchase in the requested time zone, like the
+// Postgres spending by hour query
+type hourlyReceiptRepository struct {
+	repository.ReceiptRepository
+	receipts []domain.Receipt
+}
+
+func (r *hourlyReceiptRepository) GetSpendingByHour(ctx context.Context, scope domain.ReceiptScope, timezone string, startDate, endDate *string) ([]domain.HourlySpend, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	index := map[int]int{}
+	var hours []domain.HourlySpend
+	for _, receipt := range r.receipts {
+		purchased := receipt.PurchaseTime()
+		if purchased == nil {
+			continue
+		}
+		hour := purchased.In(loc).Hour()
+		i, ok := index[hour]
+		if !ok {
+			i = len(hours)
+			index[hour] = i
+			hours = append(hours, domain.HourlySpend{Hour: hour})
+		}
+		hours[i].Total += receipt.Total
+		hours[i].Count++
+	}
+	return hours, nil
+}
+
+func TestGetSpendingByHour(t *testing.T) {
+	at := func(value string) domain.FlexibleDate {
+		date, err := domain.ParseFlexibleDate(value, domain.DayFirst)
+		require.NoError(t, err)
+		return domain.FlexibleDate{Time: date}
+	}
+	repo := &hourlyReceiptRepository{receipts: []domain.Receipt{
+		{ID: "breakfast", Date: at("2025-03-14T01:15:00Z"), Total: 6},
+		{ID: "lunch", Date: at("2025-03-14T12:45:00+07:00"), Total: 10},
+		{ID: "late", Date: at("2025-03-14T17:30:00Z"), Total: 4},
+		{ID: "date-only", Date: at("2025-03-14"), Total: 100},
+	}}
+	ctx := context.Background()
+	scope := domain.ReceiptScope{UserID: "user-1"}
+
+	svc := NewReceiptService(ReceiptServiceConfig{
+		Repository:     repo,
+		UserRepository: &timezoneUserRepository{timezone: "Asia/Jakarta"},
+	})
+	spending, err := svc.GetSpendingByHour(ctx, scope, nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Asia/Jakarta", spending.Timezone)
+	require.Len(t, spending.Hours, 24)
+	// Jakarta is UTC+7, so 01:15 UTC is 08:15 and 17:30 UTC is 00:30 the next day
+	assert.Equal(t, domain.HourlySpend{Hour: 8, Total: 6, Count: 1}, spending.Hours[8])
+	assert.Equal(t, domain.HourlySpend{Hour: 12, Total: 10, Count: 1}, spending.Hours[12])
+	assert.Equal(t, domain.HourlySpend{Hour: 0, Total: 4, Count: 1}, spending.Hours[0])
+	assert.Equal(t, domain.HourlySpend{Hour: 17}, spending.Hours[17])
+
+	t.Run("receipts with only a date are not counted", func(t *testing.T) {
+		var count int
+		for _, hour := range spending.Hours {
+			count += hour.Count
+		}
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("UTC without a time zone preference", func(t *testing.T) {
+		svc := NewReceiptService(ReceiptServiceConfig{Repository: repo})
+		spending, err := svc.GetSpendingByHour(ctx, scope, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "UTC", spending.Timezone)
+		assert.Equal(t, 1, spending.Hours[17].Count)
+	})
+}
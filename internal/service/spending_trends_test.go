@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// trendReceiptRepository buckets one receipt per day by month, like the Postgres monthly trend query
+type trendReceiptRepository struct {
+	repository.ReceiptRepository
+	dates []time.Time
+}
+
+func (r *trendReceiptRepository) GetSpendingTrends(ctx context.Context, scope domain.ReceiptScope, period string, startDate, endDate *string) (*domain.SpendingTrends, error) {
+	trends := &domain.SpendingTrends{Period: period}
+	for _, date := range r.dates {
+		day := date.Format("2006-01-02")
+		if day < *startDate || day > *endDate {
+			continue
+		}
+		month := date.Format("2006-01")
+		if n := len(trends.Data); n > 0 && trends.Data[n-1].Date == month {
+			trends.Data[n-1].Amount++
+			continue
+		}
+		trends.Data = append(trends.Data, domain.SpendingTrendDataItem{Date: month, Amount: 1})
+	}
+	return trends, nil
+}
+
+// timezoneUserRepository returns the same preferences for every user
+type timezoneUserRepository struct {
+	repository.UserRepository
+	timezone string
+}
+
+func (r *timezoneUserRepository) GetUserPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	return &domain.UserPreferences{DefaultCurrency: "USD", Timezone: r.timezone}, nil
+}
+
+func TestSpendingTrendsWindow(t *testing.T) {
+	// A receipt every day for two years up to the end of June 2025
+	repo := &trendReceiptRepository{}
+	for day := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC); day.Month() != time.July || day.Year() != 2025; day = day.AddDate(0, 0, 1) {
+		repo.dates = append(repo.dates, day)
+	}
+	// 20:00 UTC on 31 May is already 1 June in Jakarta
+	clock := &fakeClock{now: time.Date(2025, time.May, 31, 20, 0, 0, 0, time.UTC)}
+	ctx := context.Background()
+	scope := domain.ReceiptScope{UserID: "user-1"}
+
+	svc := NewReceiptService(ReceiptServiceConfig{Repository: repo, Clock: clock})
+	window, err := domain.ParseTrendWindow("12m")
+	require.NoError(t, err)
+
+	trends, err := svc.GetSpendingTrendsWindow(ctx, scope, "monthly", window)
+	require.NoError(t, err)
+	require.Len(t, trends.Data, 12)
+	assert.Equal(t, "monthly", trends.Period)
+	assert.Equal(t, "2024-06", trends.Data[0].Date)
+	assert.Equal(t, domain.SpendingTrendDataItem{Date: "2025-05", Amount: 31}, trends.Data[11])
+
+	t.Run("in the user's time zone", func(t *testing.T) {
+		svc := NewReceiptService(ReceiptServiceConfig{
+			Repository:     repo,
+			UserRepository: &timezoneUserRepository{timezone: "Asia/Jakarta"},
+			Clock:          clock,
+		})
+
+		trends, err := svc.GetSpendingTrendsWindow(ctx, scope, "monthly", window)
+		require.NoError(t, err)
+		require.Len(t, trends.Data, 12)
+		assert.Equal(t, "2024-07", trends.Data[0].Date)
+		assert.Equal(t, domain.SpendingTrendDataItem{Date: "2025-06", Amount: 1}, trends.Data[11])
+	})
+
+	t.Run("period follows the window", func(t *testing.T) {
+		for value, want := range map[string]string{"30d": "daily", "3m": "weekly", "1y": "monthly", "5y": "yearly"} {
+			window, err := domain.ParseTrendWindow(value)
+			require.NoError(t, err)
+			trends, err := svc.GetSpendingTrendsWindow(ctx, scope, "", window)
+			require.NoError(t, err)
+			assert.Equal(t, want, trends.Period, value)
+		}
+	})
+
+	t.Run("invalid windows", func(t *testing.T) {
+		for _, value := range []string{"", "d", "0m", "-3d", "12w", "11y"} {
+			_, err := domain.ParseTrendWindow(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+)
+
+// userCache keeps recently looked up users in memory for a short time, so per-request lookups
+// don't always reach the database. It is safe for concurrent use.
+type userCache struct {
+	mu      sync.Mutex
+	users   map[string]cachedUser
+	size    int
+	ttl     time.Duration
+	clock   Clock
+	enabled bool
+}
+
+type cachedUser struct {
+	user      domain.User
+	expiresAt time.Time
+}
+
+// newUserCache creates a cache holding up to size users for ttl each.
+// A zero size or TTL disables caching.
+func newUserCache(size int, ttl time.Duration, clock Clock) *userCache {
+	return &userCache{
+		users:   make(map[string]cachedUser),
+		size:    size,
+		ttl:     ttl,
+		clock:   clock,
+		enabled: size > 0 && ttl > 0,
+	}
+}
+
+// get returns a copy of the cached user, or false when it isn't cached or has expired
+func (c *userCache) get(userID string) (*domain.User, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.users[userID]
+	if !ok || !c.clock.Now().Before(cached.expiresAt) {
+		return nil, false
+	}
+	user := cached.user
+	return &user, true
+}
+
+// put caches a copy of the user. When the cache is full, expired users are dropped first,
+// then the user closest to expiring.
+func (c *userCache) put(user *domain.User) {
+	if !c.enabled || user == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if _, ok := c.users[user.ID]; !ok && len(c.users) >= c.size {
+		c.evict(now)
+	}
+	c.users[user.ID] = cachedUser{user: *user, expiresAt: now.Add(c.ttl)}
+}
+
+// evict makes room for one user. The caller must hold mu.
+func (c *userCache) evict(now time.Time) {
+	var oldestID string
+	var oldest time.Time
+	for id, cached := range c.users {
+		if !now.Before(cached.expiresAt) {
+			delete(c.users, id)
+			continue
+		}
+		if oldestID == "" || cached.expiresAt.Before(oldest) {
+			oldestID, oldest = id, cached.expiresAt
+		}
+	}
+	if len(c.users) >= c.size {
+		delete(c.users, oldestID)
+	}
+}
+
+// invalidate drops the user from the cache, so the next lookup reads it again
+func (c *userCache) invalidate(userID string) {
+	if !c.enabled {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.users, userID)
+}
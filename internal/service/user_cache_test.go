@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// countingUserRepository stores users in memory and counts GetUserByID calls
+type countingUserRepository struct {
+	repository.UserRepository
+	users   map[string]domain.User
+	lookups int
+}
+
+func (r *countingUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	r.lookups++
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *countingUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func TestGetUserByIDCache(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	repo := &countingUserRepository{users: map[string]domain.User{
+		"user-1": {ID: "user-1", Email: "ana@example.com", Name: "Ana"},
+		"user-2": {ID: "user-2", Email: "budi@example.com", Name: "Budi"},
+	}}
+	svc := NewAuthService(AuthServiceConfig{
+		UserRepo:            repo,
+		JWTSecret:           "test-secret",
+		JWTAccessExpiration: time.Hour,
+		Clock:               clock,
+		UserCacheSize:       1,
+		UserCacheTTL:        time.Minute,
+	})
+	ctx := context.Background()
+
+	user, err := svc.GetUserByID(ctx, "user-1")
+	require.NoError(t, err)
+	// Changes to a returned user don't leak into the cache
+	user.Name = "Changed"
+
+	// Token generation and repeated lookups within the TTL are served from the cache
+	_, err = svc.GenerateTokens("user-1")
+	require.NoError(t, err)
+	user, err = svc.GetUserByID(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", user.Name)
+	assert.Equal(t, 1, repo.lookups)
+
+	t.Run("updating a user invalidates it", func(t *testing.T) {
+		repo.lookups = 0
+		err := svc.(*authService).updateUser(ctx, &domain.User{ID: "user-1", Email: "ana@example.com", Name: "Ana Maria"})
+		require.NoError(t, err)
+
+		user, err := svc.GetUserByID(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Ana Maria", user.Name)
+		assert.Equal(t, 1, repo.lookups)
+	})
+
+	t.Run("users are read again after the TTL", func(t *testing.T) {
+		repo.lookups = 0
+		clock.Advance(time.Minute)
+
+		_, err := svc.GetUserByID(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.lookups)
+	})
+
+	t.Run("a full cache evicts to make room", func(t *testing.T) {
+		repo.lookups = 0
+		_, err := svc.GetUserByID(ctx, "user-2")
+		require.NoError(t, err)
+		_, err = svc.GetUserByID(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo.lookups)
+	})
+
+	t.Run("missing users are not cached", func(t *testing.T) {
+		repo.lookups = 0
+		for range 2 {
+			_, err := svc.GetUserByID(ctx, "user-3")
+			assert.ErrorIs(t, err, ErrUserNotFound)
+		}
+		assert.Equal(t, 2, repo.lookups)
+	})
+}
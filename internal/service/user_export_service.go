@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ridwanfathin/invoice-processor-service/internal/domain"
+	"github.com/ridwanfathin/invoice-processor-service/internal/repository"
+)
+
+// UserExportService defines the interface for exporting a user's data for portability
+type UserExportService interface {
+	// ExportUserData assembles the user's profile and the receipts they own dated within the optional
+	// range, with items
+	ExportUserData(ctx context.Context, userID string, startDate, endDate *time.Time) (*domain.UserDataExport, error)
+}
+
+// ExportTooLargeError is returned when an export would include more receipts than the configured maximum
+type ExportTooLargeError struct {
+	Receipts int
+	Limit    int
+}
+
+func (e *ExportTooLargeError) Error() string {
+	return fmt.Sprintf("export covers %d receipts, more than the limit of %d", e.Receipts, e.Limit)
+}
+
+// userExportService implements UserExportService
+type userExportService struct {
+	authService AuthService
+	receiptRepo repository.ReceiptRepository
+	maxReceipts int
+}
+
+// NewUserExportService creates a new UserExportService. Exports covering more than maxReceipts
+// receipts are refused; zero or less allows any size.
+func NewUserExportService(authService AuthService, receiptRepo repository.ReceiptRepository, maxReceipts int) UserExportService {
+	return &userExportService{
+		authService: authService,
+		receiptRepo: receiptRepo,
+		maxReceipts: maxReceipts,
+	}
+}
+
+// ExportUserData assembles the user's profile and the receipts they own dated within the optional
+// range, with items. Exports over the receipt limit fail with an ExportTooLargeError before any
+// receipts are loaded.
+func (s *userExportService) ExportUserData(ctx context.Context, userID string, startDate, endDate *time.Time) (*domain.UserDataExport, error) {
+	if s.maxReceipts > 0 {
+		count, err := s.receiptRepo.CountReceipts(ctx, domain.ReceiptFilter{UserID: userID, StartDate: startDate, EndDate: endDate})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count exported receipts: %w", err)
+		}
+		if count > s.maxReceipts {
+			return nil, &ExportTooLargeError{Receipts: count, Limit: s.maxReceipts}
+		}
+	}
+
+	profile, err := s.authService.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export profile: %w", err)
+	}
+
+	receipts, err := s.receiptRepo.GetReceiptsWithItems(ctx, repository.ReceiptFilterWithItems{
+		UserID:    userID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export receipts: %w", err)
+	}
+
+	return &domain.UserDataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    profile,
+		Receipts:   receipts,
+	}, nil
+}
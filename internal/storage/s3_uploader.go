@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ridwanfathin/invoice-processor-service/internal/imageutil"
 )
 
 // S3Uploader handles uploading images to S3-compatible storage
@@ -52,24 +53,46 @@ func NewS3Uploader(config *Config) (*S3Uploader, error) {
 	}, nil
 }
 
-// UploadImage uploads an image to S3 and returns the public URL
+// UploadImage uploads an image to S3 and returns the public URL.
+// The content type is detected from the image data.
 func (u *S3Uploader) UploadImage(imageData []byte, filename string) (string, error) {
+	_, contentType := imageutil.FileType(imageData)
+
 	// Upload the file to S3
 	_, err := u.s3Client.PutObject(&s3.PutObjectInput{
 		Bucket:        aws.String(u.bucket),
 		Key:           aws.String(filename),
 		Body:          bytes.NewReader(imageData),
-		ContentType:   aws.String("image/png"),
+		ContentType:   aws.String(contentType),
 		ContentLength: aws.Int64(int64(len(imageData))),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload to S3: %w", err)
 	}
 
-	// Construct the public URL
+	return u.publicURLPrefix() + filename, nil
+}
+
+// DeleteImage deletes an image previously returned by UploadImage, identified by its public URL
+func (u *S3Uploader) DeleteImage(imageURL string) error {
+	filename := strings.TrimPrefix(imageURL, u.publicURLPrefix())
+	if filename == imageURL || filename == "" {
+		return fmt.Errorf("image is not stored in bucket %s: %s", u.bucket, imageURL)
+	}
+
+	_, err := u.s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(filename),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete from S3: %w", err)
+	}
+	return nil
+}
+
+// publicURLPrefix returns the public URL of the bucket, to which object keys are appended
+func (u *S3Uploader) publicURLPrefix() string {
 	// Format: https://{project-ref}.storage.supabase.co/storage/v1/object/public/{bucket}/{filename}
 	baseURL := strings.Replace(u.endpoint, "/storage/v1/s3", "", 1)
-	publicURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s", baseURL, u.bucket, filename)
-
-	return publicURL, nil
+	return fmt.Sprintf("%s/storage/v1/object/public/%s/", baseURL, u.bucket)
 }
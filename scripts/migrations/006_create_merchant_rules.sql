@@ -0,0 +1,22 @@
+-- Create merchant_rules table for per-user merchant to category mappings
+CREATE TABLE IF NOT EXISTS merchant_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merchant VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ensure one rule per merchant per user, matched case-insensitively
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchant_rules_user_merchant ON merchant_rules(user_id, LOWER(TRIM(merchant)));
+
+-- Add trigger for updated_at timestamp on merchant_rules
+CREATE TRIGGER update_merchant_rules_modtime
+BEFORE UPDATE ON merchant_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_modified_column();
+
+-- Add comments to explain the table
+COMMENT ON TABLE merchant_rules IS 'User-defined rules that assign a category to every item from a merchant';
+COMMENT ON COLUMN merchant_rules.merchant IS 'Merchant name, matched case-insensitively against receipts.merchant';
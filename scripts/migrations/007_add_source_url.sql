@@ -0,0 +1,7 @@
+-- Add source_url column to receipts table
+-- This will store the remote URL a receipt image was fetched from when scanned by URL
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS source_url TEXT;
+
+-- Add comment to explain the column
+COMMENT ON COLUMN receipts.source_url IS 'Remote URL the receipt image was fetched from for scan-by-URL requests';
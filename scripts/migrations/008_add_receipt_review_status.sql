@@ -0,0 +1,11 @@
+-- Add review status and extraction confidence columns to receipts table
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'verified',
+ADD COLUMN IF NOT EXISTS confidence NUMERIC(4, 3);
+
+-- Create index for the needsReview filter
+CREATE INDEX IF NOT EXISTS idx_receipts_user_status ON receipts(user_id, status);
+
+-- Add comments to explain the columns
+COMMENT ON COLUMN receipts.status IS 'Review status: verified, or unverified when extraction confidence is below MIN_CONFIDENCE_AUTOSAVE';
+COMMENT ON COLUMN receipts.confidence IS 'Extraction confidence between 0 and 1 reported by the AI extractor, NULL if not reported';
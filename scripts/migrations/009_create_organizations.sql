@@ -0,0 +1,38 @@
+-- Create organizations table for teams sharing receipts
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create organization_members table linking users to organizations
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Create index for listing a user's organizations
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- Allow a receipt to be shared with an organization
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- Create index for organization-scoped listing and insights
+CREATE INDEX IF NOT EXISTS idx_receipts_org_id_date ON receipts(org_id, date) WHERE org_id IS NOT NULL;
+
+-- Add trigger for updated_at timestamp on organizations
+CREATE TRIGGER update_organizations_modtime
+BEFORE UPDATE ON organizations
+FOR EACH ROW
+EXECUTE FUNCTION update_modified_column();
+
+-- Add comments to explain the tables
+COMMENT ON TABLE organizations IS 'Teams of users sharing receipts';
+COMMENT ON COLUMN organization_members.role IS 'Member role: owner or member. Only owners can invite members';
+COMMENT ON COLUMN receipts.org_id IS 'Organization the receipt is shared with, NULL for personal receipts';
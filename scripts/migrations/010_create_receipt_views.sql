@@ -0,0 +1,22 @@
+-- Create receipt_views table for saved per-user listing filters and sort
+CREATE TABLE IF NOT EXISTS receipt_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ensure view names are unique per user, matched case-insensitively
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipt_views_user_name ON receipt_views(user_id, LOWER(name));
+
+-- Add trigger for updated_at timestamp on receipt_views
+CREATE TRIGGER update_receipt_views_modtime
+BEFORE UPDATE ON receipt_views
+FOR EACH ROW
+EXECUTE FUNCTION update_modified_column();
+
+-- Add comments to explain the table
+COMMENT ON TABLE receipt_views IS 'Named receipt listing presets applied with GET /v1/receipts?view=name';
+COMMENT ON COLUMN receipt_views.filters IS 'Listing query parameters as a JSON object, e.g. {"startDate": "2024-01-01", "category": "Food", "sortBy": "total"}';
@@ -0,0 +1,6 @@
+-- Add payment method column to receipts table
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS payment_method VARCHAR(50);
+
+-- Add comment to explain the column
+COMMENT ON COLUMN receipts.payment_method IS 'How the receipt was paid: cash, card or another method as printed, NULL if not captured';
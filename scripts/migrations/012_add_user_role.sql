@@ -0,0 +1,6 @@
+-- Add role column to users table
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- Add comment to explain the column
+COMMENT ON COLUMN users.role IS 'User role: user, or admin for access to the /v1/admin endpoints';
@@ -0,0 +1,13 @@
+-- Add normalized merchant column to receipts table
+-- Existing rows are left NULL and filled by POST /v1/admin/backfill?field=normalized_merchant
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS normalized_merchant VARCHAR(255);
+
+-- Create index for grouping receipts by merchant
+CREATE INDEX IF NOT EXISTS idx_receipts_user_normalized_merchant ON receipts(user_id, normalized_merchant);
+
+-- Create partial index so backfill batches find unfilled rows quickly
+CREATE INDEX IF NOT EXISTS idx_receipts_normalized_merchant_missing ON receipts(id) WHERE normalized_merchant IS NULL;
+
+-- Add comment to explain the column
+COMMENT ON COLUMN receipts.normalized_merchant IS 'Merchant name lowercased with punctuation and trailing store numbers removed, used to group merchant variants';
@@ -0,0 +1,19 @@
+-- Create user_preferences table for per-user display and conversion defaults
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    default_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add trigger for updated_at timestamp on user_preferences
+CREATE TRIGGER update_user_preferences_modtime
+BEFORE UPDATE ON user_preferences
+FOR EACH ROW
+EXECUTE FUNCTION update_modified_column();
+
+-- Add comments to explain the table
+COMMENT ON TABLE user_preferences IS 'Per-user preferences; users without a row use the defaults';
+COMMENT ON COLUMN user_preferences.default_currency IS 'ISO 4217 code used when the user does not pick a currency, e.g. USD';
+COMMENT ON COLUMN user_preferences.timezone IS 'IANA time zone name, e.g. Asia/Jakarta';
@@ -0,0 +1,6 @@
+-- Add extraction method column to receipts table
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS extraction_method VARCHAR(32);
+
+-- Add comment to explain the column
+COMMENT ON COLUMN receipts.extraction_method IS 'Extractor that produced a scanned receipt: mlx, openrouter or openrouter_fallback, NULL for manually entered receipts';
@@ -0,0 +1,8 @@
+-- Add tip and service charge columns to receipts table
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS tip DECIMAL(10, 2) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS service_charge DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Add comments to explain the columns
+COMMENT ON COLUMN receipts.tip IS 'Gratuity added to the receipt, included in the total alongside subtotal and tax';
+COMMENT ON COLUMN receipts.service_charge IS 'Service charge added to the receipt, included in the total alongside subtotal and tax';
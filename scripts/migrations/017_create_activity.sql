@@ -0,0 +1,15 @@
+-- Create activity table for the per-user feed of receipt actions
+CREATE TABLE IF NOT EXISTS activity (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(32) NOT NULL,
+    receipt_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index the feed query, newest first per user
+CREATE INDEX IF NOT EXISTS idx_activity_user_created ON activity(user_id, created_at DESC);
+
+-- Add comments to explain the table
+COMMENT ON TABLE activity IS 'Receipt actions shown in GET /v1/activity. receipt_id has no foreign key so deletions stay in the feed';
+COMMENT ON COLUMN activity.action IS 'One of scanned, created, updated or deleted';
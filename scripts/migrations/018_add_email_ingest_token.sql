@@ -0,0 +1,9 @@
+-- Give every user a secret token for their receipt forwarding address
+-- The default is evaluated per row, so existing users each get their own token
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_ingest_token VARCHAR(64) NOT NULL DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+-- Tokens identify the user on POST /v1/ingest/email, so they must be unique
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_ingest_token ON users(email_ingest_token);
+
+-- Add comments to explain the column
+COMMENT ON COLUMN users.email_ingest_token IS 'Secret in the local part of the user''s forwarding address, as in receipts+<token>@domain';
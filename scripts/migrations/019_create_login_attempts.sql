@@ -0,0 +1,12 @@
+-- Create login_attempts table tracking failed password logins for account lockout
+CREATE TABLE IF NOT EXISTS login_attempts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments to explain the table
+COMMENT ON TABLE login_attempts IS 'Consecutive failed password logins per user, deleted on a successful login';
+COMMENT ON COLUMN login_attempts.failed_count IS 'Failures since the last successful login or lockout';
+COMMENT ON COLUMN login_attempts.locked_until IS 'Password logins are refused until this time';
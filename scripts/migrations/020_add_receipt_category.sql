@@ -0,0 +1,5 @@
+-- Add an optional receipt-level category for users who categorize whole receipts
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+
+-- Add comments to explain the column
+COMMENT ON COLUMN receipts.category IS 'Receipt-level category, inherited in insights by items without their own category';
@@ -0,0 +1,5 @@
+-- Keep the time of purchase for receipts whose date includes a time of day
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS purchased_at TIMESTAMP WITH TIME ZONE;
+
+-- Add comments to explain the column
+COMMENT ON COLUMN receipts.purchased_at IS 'Time of purchase when the receipt shows one, NULL for receipts with only a date';
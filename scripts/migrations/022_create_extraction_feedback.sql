@@ -0,0 +1,20 @@
+-- Create extraction_feedback table recording whether users corrected scanned fields, with no user data
+CREATE TABLE IF NOT EXISTS extraction_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    field VARCHAR(20) NOT NULL,
+    corrected BOOLEAN NOT NULL,
+    extraction_method VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for aggregating accuracy by field
+CREATE INDEX IF NOT EXISTS idx_extraction_feedback_field ON extraction_feedback(field, extraction_method);
+
+-- Let users opt in to sharing extraction feedback
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS share_extraction_feedback BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Add comments to explain the table
+COMMENT ON TABLE extraction_feedback IS 'Anonymous record of whether a scanned field was corrected on the first edit of the receipt; not linked to users or receipts';
+COMMENT ON COLUMN extraction_feedback.field IS 'Scanned field: merchant, date, total or items';
+COMMENT ON COLUMN extraction_feedback.extraction_method IS 'Extractor that produced the scanned value, e.g. mlx or openrouter';
+COMMENT ON COLUMN user_preferences.share_extraction_feedback IS 'Whether the user consents to anonymous extraction feedback being recorded';
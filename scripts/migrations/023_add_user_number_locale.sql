@@ -0,0 +1,4 @@
+-- Let users choose how amounts are formatted for display
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS number_locale VARCHAR(10) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN user_preferences.number_locale IS 'Locale whose digit grouping and decimal mark display amounts, e.g. de-DE; empty leaves amounts unformatted';
@@ -0,0 +1,8 @@
+-- Keep the seller's tax or business registration number printed on business receipts
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS tax_id TEXT;
+
+-- Listings filter a user's receipts by tax ID
+CREATE INDEX IF NOT EXISTS idx_receipts_user_tax_id ON receipts (user_id, tax_id) WHERE tax_id IS NOT NULL;
+
+-- Add comments to explain the column
+COMMENT ON COLUMN receipts.tax_id IS 'Seller tax or registration number (e.g. NPWP, VAT ID) as printed, NULL when the receipt shows none';
@@ -0,0 +1,2 @@
+-- Incremental sync lists a user's receipts updated after their last sync, oldest change first
+CREATE INDEX IF NOT EXISTS idx_receipts_user_updated_at ON receipts (user_id, updated_at, id);